@@ -43,19 +43,23 @@ var (
 
 	pallasInitonce sync.Once
 	pallas         Curve
+
+	ristretto255Initonce sync.Once
+	ristretto255         Curve
 )
 
 const (
-	K256Name       = "secp256k1"
-	BLS12381G1Name = "BLS12381G1"
-	BLS12381G2Name = "BLS12381G2"
-	BLS12831Name   = "BLS12831"
-	P256Name       = "P-256"
-	ED25519Name    = "ed25519"
-	PallasName     = "pallas"
-	BLS12377G1Name = "BLS12377G1"
-	BLS12377G2Name = "BLS12377G2"
-	BLS12377Name   = "BLS12377"
+	K256Name         = "secp256k1"
+	BLS12381G1Name   = "BLS12381G1"
+	BLS12381G2Name   = "BLS12381G2"
+	BLS12831Name     = "BLS12831"
+	P256Name         = "P-256"
+	ED25519Name      = "ed25519"
+	PallasName       = "pallas"
+	BLS12377G1Name   = "BLS12377G1"
+	BLS12377G2Name   = "BLS12377G2"
+	BLS12377Name     = "BLS12377"
+	RISTRETTO255Name = "ristretto255"
 )
 
 const scalarBytes = 32
@@ -412,6 +416,8 @@ func (c Curve) ToEllipticCurve() (elliptic.Curve, error) {
 		return nil, err
 	case BLS12377Name:
 		return nil, err
+	case RISTRETTO255Name:
+		return nil, err
 	default:
 		return nil, err
 	}
@@ -478,6 +484,8 @@ func GetCurveByName(name string) *Curve {
 		return BLS12377G2()
 	case BLS12377Name:
 		return BLS12377G1()
+	case RISTRETTO255Name:
+		return RISTRETTO255()
 	default:
 		return nil
 	}
@@ -636,6 +644,21 @@ func pallasInit() {
 	}
 }
 
+// RISTRETTO255 returns the prime order ristretto255 group
+// built on top of curve25519
+func RISTRETTO255() *Curve {
+	ristretto255Initonce.Do(ristretto255Init)
+	return &ristretto255
+}
+
+func ristretto255Init() {
+	ristretto255 = Curve{
+		Scalar: new(ScalarRistretto255).Zero(),
+		Point:  new(PointRistretto255).Identity(),
+		Name:   RISTRETTO255Name,
+	}
+}
+
 // https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-11#appendix-G.2.1
 func osswu3mod4(u *big.Int, p *sswuParams) (x, y *big.Int) {
 	params := p.Params
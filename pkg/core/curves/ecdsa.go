@@ -8,16 +8,25 @@ package curves
 
 import (
 	"crypto/ecdsa"
+	"fmt"
 	"math/big"
 )
 
+// EthereumSignatureLength is the length of the r || s || v encoding of a signature used by Ethereum
+const EthereumSignatureLength = 65
+
+// ethereumRecoveryIdOffset is added to the recovery id in the Ethereum encoding
+const ethereumRecoveryIdOffset = 27
+
 // EcdsaVerify runs a curve- or algorithm-specific ECDSA verification function on input
 // an ECDSA public (verification) key, a message digest, and an ECDSA signature.
 // It must return true if all the parameters are sane and the ECDSA signature is valid,
 // and false otherwise
 type EcdsaVerify func(pubKey *EcPoint, hash []byte, signature *EcdsaSignature) bool
 
-// EcdsaSignature represents a (composite) digital signature
+// EcdsaSignature represents a (composite) digital signature.
+// V is the recovery id: bit 0 is the parity of the y coordinate of the nonce point R, bit 1 is set when
+// the x coordinate of R is not smaller than the group order. It refers to the signature after low-S normalization.
 type EcdsaSignature struct {
 	V    int
 	R, S *big.Int
@@ -36,3 +45,121 @@ func VerifyEcdsa(pk *EcPoint, hash []byte, sig *EcdsaSignature) bool {
 		},
 		hash, sig.R, sig.S)
 }
+
+// RecoverPublicKey returns the public key that produced sig on hash, using the recovery id sig.V.
+// It is the counterpart of VerifyEcdsa, and the recovered key verifies sig. Only K256 and P256 are supported.
+func RecoverPublicKey(curve *Curve, hash []byte, sig *EcdsaSignature) (Point, error) {
+	if curve == nil || sig == nil || sig.R == nil || sig.S == nil {
+		return nil, fmt.Errorf("invalid arguments")
+	}
+	if curve.Name != K256Name && curve.Name != P256Name {
+		return nil, fmt.Errorf("public key recovery is not supported for %s", curve.Name)
+	}
+	ellipticCurve, err := curve.ToEllipticCurve()
+	if err != nil {
+		return nil, err
+	}
+	params := ellipticCurve.Params()
+	n := params.N
+	if sig.R.Sign() <= 0 || sig.R.Cmp(n) >= 0 || sig.S.Sign() <= 0 || sig.S.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("signature is out of range")
+	}
+	if sig.V < 0 || sig.V > 3 {
+		return nil, fmt.Errorf("invalid recovery id %d", sig.V)
+	}
+
+	// R = (r + jn, y) with j = bit 1 of V and the parity of y bit 0 of V
+	x := new(big.Int).Set(sig.R)
+	if sig.V&2 != 0 {
+		x.Add(x, n)
+	}
+	if x.Cmp(params.P) >= 0 {
+		return nil, fmt.Errorf("invalid recovery id %d", sig.V)
+	}
+	compressed := make([]byte, 33)
+	compressed[0] = 2 | byte(sig.V&1)
+	x.FillBytes(compressed[1:])
+	// Compressed points decode to the identity when x is not the coordinate of a point
+	bigR, err := curve.Point.FromAffineCompressed(compressed)
+	if err != nil || bigR.IsIdentity() {
+		return nil, fmt.Errorf("r is not the x coordinate of a point")
+	}
+
+	// Q = r^-1 (sR - eG)
+	e, err := curve.Scalar.SetBigInt(hashToInt(hash, n))
+	if err != nil {
+		return nil, err
+	}
+	r, err := curve.Scalar.SetBigInt(sig.R)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := curve.Scalar.SetBigInt(sig.S)
+	if err != nil {
+		return nil, err
+	}
+	rInv, err := r.Invert()
+	if err != nil {
+		return nil, err
+	}
+	publicKey := bigR.Mul(sc).Sub(curve.ScalarBaseMult(e)).Mul(rInv)
+	if publicKey.IsIdentity() {
+		return nil, fmt.Errorf("recovered public key is the identity")
+	}
+	return publicKey, nil
+}
+
+// hashToInt converts a hash to an integer mod n, using the leftmost bits of the hash like crypto/ecdsa
+func hashToInt(hash []byte, n *big.Int) *big.Int {
+	orderBits := n.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(hash) > orderBytes {
+		hash = hash[:orderBytes]
+	}
+	ret := new(big.Int).SetBytes(hash)
+	excess := len(hash)*8 - orderBits
+	if excess > 0 {
+		ret.Rsh(ret, uint(excess))
+	}
+	return ret.Mod(ret, n)
+}
+
+// EthereumBytes encodes a K256 signature as the 65 bytes r || s || v used by Ethereum, where r and s are 32 byte big
+// endian integers and v = 27 + V. Ethereum requires a low S and a recovery id of 0 or 1.
+func (sig *EcdsaSignature) EthereumBytes() ([]byte, error) {
+	if sig == nil || sig.R == nil || sig.S == nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	n := K256Curve().Params().N
+	if sig.R.Sign() <= 0 || sig.R.Cmp(n) >= 0 || sig.S.Sign() <= 0 || sig.S.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		return nil, fmt.Errorf("signature must have a low s")
+	}
+	if sig.V != 0 && sig.V != 1 {
+		return nil, fmt.Errorf("invalid recovery id %d", sig.V)
+	}
+	out := make([]byte, EthereumSignatureLength)
+	sig.R.FillBytes(out[:32])
+	sig.S.FillBytes(out[32:64])
+	out[64] = byte(sig.V + ethereumRecoveryIdOffset)
+	return out, nil
+}
+
+// NewEcdsaSignatureFromEthereumBytes decodes the 65 bytes r || s || v used by Ethereum. Both v = 27 + V and
+// v = V are accepted.
+func NewEcdsaSignatureFromEthereumBytes(b []byte) (*EcdsaSignature, error) {
+	if len(b) != EthereumSignatureLength {
+		return nil, fmt.Errorf("signature must be %d bytes", EthereumSignatureLength)
+	}
+	v := int(b[64])
+	if v >= ethereumRecoveryIdOffset {
+		v -= ethereumRecoveryIdOffset
+	}
+	if v != 0 && v != 1 {
+		return nil, fmt.Errorf("invalid recovery id %d", b[64])
+	}
+	return &EcdsaSignature{
+		V: v,
+		R: new(big.Int).SetBytes(b[:32]),
+		S: new(big.Int).SetBytes(b[32:64]),
+	}, nil
+}
@@ -0,0 +1,136 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// signWithRecoveryId computes a low-S ecdsa signature and its recovery id from the nonce point
+func signWithRecoveryId(t *testing.T, curve *Curve, sk Scalar, hash []byte) *EcdsaSignature {
+	ellipticCurve, err := curve.ToEllipticCurve()
+	require.NoError(t, err)
+	n := ellipticCurve.Params().N
+
+	k := curve.Scalar.Random(crand.Reader)
+	compressed := curve.ScalarBaseMult(k).ToAffineCompressed()
+	x := new(big.Int).SetBytes(compressed[1:])
+	v := int(compressed[0] & 1)
+	if x.Cmp(n) >= 0 {
+		v |= 2
+	}
+	r, err := curve.Scalar.SetBigInt(x)
+	require.NoError(t, err)
+	e, err := curve.Scalar.SetBigInt(hashToInt(hash, n))
+	require.NoError(t, err)
+	kInv, err := k.Invert()
+	require.NoError(t, err)
+	s := e.Add(r.Mul(sk)).Mul(kInv)
+	if s.BigInt().Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		s = s.Neg()
+		v ^= 1
+	}
+	return &EcdsaSignature{V: v, R: r.BigInt(), S: s.BigInt()}
+}
+
+func TestRecoverPublicKey(t *testing.T) {
+	for _, curve := range []*Curve{K256(), P256()} {
+		ellipticCurve, err := curve.ToEllipticCurve()
+		require.NoError(t, err)
+		for i := 0; i < 32; i++ {
+			sk := curve.Scalar.Random(crand.Reader)
+			pk := curve.ScalarBaseMult(sk)
+			hash := sha256.Sum256([]byte{byte(i)})
+			sig := signWithRecoveryId(t, curve, sk, hash[:])
+
+			recovered, err := RecoverPublicKey(curve, hash[:], sig)
+			require.NoError(t, err)
+			require.True(t, pk.Equal(recovered))
+
+			ecPoint, err := PointFromBytesUncompressed(ellipticCurve, recovered.ToAffineUncompressed()[1:])
+			require.NoError(t, err)
+			require.True(t, VerifyEcdsa(ecPoint, hash[:], sig))
+
+			// The other recovery id for the same r gives a different key
+			flipped := &EcdsaSignature{V: sig.V ^ 1, R: sig.R, S: sig.S}
+			other, err := RecoverPublicKey(curve, hash[:], flipped)
+			require.NoError(t, err)
+			require.False(t, pk.Equal(other))
+		}
+	}
+}
+
+func TestRecoverPublicKeyInvalid(t *testing.T) {
+	curve := K256()
+	sk := curve.Scalar.Random(crand.Reader)
+	hash := sha256.Sum256([]byte("recover"))
+	sig := signWithRecoveryId(t, curve, sk, hash[:])
+	n := K256Curve().Params().N
+
+	for _, bad := range []*EcdsaSignature{
+		nil,
+		{V: sig.V, R: nil, S: sig.S},
+		{V: sig.V, R: big.NewInt(0), S: sig.S},
+		{V: sig.V, R: sig.R, S: big.NewInt(0)},
+		{V: sig.V, R: n, S: sig.S},
+		{V: sig.V, R: sig.R, S: n},
+		{V: 4, R: sig.R, S: sig.S},
+		{V: -1, R: sig.R, S: sig.S},
+	} {
+		_, err := RecoverPublicKey(curve, hash[:], bad)
+		require.Error(t, err)
+	}
+	_, err := RecoverPublicKey(ED25519(), hash[:], sig)
+	require.Error(t, err)
+	_, err = RecoverPublicKey(nil, hash[:], sig)
+	require.Error(t, err)
+}
+
+func TestEcdsaSignatureEthereumBytes(t *testing.T) {
+	curve := K256()
+	sk := curve.Scalar.Random(crand.Reader)
+	pk := curve.ScalarBaseMult(sk)
+	hash := sha256.Sum256([]byte("ethereum"))
+	sig := signWithRecoveryId(t, curve, sk, hash[:])
+
+	b, err := sig.EthereumBytes()
+	require.NoError(t, err)
+	require.Len(t, b, EthereumSignatureLength)
+	require.Equal(t, byte(27+sig.V), b[64])
+
+	decoded, err := NewEcdsaSignatureFromEthereumBytes(b)
+	require.NoError(t, err)
+	require.Equal(t, sig.V, decoded.V)
+	require.Equal(t, 0, sig.R.Cmp(decoded.R))
+	require.Equal(t, 0, sig.S.Cmp(decoded.S))
+	recovered, err := RecoverPublicKey(curve, hash[:], decoded)
+	require.NoError(t, err)
+	require.True(t, pk.Equal(recovered))
+
+	// v without the offset is accepted
+	b[64] -= 27
+	decoded, err = NewEcdsaSignatureFromEthereumBytes(b)
+	require.NoError(t, err)
+	require.Equal(t, sig.V, decoded.V)
+
+	b[64] = 29
+	_, err = NewEcdsaSignatureFromEthereumBytes(b)
+	require.Error(t, err)
+	_, err = NewEcdsaSignatureFromEthereumBytes(b[:64])
+	require.Error(t, err)
+
+	high := &EcdsaSignature{V: sig.V, R: sig.R, S: new(big.Int).Sub(K256Curve().Params().N, sig.S)}
+	_, err = high.EthereumBytes()
+	require.Error(t, err)
+	_, err = (&EcdsaSignature{V: 2, R: sig.R, S: sig.S}).EthereumBytes()
+	require.Error(t, err)
+}
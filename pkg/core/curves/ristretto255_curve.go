@@ -0,0 +1,571 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"io"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"github.com/bwesterb/go-ristretto"
+
+	"github.com/coinbase/kryptology/internal"
+)
+
+// ristretto255HashDst is the domain separation tag for hash_to_ristretto255
+// https://datatracker.ietf.org/doc/html/rfc9380#appendix-B
+const ristretto255HashDst = "ristretto255_XMD:SHA-512_R255MAP_RO_"
+
+// ScalarRistretto255 is an element of the prime order scalar field of ristretto255.
+// The field is the same as ed25519's.
+type ScalarRistretto255 struct {
+	value *edwards25519.Scalar
+}
+
+// PointRistretto255 is an element of the prime order ristretto255 group
+// as described in https://datatracker.ietf.org/doc/html/rfc9496
+type PointRistretto255 struct {
+	value *ristretto.Point
+}
+
+func (s *ScalarRistretto255) Random(reader io.Reader) Scalar {
+	if reader == nil {
+		return nil
+	}
+	var seed [64]byte
+	_, _ = reader.Read(seed[:])
+	return s.Hash(seed[:])
+}
+
+func (s *ScalarRistretto255) Hash(bytes []byte) Scalar {
+	v := new(ristretto.Scalar).Derive(bytes)
+	var data [32]byte
+	v.BytesInto(&data)
+	value, err := edwards25519.NewScalar().SetCanonicalBytes(data[:])
+	if err != nil {
+		return nil
+	}
+	return &ScalarRistretto255{value}
+}
+
+func (s *ScalarRistretto255) Zero() Scalar {
+	return &ScalarRistretto255{
+		value: edwards25519.NewScalar(),
+	}
+}
+
+func (s *ScalarRistretto255) One() Scalar {
+	return &ScalarRistretto255{
+		value: edwards25519.NewScalar().Set(scOne),
+	}
+}
+
+func (s *ScalarRistretto255) IsZero() bool {
+	i := byte(0)
+	for _, b := range s.value.Bytes() {
+		i |= b
+	}
+	return i == 0
+}
+
+func (s *ScalarRistretto255) IsOne() bool {
+	data := s.value.Bytes()
+	i := byte(0)
+	for j := 1; j < len(data); j++ {
+		i |= data[j]
+	}
+	return i == 0 && data[0] == 1
+}
+
+func (s *ScalarRistretto255) IsOdd() bool {
+	return s.value.Bytes()[0]&1 == 1
+}
+
+func (s *ScalarRistretto255) IsEven() bool {
+	return s.value.Bytes()[0]&1 == 0
+}
+
+func (s *ScalarRistretto255) New(input int) Scalar {
+	var data [64]byte
+	i := input
+	if input < 0 {
+		i = -input
+	}
+	data[0] = byte(i)
+	data[1] = byte(i >> 8)
+	data[2] = byte(i >> 16)
+	data[3] = byte(i >> 24)
+	value, err := edwards25519.NewScalar().SetUniformBytes(data[:])
+	if err != nil {
+		return nil
+	}
+	if input < 0 {
+		value.Negate(value)
+	}
+
+	return &ScalarRistretto255{
+		value,
+	}
+}
+
+func (s *ScalarRistretto255) Cmp(rhs Scalar) int {
+	r := s.Sub(rhs)
+	if r != nil && r.IsZero() {
+		return 0
+	} else {
+		return -2
+	}
+}
+
+func (s *ScalarRistretto255) Square() Scalar {
+	value := edwards25519.NewScalar().Multiply(s.value, s.value)
+	return &ScalarRistretto255{value}
+}
+
+func (s *ScalarRistretto255) Double() Scalar {
+	return &ScalarRistretto255{
+		value: edwards25519.NewScalar().Add(s.value, s.value),
+	}
+}
+
+func (s *ScalarRistretto255) Invert() (Scalar, error) {
+	return &ScalarRistretto255{
+		value: edwards25519.NewScalar().Invert(s.value),
+	}, nil
+}
+
+func (s *ScalarRistretto255) Sqrt() (Scalar, error) {
+	bi25519, _ := new(big.Int).SetString("1000000000000000000000000000000014DEF9DEA2F79CD65812631A5CF5D3ED", 16)
+	x := s.BigInt()
+	x.ModSqrt(x, bi25519)
+	return s.SetBigInt(x)
+}
+
+func (s *ScalarRistretto255) Cube() Scalar {
+	value := edwards25519.NewScalar().Multiply(s.value, s.value)
+	value.Multiply(value, s.value)
+	return &ScalarRistretto255{value}
+}
+
+func (s *ScalarRistretto255) Add(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarRistretto255)
+	if ok {
+		return &ScalarRistretto255{
+			value: edwards25519.NewScalar().Add(s.value, r.value),
+		}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarRistretto255) Sub(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarRistretto255)
+	if ok {
+		return &ScalarRistretto255{
+			value: edwards25519.NewScalar().Subtract(s.value, r.value),
+		}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarRistretto255) Mul(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarRistretto255)
+	if ok {
+		return &ScalarRistretto255{
+			value: edwards25519.NewScalar().Multiply(s.value, r.value),
+		}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarRistretto255) MulAdd(y, z Scalar) Scalar {
+	yy, ok := y.(*ScalarRistretto255)
+	if !ok {
+		return nil
+	}
+	zz, ok := z.(*ScalarRistretto255)
+	if !ok {
+		return nil
+	}
+	return &ScalarRistretto255{value: edwards25519.NewScalar().MultiplyAdd(s.value, yy.value, zz.value)}
+}
+
+func (s *ScalarRistretto255) Div(rhs Scalar) Scalar {
+	r, ok := rhs.(*ScalarRistretto255)
+	if ok {
+		value := edwards25519.NewScalar().Invert(r.value)
+		value.Multiply(value, s.value)
+		return &ScalarRistretto255{value}
+	} else {
+		return nil
+	}
+}
+
+func (s *ScalarRistretto255) Neg() Scalar {
+	return &ScalarRistretto255{
+		value: edwards25519.NewScalar().Negate(s.value),
+	}
+}
+
+func (s *ScalarRistretto255) SetBigInt(x *big.Int) (Scalar, error) {
+	if x == nil {
+		return nil, fmt.Errorf("invalid value")
+	}
+
+	bi25519, _ := new(big.Int).SetString("1000000000000000000000000000000014DEF9DEA2F79CD65812631A5CF5D3ED", 16)
+	var v big.Int
+	buf := v.Mod(x, bi25519).Bytes()
+	var rBuf [32]byte
+	for i := 0; i < len(buf) && i < 32; i++ {
+		rBuf[i] = buf[len(buf)-i-1]
+	}
+	value, err := edwards25519.NewScalar().SetCanonicalBytes(rBuf[:])
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarRistretto255{value}, nil
+}
+
+func (s *ScalarRistretto255) BigInt() *big.Int {
+	var ret big.Int
+	buf := internal.ReverseScalarBytes(s.value.Bytes())
+	return ret.SetBytes(buf)
+}
+
+func (s *ScalarRistretto255) Bytes() []byte {
+	return s.value.Bytes()
+}
+
+// SetBytes takes input a 32-byte long little-endian array and returns a ristretto255 scalar.
+// The input must be canonical, i.e. reduced modulo the group order.
+func (s *ScalarRistretto255) SetBytes(input []byte) (Scalar, error) {
+	if len(input) != 32 {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+	value, err := edwards25519.NewScalar().SetCanonicalBytes(input)
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarRistretto255{value}, nil
+}
+
+// SetBytesWide takes input a 64-byte long byte array, reduces it and returns a ristretto255 scalar.
+// If bytes is not of the right length, it returns nil and an error
+func (s *ScalarRistretto255) SetBytesWide(bytes []byte) (Scalar, error) {
+	value, err := edwards25519.NewScalar().SetUniformBytes(bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarRistretto255{value}, nil
+}
+
+func (s *ScalarRistretto255) Point() Point {
+	return new(PointRistretto255).Identity()
+}
+
+func (s *ScalarRistretto255) Clone() Scalar {
+	return &ScalarRistretto255{
+		value: edwards25519.NewScalar().Set(s.value),
+	}
+}
+
+func (s *ScalarRistretto255) MarshalBinary() ([]byte, error) {
+	return scalarMarshalBinary(s)
+}
+
+func (s *ScalarRistretto255) UnmarshalBinary(input []byte) error {
+	sc, err := scalarUnmarshalBinary(input)
+	if err != nil {
+		return err
+	}
+	ss, ok := sc.(*ScalarRistretto255)
+	if !ok {
+		return fmt.Errorf("invalid scalar")
+	}
+	s.value = ss.value
+	return nil
+}
+
+func (s *ScalarRistretto255) MarshalText() ([]byte, error) {
+	return scalarMarshalText(s)
+}
+
+func (s *ScalarRistretto255) UnmarshalText(input []byte) error {
+	sc, err := scalarUnmarshalText(input)
+	if err != nil {
+		return err
+	}
+	ss, ok := sc.(*ScalarRistretto255)
+	if !ok {
+		return fmt.Errorf("invalid scalar")
+	}
+	s.value = ss.value
+	return nil
+}
+
+func (s *ScalarRistretto255) MarshalJSON() ([]byte, error) {
+	return scalarMarshalJson(s)
+}
+
+func (s *ScalarRistretto255) UnmarshalJSON(input []byte) error {
+	sc, err := scalarUnmarshalJson(input)
+	if err != nil {
+		return err
+	}
+	S, ok := sc.(*ScalarRistretto255)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+	s.value = S.value
+	return nil
+}
+
+// toRistretto converts the scalar to the representation used by go-ristretto
+func (s *ScalarRistretto255) toRistretto() *ristretto.Scalar {
+	var data [32]byte
+	copy(data[:], s.value.Bytes())
+	return new(ristretto.Scalar).SetBytes(&data)
+}
+
+func (p *PointRistretto255) Random(reader io.Reader) Point {
+	var seed [64]byte
+	_, _ = reader.Read(seed[:])
+	return p.Hash(seed[:])
+}
+
+// Hash computes hash_to_ristretto255 with expand_message_xmd using SHA-512
+// https://datatracker.ietf.org/doc/html/rfc9380#appendix-B
+func (p *PointRistretto255) Hash(bytes []byte) Point {
+	uniform, err := expandMsgXmd(sha512.New(), bytes, []byte(ristretto255HashDst), 64)
+	if err != nil {
+		return nil
+	}
+	return p.oneWayMap(uniform)
+}
+
+// oneWayMap implements the element derivation function from 64 uniform bytes
+// https://datatracker.ietf.org/doc/html/rfc9496#section-4.3.4
+func (p *PointRistretto255) oneWayMap(uniform []byte) *PointRistretto255 {
+	var r0, r1 [32]byte
+	copy(r0[:], uniform[:32])
+	copy(r1[:], uniform[32:64])
+	// Mask the most significant bit as required by the specification
+	r0[31] &= 0x7F
+	r1[31] &= 0x7F
+	var p0, p1 ristretto.Point
+	p0.SetElligator(&r0)
+	p1.SetElligator(&r1)
+	return &PointRistretto255{value: new(ristretto.Point).Add(&p0, &p1)}
+}
+
+func (p *PointRistretto255) Identity() Point {
+	return &PointRistretto255{
+		value: new(ristretto.Point).SetZero(),
+	}
+}
+
+func (p *PointRistretto255) Generator() Point {
+	return &PointRistretto255{
+		value: new(ristretto.Point).SetBase(),
+	}
+}
+
+func (p *PointRistretto255) IsIdentity() bool {
+	return p.Equal(p.Identity())
+}
+
+func (p *PointRistretto255) IsNegative() bool {
+	// Negative points don't really exist in ristretto255
+	return false
+}
+
+func (p *PointRistretto255) IsOnCurve() bool {
+	// Every valid ristretto255 element is in the prime order group
+	return p.value != nil
+}
+
+func (p *PointRistretto255) Double() Point {
+	return &PointRistretto255{value: new(ristretto.Point).Double(p.value)}
+}
+
+func (p *PointRistretto255) Scalar() Scalar {
+	return new(ScalarRistretto255).Zero()
+}
+
+func (p *PointRistretto255) Neg() Point {
+	return &PointRistretto255{value: new(ristretto.Point).Neg(p.value)}
+}
+
+func (p *PointRistretto255) Add(rhs Point) Point {
+	if rhs == nil {
+		return nil
+	}
+	r, ok := rhs.(*PointRistretto255)
+	if ok {
+		return &PointRistretto255{value: new(ristretto.Point).Add(p.value, r.value)}
+	} else {
+		return nil
+	}
+}
+
+func (p *PointRistretto255) Sub(rhs Point) Point {
+	if rhs == nil {
+		return nil
+	}
+	r, ok := rhs.(*PointRistretto255)
+	if ok {
+		return &PointRistretto255{value: new(ristretto.Point).Sub(p.value, r.value)}
+	} else {
+		return nil
+	}
+}
+
+func (p *PointRistretto255) Mul(rhs Scalar) Point {
+	if rhs == nil {
+		return nil
+	}
+	r, ok := rhs.(*ScalarRistretto255)
+	if ok {
+		value := new(ristretto.Point).ScalarMult(p.value, r.toRistretto())
+		return &PointRistretto255{value}
+	} else {
+		return nil
+	}
+}
+
+func (p *PointRistretto255) Equal(rhs Point) bool {
+	r, ok := rhs.(*PointRistretto255)
+	if ok {
+		return p.value.Equals(r.value)
+	} else {
+		return false
+	}
+}
+
+// Set is not supported since ristretto255 elements do not
+// have a canonical affine representation except for the identity
+func (p *PointRistretto255) Set(x, y *big.Int) (Point, error) {
+	if x == nil || y == nil {
+		return nil, fmt.Errorf("invalid coordinates")
+	}
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return p.Identity(), nil
+	}
+	return nil, fmt.Errorf("ristretto255 does not support setting affine coordinates")
+}
+
+// ToAffineCompressed returns the canonical 32-byte ristretto255 encoding
+func (p *PointRistretto255) ToAffineCompressed() []byte {
+	var out [32]byte
+	p.value.BytesInto(&out)
+	return out[:]
+}
+
+// ToAffineUncompressed returns the canonical 32-byte ristretto255 encoding
+// as there is no separate uncompressed form
+func (p *PointRistretto255) ToAffineUncompressed() []byte {
+	return p.ToAffineCompressed()
+}
+
+// FromAffineCompressed decodes a canonical 32-byte ristretto255 encoding.
+// Non-canonical encodings are rejected.
+func (p *PointRistretto255) FromAffineCompressed(inBytes []byte) (Point, error) {
+	if len(inBytes) != 32 {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+	var data [32]byte
+	copy(data[:], inBytes)
+	value := new(ristretto.Point)
+	if !value.SetBytes(&data) {
+		return nil, fmt.Errorf("invalid ristretto255 encoding")
+	}
+	return &PointRistretto255{value}, nil
+}
+
+func (p *PointRistretto255) FromAffineUncompressed(inBytes []byte) (Point, error) {
+	return p.FromAffineCompressed(inBytes)
+}
+
+func (p *PointRistretto255) CurveName() string {
+	return RISTRETTO255Name
+}
+
+func (p *PointRistretto255) SumOfProducts(points []Point, scalars []Scalar) Point {
+	if len(points) != len(scalars) {
+		return nil
+	}
+	value := new(ristretto.Point).SetZero()
+	var tmp ristretto.Point
+	for i, pt := range points {
+		pp, ok := pt.(*PointRistretto255)
+		if !ok {
+			return nil
+		}
+		ss, ok := scalars[i].(*ScalarRistretto255)
+		if !ok {
+			return nil
+		}
+		tmp.ScalarMult(pp.value, ss.toRistretto())
+		value.Add(value, &tmp)
+	}
+	return &PointRistretto255{value}
+}
+
+func (p *PointRistretto255) MarshalBinary() ([]byte, error) {
+	return pointMarshalBinary(p)
+}
+
+func (p *PointRistretto255) UnmarshalBinary(input []byte) error {
+	pt, err := pointUnmarshalBinary(input)
+	if err != nil {
+		return err
+	}
+	ppt, ok := pt.(*PointRistretto255)
+	if !ok {
+		return fmt.Errorf("invalid point")
+	}
+	p.value = ppt.value
+	return nil
+}
+
+func (p *PointRistretto255) MarshalText() ([]byte, error) {
+	return pointMarshalText(p)
+}
+
+func (p *PointRistretto255) UnmarshalText(input []byte) error {
+	pt, err := pointUnmarshalText(input)
+	if err != nil {
+		return err
+	}
+	ppt, ok := pt.(*PointRistretto255)
+	if !ok {
+		return fmt.Errorf("invalid point")
+	}
+	p.value = ppt.value
+	return nil
+}
+
+func (p *PointRistretto255) MarshalJSON() ([]byte, error) {
+	return pointMarshalJson(p)
+}
+
+func (p *PointRistretto255) UnmarshalJSON(input []byte) error {
+	pt, err := pointUnmarshalJson(input)
+	if err != nil {
+		return err
+	}
+	P, ok := pt.(*PointRistretto255)
+	if !ok {
+		return fmt.Errorf("invalid type")
+	}
+	p.value = P.value
+	return nil
+}
@@ -0,0 +1,239 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package curves
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScalarRistretto255Random(t *testing.T) {
+	ristretto255 := RISTRETTO255()
+	// Try 10 random values
+	for i := 0; i < 10; i++ {
+		sc := ristretto255.Scalar.Random(crand.Reader)
+		_, ok := sc.(*ScalarRistretto255)
+		require.True(t, ok)
+		require.True(t, !sc.IsZero())
+	}
+}
+
+func TestScalarRistretto255Hash(t *testing.T) {
+	var b [32]byte
+	sc := RISTRETTO255().Scalar.Hash(b[:])
+	_, ok := sc.(*ScalarRistretto255)
+	require.True(t, ok)
+	// The scalar field is identical to ed25519
+	expected := ED25519().Scalar.Hash(b[:])
+	require.Equal(t, expected.Bytes(), sc.Bytes())
+}
+
+func TestScalarRistretto255Arithmetic(t *testing.T) {
+	ristretto255 := RISTRETTO255()
+	three := ristretto255.Scalar.New(3)
+	nine := ristretto255.Scalar.New(9)
+	six := ristretto255.Scalar.New(6)
+	require.True(t, ristretto255.Scalar.Zero().IsZero())
+	require.True(t, ristretto255.Scalar.One().IsOne())
+	require.True(t, three.IsOdd())
+	require.True(t, six.IsEven())
+	require.Equal(t, three.Square().Cmp(nine), 0)
+	require.Equal(t, three.Cube().Cmp(ristretto255.Scalar.New(27)), 0)
+	require.Equal(t, three.Double().Cmp(six), 0)
+	require.Equal(t, nine.Add(six).Cmp(ristretto255.Scalar.New(15)), 0)
+	require.Equal(t, six.Sub(nine).Cmp(ristretto255.Scalar.New(-3)), 0)
+	require.Equal(t, nine.Mul(six).Cmp(ristretto255.Scalar.New(54)), 0)
+	require.Equal(t, ristretto255.Scalar.New(54).Div(nine).Cmp(six), 0)
+	require.Equal(t, three.MulAdd(three, six).Cmp(ristretto255.Scalar.New(15)), 0)
+	require.Equal(t, ristretto255.Scalar.One().Neg().Cmp(ristretto255.Scalar.New(-1)), 0)
+	inv, err := nine.Invert()
+	require.NoError(t, err)
+	require.True(t, inv.Mul(nine).IsOne())
+	sqrt, err := nine.Sqrt()
+	require.NoError(t, err)
+	require.Equal(t, sqrt.Square().Cmp(nine), 0)
+	// Different curves must not mix
+	require.Nil(t, three.Add(ED25519().Scalar.New(3)))
+	require.Equal(t, three.Cmp(ED25519().Scalar.New(3)), -2)
+}
+
+func TestScalarRistretto255Serialize(t *testing.T) {
+	ristretto255 := RISTRETTO255()
+	sc := ristretto255.Scalar.New(255)
+	sequence := sc.Bytes()
+	require.Equal(t, len(sequence), 32)
+	ret, err := ristretto255.Scalar.SetBytes(sequence)
+	require.NoError(t, err)
+	require.Equal(t, ret.Cmp(sc), 0)
+
+	bi, err := ristretto255.Scalar.SetBigInt(big.NewInt(255))
+	require.NoError(t, err)
+	require.Equal(t, bi.Cmp(sc), 0)
+	require.Equal(t, sc.BigInt().Int64(), int64(255))
+
+	data, err := sc.(*ScalarRistretto255).MarshalBinary()
+	require.NoError(t, err)
+	rb := new(ScalarRistretto255)
+	require.NoError(t, rb.UnmarshalBinary(data))
+	require.Equal(t, rb.Cmp(sc), 0)
+
+	data, err = sc.(*ScalarRistretto255).MarshalText()
+	require.NoError(t, err)
+	rt := new(ScalarRistretto255)
+	require.NoError(t, rt.UnmarshalText(data))
+	require.Equal(t, rt.Cmp(sc), 0)
+
+	data, err = json.Marshal(sc)
+	require.NoError(t, err)
+	rj := new(ScalarRistretto255)
+	require.NoError(t, json.Unmarshal(data, rj))
+	require.Equal(t, rj.Cmp(sc), 0)
+}
+
+func TestPointRistretto255Generator(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc9496#appendix-A.1
+	multiples := []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"e2f2ae0a6abc4e71a884a961c500515f58e30b6aa582dd8db6a65945e08d2d76",
+		"6a493210f7499cd17fecb510ae0cea23a110e8d5b901f8acadd3095c73a3b919",
+		"94741f5d5d52755ece4f23f044ee27d5d1ea1e2bd196b462166b16152a9d0259",
+		"da80862773358b466ffadfe0b3293ab3d9fd53c5ea6c955358f568322daf6a57",
+	}
+	ristretto255 := RISTRETTO255()
+	g := ristretto255.Point.Generator()
+	for i, m := range multiples {
+		expected, _ := hex.DecodeString(m)
+		pt := g.Mul(ristretto255.Scalar.New(i))
+		require.Equal(t, expected, pt.ToAffineCompressed())
+		dec, err := ristretto255.Point.FromAffineCompressed(expected)
+		require.NoError(t, err)
+		require.True(t, dec.Equal(pt))
+	}
+	require.True(t, ristretto255.Point.Identity().IsIdentity())
+}
+
+func TestPointRistretto255InvalidEncodings(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc9496#appendix-A.2
+	invalid := []string{
+		// Non-canonical field encodings
+		"00ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+		// Negative field elements
+		"0100000000000000000000000000000000000000000000000000000000000000",
+		"01ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	}
+	for _, in := range invalid {
+		data, _ := hex.DecodeString(in)
+		_, err := RISTRETTO255().Point.FromAffineCompressed(data)
+		require.Error(t, err)
+	}
+	_, err := RISTRETTO255().Point.FromAffineCompressed([]byte{1, 2, 3})
+	require.Error(t, err)
+}
+
+func TestPointRistretto255OneWayMap(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc9496#appendix-A.3
+	in, _ := hex.DecodeString("5d1be09e3d0c82fc538112490e35701979d99e06ca3e2b5b54bffe8b4dc772c14d98b696a1bbfb5ca32c436cc61c16563790306c79eaca7705668b47dffe5bb6")
+	expected, _ := hex.DecodeString("3066f82a1a747d45120d1740f14358531a8f04bbffe6a819f86dfe50f44a0a46")
+	pt := new(PointRistretto255).oneWayMap(in)
+	require.Equal(t, expected, pt.ToAffineCompressed())
+}
+
+func TestPointRistretto255Hash(t *testing.T) {
+	var b [32]byte
+	ristretto255 := RISTRETTO255()
+	p1 := ristretto255.Point.Hash(b[:])
+	p2 := ristretto255.Point.Hash(b[:])
+	require.NotNil(t, p1)
+	require.True(t, p1.Equal(p2))
+	require.False(t, p1.IsIdentity())
+
+	// Fuzz test
+	for i := 0; i < 25; i++ {
+		_, _ = crand.Read(b[:])
+		pt := ristretto255.Point.Hash(b[:])
+		require.NotNil(t, pt)
+		_, err := ristretto255.Point.FromAffineCompressed(pt.ToAffineCompressed())
+		require.NoError(t, err)
+	}
+}
+
+func TestPointRistretto255Arithmetic(t *testing.T) {
+	ristretto255 := RISTRETTO255()
+	g := ristretto255.Point.Generator()
+	four := g.Mul(ristretto255.Scalar.New(4))
+	require.True(t, g.Double().Double().Equal(four))
+	require.True(t, g.Add(g).Add(g).Add(g).Equal(four))
+	require.True(t, four.Sub(g).Sub(g).Sub(g).Sub(g).IsIdentity())
+	require.True(t, g.Neg().Add(g).IsIdentity())
+	require.True(t, g.Mul(ristretto255.Scalar.New(-1)).Equal(g.Neg()))
+	require.Nil(t, g.Add(nil))
+	require.Nil(t, g.Sub(nil))
+	require.Nil(t, g.Mul(nil))
+	require.False(t, g.Equal(nil))
+	require.Nil(t, g.Add(ED25519().Point.Generator()))
+	require.Nil(t, g.Mul(ED25519().Scalar.One()))
+
+	iden, err := ristretto255.Point.Set(big.NewInt(0), big.NewInt(0))
+	require.NoError(t, err)
+	require.True(t, iden.IsIdentity())
+	_, err = ristretto255.Point.Set(big.NewInt(1), big.NewInt(2))
+	require.Error(t, err)
+}
+
+func TestPointRistretto255SumOfProducts(t *testing.T) {
+	lhs := new(PointRistretto255).Generator().Mul(new(ScalarRistretto255).New(50))
+	points := make([]Point, 5)
+	for i := range points {
+		points[i] = new(PointRistretto255).Generator()
+	}
+	scalars := []Scalar{
+		new(ScalarRistretto255).New(8),
+		new(ScalarRistretto255).New(9),
+		new(ScalarRistretto255).New(10),
+		new(ScalarRistretto255).New(11),
+		new(ScalarRistretto255).New(12),
+	}
+	rhs := lhs.SumOfProducts(points, scalars)
+	require.NotNil(t, rhs)
+	require.True(t, lhs.Equal(rhs))
+}
+
+func TestPointRistretto255Serialize(t *testing.T) {
+	ristretto255 := RISTRETTO255()
+	pt := ristretto255.Point.Random(crand.Reader)
+
+	data, err := pt.(*PointRistretto255).MarshalBinary()
+	require.NoError(t, err)
+	rb := new(PointRistretto255)
+	require.NoError(t, rb.UnmarshalBinary(data))
+	require.True(t, rb.Equal(pt))
+
+	data, err = pt.(*PointRistretto255).MarshalText()
+	require.NoError(t, err)
+	rt := new(PointRistretto255)
+	require.NoError(t, rt.UnmarshalText(data))
+	require.True(t, rt.Equal(pt))
+
+	data, err = json.Marshal(pt)
+	require.NoError(t, err)
+	rj := new(PointRistretto255)
+	require.NoError(t, json.Unmarshal(data, rj))
+	require.True(t, rj.Equal(pt))
+
+	un := pt.ToAffineUncompressed()
+	retU, err := pt.FromAffineUncompressed(un)
+	require.NoError(t, err)
+	require.True(t, pt.Equal(retU))
+
+	require.Equal(t, RISTRETTO255(), GetCurveByName(RISTRETTO255Name))
+}
@@ -0,0 +1,69 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package protocol
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A presignature signs at most one message, since signing two messages with the same nonce reveals the secret key.
+// Presignatures are meant to be stored until a message is to be signed, so a flag in the decoded presignature cannot
+// stop a second copy from signing. Instead, the signing functions of every protocol with presignatures record the id
+// of the presignature with a PresignatureGuard before signing, and refuse presignatures whose id was already recorded.
+// There is no default guard: MemoryPresignatureGuard only remembers the presignatures used by the process that holds
+// it, so a deployment where a presignature can be loaded by several processes must pass a guard backed by shared
+// storage.
+
+// ErrPresignatureUsed is returned when signing with a presignature that was already used.
+var ErrPresignatureUsed = fmt.Errorf("the presignature was already used")
+
+// ErrNilPresignatureGuard is returned when signing with a presignature without a PresignatureGuard.
+var ErrNilPresignatureGuard = fmt.Errorf("a presignature guard is required to sign with a presignature")
+
+// PresignatureGuard records the ids of the presignatures that were used to sign.
+type PresignatureGuard interface {
+	// MarkUsed records that the presignature with the given id is used to sign. It returns ErrPresignatureUsed if the
+	// id was already recorded. Guards shared between processes must check and record the id atomically.
+	MarkUsed(id []byte) error
+}
+
+// MemoryPresignatureGuard is a PresignatureGuard that records the used presignatures in memory.
+type MemoryPresignatureGuard struct {
+	mutex sync.Mutex
+	used  map[string]bool
+}
+
+// NewMemoryPresignatureGuard creates an empty MemoryPresignatureGuard.
+func NewMemoryPresignatureGuard() *MemoryPresignatureGuard {
+	return &MemoryPresignatureGuard{used: make(map[string]bool)}
+}
+
+// MarkUsed implements PresignatureGuard.
+func (g *MemoryPresignatureGuard) MarkUsed(id []byte) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.used[string(id)] {
+		return ErrPresignatureUsed
+	}
+	g.used[string(id)] = true
+	return nil
+}
+
+// MarkPresignatureUsed records the presignature id of the protocol protocolName with guard. It returns
+// ErrNilPresignatureGuard if guard is nil. The id recorded is protocolName, a zero byte and id, so that the ids of
+// different protocols, or of different parties of a protocol, do not collide.
+func MarkPresignatureUsed(guard PresignatureGuard, protocolName string, id []byte) error {
+	if guard == nil {
+		return ErrNilPresignatureGuard
+	}
+	key := make([]byte, 0, len(protocolName)+1+len(id))
+	key = append(key, protocolName...)
+	key = append(key, 0)
+	key = append(key, id...)
+	return guard.MarkUsed(key)
+}
@@ -25,6 +25,30 @@ const (
 	// Dkls18Refresh specifies the DKG protocol of the DKLs18 potocol.
 	Dkls18Refresh = "DKLs18-Refresh"
 
+	// Dkls19Dkg specifies the t-of-n DKG protocol of the DKLs19 protocol.
+	Dkls19Dkg = "DKLs19-DKG"
+
+	// Dkls19Sign specifies the t-of-n sign protocol of the DKLs19 protocol.
+	Dkls19Sign = "DKLs19-Sign"
+
+	// Dkls19Refresh specifies the t-of-n refresh protocol of the DKLs19 protocol.
+	Dkls19Refresh = "DKLs19-Refresh"
+
+	// Cggmp21KeyGen specifies the t-of-n key generation protocol of the CGGMP21 protocol.
+	Cggmp21KeyGen = "CGGMP21-KeyGen"
+
+	// Cggmp21Refresh specifies the auxiliary info and key refresh protocol of the CGGMP21 protocol.
+	Cggmp21Refresh = "CGGMP21-Refresh"
+
+	// Cggmp21Presign specifies the presigning protocol of the CGGMP21 protocol.
+	Cggmp21Presign = "CGGMP21-Presign"
+
+	// Cggmp21Sign specifies the one round signing protocol of the CGGMP21 protocol.
+	Cggmp21Sign = "CGGMP21-Sign"
+
+	// BroadcastKey is the key of a payload addressed to all other parties of a multi-party protocol.
+	BroadcastKey = "broadcast"
+
 	// versions will increment in 100 intervals, to leave room for adding other versions in between them if it is
 	// ever needed in the future.
 
@@ -33,6 +57,10 @@ const (
 
 	// Version1 is version 2!
 	Version1 = 200
+
+	// Version2 exchanges the same protocol messages as Version1; only the encoding of the payloads differs, which
+	// is deterministic and length-prefixed instead of gob.
+	Version2 = 300
 )
 
 // Message provides serializers and deserializer for the inputs and outputs of each step of the protocol.
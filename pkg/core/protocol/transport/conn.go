@@ -0,0 +1,51 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package transport
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// NewLoopback returns the two ends of a synchronous, in-memory connection.
+func NewLoopback() (net.Conn, net.Conn) {
+	return net.Pipe()
+}
+
+// NewLocalhost returns the two ends of a TCP connection on 127.0.0.1. The listener used to set it up is closed.
+func NewLocalhost() (net.Conn, net.Conn, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	defer listener.Close()
+
+	type accepted struct {
+		conn net.Conn
+		err  error
+	}
+	acceptCh := make(chan accepted, 1)
+	go func() {
+		conn, err := listener.Accept()
+		acceptCh <- accepted{conn, err}
+	}()
+
+	dialed, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		// Unblock the pending Accept
+		listener.Close()
+		<-acceptCh
+		return nil, nil, errors.WithStack(err)
+	}
+	a := <-acceptCh
+	if a.err != nil {
+		dialed.Close()
+		return nil, nil, errors.WithStack(a.err)
+	}
+	return dialed, a.conn, nil
+}
@@ -0,0 +1,209 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package transport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+)
+
+// A frame is a 4 byte big endian length followed by a body of that length:
+//
+//	body     = session kind content
+//	session  = bytes
+//	kind     = 1 byte, one of frameMessage, frameFinished, frameAbort
+//	content  = message for frameMessage, empty for frameFinished, bytes of the error text for frameAbort
+//	message  = 1 byte 0 for a nil message, or 1 followed by
+//	           protocol:bytes version:uint64 payloads:map metadata:map
+//	map      = count:uint32 followed by count key:bytes value:bytes entries, sorted by key
+//	bytes    = length:uint32 followed by length bytes
+//
+// All integers are big endian.
+const (
+	frameMessage  byte = 0
+	frameFinished byte = 1
+	frameAbort    byte = 2
+)
+
+type frame struct {
+	kind    byte
+	message *protocol.Message
+	abort   string
+}
+
+func appendBytes(buf *bytes.Buffer, b []byte) {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(b)))
+	buf.Write(length[:])
+	buf.Write(b)
+}
+
+func appendMap(buf *bytes.Buffer, m map[string][]byte) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var count [4]byte
+	binary.BigEndian.PutUint32(count[:], uint32(len(keys)))
+	buf.Write(count[:])
+	for _, key := range keys {
+		appendBytes(buf, []byte(key))
+		appendBytes(buf, m[key])
+	}
+}
+
+// writeFrame writes f with the session id sessionId to w in a single write
+func writeFrame(w io.Writer, sessionId []byte, f *frame) error {
+	body := new(bytes.Buffer)
+	appendBytes(body, sessionId)
+	body.WriteByte(f.kind)
+	switch f.kind {
+	case frameMessage:
+		if f.message == nil {
+			body.WriteByte(0)
+			break
+		}
+		body.WriteByte(1)
+		appendBytes(body, []byte(f.message.Protocol))
+		var version [8]byte
+		binary.BigEndian.PutUint64(version[:], uint64(f.message.Version))
+		body.Write(version[:])
+		appendMap(body, f.message.Payloads)
+		metadata := make(map[string][]byte, len(f.message.Metadata))
+		for key, value := range f.message.Metadata {
+			metadata[key] = []byte(value)
+		}
+		appendMap(body, metadata)
+	case frameAbort:
+		appendBytes(body, []byte(f.abort))
+	}
+	out := new(bytes.Buffer)
+	appendBytes(out, body.Bytes())
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// frameReader parses a frame body
+type frameReader struct {
+	data []byte
+	err  error
+}
+
+func (r *frameReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = fmt.Errorf("frame is too short")
+		return nil
+	}
+	out := r.data[:n]
+	r.data = r.data[n:]
+	return out
+}
+
+func (r *frameReader) uint32() uint32 {
+	b := r.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *frameReader) bytes() []byte {
+	n := r.uint32()
+	if uint64(n) > uint64(len(r.data)) {
+		r.next(-1)
+		return nil
+	}
+	return append([]byte{}, r.next(int(n))...)
+}
+
+func (r *frameReader) byte() byte {
+	b := r.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *frameReader) readMap() map[string][]byte {
+	n := r.uint32()
+	// Every entry takes at least 8 bytes
+	if uint64(n)*8 > uint64(len(r.data)) {
+		r.next(-1)
+		return nil
+	}
+	m := make(map[string][]byte, n)
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		key := string(r.bytes())
+		if _, ok := m[key]; ok && r.err == nil {
+			r.err = fmt.Errorf("duplicate key %s", key)
+		}
+		m[key] = r.bytes()
+	}
+	return m
+}
+
+// readFrame reads a frame of at most maxSize bytes from r and checks that it belongs to the session sessionId. Frames of
+// another session are returned with ErrSessionMismatch and only their kind set.
+func readFrame(r io.Reader, sessionId []byte, maxSize uint32) (*frame, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size > maxSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds the maximum of %d", size, maxSize)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	fr := &frameReader{data: body}
+	session := fr.bytes()
+	f := &frame{kind: fr.byte()}
+	if fr.err == nil && !bytes.Equal(session, sessionId) {
+		return f, ErrSessionMismatch
+	}
+	switch f.kind {
+	case frameMessage:
+		if fr.byte() == 1 {
+			f.message = &protocol.Message{Protocol: string(fr.bytes())}
+			if version := fr.next(8); version != nil {
+				f.message.Version = uint(binary.BigEndian.Uint64(version))
+			}
+			f.message.Payloads = fr.readMap()
+			metadata := fr.readMap()
+			f.message.Metadata = make(map[string]string, len(metadata))
+			for key, value := range metadata {
+				f.message.Metadata[key] = string(value)
+			}
+		}
+	case frameFinished:
+	case frameAbort:
+		f.abort = string(fr.bytes())
+	default:
+		if fr.err == nil {
+			fr.err = fmt.Errorf("unknown frame kind %d", f.kind)
+		}
+	}
+	if fr.err == nil && len(fr.data) != 0 {
+		fr.err = fmt.Errorf("%d trailing bytes in frame", len(fr.data))
+	}
+	if fr.err != nil {
+		return nil, fr.err
+	}
+	return f, nil
+}
@@ -0,0 +1,166 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package transport runs one side of a two party protocol.Iterator over a stream such as a net.Conn.
+//
+// The two parties take turns: each turn one party runs the next round of its iterator and sends the output to the
+// other, which uses it as the input of its own next round. Every frame carries a session id that the receiver checks,
+// and either party stops with an error as soon as a round fails, times out, the context is cancelled or the peer
+// reports an error. The run succeeds once both iterators returned protocol.ErrProtocolFinished.
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+)
+
+var (
+	ErrSessionMismatch = fmt.Errorf("frame belongs to another session")
+	ErrRoundTimeout    = fmt.Errorf("round timed out")
+	ErrPeerAborted     = fmt.Errorf("peer aborted the protocol")
+)
+
+// DefaultMaxFrameSize is the largest frame accepted when Config.MaxFrameSize is zero.
+const DefaultMaxFrameSize = 16 << 20
+
+// Config specifies a run of a protocol over a connection. Both parties must use the same session id, and exactly one
+// of them must be the initiator.
+type Config struct {
+	// SessionId identifies the run of the protocol. Frames with another session id are rejected.
+	SessionId []byte
+
+	// Initiator is set on the party whose iterator runs the first round, with a nil input.
+	Initiator bool
+
+	// RoundTimeout bounds the time to send or receive a single frame. Zero means no timeout.
+	RoundTimeout time.Duration
+
+	// MaxFrameSize bounds the size of received frames. Zero means DefaultMaxFrameSize.
+	MaxFrameSize uint32
+}
+
+// deadliner is implemented by connections, like net.Conn, whose blocked reads and writes can be interrupted.
+type deadliner interface {
+	SetDeadline(t time.Time) error
+}
+
+// Run drives iterator over conn until both parties have finished the protocol, and returns nil on success. The result
+// of the protocol is then available from iterator.Result. If the local iterator fails, the peer is notified before the
+// error is returned. On a timeout or cancellation, the connection is left in an unknown state and should be closed.
+func Run(ctx context.Context, conn io.ReadWriter, iterator protocol.Iterator, config *Config) error {
+	if conn == nil || iterator == nil || config == nil {
+		return fmt.Errorf("conn, iterator and config are required")
+	}
+	if len(config.SessionId) == 0 {
+		return fmt.Errorf("session id is required")
+	}
+	maxFrameSize := config.MaxFrameSize
+	if maxFrameSize == 0 {
+		maxFrameSize = DefaultMaxFrameSize
+	}
+
+	var (
+		input        *protocol.Message
+		finished     bool
+		peerFinished bool
+		myTurn       = config.Initiator
+		round        = 0
+	)
+	for {
+		if myTurn {
+			output, err := iterator.Next(input)
+			f := &frame{kind: frameMessage, message: output}
+			switch {
+			case err == protocol.ErrProtocolFinished:
+				finished = true
+				f = &frame{kind: frameFinished}
+			case err != nil:
+				sendAbort(ctx, conn, config, err)
+				return errors.Wrapf(err, "round %d", round)
+			}
+			err = exchange(ctx, conn, config.RoundTimeout, func() error {
+				return writeFrame(conn, config.SessionId, f)
+			})
+			if err != nil {
+				return errors.Wrapf(err, "sending round %d", round)
+			}
+		} else {
+			var f *frame
+			err := exchange(ctx, conn, config.RoundTimeout, func() error {
+				var err error
+				f, err = readFrame(conn, config.SessionId, maxFrameSize)
+				return err
+			})
+			if err != nil {
+				// Aborts are not answered, even those of another session
+				if err != ErrRoundTimeout && ctx.Err() == nil && (f == nil || f.kind != frameAbort) {
+					sendAbort(ctx, conn, config, err)
+				}
+				return errors.Wrapf(err, "receiving round %d", round)
+			}
+			input = nil
+			switch f.kind {
+			case frameMessage:
+				input = f.message
+			case frameFinished:
+				peerFinished = true
+			case frameAbort:
+				return errors.Wrapf(ErrPeerAborted, "round %d: %s", round, f.abort)
+			}
+		}
+		if finished && peerFinished {
+			return nil
+		}
+		myTurn = !myTurn
+		round++
+	}
+}
+
+// sendAbort tells the peer that the protocol failed with err. This is best effort, the local error is more relevant than
+// a failure to report it.
+func sendAbort(ctx context.Context, conn io.ReadWriter, config *Config, err error) {
+	_ = exchange(ctx, conn, config.RoundTimeout, func() error {
+		return writeFrame(conn, config.SessionId, &frame{kind: frameAbort, abort: err.Error()})
+	})
+}
+
+// exchange runs fn, which reads or writes a frame on conn, until it returns, ctx is done or timeout expires. Blocked
+// connections that implement deadliner are interrupted; other connections are abandoned with fn still running.
+func exchange(ctx context.Context, conn io.ReadWriter, timeout time.Duration, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+
+	var err error
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-expired:
+		err = ErrRoundTimeout
+	}
+	if d, ok := conn.(deadliner); ok {
+		_ = d.SetDeadline(time.Now())
+		<-done
+	}
+	return err
+}
@@ -0,0 +1,175 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package transport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	v1 "github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1"
+)
+
+var sessionId = []byte("test session")
+
+// runPair runs first as the initiator on a and second on b, and returns the errors of both
+func runPair(ctx context.Context, a, b net.Conn, first, second protocol.Iterator, secondSession []byte) (error, error) {
+	secondErr := make(chan error, 1)
+	go func() {
+		secondErr <- Run(ctx, b, second, &Config{SessionId: secondSession, RoundTimeout: 10 * time.Second})
+	}()
+	firstErr := Run(ctx, a, first, &Config{SessionId: sessionId, Initiator: true, RoundTimeout: 10 * time.Second})
+	return firstErr, <-secondErr
+}
+
+func TestRunDkls(t *testing.T) {
+	loopbackA, loopbackB := NewLoopback()
+	defer loopbackA.Close()
+	defer loopbackB.Close()
+	tcpA, tcpB, err := NewLocalhost()
+	require.NoError(t, err)
+	defer tcpA.Close()
+	defer tcpB.Close()
+
+	for _, conns := range [][2]net.Conn{{loopbackA, loopbackB}, {tcpA, tcpB}} {
+		curve := curves.K256()
+		alice := v1.NewAliceDkg(curve, protocol.Version2)
+		bob := v1.NewBobDkg(curve, protocol.Version2)
+		bobErr, aliceErr := runPair(context.Background(), conns[0], conns[1], bob, alice, sessionId)
+		require.NoError(t, bobErr)
+		require.NoError(t, aliceErr)
+		aliceResult, err := alice.Result(protocol.Version2)
+		require.NoError(t, err)
+		bobResult, err := bob.Result(protocol.Version2)
+		require.NoError(t, err)
+
+		// The same connections carry the next protocol
+		msg := []byte("signed over a connection")
+		aliceSign, err := v1.NewAliceSign(curve, sha3.New256(), msg, aliceResult, protocol.Version2)
+		require.NoError(t, err)
+		bobSign, err := v1.NewBobSign(curve, sha3.New256(), msg, bobResult, protocol.Version2)
+		require.NoError(t, err)
+		aliceErr, bobErr = runPair(context.Background(), conns[0], conns[1], aliceSign, bobSign, sessionId)
+		require.NoError(t, aliceErr)
+		require.NoError(t, bobErr)
+		require.NotNil(t, bobSign.Signature)
+	}
+}
+
+func TestRunSessionMismatch(t *testing.T) {
+	a, b := NewLoopback()
+	defer a.Close()
+	defer b.Close()
+	curve := curves.K256()
+	aliceErr, bobErr := runPair(context.Background(), a, b, v1.NewBobDkg(curve, protocol.Version2),
+		v1.NewAliceDkg(curve, protocol.Version2), []byte("another session"))
+	// The peer's abort is rejected too
+	require.ErrorIs(t, aliceErr, ErrSessionMismatch)
+	require.ErrorIs(t, bobErr, ErrSessionMismatch)
+}
+
+type failingIterator struct{}
+
+func (failingIterator) Next(*protocol.Message) (*protocol.Message, error) {
+	return nil, fmt.Errorf("bad round")
+}
+
+func (failingIterator) Result(uint) (*protocol.Message, error) { return nil, nil }
+
+func TestRunPeerAborted(t *testing.T) {
+	a, b := NewLoopback()
+	defer a.Close()
+	defer b.Close()
+	curve := curves.K256()
+	firstErr, secondErr := runPair(context.Background(), a, b, v1.NewBobDkg(curve, protocol.Version2), failingIterator{}, sessionId)
+	require.ErrorIs(t, firstErr, ErrPeerAborted)
+	require.Contains(t, firstErr.Error(), "bad round")
+	require.Error(t, secondErr)
+	require.NotErrorIs(t, secondErr, ErrPeerAborted)
+}
+
+func TestRunTimeout(t *testing.T) {
+	a, b := NewLoopback()
+	defer a.Close()
+	defer b.Close()
+	// Nobody runs on b, so the first write never completes
+	err := Run(context.Background(), a, v1.NewBobDkg(curves.K256(), protocol.Version2),
+		&Config{SessionId: sessionId, Initiator: true, RoundTimeout: 50 * time.Millisecond})
+	require.ErrorIs(t, err, ErrRoundTimeout)
+}
+
+func TestRunCancel(t *testing.T) {
+	a, b, err := NewLocalhost()
+	require.NoError(t, err)
+	defer a.Close()
+	defer b.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	// Nobody writes on b, so the first read never completes
+	err = Run(ctx, a, v1.NewAliceDkg(curves.K256(), protocol.Version2), &Config{SessionId: sessionId})
+	require.ErrorIs(t, err, context.Canceled)
+
+	err = Run(ctx, a, v1.NewAliceDkg(curves.K256(), protocol.Version2), &Config{SessionId: sessionId})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFrame(t *testing.T) {
+	message := &protocol.Message{
+		Protocol: protocol.Dkls18Dkg,
+		Version:  protocol.Version2,
+		Payloads: map[string][]byte{"b": {1, 2}, "a": {}},
+		Metadata: map[string]string{"round": "1"},
+	}
+	buf := new(bytes.Buffer)
+	require.NoError(t, writeFrame(buf, sessionId, &frame{kind: frameMessage, message: message}))
+	encoded := append([]byte{}, buf.Bytes()...)
+	f, err := readFrame(buf, sessionId, DefaultMaxFrameSize)
+	require.NoError(t, err)
+	require.Equal(t, frameMessage, f.kind)
+	require.Equal(t, message, f.message)
+
+	// Maps are written in key order
+	buf.Reset()
+	require.NoError(t, writeFrame(buf, sessionId, &frame{kind: frameMessage, message: message}))
+	require.Equal(t, encoded, buf.Bytes())
+
+	_, err = readFrame(bytes.NewReader(encoded), []byte("other"), DefaultMaxFrameSize)
+	require.ErrorIs(t, err, ErrSessionMismatch)
+	_, err = readFrame(bytes.NewReader(encoded), sessionId, uint32(len(encoded)-5))
+	require.Error(t, err)
+	_, err = readFrame(bytes.NewReader(encoded[:len(encoded)-1]), sessionId, DefaultMaxFrameSize)
+	require.Error(t, err)
+
+	// The length prefix must match the body
+	corrupted := append([]byte{}, encoded...)
+	corrupted[3]--
+	_, err = readFrame(bytes.NewReader(corrupted), sessionId, DefaultMaxFrameSize)
+	require.Error(t, err)
+
+	buf.Reset()
+	require.NoError(t, writeFrame(buf, sessionId, &frame{kind: frameMessage}))
+	f, err = readFrame(buf, sessionId, DefaultMaxFrameSize)
+	require.NoError(t, err)
+	require.Nil(t, f.message)
+
+	require.NoError(t, writeFrame(buf, sessionId, &frame{kind: frameAbort, abort: "reason"}))
+	f, err = readFrame(buf, sessionId, DefaultMaxFrameSize)
+	require.NoError(t, err)
+	require.Equal(t, frameAbort, f.kind)
+	require.Equal(t, "reason", f.abort)
+}
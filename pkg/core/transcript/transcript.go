@@ -0,0 +1,98 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package transcript wraps a merlin transcript so that its state can be serialized. The state of a merlin transcript
+// is opaque, but it is a deterministic function of the operations applied to it. The transcript records these
+// operations, and restoring it replays them on a fresh merlin transcript.
+package transcript
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/gtank/merlin"
+	"github.com/pkg/errors"
+)
+
+// maxExtractLength bounds the length of the extractions replayed by UnmarshalBinary.
+const maxExtractLength = 1 << 16
+
+// Transcript is a merlin transcript that records the operations applied to it.
+type Transcript struct {
+	merlin     *merlin.Transcript
+	appLabel   string
+	operations []operation
+}
+
+// operation is an AppendMessage if Extract is false, and an ExtractBytes of Length bytes otherwise.
+type operation struct {
+	Extract bool
+	Label   []byte
+	Message []byte
+	Length  int
+}
+
+type transcriptState struct {
+	AppLabel   string
+	Operations []operation
+}
+
+// NewTranscript creates a new transcript with the supplied application label, as merlin.NewTranscript does.
+func NewTranscript(appLabel string) *Transcript {
+	return &Transcript{
+		merlin:   merlin.NewTranscript(appLabel),
+		appLabel: appLabel,
+	}
+}
+
+// AppendMessage adds the message to the transcript with the supplied label.
+func (t *Transcript) AppendMessage(label, message []byte) {
+	t.operations = append(t.operations, operation{
+		Label:   append([]byte{}, label...),
+		Message: append([]byte{}, message...),
+	})
+	t.merlin.AppendMessage(label, message)
+}
+
+// ExtractBytes returns outLen bytes that depend on the whole transcript, and appends the label to the transcript.
+func (t *Transcript) ExtractBytes(label []byte, outLen int) []byte {
+	t.operations = append(t.operations, operation{
+		Extract: true,
+		Label:   append([]byte{}, label...),
+		Length:  outLen,
+	})
+	return t.merlin.ExtractBytes(label, outLen)
+}
+
+// MarshalBinary serializes the operations applied to the transcript.
+func (t *Transcript) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(&transcriptState{AppLabel: t.appLabel, Operations: t.operations}); err != nil {
+		return nil, errors.Wrap(err, "encoding transcript")
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the transcript by replaying the serialized operations.
+func (t *Transcript) UnmarshalBinary(data []byte) error {
+	state := new(transcriptState)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(state); err != nil {
+		return errors.Wrap(err, "decoding transcript")
+	}
+	restored := NewTranscript(state.AppLabel)
+	for _, op := range state.Operations {
+		if op.Extract {
+			if op.Length < 0 || op.Length > maxExtractLength {
+				return errors.Errorf("invalid extraction length %d in transcript", op.Length)
+			}
+			restored.ExtractBytes(op.Label, op.Length)
+		} else {
+			restored.AppendMessage(op.Label, op.Message)
+		}
+	}
+	*t = *restored
+	return nil
+}
@@ -0,0 +1,39 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package transcript
+
+import (
+	"testing"
+
+	"github.com/gtank/merlin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptRestore(t *testing.T) {
+	reference := merlin.NewTranscript("test")
+	transcript := NewTranscript("test")
+	for _, tr := range []interface {
+		AppendMessage(label, message []byte)
+		ExtractBytes(label []byte, outLen int) []byte
+	}{reference, transcript} {
+		tr.AppendMessage([]byte("first"), []byte{1, 2, 3})
+		tr.ExtractBytes([]byte("challenge"), 32)
+		tr.AppendMessage([]byte("second"), nil)
+	}
+
+	data, err := transcript.MarshalBinary()
+	require.NoError(t, err)
+	restored := new(Transcript)
+	require.NoError(t, restored.UnmarshalBinary(data))
+
+	// The restored transcript continues like the original one
+	expected := reference.ExtractBytes([]byte("next"), 64)
+	require.Equal(t, expected, restored.ExtractBytes([]byte("next"), 64))
+	require.Equal(t, expected, transcript.ExtractBytes([]byte("next"), 64))
+
+	require.Error(t, restored.UnmarshalBinary(data[:len(data)-1]))
+}
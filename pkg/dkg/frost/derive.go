@@ -0,0 +1,143 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/coinbase/kryptology/internal"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/sharing"
+)
+
+const (
+	// ChainCodeSize is the size, in bytes, of a chain code
+	ChainCodeSize = 32
+	// HardenedKeyStart is the first hardened child index. Hardened children need
+	// the group secret and cannot be derived by share holders locally.
+	HardenedKeyStart = uint32(0x80000000)
+)
+
+// ChildTweak computes the public tweak t and chain code of the non-hardened child index
+// of the ed25519 key verificationKey as in BIP32-Ed25519
+//
+//	Z = HMAC-SHA512(chainCode, 0x02 || A || index)
+//	t = 8 * int(Z[:28])
+//	chainCode' = HMAC-SHA512(chainCode, 0x03 || A || index)[32:]
+//
+// where A is the encoded key and index is 4 bytes little-endian. The child key is A + t*G.
+func ChildTweak(verificationKey curves.Point, chainCode []byte, index uint32) (curves.Scalar, []byte, error) {
+	if verificationKey == nil {
+		return nil, nil, internal.ErrNilArguments
+	}
+	if verificationKey.CurveName() != curves.ED25519Name {
+		return nil, nil, fmt.Errorf("key derivation requires ed25519")
+	}
+	if len(chainCode) != ChainCodeSize {
+		return nil, nil, fmt.Errorf("chain code must be %d bytes", ChainCodeSize)
+	}
+	if index >= HardenedKeyStart {
+		return nil, nil, fmt.Errorf("hardened derivation is not supported for threshold keys")
+	}
+	var data []byte
+	data = append(data, 0x02)
+	data = append(data, verificationKey.ToAffineCompressed()...)
+	var indexBytes [4]byte
+	binary.LittleEndian.PutUint32(indexBytes[:], index)
+	data = append(data, indexBytes[:]...)
+	z := hmacSha512(chainCode, data)
+
+	// 8 * int(ZL) < 2^227 so it is already reduced modulo the group order
+	var zl [32]byte
+	copy(zl[:28], z[:28])
+	curve := curves.ED25519()
+	t, err := curve.Scalar.SetBytes(zl[:])
+	if err != nil {
+		return nil, nil, err
+	}
+	t = t.Mul(curve.Scalar.New(8))
+
+	data[0] = 0x03
+	childChainCode := hmacSha512(chainCode, data)[32:]
+	return t, childChainCode, nil
+}
+
+// DeriveVerificationKey derives the non-hardened child index of the group verification key.
+// It needs no secret material, so it can be used to watch derived accounts.
+func DeriveVerificationKey(verificationKey curves.Point, chainCode []byte, index uint32) (curves.Point, []byte, error) {
+	t, childChainCode, err := ChildTweak(verificationKey, chainCode, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	child := verificationKey.Add(curves.ED25519().ScalarBaseMult(t))
+	if child.IsIdentity() {
+		return nil, nil, fmt.Errorf("invalid child key")
+	}
+	return child, childChainCode, nil
+}
+
+// DeriveChild returns a participant holding the shares of the non-hardened child index
+// of the group key, and the child chain code. Every share holder derives locally and
+// without interaction. The Lagrange coefficients of any signing set sum to one,
+// so adding the tweak t to each share adds t to the group secret.
+// The result can be used to create FROST signers as usual.
+func (dp *DkgParticipant) DeriveChild(chainCode []byte, index uint32) (*DkgParticipant, []byte, error) {
+	if dp == nil || dp.Curve == nil {
+		return nil, nil, internal.ErrNilArguments
+	}
+	if dp.SkShare == nil || dp.VkShare == nil || dp.VerificationKey == nil {
+		return nil, nil, fmt.Errorf("dkg has not completed")
+	}
+	t, childChainCode, err := ChildTweak(dp.VerificationKey, chainCode, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	tG := dp.Curve.ScalarBaseMult(t)
+	vk := dp.VerificationKey.Add(tG)
+	if vk.IsIdentity() {
+		return nil, nil, fmt.Errorf("invalid child key")
+	}
+	child := &DkgParticipant{
+		round:           dp.round,
+		Curve:           dp.Curve,
+		Id:              dp.Id,
+		SkShare:         dp.SkShare.Add(t),
+		VerificationKey: vk,
+		VkShare:         dp.VkShare.Add(tG),
+		ctx:             dp.ctx,
+	}
+	// Adding t to every share adds tG to the constant term of the joint commitments
+	if dp.jointVerifier != nil {
+		commitments := make([]curves.Point, len(dp.jointVerifier.Commitments))
+		copy(commitments, dp.jointVerifier.Commitments)
+		commitments[0] = vk
+		child.jointVerifier = &sharing.FeldmanVerifier{Commitments: commitments}
+	}
+	return child, childChainCode, nil
+}
+
+// DerivePath applies DeriveChild for each index of path in order
+func (dp *DkgParticipant) DerivePath(chainCode []byte, path []uint32) (*DkgParticipant, []byte, error) {
+	child := dp
+	for _, index := range path {
+		var err error
+		child, chainCode, err = child.DeriveChild(chainCode, index)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return child, chainCode, nil
+}
+
+func hmacSha512(key, data []byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	_, _ = mac.Write(data)
+	return mac.Sum(nil)
+}
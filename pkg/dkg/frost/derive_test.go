@@ -0,0 +1,93 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/sharing"
+)
+
+func runTwoPartyDkg(t *testing.T, curve *curves.Curve) (*DkgParticipant, *DkgParticipant) {
+	p1, err := NewDkgParticipant(1, 2, Ctx, curve, 2)
+	require.NoError(t, err)
+	p2, err := NewDkgParticipant(2, 2, Ctx, curve, 1)
+	require.NoError(t, err)
+	bcast1, p2psend1, err := p1.Round1(nil)
+	require.NoError(t, err)
+	bcast2, p2psend2, err := p2.Round1(nil)
+	require.NoError(t, err)
+	bcast := map[uint32]*Round1Bcast{1: bcast1, 2: bcast2}
+	_, err = p1.Round2(bcast, map[uint32]*sharing.ShamirShare{2: p2psend2[1]})
+	require.NoError(t, err)
+	_, err = p2.Round2(bcast, map[uint32]*sharing.ShamirShare{1: p2psend1[2]})
+	require.NoError(t, err)
+	return p1, p2
+}
+
+func TestDeriveChildWorks(t *testing.T) {
+	p1, p2 := runTwoPartyDkg(t, testCurve)
+	chainCode := make([]byte, ChainCodeSize)
+	chainCode[0] = 1
+	path := []uint32{44, 501, 7}
+
+	c1, cc1, err := p1.DerivePath(chainCode, path)
+	require.NoError(t, err)
+	c2, cc2, err := p2.DerivePath(chainCode, path)
+	require.NoError(t, err)
+	require.Equal(t, cc1, cc2)
+	require.True(t, c1.VerificationKey.Equal(c2.VerificationKey))
+	require.False(t, c1.VerificationKey.Equal(p1.VerificationKey))
+	require.True(t, c1.VkShare.Equal(testCurve.ScalarBaseMult(c1.SkShare)))
+
+	// The derived shares reconstruct the secret of the derived verification key
+	s, err := sharing.NewShamir(2, 2, testCurve)
+	require.NoError(t, err)
+	sk, err := s.Combine(&sharing.ShamirShare{Id: c1.Id, Value: c1.SkShare.Bytes()},
+		&sharing.ShamirShare{Id: c2.Id, Value: c2.SkShare.Bytes()})
+	require.NoError(t, err)
+	require.True(t, testCurve.ScalarBaseMult(sk).Equal(c1.VerificationKey))
+
+	// Public derivation agrees with the share holders
+	vk, cc := p1.VerificationKey, chainCode
+	for _, index := range path {
+		vk, cc, err = DeriveVerificationKey(vk, cc, index)
+		require.NoError(t, err)
+	}
+	require.True(t, vk.Equal(c1.VerificationKey))
+	require.Equal(t, cc1, cc)
+
+	// Siblings are distinct
+	sibling, _, err := p1.DeriveChild(chainCode, 45)
+	require.NoError(t, err)
+	child, _, err := p1.DeriveChild(chainCode, 44)
+	require.NoError(t, err)
+	require.False(t, sibling.VerificationKey.Equal(child.VerificationKey))
+}
+
+func TestDeriveChildBadInput(t *testing.T) {
+	p1, _ := runTwoPartyDkg(t, testCurve)
+	chainCode := make([]byte, ChainCodeSize)
+	_, _, err := p1.DeriveChild(chainCode, HardenedKeyStart)
+	require.Error(t, err)
+	_, _, err = p1.DeriveChild(chainCode[:31], 0)
+	require.Error(t, err)
+
+	// The DKG must have completed
+	p, err := NewDkgParticipant(1, 2, Ctx, testCurve, 2)
+	require.NoError(t, err)
+	_, _, err = p.DeriveChild(chainCode, 0)
+	require.Error(t, err)
+
+	// Only ed25519 keys are supported
+	k1, _ := runTwoPartyDkg(t, curves.K256())
+	_, _, err = k1.DeriveChild(chainCode, 0)
+	require.Error(t, err)
+}
@@ -9,6 +9,7 @@ package frost
 import (
 	"bytes"
 	crand "crypto/rand"
+	"encoding/binary"
 	"encoding/gob"
 	"fmt"
 	"reflect"
@@ -110,17 +111,8 @@ func (dp *DkgParticipant) Round1(secret []byte) (*Round1Bcast, Round1P2PSend, er
 	Ri := dp.Curve.ScalarBaseMult(ki)
 
 	// Step 4 - Compute Ci = H(i, CTX, g^{a_(i,0)}, R_i), where CTX is fixed context string
-	var msg []byte
-	// Append participant id
-	msg = append(msg, byte(dp.Id))
-	// Append CTX
-	msg = append(msg, dp.ctx)
-	// Append a_{i,0}*G
-	msg = append(msg, verifiers.Commitments[0].ToAffineCompressed()...)
-	// Append Ri
-	msg = append(msg, Ri.ToAffineCompressed()...)
 	// Hash the message and get Ci
-	ci := dp.Curve.Scalar.Hash(msg)
+	ci := dp.Curve.Scalar.Hash(dp.challengeMessage(dp.Id, verifiers.Commitments[0], Ri))
 
 	// Step 5 - Compute Wi = ki+a_{i,0}*c_i mod q. Note that a_{i,0} is the secret.
 	// Note: We have to compute scalar in the following way when using ed25519 curve, rather than scalar := dp.Scalar.Mul(s, Ci)
@@ -146,3 +138,20 @@ func (dp *DkgParticipant) Round1(secret []byte) (*Round1Bcast, Round1P2PSend, er
 	// return
 	return round1Bcast, p2pSend, nil
 }
+
+// challengeMessage encodes (i, CTX, A_{i,0}, R_i) for the proof of knowledge of a_{i,0}.
+// The id is encoded as 4 bytes and the whole context string is included so neither is truncated.
+func (dp *DkgParticipant) challengeMessage(id uint32, ai0, ri curves.Point) []byte {
+	var msg []byte
+	// Append participant id
+	var idBytes [4]byte
+	binary.BigEndian.PutUint32(idBytes[:], id)
+	msg = append(msg, idBytes[:]...)
+	// Append CTX
+	msg = append(msg, dp.ctx...)
+	// Append a_{i,0}*G
+	msg = append(msg, ai0.ToAffineCompressed()...)
+	// Append Ri
+	msg = append(msg, ri.ToAffineCompressed()...)
+	return msg
+}
@@ -90,17 +90,8 @@ func (dp *DkgParticipant) Round2(bcast map[uint32]*Round1Bcast, p2psend map[uint
 		}
 
 		prod := prod1.Add(prod2)
-		var msg []byte
-		// Append participant id
-		msg = append(msg, byte(id))
-		// Append CTX
-		msg = append(msg, dp.ctx)
-		// Append Aj0
-		msg = append(msg, Aj0.ToAffineCompressed()...)
-		// Append prod
-		msg = append(msg, prod.ToAffineCompressed()...)
 		// Hash the message and get cj
-		cj := dp.Curve.Scalar.Hash(msg)
+		cj := dp.Curve.Scalar.Hash(dp.challengeMessage(id, Aj0, prod))
 		// Check equation
 		if cj.Cmp(bcast[id].Ci) != 0 {
 			return nil, fmt.Errorf("Hash check fails for participant with id %d\n", id)
@@ -117,7 +108,6 @@ func (dp *DkgParticipant) Round2(bcast map[uint32]*Round1Bcast, p2psend map[uint
 	if err != nil {
 		return nil, err
 	}
-	vk := dp.verifiers.Commitments[0]
 	// Step 6 - Compute signing key share ski = \sum_{j=1}^n xji
 	for id := range bcast {
 		if id == dp.Id {
@@ -131,12 +121,21 @@ func (dp *DkgParticipant) Round2(bcast map[uint32]*Round1Bcast, p2psend map[uint
 	}
 
 	// Step 8 - Compute verification key vk = sum(A_{j,0}), j = 1,...,n
+	// The joint commitments are the sums of the commitments of all participants
+	joint := make([]curves.Point, len(dp.verifiers.Commitments))
+	copy(joint, dp.verifiers.Commitments)
 	for id := range bcast {
 		if id == dp.Id {
 			continue
 		}
-		vk = vk.Add(bcast[id].Verifiers.Commitments[0])
+		if len(bcast[id].Verifiers.Commitments) != len(joint) {
+			return nil, fmt.Errorf("invalid number of commitments from participant %d", id)
+		}
+		for k, c := range bcast[id].Verifiers.Commitments {
+			joint[k] = joint[k].Add(c)
+		}
 	}
+	vk := joint[0]
 
 	// Store signing key share
 	dp.SkShare = sk
@@ -147,6 +146,8 @@ func (dp *DkgParticipant) Round2(bcast map[uint32]*Round1Bcast, p2psend map[uint
 	// Store verification key
 	dp.VerificationKey = vk
 
+	dp.jointVerifier = &sharing.FeldmanVerifier{Commitments: joint}
+
 	// Update round number
 	dp.round = 3
 
@@ -7,6 +7,7 @@
 package frost
 
 import (
+	crand "crypto/rand"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -142,6 +143,22 @@ func TestDkgRound2BadInput(t *testing.T) {
 	require.Error(t, err)
 }
 
+// Participants must agree on the whole context string
+func TestDkgRound2ContextMismatch(t *testing.T) {
+	p1, err := NewDkgParticipant(1, 2, "context one", testCurve, 2)
+	require.NoError(t, err)
+	p2, err := NewDkgParticipant(2, 2, "context two", testCurve, 1)
+	require.NoError(t, err)
+	bcast1, _, err := p1.Round1(nil)
+	require.NoError(t, err)
+	bcast2, p2psend2, err := p2.Round1(nil)
+	require.NoError(t, err)
+	bcast := map[uint32]*Round1Bcast{1: bcast1, 2: bcast2}
+	p2p := map[uint32]*sharing.ShamirShare{2: p2psend2[1]}
+	_, err = p1.Round2(bcast, p2p)
+	require.Error(t, err)
+}
+
 // Test full round works
 func TestFullDkgRoundsWorks(t *testing.T) {
 	// Initiate two participants and running round 1
@@ -166,3 +183,136 @@ func TestFullDkgRoundsWorks(t *testing.T) {
 	vk := testCurve.ScalarBaseMult(sk)
 	require.True(t, vk.Equal(p1.VerificationKey))
 }
+
+func TestFullDkgRoundsRistretto255(t *testing.T) {
+	curve := curves.RISTRETTO255()
+	p1, err := NewDkgParticipant(1, 2, Ctx, curve, 2)
+	require.NoError(t, err)
+	p2, err := NewDkgParticipant(2, 2, Ctx, curve, 1)
+	require.NoError(t, err)
+	bcast1, p2psend1, err := p1.Round1(nil)
+	require.NoError(t, err)
+	bcast2, p2psend2, err := p2.Round1(nil)
+	require.NoError(t, err)
+	bcast := map[uint32]*Round1Bcast{1: bcast1, 2: bcast2}
+
+	round2Out1, err := p1.Round2(bcast, map[uint32]*sharing.ShamirShare{2: p2psend2[1]})
+	require.NoError(t, err)
+	round2Out2, err := p2.Round2(bcast, map[uint32]*sharing.ShamirShare{1: p2psend1[2]})
+	require.NoError(t, err)
+	require.True(t, round2Out1.VerificationKey.Equal(round2Out2.VerificationKey))
+
+	s, _ := sharing.NewShamir(2, 2, curve)
+	sk, err := s.Combine(&sharing.ShamirShare{Id: p1.Id, Value: p1.SkShare.Bytes()},
+		&sharing.ShamirShare{Id: p2.Id, Value: p2.SkShare.Bytes()})
+	require.NoError(t, err)
+	require.True(t, curve.ScalarBaseMult(sk).Equal(p1.VerificationKey))
+}
+
+func TestFeldmanOutputReshare(t *testing.T) {
+	p1, p2 := runTwoPartyDkg(t, testCurve)
+	share1, verifier, err := p1.FeldmanOutput()
+	require.NoError(t, err)
+	share2, verifier2, err := p2.FeldmanOutput()
+	require.NoError(t, err)
+	require.True(t, verifier.Commitments[0].Equal(p1.VerificationKey))
+	require.True(t, verifier.Commitments[1].Equal(verifier2.Commitments[1]))
+	require.NoError(t, verifier.Verify(share1))
+	require.NoError(t, verifier.Verify(share2))
+
+	// Move the key to a 2 of 3 committee
+	reshare, err := sharing.NewReshare(2, 3, testCurve)
+	require.NoError(t, err)
+	quorum := []uint32{1, 2}
+	verifiers := make(map[uint32]*sharing.FeldmanVerifier)
+	subShares := make(map[uint32][]*sharing.ShamirShare)
+	for _, share := range []*sharing.ShamirShare{share1, share2} {
+		verifiers[share.Id], subShares[share.Id], err = reshare.Split(verifier, share, quorum, crand.Reader)
+		require.NoError(t, err)
+	}
+	newShares := make([]*sharing.ShamirShare, 3)
+	for j := uint32(1); j <= 3; j++ {
+		var newVerifier *sharing.FeldmanVerifier
+		newShares[j-1], newVerifier, err = reshare.Combine(j, verifier, quorum, verifiers,
+			map[uint32]*sharing.ShamirShare{1: subShares[1][j-1], 2: subShares[2][j-1]})
+		require.NoError(t, err)
+		require.True(t, newVerifier.Commitments[0].Equal(p1.VerificationKey))
+	}
+	feldman, err := sharing.NewFeldman(2, 3, testCurve)
+	require.NoError(t, err)
+	vk, err := feldman.CombinePoints(newShares[0], newShares[2])
+	require.NoError(t, err)
+	require.True(t, vk.Equal(p1.VerificationKey))
+
+	// Derived keys keep matching commitments
+	child, _, err := p1.DeriveChild(make([]byte, ChainCodeSize), 1)
+	require.NoError(t, err)
+	childShare, childVerifier, err := child.FeldmanOutput()
+	require.NoError(t, err)
+	require.True(t, childVerifier.Commitments[0].Equal(child.VerificationKey))
+	require.NoError(t, childVerifier.Verify(childShare))
+
+	// The output is only available once the dkg completed
+	p3, err := NewDkgParticipant(1, 2, Ctx, testCurve, 2)
+	require.NoError(t, err)
+	_, _, err = p3.FeldmanOutput()
+	require.Error(t, err)
+}
+
+func TestFeldmanOutputRepair(t *testing.T) {
+	// Run a 2 of 3 dkg
+	ids := []uint32{1, 2, 3}
+	participants := make(map[uint32]*DkgParticipant, len(ids))
+	for _, id := range ids {
+		var others []uint32
+		for _, other := range ids {
+			if other != id {
+				others = append(others, other)
+			}
+		}
+		p, err := NewDkgParticipant(id, 2, Ctx, testCurve, others...)
+		require.NoError(t, err)
+		participants[id] = p
+	}
+	bcast := make(map[uint32]*Round1Bcast, len(ids))
+	p2p := make(map[uint32]Round1P2PSend, len(ids))
+	for id, p := range participants {
+		var err error
+		bcast[id], p2p[id], err = p.Round1(nil)
+		require.NoError(t, err)
+	}
+	for id, p := range participants {
+		received := make(map[uint32]*sharing.ShamirShare, len(ids)-1)
+		for sender, sent := range p2p {
+			if sender != id {
+				received[sender] = sent[id]
+			}
+		}
+		_, err := p.Round2(bcast, received)
+		require.NoError(t, err)
+	}
+
+	// Participants 1 and 3 repair the share of participant 2
+	repair, err := sharing.NewRepair(testCurve)
+	require.NoError(t, err)
+	helpers := []uint32{1, 3}
+	_, verifier, err := participants[1].FeldmanOutput()
+	require.NoError(t, err)
+	commitments := make(map[uint32]*sharing.RepairCommitments, len(helpers))
+	pieces := make(map[uint32]map[uint32]*sharing.ShamirShare, len(helpers))
+	for _, id := range helpers {
+		share, _, err := participants[id].FeldmanOutput()
+		require.NoError(t, err)
+		commitments[id], pieces[id], err = repair.Split(verifier, share, helpers, 2, crand.Reader)
+		require.NoError(t, err)
+	}
+	sums := make(map[uint32]*sharing.ShamirShare, len(helpers))
+	for _, id := range helpers {
+		sums[id], err = repair.Aggregate(id, verifier, helpers, 2, commitments,
+			map[uint32]*sharing.ShamirShare{1: pieces[1][id], 3: pieces[3][id]})
+		require.NoError(t, err)
+	}
+	repaired, err := repair.Combine(verifier, helpers, 2, commitments, sums)
+	require.NoError(t, err)
+	require.Equal(t, participants[2].SkShare.Bytes(), repaired.Value)
+}
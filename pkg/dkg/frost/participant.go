@@ -8,8 +8,6 @@
 package frost
 
 import (
-	"strconv"
-
 	"github.com/coinbase/kryptology/internal"
 	"github.com/coinbase/kryptology/pkg/core/curves"
 	"github.com/coinbase/kryptology/pkg/sharing"
@@ -25,8 +23,9 @@ type DkgParticipant struct {
 	VkShare                curves.Point
 	feldman                *sharing.Feldman
 	verifiers              *sharing.FeldmanVerifier
+	jointVerifier          *sharing.FeldmanVerifier
 	secretShares           []*sharing.ShamirShare
-	ctx                    byte
+	ctx                    []byte
 }
 
 type dkgParticipantData struct {
@@ -51,15 +50,27 @@ func NewDkgParticipant(id, threshold uint32, ctx string, curve *curves.Curve, ot
 		}
 	}
 
-	// SetBigInt the common fixed string
-	ctxV, _ := strconv.Atoi(ctx)
-
 	return &DkgParticipant{
 		Id:                     id,
 		round:                  1,
 		Curve:                  curve,
 		feldman:                feldman,
 		otherParticipantShares: otherParticipantShares,
-		ctx:                    byte(ctxV),
+		ctx:                    []byte(ctx),
 	}, nil
 }
+
+// FeldmanOutput returns the key share of the participant as a ShamirShare and the joint Feldman
+// commitments of the group, the sums of the commitments of all participants. They are the inputs
+// of sharing.Reshare to move the key to a new committee.
+func (dp *DkgParticipant) FeldmanOutput() (*sharing.ShamirShare, *sharing.FeldmanVerifier, error) {
+	if dp == nil || dp.Curve == nil {
+		return nil, nil, internal.ErrNilArguments
+	}
+	if dp.round != 3 || dp.SkShare == nil || dp.jointVerifier == nil {
+		return nil, nil, internal.ErrInvalidRound
+	}
+	commitments := make([]curves.Point, len(dp.jointVerifier.Commitments))
+	copy(commitments, dp.jointVerifier.Commitments)
+	return &sharing.ShamirShare{Id: dp.Id, Value: dp.SkShare.Bytes()}, &sharing.FeldmanVerifier{Commitments: commitments}, nil
+}
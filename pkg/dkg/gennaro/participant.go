@@ -13,6 +13,7 @@ import (
 
 	"github.com/coinbase/kryptology/internal"
 	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/sharing"
 	"github.com/coinbase/kryptology/pkg/sharing/v1"
 )
 
@@ -95,3 +96,47 @@ type dkgParticipantData struct {
 	Share     *v1.ShamirShare
 	Verifiers []*v1.ShareVerifier
 }
+
+// FeldmanOutput returns the secret key share of the participant as a sharing.ShamirShare and the
+// joint Feldman commitments of the group, the sums of the commitments of all participants.
+// They are the inputs of sharing.Reshare to move the key to a new committee.
+// Only curves known to curves.GetCurveByName are supported.
+func (dp *Participant) FeldmanOutput() (*sharing.ShamirShare, *sharing.FeldmanVerifier, error) {
+	if dp == nil || dp.curve == nil {
+		return nil, nil, internal.ErrNilArguments
+	}
+	if dp.round != 4 || dp.skShare == nil || dp.pedersenResult == nil {
+		return nil, nil, internal.ErrInvalidRound
+	}
+	curve := curves.GetCurveByName(dp.curve.Params().Name)
+	if curve == nil {
+		return nil, nil, fmt.Errorf("unsupported curve %s", dp.curve.Params().Name)
+	}
+
+	commitments := make([]curves.Point, len(dp.pedersenResult.Verifiers))
+	for k := range commitments {
+		commitments[k] = curve.NewIdentityPoint()
+	}
+	all := [][]*v1.ShareVerifier{dp.pedersenResult.Verifiers}
+	for _, other := range dp.otherParticipantShares {
+		all = append(all, other.Verifiers)
+	}
+	for _, verifiers := range all {
+		if len(verifiers) != len(commitments) {
+			return nil, nil, fmt.Errorf("invalid number of commitments")
+		}
+		for k, v := range verifiers {
+			point, err := curve.Point.Set(v.X, v.Y)
+			if err != nil {
+				return nil, nil, err
+			}
+			commitments[k] = commitments[k].Add(point)
+		}
+	}
+
+	value, err := curve.Scalar.SetBigInt(dp.skShare.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &sharing.ShamirShare{Id: dp.id, Value: value.Bytes()}, &sharing.FeldmanVerifier{Commitments: commitments}, nil
+}
@@ -7,6 +7,7 @@
 package gennaro
 
 import (
+	crand "crypto/rand"
 	"fmt"
 	"testing"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/sharing"
 	v1 "github.com/coinbase/kryptology/pkg/sharing/v1"
 )
 
@@ -379,3 +381,49 @@ func TestParticipantArbitraryIds(t *testing.T) {
 	_, err = NewParticipant(1, 2, testGenerator, curves.NewK256Scalar(), 4)
 	require.Error(t, err)
 }
+
+// Test the dkg output can be reshared to a new committee
+func TestParticipantFeldmanOutputReshare(t *testing.T) {
+	p1, p2, round3Input := PrepareRound3Input(t)
+	_, _, err := p1.FeldmanOutput()
+	require.Error(t, err)
+	_, _, err = p1.Round3(round3Input)
+	require.NoError(t, err)
+	_, _, err = p2.Round3(round3Input)
+	require.NoError(t, err)
+
+	share1, verifier, err := p1.FeldmanOutput()
+	require.NoError(t, err)
+	share2, _, err := p2.FeldmanOutput()
+	require.NoError(t, err)
+	curve := curves.K256()
+	pk, err := curve.Point.Set(p1.verificationKey.X, p1.verificationKey.Y)
+	require.NoError(t, err)
+	require.True(t, verifier.Commitments[0].Equal(pk))
+	require.NoError(t, verifier.Verify(share1))
+	require.NoError(t, verifier.Verify(share2))
+
+	// Move the key to a 3 of 4 committee
+	reshare, err := sharing.NewReshare(3, 4, curve)
+	require.NoError(t, err)
+	quorum := []uint32{1, 2}
+	verifiers := make(map[uint32]*sharing.FeldmanVerifier)
+	subShares := make(map[uint32][]*sharing.ShamirShare)
+	for _, share := range []*sharing.ShamirShare{share1, share2} {
+		verifiers[share.Id], subShares[share.Id], err = reshare.Split(verifier, share, quorum, crand.Reader)
+		require.NoError(t, err)
+	}
+	newShares := make([]*sharing.ShamirShare, 4)
+	for j := uint32(1); j <= 4; j++ {
+		var newVerifier *sharing.FeldmanVerifier
+		newShares[j-1], newVerifier, err = reshare.Combine(j, verifier, quorum, verifiers,
+			map[uint32]*sharing.ShamirShare{1: subShares[1][j-1], 2: subShares[2][j-1]})
+		require.NoError(t, err)
+		require.True(t, newVerifier.Commitments[0].Equal(pk))
+	}
+	feldman, err := sharing.NewFeldman(3, 4, curve)
+	require.NoError(t, err)
+	combined, err := feldman.CombinePoints(newShares[0], newShares[1], newShares[3])
+	require.NoError(t, err)
+	require.True(t, combined.Equal(pk))
+}
@@ -23,11 +23,11 @@ import (
 	"crypto/subtle"
 	"fmt"
 
-	"github.com/gtank/merlin"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
 
 	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/transcript"
 	"github.com/coinbase/kryptology/pkg/zkp/schnorr"
 )
 
@@ -98,7 +98,7 @@ type Sender struct {
 	// batchSize is the number of parallel OTs.
 	batchSize int
 
-	transcript *merlin.Transcript
+	transcript *transcript.Transcript
 }
 
 // Receiver stores state for the "receiver" role in OT. Protocol 7, Appendix A, of DKLs.
@@ -117,7 +117,7 @@ type Receiver struct {
 	// batchSize is the number of parallel OTs.
 	batchSize int
 
-	transcript *merlin.Transcript
+	transcript *transcript.Transcript
 }
 
 // NewSender creates a new "sender" object, ready to participate in a _random_ verified simplest OT in the role of the sender.
@@ -128,13 +128,13 @@ func NewSender(curve *curves.Curve, batchSize int, uniqueSessionId [DigestSize]b
 	if batchSize&0x07 != 0 { // This is the same as `batchSize % 8 != 0`, but is constant time
 		return nil, errors.New("batch size should be a multiple of 8")
 	}
-	transcript := merlin.NewTranscript("Coinbase_DKLs_SeedOT")
-	transcript.AppendMessage([]byte("session_id"), uniqueSessionId[:])
+	t := transcript.NewTranscript("Coinbase_DKLs_SeedOT")
+	t.AppendMessage([]byte("session_id"), uniqueSessionId[:])
 	return &Sender{
 		Output:     &SenderOutput{},
 		curve:      curve,
 		batchSize:  batchSize,
-		transcript: transcript,
+		transcript: t,
 	}, nil
 }
 
@@ -146,14 +146,14 @@ func NewReceiver(curve *curves.Curve, batchSize int, uniqueSessionId [DigestSize
 		return nil, errors.New("batch size should be a multiple of 8")
 	}
 
-	transcript := merlin.NewTranscript("Coinbase_DKLs_SeedOT")
-	transcript.AppendMessage([]byte("session_id"), uniqueSessionId[:])
+	t := transcript.NewTranscript("Coinbase_DKLs_SeedOT")
+	t.AppendMessage([]byte("session_id"), uniqueSessionId[:])
 
 	receiver := &Receiver{
 		Output:     &ReceiverOutput{},
 		curve:      curve,
 		batchSize:  batchSize,
-		transcript: transcript,
+		transcript: t,
 	}
 	batchSizeBytes := batchSize >> 3 // divide by 8
 	receiver.Output.PackedRandomChoiceBits = make([]byte, batchSizeBytes)
@@ -0,0 +1,132 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package simplest
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/transcript"
+)
+
+func init() {
+	gob.Register(&curves.ScalarK256{})
+	gob.Register(&curves.PointK256{})
+	gob.Register(&curves.ScalarP256{})
+	gob.Register(&curves.PointP256{})
+}
+
+type senderState struct {
+	Output     *SenderOutput
+	Curve      string
+	SecretKey  curves.Scalar
+	PublicKey  curves.Point
+	BatchSize  int
+	Transcript *transcript.Transcript
+}
+
+type receiverState struct {
+	Output          *ReceiverOutput
+	Curve           string
+	SenderPublicKey curves.Point
+	SenderChallenge []OtChallenge
+	BatchSize       int
+	Transcript      *transcript.Transcript
+}
+
+// MarshalBinary serializes the state of the sender between two rounds of the protocol.
+// The state contains the secret key of the sender and must be kept secret.
+func (sender *Sender) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := gob.NewEncoder(buf).Encode(&senderState{
+		Output:     sender.Output,
+		Curve:      sender.curve.Name,
+		SecretKey:  sender.secretKey,
+		PublicKey:  sender.publicKey,
+		BatchSize:  sender.batchSize,
+		Transcript: sender.transcript,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding seed OT sender")
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the state of the sender serialized by MarshalBinary.
+func (sender *Sender) UnmarshalBinary(data []byte) error {
+	state := new(senderState)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(state); err != nil {
+		return errors.Wrap(err, "decoding seed OT sender")
+	}
+	curve := curves.GetCurveByName(state.Curve)
+	if curve == nil {
+		return errors.Errorf("unknown curve %s", state.Curve)
+	}
+	if state.Transcript == nil {
+		return errors.New("seed OT sender has no transcript")
+	}
+	if state.Output == nil {
+		state.Output = &SenderOutput{}
+	}
+	*sender = Sender{
+		Output:     state.Output,
+		curve:      curve,
+		secretKey:  state.SecretKey,
+		publicKey:  state.PublicKey,
+		batchSize:  state.BatchSize,
+		transcript: state.Transcript,
+	}
+	return nil
+}
+
+// MarshalBinary serializes the state of the receiver between two rounds of the protocol.
+// The state contains the choice bits of the receiver and must be kept secret.
+func (receiver *Receiver) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := gob.NewEncoder(buf).Encode(&receiverState{
+		Output:          receiver.Output,
+		Curve:           receiver.curve.Name,
+		SenderPublicKey: receiver.senderPublicKey,
+		SenderChallenge: receiver.senderChallenge,
+		BatchSize:       receiver.batchSize,
+		Transcript:      receiver.transcript,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding seed OT receiver")
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the state of the receiver serialized by MarshalBinary.
+func (receiver *Receiver) UnmarshalBinary(data []byte) error {
+	state := new(receiverState)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(state); err != nil {
+		return errors.Wrap(err, "decoding seed OT receiver")
+	}
+	curve := curves.GetCurveByName(state.Curve)
+	if curve == nil {
+		return errors.Errorf("unknown curve %s", state.Curve)
+	}
+	if state.Transcript == nil {
+		return errors.New("seed OT receiver has no transcript")
+	}
+	if state.Output == nil {
+		state.Output = &ReceiverOutput{}
+	}
+	*receiver = Receiver{
+		Output:          state.Output,
+		curve:           curve,
+		senderPublicKey: state.SenderPublicKey,
+		senderChallenge: state.SenderChallenge,
+		batchSize:       state.BatchSize,
+		transcript:      state.Transcript,
+	}
+	return nil
+}
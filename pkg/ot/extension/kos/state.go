@@ -0,0 +1,73 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package kos
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
+)
+
+func init() {
+	gob.Register(&curves.ScalarK256{})
+	gob.Register(&curves.ScalarP256{})
+}
+
+type receiverState struct {
+	OutputAdditiveShares  [L][OtWidth]curves.Scalar
+	SeedOtResults         *simplest.SenderOutput
+	ExtendedPackedChoices [cOtExtendedBlockSizeBytes]byte
+	Psi                   [lPrime][KappaBytes]byte
+	Curve                 string
+	UniqueSessionId       [simplest.DigestSize]byte
+}
+
+// MarshalBinary serializes the state of the receiver between two rounds of the protocol.
+// The state contains the choice bits and seed OT results of the receiver and must be kept secret.
+func (receiver *Receiver) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := gob.NewEncoder(buf).Encode(&receiverState{
+		OutputAdditiveShares:  receiver.OutputAdditiveShares,
+		SeedOtResults:         receiver.seedOtResults,
+		ExtendedPackedChoices: receiver.extendedPackedChoices,
+		Psi:                   receiver.psi,
+		Curve:                 receiver.curve.Name,
+		UniqueSessionId:       receiver.uniqueSessionId,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding cOT receiver")
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the state of the receiver serialized by MarshalBinary.
+func (receiver *Receiver) UnmarshalBinary(data []byte) error {
+	state := new(receiverState)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(state); err != nil {
+		return errors.Wrap(err, "decoding cOT receiver")
+	}
+	curve := curves.GetCurveByName(state.Curve)
+	if curve == nil {
+		return errors.Errorf("unknown curve %s", state.Curve)
+	}
+	if state.SeedOtResults == nil {
+		return errors.New("cOT receiver has no seed OT results")
+	}
+	*receiver = Receiver{
+		OutputAdditiveShares:  state.OutputAdditiveShares,
+		seedOtResults:         state.SeedOtResults,
+		extendedPackedChoices: state.ExtendedPackedChoices,
+		psi:                   state.Psi,
+		curve:                 curve,
+		uniqueSessionId:       state.UniqueSessionId,
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains proofs that Paillier moduli have no small factors: CGGMP21 §C.5 fig 28
+
+package paillier
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/kryptology/internal"
+	crypto "github.com/coinbase/kryptology/pkg/core"
+)
+
+const (
+	// facEll is the bit length ℓ of the challenge and of the smallest allowed factor of N
+	facEll = 256
+	// facEpsilon is the slackness ε of the ranges
+	facEpsilon = 2 * facEll
+)
+
+// FacProofParams contains the inputs to prove Π^fac. NHat, S, T are the ring-Pedersen
+// parameters of the verifier: NHat is a product of two safe primes and S, T generate the same
+// group of quadratic residues mod NHat.
+type FacProofParams struct {
+	SecretKey  *SecretKey
+	NHat, S, T *big.Int
+	// Sid binds the proof to a session, for example the ids of the parties
+	Sid []byte
+}
+
+// FacVerifyParams contains the inputs to verify Π^fac
+type FacVerifyParams struct {
+	PublicKey  *PublicKey
+	NHat, S, T *big.Int
+	Sid        []byte
+}
+
+// FacProof proves that the factors p, q of a Paillier modulus N are both larger than 2^ℓ
+type FacProof struct {
+	// P, Q, A, B, T are the commitments, Sigma is the randomness of s^N t^Sigma
+	P, Q, A, B, T, Sigma *big.Int
+	// Z1, Z2, W1, W2, V are the responses
+	Z1, Z2, W1, W2, V *big.Int
+}
+
+// Prove that a Paillier modulus has no small factors
+// CGGMP21 §C.5 fig 28
+func (p *FacProofParams) Prove() (*FacProof, error) {
+	if p.SecretKey == nil || p.SecretKey.N == nil || crypto.AnyNil(p.NHat, p.S, p.T) {
+		return nil, internal.ErrNilArguments
+	}
+	n0 := p.SecretKey.N
+	pp, qq, err := p.SecretKey.primes()
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Sample
+	// α, β <- ±2^{ℓ+ε}·sqrt(N0)
+	// μ, ν <- ±2^ℓ·NHat
+	// σ <- ±2^ℓ·N0·NHat
+	// r <- ±2^{ℓ+ε}·N0·NHat
+	// x, y <- ±2^{ℓ+ε}·NHat
+	sqrtN0 := new(big.Int).Sqrt(n0)
+	n0NHat := new(big.Int).Mul(n0, p.NHat)
+	bounds := []*big.Int{
+		new(big.Int).Lsh(sqrtN0, facEll+facEpsilon),
+		new(big.Int).Lsh(sqrtN0, facEll+facEpsilon),
+		new(big.Int).Lsh(p.NHat, facEll),
+		new(big.Int).Lsh(p.NHat, facEll),
+		new(big.Int).Lsh(n0NHat, facEll),
+		new(big.Int).Lsh(n0NHat, facEll+facEpsilon),
+		new(big.Int).Lsh(p.NHat, facEll+facEpsilon),
+		new(big.Int).Lsh(p.NHat, facEll+facEpsilon),
+	}
+	values := make([]*big.Int, len(bounds))
+	for i, bound := range bounds {
+		values[i], err = randSigned(bound)
+		if err != nil {
+			return nil, err
+		}
+	}
+	alpha, beta, mu, nu, sigma, r, x, y := values[0], values[1], values[2], values[3], values[4], values[5], values[6], values[7]
+
+	// 2. P = s^p t^μ, Q = s^q t^ν, A = s^α t^x, B = s^β t^y, T = Q^α t^r mod NHat
+	proof := &FacProof{Sigma: sigma}
+	if proof.P, err = pedersen(p.S, pp, p.T, mu, p.NHat); err != nil {
+		return nil, err
+	}
+	if proof.Q, err = pedersen(p.S, qq, p.T, nu, p.NHat); err != nil {
+		return nil, err
+	}
+	if proof.A, err = pedersen(p.S, alpha, p.T, x, p.NHat); err != nil {
+		return nil, err
+	}
+	if proof.B, err = pedersen(p.S, beta, p.T, y, p.NHat); err != nil {
+		return nil, err
+	}
+	if proof.T, err = pedersen(proof.Q, alpha, p.T, r, p.NHat); err != nil {
+		return nil, err
+	}
+
+	// 3. e <- FS-HASH(N0, NHat, s, t, P, Q, A, B, T, σ, sid)
+	e, err := facChallenge(n0, p.NHat, p.S, p.T, proof, p.Sid)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. σ' = σ - νp
+	// z1 = α + ep, z2 = β + eq, w1 = x + eμ, w2 = y + eν, v = r + eσ'
+	sigmaHat := new(big.Int).Sub(sigma, new(big.Int).Mul(nu, pp))
+	proof.Z1 = new(big.Int).Add(alpha, new(big.Int).Mul(e, pp))
+	proof.Z2 = new(big.Int).Add(beta, new(big.Int).Mul(e, qq))
+	proof.W1 = new(big.Int).Add(x, new(big.Int).Mul(e, mu))
+	proof.W2 = new(big.Int).Add(y, new(big.Int).Mul(e, nu))
+	proof.V = new(big.Int).Add(r, new(big.Int).Mul(e, sigmaHat))
+	return proof, nil
+}
+
+// Verify that a Paillier modulus has no small factors
+// CGGMP21 §C.5 fig 28
+func (p FacProof) Verify(vp *FacVerifyParams) error {
+	if vp == nil || vp.PublicKey == nil || vp.PublicKey.N == nil || crypto.AnyNil(vp.NHat, vp.S, vp.T) {
+		return internal.ErrNilArguments
+	}
+	if crypto.AnyNil(p.P, p.Q, p.A, p.B, p.T, p.Sigma, p.Z1, p.Z2, p.W1, p.W2, p.V) {
+		return internal.ErrNilArguments
+	}
+	n0 := vp.PublicKey.N
+	if n0.Sign() != 1 || vp.NHat.Bit(0) == 0 {
+		return fmt.Errorf("invalid modulus")
+	}
+	for _, v := range []*big.Int{vp.S, vp.T, p.P, p.Q, p.A, p.B, p.T} {
+		if err := crypto.In(v, vp.NHat); err != nil {
+			return err
+		}
+		if new(big.Int).GCD(nil, nil, v, vp.NHat).Cmp(crypto.One) != 0 {
+			return fmt.Errorf("value is not a unit mod NHat")
+		}
+	}
+
+	// 1. z1, z2 ∈ ±sqrt(N0)·2^{ℓ+ε}
+	bound := new(big.Int).Lsh(new(big.Int).Sqrt(n0), facEll+facEpsilon)
+	if new(big.Int).Abs(p.Z1).Cmp(bound) == 1 || new(big.Int).Abs(p.Z2).Cmp(bound) == 1 {
+		return fmt.Errorf("response is out of range")
+	}
+
+	e, err := facChallenge(n0, vp.NHat, vp.S, vp.T, &p, vp.Sid)
+	if err != nil {
+		return err
+	}
+
+	// 2. R = s^N0 t^σ mod NHat
+	R, err := pedersen(vp.S, n0, vp.T, p.Sigma, vp.NHat)
+	if err != nil {
+		return err
+	}
+
+	// 3. s^z1 t^w1 = A P^e, s^z2 t^w2 = B Q^e and Q^z1 t^v = T R^e mod NHat
+	for i, check := range []struct{ g, z, w, c, d *big.Int }{
+		{vp.S, p.Z1, p.W1, p.A, p.P},
+		{vp.S, p.Z2, p.W2, p.B, p.Q},
+		{p.Q, p.Z1, p.V, p.T, R},
+	} {
+		lhs, err := pedersen(check.g, check.z, vp.T, check.w, vp.NHat)
+		if err != nil {
+			return err
+		}
+		rhs := new(big.Int).Exp(check.d, e, vp.NHat)
+		rhs.Mul(rhs, check.c).Mod(rhs, vp.NHat)
+		if lhs.Cmp(rhs) != 0 {
+			return fmt.Errorf("not equal at %d", i)
+		}
+	}
+	return nil
+}
+
+// facChallenge computes the challenge e in [0, 2^ℓ) of Π^fac
+func facChallenge(n0, nHat, s, t *big.Int, proof *FacProof, sid []byte) (*big.Int, error) {
+	// The sign of σ is hashed separately since the hash only takes its absolute value
+	sign := big.NewInt(int64(proof.Sigma.Sign() + 1))
+	e, err := crypto.FiatShamir(n0, nHat, s, t, proof.P, proof.Q, proof.A, proof.B, proof.T,
+		proof.Sigma, sign, new(big.Int).SetBytes(sid), big.NewInt(int64(len(sid))))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(e[:facEll/8]), nil
+}
+
+// pedersen computes g^a h^b mod n, where the exponents can be negative
+func pedersen(g, a, h, b, n *big.Int) (*big.Int, error) {
+	ga, err := expSigned(g, a, n)
+	if err != nil {
+		return nil, err
+	}
+	hb, err := expSigned(h, b, n)
+	if err != nil {
+		return nil, err
+	}
+	return ga.Mul(ga, hb).Mod(ga, n), nil
+}
+
+// expSigned computes x^e mod n, inverting x when e is negative
+func expSigned(x, e, n *big.Int) (*big.Int, error) {
+	if e.Sign() >= 0 {
+		return new(big.Int).Exp(x, e, n), nil
+	}
+	xInv, err := crypto.Inv(x, n)
+	if err != nil {
+		return nil, err
+	}
+	return xInv.Exp(xInv, new(big.Int).Neg(e), n), nil
+}
+
+// randSigned samples a uniform integer in [-bound, bound]
+func randSigned(bound *big.Int) (*big.Int, error) {
+	r, err := crand.Int(crand.Reader, new(big.Int).Add(new(big.Int).Lsh(bound, 1), crypto.One))
+	if err != nil {
+		return nil, err
+	}
+	return r.Sub(r, bound), nil
+}
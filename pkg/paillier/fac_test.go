@@ -0,0 +1,76 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package paillier
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	crypto "github.com/coinbase/kryptology/pkg/core"
+)
+
+// testRingPedersen returns ring-Pedersen parameters NHat, s, t of the verifier
+func testRingPedersen(t *testing.T) (*big.Int, *big.Int, *big.Int) {
+	t.Helper()
+	sk, err := NewSecretKey(testPrimes[2], testPrimes[3])
+	require.NoError(t, err)
+	r, err := crypto.Rand(sk.N)
+	require.NoError(t, err)
+	s := new(big.Int).Exp(r, big.NewInt(2), sk.N)
+	lambda, err := crypto.Rand(sk.Totient)
+	require.NoError(t, err)
+	return sk.N, s, new(big.Int).Exp(s, lambda, sk.N)
+}
+
+func TestFacProof(t *testing.T) {
+	nHat, s, tt := testRingPedersen(t)
+	sk, err := NewSecretKey(testPrimes[0], testPrimes[1])
+	require.NoError(t, err)
+	sid := []byte("fac proof test")
+	proof, err := (&FacProofParams{SecretKey: sk, NHat: nHat, S: s, T: tt, Sid: sid}).Prove()
+	require.NoError(t, err)
+	vp := &FacVerifyParams{PublicKey: &sk.PublicKey, NHat: nHat, S: s, T: tt, Sid: sid}
+	require.NoError(t, proof.Verify(vp))
+
+	// The proof survives a JSON round trip
+	data, err := json.Marshal(proof)
+	require.NoError(t, err)
+	restored := new(FacProof)
+	require.NoError(t, json.Unmarshal(data, restored))
+	require.NoError(t, restored.Verify(vp))
+
+	// The proof is bound to the session, the modulus and the verifier's parameters
+	require.Error(t, proof.Verify(&FacVerifyParams{PublicKey: &sk.PublicKey, NHat: nHat, S: s, T: tt, Sid: []byte("other")}))
+	require.Error(t, proof.Verify(&FacVerifyParams{PublicKey: &sk.PublicKey, NHat: nHat, S: tt, T: s, Sid: sid}))
+	other, err := NewSecretKey(testPrimes[4], testPrimes[5])
+	require.NoError(t, err)
+	require.Error(t, proof.Verify(&FacVerifyParams{PublicKey: &other.PublicKey, NHat: nHat, S: s, T: tt, Sid: sid}))
+
+	// Tampered proofs are rejected
+	restored.Z2 = new(big.Int).Add(restored.Z2, big.NewInt(1))
+	require.Error(t, restored.Verify(vp))
+	restored.Z2 = proof.Z2
+	restored.Sigma = new(big.Int).Neg(restored.Sigma)
+	require.Error(t, restored.Verify(vp))
+	require.Error(t, FacProof{}.Verify(vp))
+	require.Error(t, proof.Verify(nil))
+}
+
+func TestFacProofSmallFactor(t *testing.T) {
+	nHat, s, tt := testRingPedersen(t)
+	// N = pq with a 61 bit factor p
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 61), big.NewInt(1))
+	q := new(big.Int).Mul(testPrimes[0], testPrimes[1])
+	sk, err := NewSecretKey(p, q)
+	require.NoError(t, err)
+	proof, err := (&FacProofParams{SecretKey: sk, NHat: nHat, S: s, T: tt}).Prove()
+	require.NoError(t, err)
+	require.EqualError(t, proof.Verify(&FacVerifyParams{PublicKey: &sk.PublicKey, NHat: nHat, S: s, T: tt}), "response is out of range")
+}
@@ -0,0 +1,276 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains proofs that Paillier moduli are Paillier-Blum moduli: CGGMP21 §C.1 fig 16
+
+package paillier
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/kryptology/internal"
+	crypto "github.com/coinbase/kryptology/pkg/core"
+)
+
+// ModProofLength is the number of challenges m of the Paillier-Blum modulus proof,
+// the proof is sound except with probability 2^-m
+const ModProofLength = 80
+
+// ModProofParams contains the inputs to prove Π^mod
+type ModProofParams struct {
+	SecretKey *SecretKey
+	// Sid binds the proof to a session, for example the ids of the parties
+	Sid []byte
+}
+
+// ModVerifyParams contains the inputs to verify Π^mod
+type ModVerifyParams struct {
+	PublicKey *PublicKey
+	Sid       []byte
+}
+
+// ModProof proves that a Paillier modulus N = pq is a Paillier-Blum modulus: gcd(N, φ(N)) = 1 and
+// p ≡ q ≡ 3 mod 4.
+type ModProof struct {
+	// W is an element of Z_N with Jacobi symbol -1
+	W *big.Int
+	// X are the fourth roots of (-1)^A w^B y for the challenges y
+	X []*big.Int
+	A []bool
+	B []bool
+	// Z are the N-th roots of the challenges y
+	Z []*big.Int
+}
+
+// Prove that a Paillier modulus is a Paillier-Blum modulus
+// CGGMP21 §C.1 fig 16
+func (p *ModProofParams) Prove() (*ModProof, error) {
+	if p.SecretKey == nil || p.SecretKey.N == nil || p.SecretKey.Totient == nil {
+		return nil, internal.ErrNilArguments
+	}
+	n := p.SecretKey.N
+	P, Q, err := p.SecretKey.primes()
+	if err != nil {
+		return nil, err
+	}
+	if P.Bit(1) == 0 || Q.Bit(1) == 0 {
+		return nil, fmt.Errorf("paillier primes must be 3 mod 4")
+	}
+
+	// 1. Sample w with Jacobi symbol (w|N) = -1
+	var w *big.Int
+	for w == nil || big.Jacobi(w, n) != -1 {
+		w, err = crypto.Rand(n)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. y_i <- FS-HASH(N, w, sid) for i = [1, ..., m]
+	y, err := modChallenges(n, w, p.Sid)
+	if err != nil {
+		return nil, err
+	}
+
+	// M = N^{-1} mod φ(N)
+	M, err := crypto.Inv(n, p.SecretKey.Totient)
+	if err != nil {
+		return nil, err
+	}
+	// The exponents of the fourth roots of quadratic residues mod P and Q: ((P+1)/4)^2 mod (P-1)
+	eP := fourthRootExp(P)
+	eQ := fourthRootExp(Q)
+	qInv := new(big.Int).ModInverse(Q, P)
+	minusOne := new(big.Int).Sub(n, crypto.One)
+
+	proof := &ModProof{
+		W: w,
+		X: make([]*big.Int, ModProofLength),
+		A: make([]bool, ModProofLength),
+		B: make([]bool, ModProofLength),
+		Z: make([]*big.Int, ModProofLength),
+	}
+	for i, yi := range y {
+		// 3. z_i = y_i^M mod N
+		proof.Z[i], err = crypto.Exp(yi, M, n)
+		if err != nil {
+			return nil, err
+		}
+
+		// 4. Find the unique a_i, b_i such that y'_i = (-1)^a_i w^b_i y_i is a quadratic residue mod N
+		found := false
+		for _, ab := range [][2]bool{{false, false}, {true, false}, {false, true}, {true, true}} {
+			yPrime := new(big.Int).Set(yi)
+			if ab[0] {
+				yPrime.Mul(yPrime, minusOne).Mod(yPrime, n)
+			}
+			if ab[1] {
+				yPrime.Mul(yPrime, w).Mod(yPrime, n)
+			}
+			yP := new(big.Int).Mod(yPrime, P)
+			yQ := new(big.Int).Mod(yPrime, Q)
+			if big.Jacobi(yP, P) != 1 || big.Jacobi(yQ, Q) != 1 {
+				continue
+			}
+
+			// 5. x_i = y'_i^{1/4} mod N, computed mod P and Q and recombined
+			xP := new(big.Int).Exp(yP, eP, P)
+			xQ := new(big.Int).Exp(yQ, eQ, Q)
+			h := new(big.Int).Sub(xP, xQ)
+			h.Mul(h, qInv).Mod(h, P)
+			proof.X[i] = h.Mul(h, Q).Add(h, xQ)
+			proof.A[i], proof.B[i] = ab[0], ab[1]
+			found = true
+			break
+		}
+		if !found {
+			return nil, fmt.Errorf("paillier modulus is not a Blum integer")
+		}
+	}
+
+	// 6. Return (w, [(x_i, a_i, b_i, z_i)])
+	return proof, nil
+}
+
+// Verify that a Paillier modulus is a Paillier-Blum modulus
+// CGGMP21 §C.1 fig 16
+func (p ModProof) Verify(vp *ModVerifyParams) error {
+	if vp == nil || vp.PublicKey == nil || vp.PublicKey.N == nil || p.W == nil {
+		return internal.ErrNilArguments
+	}
+	if len(p.X) != ModProofLength || len(p.A) != ModProofLength ||
+		len(p.B) != ModProofLength || len(p.Z) != ModProofLength {
+		return fmt.Errorf("proof must have %d values", ModProofLength)
+	}
+	n := vp.PublicKey.N
+
+	// 1. N is an odd composite number
+	if n.Bit(0) == 0 || n.Cmp(crypto.One) <= 0 {
+		return fmt.Errorf("paillier modulus must be odd")
+	}
+	if n.ProbablyPrime(20) {
+		return fmt.Errorf("paillier modulus must not be prime")
+	}
+	if err := crypto.In(p.W, n); err != nil {
+		return err
+	}
+	if big.Jacobi(p.W, n) != -1 {
+		return fmt.Errorf("w must have jacobi symbol -1")
+	}
+
+	// 2. y_i <- FS-HASH(N, w, sid) for i = [1, ..., m]
+	y, err := modChallenges(n, p.W, vp.Sid)
+	if err != nil {
+		return err
+	}
+
+	minusOne := new(big.Int).Sub(n, crypto.One)
+	four := big.NewInt(4)
+	for i, yi := range y {
+		if p.X[i] == nil || p.Z[i] == nil {
+			return internal.ErrNilArguments
+		}
+		// 3. z_i^N = y_i mod N
+		zN, err := crypto.Exp(p.Z[i], n, n)
+		if err != nil {
+			return err
+		}
+		if zN.Cmp(yi) != 0 {
+			return fmt.Errorf("z^N != y at %d", i)
+		}
+
+		// 4. x_i^4 = (-1)^a_i w^b_i y_i mod N
+		rhs := new(big.Int).Set(yi)
+		if p.A[i] {
+			rhs.Mul(rhs, minusOne).Mod(rhs, n)
+		}
+		if p.B[i] {
+			rhs.Mul(rhs, p.W).Mod(rhs, n)
+		}
+		x4, err := crypto.Exp(p.X[i], four, n)
+		if err != nil {
+			return err
+		}
+		if x4.Cmp(rhs) != 0 {
+			return fmt.Errorf("x^4 != (-1)^a w^b y at %d", i)
+		}
+	}
+	return nil
+}
+
+// modChallenges computes the ModProofLength challenges y_i of Π^mod in Z_N*
+func modChallenges(n, w *big.Int, sid []byte) ([]*big.Int, error) {
+	seed, err := crypto.FiatShamir(n, w, new(big.Int).SetBytes(sid), big.NewInt(int64(len(sid))))
+	if err != nil {
+		return nil, err
+	}
+	return challengesModN(n, new(big.Int).SetBytes(seed), ModProofLength)
+}
+
+// challengesModN computes count deterministic challenges in Z_N* from seed by rejection sampling
+func challengesModN(n, seed *big.Int, count int) ([]*big.Int, error) {
+	b := n.BitLen()
+	// a modulus that is too small turns this function into an infinite loop
+	if b < 8 {
+		return nil, fmt.Errorf("modulus is too small")
+	}
+	// The output bit-length of fiat-shamir hash
+	const h int = 256
+	s := (b + h - 1) / h
+
+	challenges := make([]*big.Int, 0, count)
+	m := big.NewInt(0)
+	for j := 0; len(challenges) < count; j++ {
+		var ej []byte
+		for k := 1; k <= s; k++ {
+			res, err := crypto.FiatShamir(seed, big.NewInt(int64(j)), big.NewInt(int64(k)), m)
+			if err != nil {
+				return nil, err
+			}
+			ej = append(ej, res...)
+		}
+		// Truncate to b bits
+		xj := new(big.Int).SetBytes(ej[:(b+7)/8])
+		xj.Rsh(xj, uint((8-b%8)%8))
+		if xj.Sign() == 1 && xj.Cmp(n) == -1 && new(big.Int).GCD(nil, nil, xj, n).Cmp(crypto.One) == 0 {
+			challenges = append(challenges, xj)
+		}
+	}
+	return challenges, nil
+}
+
+// fourthRootExp returns ((p+1)/4)^2 mod (p-1), the exponent that maps a quadratic residue mod
+// a prime p ≡ 3 mod 4 to its fourth root that is a quadratic residue
+func fourthRootExp(p *big.Int) *big.Int {
+	e := new(big.Int).Add(p, crypto.One)
+	e.Rsh(e, 2)
+	e.Mul(e, e)
+	return e.Mod(e, new(big.Int).Sub(p, crypto.One))
+}
+
+// primes recovers the primes P, Q of the modulus from N and φ(N):
+// P + Q = N - φ(N) + 1 and P - Q = sqrt((P + Q)^2 - 4N)
+func (sk *SecretKey) primes() (*big.Int, *big.Int, error) {
+	if sk.N == nil || sk.Totient == nil {
+		return nil, nil, internal.ErrNilArguments
+	}
+	sum := new(big.Int).Sub(sk.N, sk.Totient)
+	sum.Add(sum, crypto.One)
+	d := new(big.Int).Mul(sum, sum)
+	d.Sub(d, new(big.Int).Lsh(sk.N, 2))
+	if d.Sign() < 0 {
+		return nil, nil, fmt.Errorf("invalid paillier secret key")
+	}
+	d.Sqrt(d)
+	p := new(big.Int).Add(sum, d)
+	p.Rsh(p, 1)
+	q := new(big.Int).Sub(sum, d)
+	q.Rsh(q, 1)
+	if new(big.Int).Mul(p, q).Cmp(sk.N) != 0 {
+		return nil, nil, fmt.Errorf("invalid paillier secret key")
+	}
+	return p, q, nil
+}
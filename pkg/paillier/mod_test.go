@@ -0,0 +1,68 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package paillier
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModProof(t *testing.T) {
+	sk, err := NewSecretKey(testPrimes[0], testPrimes[1])
+	require.NoError(t, err)
+	sid := []byte("mod proof test")
+	proof, err := (&ModProofParams{SecretKey: sk, Sid: sid}).Prove()
+	require.NoError(t, err)
+	require.NoError(t, proof.Verify(&ModVerifyParams{PublicKey: &sk.PublicKey, Sid: sid}))
+
+	// The proof survives a JSON round trip
+	data, err := json.Marshal(proof)
+	require.NoError(t, err)
+	restored := new(ModProof)
+	require.NoError(t, json.Unmarshal(data, restored))
+	require.NoError(t, restored.Verify(&ModVerifyParams{PublicKey: &sk.PublicKey, Sid: sid}))
+
+	// The proof is bound to the session and the modulus
+	require.Error(t, proof.Verify(&ModVerifyParams{PublicKey: &sk.PublicKey, Sid: []byte("other session")}))
+	other, err := NewSecretKey(testPrimes[2], testPrimes[3])
+	require.NoError(t, err)
+	require.Error(t, proof.Verify(&ModVerifyParams{PublicKey: &other.PublicKey, Sid: sid}))
+
+	// Tampered proofs are rejected
+	restored.X[3] = new(big.Int).Add(restored.X[3], big.NewInt(1))
+	require.Error(t, restored.Verify(&ModVerifyParams{PublicKey: &sk.PublicKey, Sid: sid}))
+	restored.X[3] = proof.X[3]
+	restored.A[5] = !restored.A[5]
+	require.Error(t, restored.Verify(&ModVerifyParams{PublicKey: &sk.PublicKey, Sid: sid}))
+	restored.A[5] = proof.A[5]
+	restored.Z = restored.Z[1:]
+	require.Error(t, restored.Verify(&ModVerifyParams{PublicKey: &sk.PublicKey, Sid: sid}))
+	require.Error(t, ModProof{}.Verify(&ModVerifyParams{PublicKey: &sk.PublicKey, Sid: sid}))
+	require.Error(t, proof.Verify(nil))
+}
+
+func TestModProofInvalidModulus(t *testing.T) {
+	// 13 is 1 mod 4
+	sk, err := NewSecretKey(big.NewInt(13), testPrimes[0])
+	require.NoError(t, err)
+	_, err = (&ModProofParams{SecretKey: sk}).Prove()
+	require.Error(t, err)
+	_, err = (&ModProofParams{}).Prove()
+	require.Error(t, err)
+
+	// A prime modulus is rejected
+	blum, err := NewSecretKey(testPrimes[0], testPrimes[1])
+	require.NoError(t, err)
+	proof, err := (&ModProofParams{SecretKey: blum}).Prove()
+	require.NoError(t, err)
+	prime, err := NewPubkey(testPrimes[0])
+	require.NoError(t, err)
+	require.Error(t, proof.Verify(&ModVerifyParams{PublicKey: prime}))
+}
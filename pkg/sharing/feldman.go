@@ -23,14 +23,7 @@ func (v FeldmanVerifier) Verify(share *ShamirShare) error {
 	if err != nil {
 		return err
 	}
-	x := curve.Scalar.New(int(share.Id))
-	i := curve.Scalar.One()
-	rhs := v.Commitments[0]
-
-	for j := 1; j < len(v.Commitments); j++ {
-		i = i.Mul(x)
-		rhs = rhs.Add(v.Commitments[j].Mul(i))
-	}
+	rhs := v.evaluate(curve, share.Id)
 	sc, _ := curve.Scalar.SetBytes(share.Value)
 	lhs := v.Commitments[0].Generator().Mul(sc)
 
@@ -41,6 +34,19 @@ func (v FeldmanVerifier) Verify(share *ShamirShare) error {
 	}
 }
 
+// evaluate computes the commitment to the share of id, the public share f(id)·G
+func (v FeldmanVerifier) evaluate(curve *curves.Curve, id uint32) curves.Point {
+	x := curve.Scalar.New(int(id))
+	i := curve.Scalar.One()
+	rhs := v.Commitments[0]
+
+	for j := 1; j < len(v.Commitments); j++ {
+		i = i.Mul(x)
+		rhs = rhs.Add(v.Commitments[j].Mul(i))
+	}
+	return rhs
+}
+
 type Feldman struct {
 	Threshold, Limit uint32
 	Curve            *curves.Curve
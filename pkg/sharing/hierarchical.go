@@ -0,0 +1,255 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+// Level is a level of a hierarchical sharing. Levels are ordered from the most to the least privileged.
+type Level struct {
+	// Threshold is the number of participants from this level and the levels before it that every
+	// authorized set contains. The threshold of the last level is the size of authorized sets.
+	Threshold uint32
+	// Limit is the number of participants of the level
+	Limit uint32
+}
+
+// Hierarchical is a conjunctive hierarchical Feldman sharing based on Birkhoff interpolation, from
+// Tassa, Hierarchical Threshold Secret Sharing, Journal of Cryptology 2007.
+// A participant of level ℓ holds f^(k)(id), the k-th derivative of the sharing polynomial, where k is the
+// threshold of level ℓ-1, and 0 for the first level. So "two officers and any three staff" are the levels
+// {2, officers} and {5, staff}.
+// Identifiers are assigned consecutively from 1, level after level, which keeps Birkhoff interpolation
+// well defined for authorized sets.
+type Hierarchical struct {
+	Levels []Level
+	Curve  *curves.Curve
+}
+
+// NewHierarchical creates a hierarchical sharing with the given levels
+func NewHierarchical(curve *curves.Curve, levels ...Level) (*Hierarchical, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("invalid curve")
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("at least one level is required")
+	}
+	previous := uint32(0)
+	participants := uint64(0)
+	for i, level := range levels {
+		if level.Limit == 0 {
+			return nil, fmt.Errorf("level %d has no participants", i)
+		}
+		if level.Threshold <= previous {
+			return nil, fmt.Errorf("thresholds must increase from level to level")
+		}
+		participants += uint64(level.Limit)
+		if participants < uint64(level.Threshold) {
+			return nil, fmt.Errorf("threshold of level %d cannot be reached", i)
+		}
+		previous = level.Threshold
+	}
+	if previous < 2 {
+		return nil, fmt.Errorf("threshold cannot be less than 2")
+	}
+	if participants > uint64(^uint32(0)) {
+		return nil, fmt.Errorf("too many participants")
+	}
+	copied := make([]Level, len(levels))
+	copy(copied, levels)
+	return &Hierarchical{copied, curve}, nil
+}
+
+// Threshold returns the size of authorized sets, the threshold of the last level
+func (h Hierarchical) Threshold() uint32 {
+	return h.Levels[len(h.Levels)-1].Threshold
+}
+
+// LevelOf returns the index of the level of the participant id
+func (h Hierarchical) LevelOf(id uint32) (int, error) {
+	last := uint32(0)
+	for i, level := range h.Levels {
+		if id > last && id <= last+level.Limit {
+			return i, nil
+		}
+		last += level.Limit
+	}
+	return 0, fmt.Errorf("invalid share identifier")
+}
+
+// order returns the order of the derivative held by the participant id
+func (h Hierarchical) order(id uint32) (uint32, error) {
+	level, err := h.LevelOf(id)
+	if err != nil {
+		return 0, err
+	}
+	if level == 0 {
+		return 0, nil
+	}
+	return h.Levels[level-1].Threshold, nil
+}
+
+// Split shares secret and returns the Feldman commitments and the shares, the share at index i for the
+// participant i+1
+func (h Hierarchical) Split(secret curves.Scalar, reader io.Reader) (*FeldmanVerifier, []*ShamirShare, error) {
+	if secret.IsZero() {
+		return nil, nil, fmt.Errorf("invalid secret")
+	}
+	t := h.Threshold()
+	poly := new(Polynomial).Init(secret, t, reader)
+	verifier := &FeldmanVerifier{Commitments: make([]curves.Point, t)}
+	for i := range verifier.Commitments {
+		verifier.Commitments[i] = h.Curve.ScalarBaseMult(poly.Coefficients[i])
+	}
+	count := uint32(0)
+	for _, level := range h.Levels {
+		count += level.Limit
+	}
+	shares := make([]*ShamirShare, count)
+	for i := range shares {
+		id := uint32(i + 1)
+		k, _ := h.order(id)
+		value := h.Curve.Scalar.Zero()
+		for j, row := range h.derivativeRow(id, k) {
+			value = value.Add(poly.Coefficients[j].Mul(row))
+		}
+		shares[i] = &ShamirShare{Id: id, Value: value.Bytes()}
+	}
+	return verifier, shares, nil
+}
+
+// derivativeRow returns the factors d^k/dx^k x^j at id for j in [0, t), so that f^(k)(id) is the sum
+// of the coefficients of f times the factors
+func (h Hierarchical) derivativeRow(id, k uint32) []curves.Scalar {
+	t := h.Threshold()
+	x := h.Curve.Scalar.New(int(id))
+	row := make([]curves.Scalar, t)
+	power := h.Curve.Scalar.One()
+	for j := uint32(0); j < t; j++ {
+		if j < k {
+			row[j] = h.Curve.Scalar.Zero()
+			continue
+		}
+		// j! / (j-k)! x^(j-k)
+		falling := h.Curve.Scalar.One()
+		for m := j - k + 1; m <= j; m++ {
+			falling = falling.Mul(h.Curve.Scalar.New(int(m)))
+		}
+		row[j] = falling.Mul(power)
+		power = power.Mul(x)
+	}
+	return row
+}
+
+// Verify checks share against the Feldman commitments to the coefficients of the polynomial
+func (h Hierarchical) Verify(verifier *FeldmanVerifier, share *ShamirShare) error {
+	if verifier == nil || uint32(len(verifier.Commitments)) != h.Threshold() || share == nil {
+		return fmt.Errorf("invalid arguments")
+	}
+	if err := share.Validate(h.Curve); err != nil {
+		return err
+	}
+	k, err := h.order(share.Id)
+	if err != nil {
+		return err
+	}
+	rhs := h.Curve.NewIdentityPoint()
+	for j, row := range h.derivativeRow(share.Id, k) {
+		rhs = rhs.Add(verifier.Commitments[j].Mul(row))
+	}
+	sc, _ := h.Curve.Scalar.SetBytes(share.Value)
+	if !h.Curve.ScalarBaseMult(sc).Equal(rhs) {
+		return fmt.Errorf("not equal")
+	}
+	return nil
+}
+
+// Authorized returns whether the participants ids can reconstruct: for every level, they contain at
+// least its threshold of participants from that level and the levels before it
+func (h Hierarchical) Authorized(ids []uint32) bool {
+	counts := make([]uint32, len(h.Levels))
+	dups := make(map[uint32]bool, len(ids))
+	for _, id := range ids {
+		level, err := h.LevelOf(id)
+		if err != nil || dups[id] {
+			return false
+		}
+		dups[id] = true
+		counts[level]++
+	}
+	total := uint32(0)
+	for i, level := range h.Levels {
+		total += counts[i]
+		if total < level.Threshold {
+			return false
+		}
+	}
+	return true
+}
+
+// BirkhoffCoeffs returns coefficients β_i such that the secret is Σ β_i s_i for the shares s_i of the
+// participants ids. They replace the Lagrange coefficients in FROST signing. Participants beyond what
+// interpolation needs may get a coefficient of zero.
+func (h Hierarchical) BirkhoffCoeffs(ids []uint32) (map[uint32]curves.Scalar, error) {
+	if !h.Authorized(ids) {
+		return nil, fmt.Errorf("participants are not an authorized set")
+	}
+	// Solve Σ_i β_i d^k_i/dx^k_i x^j (x_i) = 1 for j = 0 and 0 otherwise
+	t := h.Threshold()
+	matrix := make([][]curves.Scalar, t)
+	for j := range matrix {
+		matrix[j] = make([]curves.Scalar, len(ids)+1)
+		matrix[j][len(ids)] = h.Curve.Scalar.Zero()
+	}
+	matrix[0][len(ids)] = h.Curve.Scalar.One()
+	for i, id := range ids {
+		k, _ := h.order(id)
+		for j, factor := range h.derivativeRow(id, k) {
+			matrix[j][i] = factor
+		}
+	}
+	solution, err := solveLinear(h.Curve, matrix, len(ids))
+	if err == errInconsistentSystem {
+		return nil, fmt.Errorf("birkhoff interpolation is not possible for these participants")
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[uint32]curves.Scalar, len(ids))
+	for i, id := range ids {
+		result[id] = solution[i]
+	}
+	return result, nil
+}
+
+// Combine reconstructs the secret from the shares of an authorized set
+func (h Hierarchical) Combine(shares ...*ShamirShare) (curves.Scalar, error) {
+	ids := make([]uint32, len(shares))
+	for i, share := range shares {
+		if share == nil {
+			return nil, fmt.Errorf("invalid share")
+		}
+		if err := share.Validate(h.Curve); err != nil {
+			return nil, err
+		}
+		ids[i] = share.Id
+	}
+	coefficients, err := h.BirkhoffCoeffs(ids)
+	if err != nil {
+		return nil, err
+	}
+	secret := h.Curve.Scalar.Zero()
+	for _, share := range shares {
+		sc, _ := h.Curve.Scalar.SetBytes(share.Value)
+		secret = secret.Add(sc.Mul(coefficients[share.Id]))
+	}
+	return secret, nil
+}
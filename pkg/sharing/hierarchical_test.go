@@ -0,0 +1,108 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+func TestHierarchicalInvalidArgs(t *testing.T) {
+	curve := curves.K256()
+	_, err := NewHierarchical(curve)
+	require.Error(t, err)
+	_, err = NewHierarchical(nil, Level{2, 3})
+	require.Error(t, err)
+	_, err = NewHierarchical(curve, Level{1, 3})
+	require.Error(t, err)
+	_, err = NewHierarchical(curve, Level{3, 2})
+	require.Error(t, err)
+	_, err = NewHierarchical(curve, Level{2, 3}, Level{2, 3})
+	require.Error(t, err)
+	_, err = NewHierarchical(curve, Level{2, 3}, Level{5, 0})
+	require.Error(t, err)
+	_, err = NewHierarchical(curve, Level{2, 3}, Level{7, 3})
+	require.Error(t, err)
+}
+
+func TestHierarchical(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.ED25519()} {
+		// Two officers plus any three staff: officers are 1 to 3, staff 4 to 9
+		scheme, err := NewHierarchical(curve, Level{Threshold: 2, Limit: 3}, Level{Threshold: 5, Limit: 6})
+		require.NoError(t, err)
+		require.Equal(t, uint32(5), scheme.Threshold())
+		level, err := scheme.LevelOf(3)
+		require.NoError(t, err)
+		require.Equal(t, 0, level)
+		level, err = scheme.LevelOf(4)
+		require.NoError(t, err)
+		require.Equal(t, 1, level)
+		_, err = scheme.LevelOf(10)
+		require.Error(t, err)
+
+		secret := curve.Scalar.Random(crand.Reader)
+		verifier, shares, err := scheme.Split(secret, crand.Reader)
+		require.NoError(t, err)
+		require.Len(t, shares, 9)
+		for _, share := range shares {
+			require.NoError(t, scheme.Verify(verifier, share))
+		}
+		tampered := &ShamirShare{Id: 5, Value: shares[3].Value}
+		require.Error(t, scheme.Verify(verifier, tampered))
+
+		for _, set := range [][]uint32{{1, 2, 4, 5, 6}, {1, 3, 7, 8, 9}, {1, 2, 3, 4, 5}, {1, 2, 3, 4, 5, 6, 7}} {
+			require.True(t, scheme.Authorized(set))
+			subset := make([]*ShamirShare, len(set))
+			for i, id := range set {
+				subset[i] = shares[id-1]
+			}
+			recovered, err := scheme.Combine(subset...)
+			require.NoError(t, err)
+			require.Equal(t, 0, recovered.Cmp(secret))
+
+			// The coefficients also apply to the public shares
+			coefficients, err := scheme.BirkhoffCoeffs(set)
+			require.NoError(t, err)
+			public := curve.NewIdentityPoint()
+			for _, id := range set {
+				sc, err := curve.Scalar.SetBytes(shares[id-1].Value)
+				require.NoError(t, err)
+				public = public.Add(curve.ScalarBaseMult(sc).Mul(coefficients[id]))
+			}
+			require.True(t, public.Equal(verifier.Commitments[0]))
+		}
+
+		// One officer or too few participants are not enough
+		for _, set := range [][]uint32{{1, 4, 5, 6, 7}, {4, 5, 6, 7, 8, 9}, {1, 2, 4, 5}, {1, 1, 2, 4, 5}} {
+			require.False(t, scheme.Authorized(set))
+			subset := make([]*ShamirShare, len(set))
+			for i, id := range set {
+				subset[i] = shares[id-1]
+			}
+			_, err = scheme.Combine(subset...)
+			require.Error(t, err)
+		}
+	}
+}
+
+func TestHierarchicalSingleLevelIsShamir(t *testing.T) {
+	curve := curves.K256()
+	scheme, err := NewHierarchical(curve, Level{Threshold: 3, Limit: 5})
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	_, shares, err := scheme.Split(secret, crand.Reader)
+	require.NoError(t, err)
+	shamir, err := NewShamir(3, 5, curve)
+	require.NoError(t, err)
+	recovered, err := shamir.Combine(shares[0], shares[2], shares[4])
+	require.NoError(t, err)
+	require.Equal(t, 0, recovered.Cmp(secret))
+}
@@ -0,0 +1,291 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+// pvssGeneratorLabel is hashed to the curve to get the generator of the commitments, whose discrete
+// logarithm to the base point is unknown
+const pvssGeneratorLabel = "kryptology pvss commitment generator"
+
+// Pvss is the publicly verifiable secret sharing of Schoenmakers, A Simple Publicly Verifiable Secret
+// Sharing Scheme and its Application to Electronic Voting, CRYPTO 1999.
+//
+// The dealer commits to the coefficients of the sharing polynomial f with C_j = a_j·H and encrypts the
+// share of the participant i to its public key y_i = x_i·G as Y_i = f(i)·y_i. A DLEQ proof shows that
+// X_i = Σ C_j·i^j and Y_i have the same discrete logarithm f(i) to the bases H and y_i, so anyone can
+// check every encrypted share. The participant i decrypts S_i = x_i^-1·Y_i = f(i)·G and proves it with
+// a DLEQ proof. Any threshold of decrypted shares reconstructs the secret s·G, not the scalar s.
+type Pvss struct {
+	Threshold, Limit uint32
+	Curve            *curves.Curve
+	// Generator is H, the base of the commitments
+	Generator curves.Point
+}
+
+// DleqProof is a non-interactive proof that two points have the same discrete logarithm to two bases
+type DleqProof struct {
+	C, S curves.Scalar
+}
+
+// PvssShare is an encrypted or decrypted share of the participant Id, with the DLEQ proof that it is
+// correct
+type PvssShare struct {
+	Id    uint32
+	Value curves.Point
+	Proof *DleqProof
+}
+
+// PvssDeal is the output of the dealer: the commitments to the coefficients of the sharing polynomial
+// and the encrypted shares, the share at index i for the participant i+1
+type PvssDeal struct {
+	Commitments []curves.Point
+	Shares      []*PvssShare
+}
+
+// NewPvss creates a publicly verifiable sharing on curve
+func NewPvss(threshold, limit uint32, curve *curves.Curve) (*Pvss, error) {
+	if limit < threshold {
+		return nil, fmt.Errorf("limit cannot be less than threshold")
+	}
+	if threshold < 2 {
+		return nil, fmt.Errorf("threshold cannot be less than 2")
+	}
+	if limit > 255 {
+		return nil, fmt.Errorf("cannot exceed 255 shares")
+	}
+	if curve == nil {
+		return nil, fmt.Errorf("invalid curve")
+	}
+	generator := curve.Point.Hash([]byte(pvssGeneratorLabel))
+	return &Pvss{threshold, limit, curve, generator}, nil
+}
+
+// Split shares secret among the holders of publicKeys, where publicKeys[i] is the public key x_i·G of
+// the participant i+1
+func (p Pvss) Split(secret curves.Scalar, publicKeys []curves.Point, reader io.Reader) (*PvssDeal, error) {
+	if secret == nil || secret.IsZero() {
+		return nil, fmt.Errorf("invalid secret")
+	}
+	if err := p.checkPublicKeys(publicKeys); err != nil {
+		return nil, err
+	}
+	poly := new(Polynomial).Init(secret, p.Threshold, reader)
+	deal := &PvssDeal{
+		Commitments: make([]curves.Point, p.Threshold),
+		Shares:      make([]*PvssShare, p.Limit),
+	}
+	for i := range deal.Commitments {
+		deal.Commitments[i] = p.Generator.Mul(poly.Coefficients[i])
+	}
+	verifier := &FeldmanVerifier{Commitments: deal.Commitments}
+	for i, y := range publicKeys {
+		id := uint32(i + 1)
+		value := poly.Evaluate(p.Curve.Scalar.New(int(id)))
+		encrypted := y.Mul(value)
+		x := verifier.evaluate(p.Curve, id)
+		deal.Shares[i] = &PvssShare{
+			Id:    id,
+			Value: encrypted,
+			Proof: p.proveDleq(pvssLabel("share", id, deal.Commitments), p.Generator, x, y, encrypted, value, reader),
+		}
+	}
+	return deal, nil
+}
+
+// Verify checks that every encrypted share of deal is the evaluation of the committed polynomial,
+// encrypted to its public key. It needs no secret and can be run by any observer.
+func (p Pvss) Verify(deal *PvssDeal, publicKeys []curves.Point) error {
+	if err := p.checkDeal(deal); err != nil {
+		return err
+	}
+	if err := p.checkPublicKeys(publicKeys); err != nil {
+		return err
+	}
+	for i, y := range publicKeys {
+		if err := p.verifyEncryptedShare(deal, uint32(i+1), y); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decrypt decrypts the share of the participant id with its secret key, after checking its encrypted
+// share, and proves that the decryption is correct
+func (p Pvss) Decrypt(deal *PvssDeal, id uint32, secretKey curves.Scalar, reader io.Reader) (*PvssShare, error) {
+	if secretKey == nil || secretKey.IsZero() {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	if err := p.checkDeal(deal); err != nil {
+		return nil, err
+	}
+	publicKey := p.Curve.ScalarBaseMult(secretKey)
+	if err := p.verifyEncryptedShare(deal, id, publicKey); err != nil {
+		return nil, err
+	}
+	inverse, err := secretKey.Invert()
+	if err != nil {
+		return nil, err
+	}
+	encrypted := deal.Shares[id-1].Value
+	decrypted := encrypted.Mul(inverse)
+	return &PvssShare{
+		Id:    id,
+		Value: decrypted,
+		Proof: p.proveDleq(pvssLabel("decryption", id, deal.Commitments), p.Curve.NewGeneratorPoint(), publicKey, decrypted, encrypted, secretKey, reader),
+	}, nil
+}
+
+// VerifyDecryption checks that share is the decryption of the encrypted share of its participant in deal
+// with the secret key of publicKey
+func (p Pvss) VerifyDecryption(deal *PvssDeal, publicKey curves.Point, share *PvssShare) error {
+	if err := p.checkDeal(deal); err != nil {
+		return err
+	}
+	if share == nil || share.Id == 0 || share.Id > p.Limit || !p.validPoint(share.Value) {
+		return fmt.Errorf("invalid share")
+	}
+	if !p.validPoint(publicKey) {
+		return fmt.Errorf("invalid public key")
+	}
+	encrypted := deal.Shares[share.Id-1]
+	if encrypted == nil || !p.validPoint(encrypted.Value) {
+		return fmt.Errorf("encrypted share %d is invalid", share.Id)
+	}
+	label := pvssLabel("decryption", share.Id, deal.Commitments)
+	if err := p.verifyDleq(label, p.Curve.NewGeneratorPoint(), publicKey, share.Value, encrypted.Value, share.Proof); err != nil {
+		return fmt.Errorf("decrypted share %d is invalid", share.Id)
+	}
+	return nil
+}
+
+// Combine reconstructs the secret s·G from a threshold of decrypted shares, which should be checked
+// with VerifyDecryption first
+func (p Pvss) Combine(shares ...*PvssShare) (curves.Point, error) {
+	if len(shares) < int(p.Threshold) {
+		return nil, fmt.Errorf("invalid number of shares")
+	}
+	dups := make(map[uint32]bool, len(shares))
+	xs := make([]curves.Scalar, len(shares))
+	ys := make([]curves.Point, len(shares))
+	for i, share := range shares {
+		if share == nil || share.Id == 0 || share.Id > p.Limit || !p.validPoint(share.Value) {
+			return nil, fmt.Errorf("invalid share")
+		}
+		if dups[share.Id] {
+			return nil, fmt.Errorf("duplicate share")
+		}
+		dups[share.Id] = true
+		xs[i] = p.Curve.Scalar.New(int(share.Id))
+		ys[i] = share.Value
+	}
+	shamir := &Shamir{threshold: p.Threshold, limit: p.Limit, curve: p.Curve}
+	return shamir.interpolatePoint(xs, ys)
+}
+
+// verifyEncryptedShare checks the DLEQ proof of the encrypted share of id against the commitments
+func (p Pvss) verifyEncryptedShare(deal *PvssDeal, id uint32, publicKey curves.Point) error {
+	if id == 0 || id > p.Limit {
+		return fmt.Errorf("invalid share identifier")
+	}
+	if !p.validPoint(publicKey) {
+		return fmt.Errorf("invalid public key")
+	}
+	share := deal.Shares[id-1]
+	if share == nil || share.Id != id || !p.validPoint(share.Value) {
+		return fmt.Errorf("encrypted share %d is invalid", id)
+	}
+	verifier := &FeldmanVerifier{Commitments: deal.Commitments}
+	x := verifier.evaluate(p.Curve, id)
+	label := pvssLabel("share", id, deal.Commitments)
+	if err := p.verifyDleq(label, p.Generator, x, publicKey, share.Value, share.Proof); err != nil {
+		return fmt.Errorf("encrypted share %d is invalid", id)
+	}
+	return nil
+}
+
+// checkDeal checks the number of commitments and shares of deal and that the commitments are points
+// of the curve
+func (p Pvss) checkDeal(deal *PvssDeal) error {
+	if deal == nil || uint32(len(deal.Commitments)) != p.Threshold || uint32(len(deal.Shares)) != p.Limit {
+		return fmt.Errorf("invalid deal")
+	}
+	for _, c := range deal.Commitments {
+		if c == nil || c.CurveName() != p.Curve.Name || !c.IsOnCurve() {
+			return fmt.Errorf("invalid commitments")
+		}
+	}
+	return nil
+}
+
+// checkPublicKeys checks that there is a valid public key for every participant
+func (p Pvss) checkPublicKeys(publicKeys []curves.Point) error {
+	if uint32(len(publicKeys)) != p.Limit {
+		return fmt.Errorf("expected %d public keys", p.Limit)
+	}
+	for i, y := range publicKeys {
+		if !p.validPoint(y) {
+			return fmt.Errorf("invalid public key for %d", i+1)
+		}
+	}
+	return nil
+}
+
+// validPoint returns whether point is a point of the curve other than the identity
+func (p Pvss) validPoint(point curves.Point) bool {
+	return point != nil && point.CurveName() == p.Curve.Name && point.IsOnCurve() && !point.IsIdentity()
+}
+
+// proveDleq proves that h1 = x·g1 and h2 = x·g2
+func (p Pvss) proveDleq(label []byte, g1, h1, g2, h2 curves.Point, x curves.Scalar, reader io.Reader) *DleqProof {
+	k := p.Curve.Scalar.Random(reader)
+	a1 := g1.Mul(k)
+	a2 := g2.Mul(k)
+	c := p.Curve.Scalar.Hash(dleqTranscript(label, g1, h1, g2, h2, a1, a2))
+	return &DleqProof{C: c, S: k.Sub(c.Mul(x))}
+}
+
+// verifyDleq checks proof that h1 and h2 have the same discrete logarithm to the bases g1 and g2
+func (p Pvss) verifyDleq(label []byte, g1, h1, g2, h2 curves.Point, proof *DleqProof) error {
+	if proof == nil || proof.C == nil || proof.S == nil {
+		return fmt.Errorf("invalid proof")
+	}
+	a1 := g1.Mul(proof.S).Add(h1.Mul(proof.C))
+	a2 := g2.Mul(proof.S).Add(h2.Mul(proof.C))
+	c := p.Curve.Scalar.Hash(dleqTranscript(label, g1, h1, g2, h2, a1, a2))
+	if c.Cmp(proof.C) != 0 {
+		return fmt.Errorf("dleq verification failed")
+	}
+	return nil
+}
+
+// pvssLabel binds a proof to its purpose, the participant and the commitments of the deal
+func pvssLabel(purpose string, id uint32, commitments []curves.Point) []byte {
+	label := []byte("kryptology pvss " + purpose)
+	var idBytes [4]byte
+	binary.BigEndian.PutUint32(idBytes[:], id)
+	label = append(label, idBytes[:]...)
+	for _, c := range commitments {
+		label = append(label, c.ToAffineCompressed()...)
+	}
+	return label
+}
+
+// dleqTranscript serializes the label and the points hashed into the challenge of a DLEQ proof
+func dleqTranscript(label []byte, points ...curves.Point) []byte {
+	transcript := append([]byte{}, label...)
+	for _, point := range points {
+		transcript = append(transcript, point.ToAffineCompressed()...)
+	}
+	return transcript
+}
@@ -0,0 +1,149 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+// pvssKeys generates a key pair for every participant
+func pvssKeys(curve *curves.Curve, limit uint32) ([]curves.Scalar, []curves.Point) {
+	secretKeys := make([]curves.Scalar, limit)
+	publicKeys := make([]curves.Point, limit)
+	for i := range secretKeys {
+		secretKeys[i] = curve.Scalar.Random(crand.Reader)
+		publicKeys[i] = curve.ScalarBaseMult(secretKeys[i])
+	}
+	return secretKeys, publicKeys
+}
+
+func TestPvssInvalidArgs(t *testing.T) {
+	curve := curves.K256()
+	_, err := NewPvss(3, 2, curve)
+	require.Error(t, err)
+	_, err = NewPvss(1, 2, curve)
+	require.Error(t, err)
+	_, err = NewPvss(2, 256, curve)
+	require.Error(t, err)
+	_, err = NewPvss(2, 3, nil)
+	require.Error(t, err)
+
+	scheme, err := NewPvss(2, 3, curve)
+	require.NoError(t, err)
+	_, publicKeys := pvssKeys(curve, 3)
+	_, err = scheme.Split(curve.Scalar.Zero(), publicKeys, crand.Reader)
+	require.Error(t, err)
+	_, err = scheme.Split(curve.Scalar.Random(crand.Reader), publicKeys[:2], crand.Reader)
+	require.Error(t, err)
+	_, err = scheme.Split(curve.Scalar.Random(crand.Reader), []curves.Point{publicKeys[0], publicKeys[1], curve.NewIdentityPoint()}, crand.Reader)
+	require.Error(t, err)
+	_, err = scheme.Split(curve.Scalar.Random(crand.Reader), []curves.Point{publicKeys[0], publicKeys[1], curves.ED25519().NewGeneratorPoint()}, crand.Reader)
+	require.Error(t, err)
+}
+
+func TestPvss(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256(), curves.ED25519()} {
+		scheme, err := NewPvss(3, 5, curve)
+		require.NoError(t, err)
+		require.False(t, scheme.Generator.Equal(curve.NewGeneratorPoint()))
+		secretKeys, publicKeys := pvssKeys(curve, 5)
+		secret := curve.Scalar.Random(crand.Reader)
+		deal, err := scheme.Split(secret, publicKeys, crand.Reader)
+		require.NoError(t, err)
+		require.NoError(t, scheme.Verify(deal, publicKeys))
+
+		decrypted := make([]*PvssShare, 5)
+		for i := range decrypted {
+			id := uint32(i + 1)
+			decrypted[i], err = scheme.Decrypt(deal, id, secretKeys[i], crand.Reader)
+			require.NoError(t, err)
+			require.NoError(t, scheme.VerifyDecryption(deal, publicKeys[i], decrypted[i]))
+		}
+
+		expected := curve.ScalarBaseMult(secret)
+		for _, set := range [][]int{{0, 1, 2}, {0, 2, 4}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+			shares := make([]*PvssShare, len(set))
+			for i, index := range set {
+				shares[i] = decrypted[index]
+			}
+			recovered, err := scheme.Combine(shares...)
+			require.NoError(t, err)
+			require.True(t, recovered.Equal(expected))
+		}
+		_, err = scheme.Combine(decrypted[0], decrypted[1])
+		require.Error(t, err)
+		_, err = scheme.Combine(decrypted[0], decrypted[1], decrypted[1])
+		require.Error(t, err)
+
+		// A participant cannot decrypt the share of another one
+		_, err = scheme.Decrypt(deal, 2, secretKeys[0], crand.Reader)
+		require.Error(t, err)
+	}
+}
+
+func TestPvssDetectsCheatingDealer(t *testing.T) {
+	curve := curves.K256()
+	scheme, err := NewPvss(2, 3, curve)
+	require.NoError(t, err)
+	secretKeys, publicKeys := pvssKeys(curve, 3)
+	deal, err := scheme.Split(curve.Scalar.Random(crand.Reader), publicKeys, crand.Reader)
+	require.NoError(t, err)
+
+	// An encrypted share that does not match the commitments
+	original := deal.Shares[1].Value
+	deal.Shares[1].Value = original.Add(curve.NewGeneratorPoint())
+	err = scheme.Verify(deal, publicKeys)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "share 2")
+	_, err = scheme.Decrypt(deal, 2, secretKeys[1], crand.Reader)
+	require.Error(t, err)
+	_, err = scheme.Decrypt(deal, 1, secretKeys[0], crand.Reader)
+	require.NoError(t, err)
+	deal.Shares[1].Value = original
+
+	// Commitments to another polynomial
+	other, err := scheme.Split(curve.Scalar.Random(crand.Reader), publicKeys, crand.Reader)
+	require.NoError(t, err)
+	commitments := deal.Commitments
+	deal.Commitments = other.Commitments
+	require.Error(t, scheme.Verify(deal, publicKeys))
+	deal.Commitments = commitments
+
+	// Shares encrypted to the wrong keys
+	_, otherKeys := pvssKeys(curve, 3)
+	require.Error(t, scheme.Verify(deal, otherKeys))
+
+	// A missing proof
+	proof := deal.Shares[0].Proof
+	deal.Shares[0].Proof = nil
+	require.Error(t, scheme.Verify(deal, publicKeys))
+	deal.Shares[0].Proof = proof
+	require.NoError(t, scheme.Verify(deal, publicKeys))
+}
+
+func TestPvssDetectsWrongDecryption(t *testing.T) {
+	curve := curves.ED25519()
+	scheme, err := NewPvss(2, 3, curve)
+	require.NoError(t, err)
+	secretKeys, publicKeys := pvssKeys(curve, 3)
+	deal, err := scheme.Split(curve.Scalar.Random(crand.Reader), publicKeys, crand.Reader)
+	require.NoError(t, err)
+	share, err := scheme.Decrypt(deal, 1, secretKeys[0], crand.Reader)
+	require.NoError(t, err)
+
+	wrong := &PvssShare{Id: 1, Value: share.Value.Add(curve.NewGeneratorPoint()), Proof: share.Proof}
+	require.Error(t, scheme.VerifyDecryption(deal, publicKeys[0], wrong))
+	require.Error(t, scheme.VerifyDecryption(deal, publicKeys[1], share))
+	moved := &PvssShare{Id: 2, Value: share.Value, Proof: share.Proof}
+	require.Error(t, scheme.VerifyDecryption(deal, publicKeys[1], moved))
+	require.Error(t, scheme.VerifyDecryption(deal, publicKeys[0], &PvssShare{Id: 1, Value: share.Value}))
+}
@@ -0,0 +1,225 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+// Repair recovers the share of a lost identifier r from a set of helpers that hold shares of the
+// same Feldman sharing, without any helper learning more than its own share.
+//
+// Every helper i computes δ_i = λ_i(r)·s_i, where λ_i(r) is its Lagrange coefficient at r over the
+// helpers, and splits it into random pieces δ_ij that sum to δ_i, one for each helper j. It commits to
+// the pieces with δ_ij·G. Every helper j sums the pieces it receives into σ_j and sends it to the holder
+// of r, who sums them into s_r = Σ λ_i(r)·s_i. The commitments and the FeldmanVerifier let every
+// step be checked.
+type Repair struct {
+	Curve *curves.Curve
+}
+
+// RepairCommitments are the commitments δ_ij·G of a helper to its pieces, indexed by the helper j
+type RepairCommitments struct {
+	Commitments map[uint32]curves.Point
+}
+
+// NewRepair creates a repair of shares on curve
+func NewRepair(curve *curves.Curve) (*Repair, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("invalid curve")
+	}
+	return &Repair{curve}, nil
+}
+
+// Split computes the pieces of the helper that holds share to repair the share of lostId. verifier is
+// the FeldmanVerifier of the sharing and helpers the ids of the helpers, which must include share.Id and
+// reach the threshold. It returns the commitments to broadcast and the pieces, where the piece with Id j
+// is sent to the helper j.
+func (r Repair) Split(verifier *FeldmanVerifier, share *ShamirShare, helpers []uint32, lostId uint32, reader io.Reader) (*RepairCommitments, map[uint32]*ShamirShare, error) {
+	if share == nil {
+		return nil, nil, fmt.Errorf("invalid share")
+	}
+	coefficients, err := r.helperCoefficients(verifier, helpers, lostId)
+	if err != nil {
+		return nil, nil, err
+	}
+	lambda, ok := coefficients[share.Id]
+	if !ok {
+		return nil, nil, fmt.Errorf("share %d is not one of the helpers", share.Id)
+	}
+	if err = verifier.Verify(share); err != nil {
+		return nil, nil, fmt.Errorf("share %d does not match the commitments", share.Id)
+	}
+	sc, err := r.Curve.Scalar.SetBytes(share.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The last piece is δ_i minus the sum of the random pieces
+	remainder := sc.Mul(lambda)
+	commitments := &RepairCommitments{Commitments: make(map[uint32]curves.Point, len(helpers))}
+	pieces := make(map[uint32]*ShamirShare, len(helpers))
+	for k, id := range helpers {
+		piece := remainder
+		if k < len(helpers)-1 {
+			piece = r.Curve.Scalar.Random(reader)
+			remainder = remainder.Sub(piece)
+		}
+		commitments.Commitments[id] = r.Curve.ScalarBaseMult(piece)
+		pieces[id] = &ShamirShare{Id: id, Value: piece.Bytes()}
+	}
+	return commitments, pieces, nil
+}
+
+// Aggregate sums the pieces received by the helper id into σ_j, to be sent to the holder of lostId.
+// commitments and pieces are indexed by the helper that sent them. The commitments of every helper are
+// checked to sum to λ_i(r) times its public share, and every piece against its commitment.
+func (r Repair) Aggregate(id uint32, verifier *FeldmanVerifier, helpers []uint32, lostId uint32, commitments map[uint32]*RepairCommitments, pieces map[uint32]*ShamirShare) (*ShamirShare, error) {
+	if err := r.verifyCommitments(verifier, helpers, lostId, commitments); err != nil {
+		return nil, err
+	}
+	isHelper := false
+	for _, helper := range helpers {
+		isHelper = isHelper || helper == id
+	}
+	if !isHelper {
+		return nil, fmt.Errorf("%d is not one of the helpers", id)
+	}
+	if len(pieces) != len(helpers) {
+		return nil, fmt.Errorf("expected a piece from every helper")
+	}
+	sum := r.Curve.Scalar.Zero()
+	for _, dealer := range helpers {
+		piece := pieces[dealer]
+		if piece == nil || piece.Id != id {
+			return nil, fmt.Errorf("invalid piece from %d", dealer)
+		}
+		sc, err := r.Curve.Scalar.SetBytes(piece.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid piece from %d", dealer)
+		}
+		if !r.Curve.ScalarBaseMult(sc).Equal(commitments[dealer].Commitments[id]) {
+			return nil, fmt.Errorf("piece from %d does not match its commitment", dealer)
+		}
+		sum = sum.Add(sc)
+	}
+	return &ShamirShare{Id: id, Value: sum.Bytes()}, nil
+}
+
+// Combine computes the repaired share of lostId from the sums σ_j of the helpers, indexed by the helper.
+// Every sum is checked against the commitments to the pieces, and the repaired share against verifier.
+func (r Repair) Combine(verifier *FeldmanVerifier, helpers []uint32, lostId uint32, commitments map[uint32]*RepairCommitments, sums map[uint32]*ShamirShare) (*ShamirShare, error) {
+	if err := r.verifyCommitments(verifier, helpers, lostId, commitments); err != nil {
+		return nil, err
+	}
+	if len(sums) != len(helpers) {
+		return nil, fmt.Errorf("expected a sum from every helper")
+	}
+	value := r.Curve.Scalar.Zero()
+	for _, id := range helpers {
+		sum := sums[id]
+		if sum == nil || sum.Id != id {
+			return nil, fmt.Errorf("invalid sum from %d", id)
+		}
+		sc, err := r.Curve.Scalar.SetBytes(sum.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sum from %d", id)
+		}
+		expected := r.Curve.NewIdentityPoint()
+		for _, dealer := range helpers {
+			expected = expected.Add(commitments[dealer].Commitments[id])
+		}
+		if !r.Curve.ScalarBaseMult(sc).Equal(expected) {
+			return nil, fmt.Errorf("sum from %d does not match the commitments", id)
+		}
+		value = value.Add(sc)
+	}
+	share := &ShamirShare{Id: lostId, Value: value.Bytes()}
+	if err := verifier.Verify(share); err != nil {
+		return nil, fmt.Errorf("repaired share does not match the commitments")
+	}
+	return share, nil
+}
+
+// verifyCommitments checks that the commitments of every helper are to pieces of λ_i(r)·s_i
+func (r Repair) verifyCommitments(verifier *FeldmanVerifier, helpers []uint32, lostId uint32, commitments map[uint32]*RepairCommitments) error {
+	coefficients, err := r.helperCoefficients(verifier, helpers, lostId)
+	if err != nil {
+		return err
+	}
+	if len(commitments) != len(helpers) {
+		return fmt.Errorf("expected commitments from every helper")
+	}
+	for _, dealer := range helpers {
+		c := commitments[dealer]
+		if c == nil || len(c.Commitments) != len(helpers) {
+			return fmt.Errorf("invalid commitments from %d", dealer)
+		}
+		sum := r.Curve.NewIdentityPoint()
+		for _, id := range helpers {
+			point := c.Commitments[id]
+			if point == nil || point.CurveName() != r.Curve.Name || !point.IsOnCurve() {
+				return fmt.Errorf("invalid commitments from %d", dealer)
+			}
+			sum = sum.Add(point)
+		}
+		if !sum.Equal(verifier.evaluate(r.Curve, dealer).Mul(coefficients[dealer])) {
+			return fmt.Errorf("commitments from %d do not match its share", dealer)
+		}
+	}
+	return nil
+}
+
+// helperCoefficients checks the commitments and the helpers, and returns the Lagrange coefficients
+// at lostId of the helpers
+func (r Repair) helperCoefficients(verifier *FeldmanVerifier, helpers []uint32, lostId uint32) (map[uint32]curves.Scalar, error) {
+	if verifier == nil || len(verifier.Commitments) == 0 {
+		return nil, fmt.Errorf("invalid commitments")
+	}
+	for _, c := range verifier.Commitments {
+		if c == nil || c.CurveName() != r.Curve.Name || !c.IsOnCurve() {
+			return nil, fmt.Errorf("invalid commitments")
+		}
+	}
+	if lostId == 0 {
+		return nil, fmt.Errorf("invalid identifier")
+	}
+	if len(helpers) < len(verifier.Commitments) {
+		return nil, fmt.Errorf("fewer helpers than the threshold")
+	}
+	dups := make(map[uint32]bool, len(helpers))
+	for _, id := range helpers {
+		if id == 0 || id == lostId {
+			return nil, fmt.Errorf("invalid helper identifier %d", id)
+		}
+		if dups[id] {
+			return nil, fmt.Errorf("duplicate helper %d", id)
+		}
+		dups[id] = true
+	}
+
+	x := r.Curve.Scalar.New(int(lostId))
+	result := make(map[uint32]curves.Scalar, len(helpers))
+	for _, i := range helpers {
+		xi := r.Curve.Scalar.New(int(i))
+		num := r.Curve.Scalar.One()
+		den := r.Curve.Scalar.One()
+		for _, j := range helpers {
+			if i == j {
+				continue
+			}
+			xj := r.Curve.Scalar.New(int(j))
+			num = num.Mul(x.Sub(xj))
+			den = den.Mul(xi.Sub(xj))
+		}
+		result[i] = num.Div(den)
+	}
+	return result, nil
+}
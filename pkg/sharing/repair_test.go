@@ -0,0 +1,150 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+// runRepairSplit runs the first step of a repair for every helper
+func runRepairSplit(t *testing.T, repair *Repair, verifier *FeldmanVerifier, shares map[uint32]*ShamirShare, helpers []uint32, lostId uint32) (map[uint32]*RepairCommitments, map[uint32]map[uint32]*ShamirShare) {
+	commitments := make(map[uint32]*RepairCommitments, len(helpers))
+	pieces := make(map[uint32]map[uint32]*ShamirShare, len(helpers))
+	for _, id := range helpers {
+		var err error
+		commitments[id], pieces[id], err = repair.Split(verifier, shares[id], helpers, lostId, crand.Reader)
+		require.NoError(t, err)
+	}
+	return commitments, pieces
+}
+
+// received collects the pieces sent to the helper id
+func received(pieces map[uint32]map[uint32]*ShamirShare, id uint32) map[uint32]*ShamirShare {
+	result := make(map[uint32]*ShamirShare, len(pieces))
+	for dealer, p := range pieces {
+		result[dealer] = p[id]
+	}
+	return result
+}
+
+func TestRepair(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.ED25519()} {
+		feldman, err := NewFeldman(3, 5, curve)
+		require.NoError(t, err)
+		verifier, shares, err := feldman.Split(curve.Scalar.Random(crand.Reader), crand.Reader)
+		require.NoError(t, err)
+		repair, err := NewRepair(curve)
+		require.NoError(t, err)
+
+		for _, helpers := range [][]uint32{{1, 3, 5}, {1, 2, 3, 5}} {
+			lostId := uint32(4)
+			commitments, pieces := runRepairSplit(t, repair, verifier, sharesById(shares), helpers, lostId)
+			sums := make(map[uint32]*ShamirShare, len(helpers))
+			for _, id := range helpers {
+				sums[id], err = repair.Aggregate(id, verifier, helpers, lostId, commitments, received(pieces, id))
+				require.NoError(t, err)
+			}
+			repaired, err := repair.Combine(verifier, helpers, lostId, commitments, sums)
+			require.NoError(t, err)
+			require.Equal(t, shares[lostId-1].Id, repaired.Id)
+			require.Equal(t, shares[lostId-1].Value, repaired.Value)
+		}
+
+		// A new identifier can be enrolled the same way
+		helpers := []uint32{2, 3, 4}
+		commitments, pieces := runRepairSplit(t, repair, verifier, sharesById(shares), helpers, 6)
+		sums := make(map[uint32]*ShamirShare, len(helpers))
+		for _, id := range helpers {
+			sums[id], err = repair.Aggregate(id, verifier, helpers, 6, commitments, received(pieces, id))
+			require.NoError(t, err)
+		}
+		enrolled, err := repair.Combine(verifier, helpers, 6, commitments, sums)
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(enrolled))
+	}
+}
+
+func TestRepairInvalidArgs(t *testing.T) {
+	curve := curves.K256()
+	feldman, err := NewFeldman(3, 5, curve)
+	require.NoError(t, err)
+	verifier, shares, err := feldman.Split(curve.Scalar.Random(crand.Reader), crand.Reader)
+	require.NoError(t, err)
+	_, err = NewRepair(nil)
+	require.Error(t, err)
+	repair, err := NewRepair(curve)
+	require.NoError(t, err)
+
+	for _, args := range []struct {
+		helpers []uint32
+		lostId  uint32
+	}{
+		{[]uint32{1, 2}, 4},
+		{[]uint32{1, 2, 4}, 4},
+		{[]uint32{1, 2, 2}, 4},
+		{[]uint32{1, 2, 3}, 0},
+		{[]uint32{2, 3, 5}, 4},
+	} {
+		_, _, err = repair.Split(verifier, shares[0], args.helpers, args.lostId, crand.Reader)
+		require.Error(t, err)
+	}
+	_, _, err = repair.Split(nil, shares[0], []uint32{1, 2, 3}, 4, crand.Reader)
+	require.Error(t, err)
+	bad := &ShamirShare{Id: 1, Value: curve.Scalar.Random(crand.Reader).Bytes()}
+	_, _, err = repair.Split(verifier, bad, []uint32{1, 2, 3}, 4, crand.Reader)
+	require.Error(t, err)
+}
+
+func TestRepairDetectsCheater(t *testing.T) {
+	curve := curves.K256()
+	feldman, err := NewFeldman(2, 4, curve)
+	require.NoError(t, err)
+	verifier, shares, err := feldman.Split(curve.Scalar.Random(crand.Reader), crand.Reader)
+	require.NoError(t, err)
+	repair, err := NewRepair(curve)
+	require.NoError(t, err)
+	helpers := []uint32{1, 3}
+	lostId := uint32(2)
+	commitments, pieces := runRepairSplit(t, repair, verifier, sharesById(shares), helpers, lostId)
+
+	// Helper 3 splits the wrong value with consistent commitments
+	other, _, err := repair.Split(verifier, shares[0], helpers, lostId, crand.Reader)
+	require.NoError(t, err)
+	cheat := map[uint32]*RepairCommitments{1: commitments[1], 3: other}
+	_, err = repair.Aggregate(1, verifier, helpers, lostId, cheat, received(pieces, 1))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "from 3")
+
+	// Helper 3 sends a piece that does not match its commitment
+	input := received(pieces, 1)
+	input[3] = &ShamirShare{Id: 1, Value: curve.Scalar.Random(crand.Reader).Bytes()}
+	_, err = repair.Aggregate(1, verifier, helpers, lostId, commitments, input)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "from 3")
+
+	// Helper 1 sends a wrong sum to the holder of the lost share
+	sums := make(map[uint32]*ShamirShare, len(helpers))
+	for _, id := range helpers {
+		sums[id], err = repair.Aggregate(id, verifier, helpers, lostId, commitments, received(pieces, id))
+		require.NoError(t, err)
+	}
+	_, err = repair.Combine(verifier, helpers, lostId, commitments, sums)
+	require.NoError(t, err)
+	sums[1] = &ShamirShare{Id: 1, Value: curve.Scalar.Random(crand.Reader).Bytes()}
+	_, err = repair.Combine(verifier, helpers, lostId, commitments, sums)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "from 1")
+
+	// Only helpers aggregate
+	_, err = repair.Aggregate(4, verifier, helpers, lostId, commitments, received(pieces, 1))
+	require.Error(t, err)
+}
@@ -0,0 +1,151 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+// Reshare moves a secret shared with Feldman commitments from an old committee to a new committee
+// with its own threshold and limit, without reconstructing the secret or changing the public key.
+//
+// Every holder i of a quorum of the old committee splits λ_i·s_i with Feldman, where λ_i is its
+// Lagrange coefficient over the quorum. The new holder j sums the sub-shares it receives, and the
+// sums of the commitments are the FeldmanVerifier of the new sharing.
+type Reshare struct {
+	Threshold, Limit uint32
+	Curve            *curves.Curve
+}
+
+// NewReshare creates a resharing to a new committee of limit holders with the given threshold
+func NewReshare(threshold, limit uint32, curve *curves.Curve) (*Reshare, error) {
+	if _, err := NewFeldman(threshold, limit, curve); err != nil {
+		return nil, err
+	}
+	return &Reshare{threshold, limit, curve}, nil
+}
+
+// Split sub-shares the share of an old holder to the new committee. oldVerifier is the FeldmanVerifier
+// of the old committee and quorum the ids of the old holders that take part, which must include share.Id
+// and reach the old threshold. It returns the commitments to broadcast and the sub-shares, where the
+// sub-share at index j-1 is sent to the new holder j.
+func (r Reshare) Split(oldVerifier *FeldmanVerifier, share *ShamirShare, quorum []uint32, reader io.Reader) (*FeldmanVerifier, []*ShamirShare, error) {
+	if share == nil {
+		return nil, nil, fmt.Errorf("invalid share")
+	}
+	coefficients, err := r.quorumCoefficients(oldVerifier, quorum)
+	if err != nil {
+		return nil, nil, err
+	}
+	lambda, ok := coefficients[share.Id]
+	if !ok {
+		return nil, nil, fmt.Errorf("share %d is not in the quorum", share.Id)
+	}
+	if err = oldVerifier.Verify(share); err != nil {
+		return nil, nil, fmt.Errorf("share %d does not match the old commitments", share.Id)
+	}
+	sc, err := r.Curve.Scalar.SetBytes(share.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+	feldman := Feldman{r.Threshold, r.Limit, r.Curve}
+	return feldman.Split(sc.Mul(lambda), reader)
+}
+
+// Combine computes the share of the new holder id from the commitments and sub-shares of every old
+// holder of the quorum, both indexed by the id of the old holder. Each sub-share is checked against
+// its commitments, and the constant term of the commitments against the public share of its dealer
+// under oldVerifier. It returns the new share and the FeldmanVerifier of the new committee, whose
+// public key is the one of oldVerifier.
+func (r Reshare) Combine(id uint32, oldVerifier *FeldmanVerifier, quorum []uint32, verifiers map[uint32]*FeldmanVerifier, subShares map[uint32]*ShamirShare) (*ShamirShare, *FeldmanVerifier, error) {
+	if id == 0 || id > r.Limit {
+		return nil, nil, fmt.Errorf("invalid share identifier")
+	}
+	coefficients, err := r.quorumCoefficients(oldVerifier, quorum)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(verifiers) != len(quorum) || len(subShares) != len(quorum) {
+		return nil, nil, fmt.Errorf("expected a sub-share from every member of the quorum")
+	}
+
+	value := r.Curve.Scalar.Zero()
+	commitments := make([]curves.Point, r.Threshold)
+	for i := range commitments {
+		commitments[i] = r.Curve.NewIdentityPoint()
+	}
+	for _, dealer := range quorum {
+		verifier, subShare := verifiers[dealer], subShares[dealer]
+		if verifier == nil || subShare == nil {
+			return nil, nil, fmt.Errorf("missing sub-share from %d", dealer)
+		}
+		if uint32(len(verifier.Commitments)) != r.Threshold {
+			return nil, nil, fmt.Errorf("invalid number of commitments from %d", dealer)
+		}
+		for _, c := range verifier.Commitments {
+			if c == nil || c.CurveName() != r.Curve.Name || !c.IsOnCurve() {
+				return nil, nil, fmt.Errorf("invalid commitment from %d", dealer)
+			}
+		}
+		if subShare.Id != id {
+			return nil, nil, fmt.Errorf("sub-share from %d is for %d", dealer, subShare.Id)
+		}
+		if err = verifier.Verify(subShare); err != nil {
+			return nil, nil, fmt.Errorf("invalid sub-share from %d", dealer)
+		}
+		// The dealer must have shared λ_i·s_i, so its constant commitment is λ_i times its old public share
+		expected := oldVerifier.evaluate(r.Curve, dealer).Mul(coefficients[dealer])
+		if !verifier.Commitments[0].Equal(expected) {
+			return nil, nil, fmt.Errorf("sub-sharing from %d does not match the old commitments", dealer)
+		}
+
+		sc, _ := r.Curve.Scalar.SetBytes(subShare.Value)
+		value = value.Add(sc)
+		for i, c := range verifier.Commitments {
+			commitments[i] = commitments[i].Add(c)
+		}
+	}
+	if !commitments[0].Equal(oldVerifier.Commitments[0]) {
+		return nil, nil, fmt.Errorf("resharing changed the public key")
+	}
+	return &ShamirShare{Id: id, Value: value.Bytes()}, &FeldmanVerifier{Commitments: commitments}, nil
+}
+
+// quorumCoefficients checks the old commitments and the quorum, and returns the Lagrange coefficients
+// at 0 of the quorum
+func (r Reshare) quorumCoefficients(oldVerifier *FeldmanVerifier, quorum []uint32) (map[uint32]curves.Scalar, error) {
+	if oldVerifier == nil || len(oldVerifier.Commitments) == 0 {
+		return nil, fmt.Errorf("invalid old commitments")
+	}
+	for _, c := range oldVerifier.Commitments {
+		if c == nil || c.CurveName() != r.Curve.Name || !c.IsOnCurve() {
+			return nil, fmt.Errorf("invalid old commitments")
+		}
+	}
+	if len(quorum) < len(oldVerifier.Commitments) {
+		return nil, fmt.Errorf("quorum is smaller than the old threshold")
+	}
+	dups := make(map[uint32]bool, len(quorum))
+	for _, id := range quorum {
+		if id == 0 {
+			return nil, fmt.Errorf("invalid identifier")
+		}
+		if dups[id] {
+			return nil, fmt.Errorf("duplicate identifier %d in quorum", id)
+		}
+		dups[id] = true
+	}
+	shamir := &Shamir{
+		threshold: uint32(len(oldVerifier.Commitments)),
+		limit:     uint32(len(quorum)),
+		curve:     r.Curve,
+	}
+	return shamir.LagrangeCoeffs(quorum)
+}
@@ -0,0 +1,173 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+// runReshare moves the shares of quorum to a new threshold-of-limit committee
+func runReshare(t *testing.T, curve *curves.Curve, oldVerifier *FeldmanVerifier, shares map[uint32]*ShamirShare, quorum []uint32, threshold, limit uint32) (*FeldmanVerifier, []*ShamirShare) {
+	reshare, err := NewReshare(threshold, limit, curve)
+	require.NoError(t, err)
+	verifiers := make(map[uint32]*FeldmanVerifier, len(quorum))
+	subShares := make(map[uint32][]*ShamirShare, len(quorum))
+	for _, id := range quorum {
+		verifiers[id], subShares[id], err = reshare.Split(oldVerifier, shares[id], quorum, crand.Reader)
+		require.NoError(t, err)
+	}
+
+	var newVerifier *FeldmanVerifier
+	newShares := make([]*ShamirShare, limit)
+	for j := uint32(1); j <= limit; j++ {
+		received := make(map[uint32]*ShamirShare, len(quorum))
+		for _, id := range quorum {
+			received[id] = subShares[id][j-1]
+		}
+		share, verifier, err := reshare.Combine(j, oldVerifier, quorum, verifiers, received)
+		require.NoError(t, err)
+		require.NoError(t, verifier.Verify(share))
+		if newVerifier != nil {
+			for k, c := range newVerifier.Commitments {
+				require.True(t, c.Equal(verifier.Commitments[k]))
+			}
+		}
+		newVerifier = verifier
+		newShares[j-1] = share
+	}
+	return newVerifier, newShares
+}
+
+func sharesById(shares []*ShamirShare) map[uint32]*ShamirShare {
+	result := make(map[uint32]*ShamirShare, len(shares))
+	for _, share := range shares {
+		result[share.Id] = share
+	}
+	return result
+}
+
+func TestReshare(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.ED25519()} {
+		feldman, err := NewFeldman(3, 5, curve)
+		require.NoError(t, err)
+		secret := curve.Scalar.Random(crand.Reader)
+		verifier, shares, err := feldman.Split(secret, crand.Reader)
+		require.NoError(t, err)
+
+		// Grow the committee to 4 of 7
+		newVerifier, newShares := runReshare(t, curve, verifier, sharesById(shares), []uint32{1, 3, 5}, 4, 7)
+		require.Len(t, newVerifier.Commitments, 4)
+		require.True(t, newVerifier.Commitments[0].Equal(verifier.Commitments[0]))
+		newFeldman, err := NewFeldman(4, 7, curve)
+		require.NoError(t, err)
+		recovered, err := newFeldman.Combine(newShares[1], newShares[2], newShares[4], newShares[6])
+		require.NoError(t, err)
+		require.Equal(t, 0, recovered.Cmp(secret))
+		_, err = newFeldman.Combine(newShares[0], newShares[1], newShares[2])
+		require.Error(t, err)
+
+		// Shrink it to 2 of 3 with a quorum larger than the threshold
+		smallVerifier, smallShares := runReshare(t, curve, newVerifier, sharesById(newShares), []uint32{2, 3, 4, 6, 7}, 2, 3)
+		require.True(t, smallVerifier.Commitments[0].Equal(verifier.Commitments[0]))
+		smallFeldman, err := NewFeldman(2, 3, curve)
+		require.NoError(t, err)
+		recovered, err = smallFeldman.Combine(smallShares[0], smallShares[2])
+		require.NoError(t, err)
+		require.Equal(t, 0, recovered.Cmp(secret))
+	}
+}
+
+func TestReshareSplitInvalidArgs(t *testing.T) {
+	curve := curves.K256()
+	feldman, err := NewFeldman(3, 5, curve)
+	require.NoError(t, err)
+	verifier, shares, err := feldman.Split(curve.Scalar.Random(crand.Reader), crand.Reader)
+	require.NoError(t, err)
+
+	_, err = NewReshare(1, 3, curve)
+	require.Error(t, err)
+	_, err = NewReshare(3, 2, curve)
+	require.Error(t, err)
+	reshare, err := NewReshare(2, 3, curve)
+	require.NoError(t, err)
+
+	// The quorum must reach the old threshold, contain the share and have no duplicates
+	_, _, err = reshare.Split(verifier, shares[0], []uint32{1, 2}, crand.Reader)
+	require.Error(t, err)
+	_, _, err = reshare.Split(verifier, shares[0], []uint32{2, 3, 4}, crand.Reader)
+	require.Error(t, err)
+	_, _, err = reshare.Split(verifier, shares[0], []uint32{1, 2, 2}, crand.Reader)
+	require.Error(t, err)
+	_, _, err = reshare.Split(nil, shares[0], []uint32{1, 2, 3}, crand.Reader)
+	require.Error(t, err)
+	_, _, err = reshare.Split(verifier, nil, []uint32{1, 2, 3}, crand.Reader)
+	require.Error(t, err)
+
+	// A share that does not match the old commitments is refused
+	bad := &ShamirShare{Id: 1, Value: curve.Scalar.Random(crand.Reader).Bytes()}
+	_, _, err = reshare.Split(verifier, bad, []uint32{1, 2, 3}, crand.Reader)
+	require.Error(t, err)
+}
+
+func TestReshareCombineDetectsCheater(t *testing.T) {
+	curve := curves.K256()
+	feldman, err := NewFeldman(2, 3, curve)
+	require.NoError(t, err)
+	verifier, shares, err := feldman.Split(curve.Scalar.Random(crand.Reader), crand.Reader)
+	require.NoError(t, err)
+	reshare, err := NewReshare(2, 4, curve)
+	require.NoError(t, err)
+
+	quorum := []uint32{1, 2}
+	verifiers := make(map[uint32]*FeldmanVerifier)
+	subShares := make(map[uint32][]*ShamirShare)
+	for _, id := range quorum {
+		verifiers[id], subShares[id], err = reshare.Split(verifier, shares[id-1], quorum, crand.Reader)
+		require.NoError(t, err)
+	}
+	received := func() map[uint32]*ShamirShare {
+		return map[uint32]*ShamirShare{1: subShares[1][0], 2: subShares[2][0]}
+	}
+	_, _, err = reshare.Combine(1, verifier, quorum, verifiers, received())
+	require.NoError(t, err)
+
+	// Old holder 2 shares a different secret with consistent commitments
+	newFeldman, err := NewFeldman(2, 4, curve)
+	require.NoError(t, err)
+	cheatVerifier, cheatShares, err := newFeldman.Split(curve.Scalar.Random(crand.Reader), crand.Reader)
+	require.NoError(t, err)
+	cheat := map[uint32]*FeldmanVerifier{1: verifiers[1], 2: cheatVerifier}
+	input := received()
+	input[2] = cheatShares[0]
+	_, _, err = reshare.Combine(1, verifier, quorum, cheat, input)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "from 2")
+
+	// A sub-share that does not match its commitments
+	input = received()
+	input[1] = &ShamirShare{Id: 1, Value: curve.Scalar.Random(crand.Reader).Bytes()}
+	_, _, err = reshare.Combine(1, verifier, quorum, verifiers, input)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "from 1")
+
+	// A sub-share for another holder, a missing sub-share and a bad identifier
+	input = received()
+	input[1] = subShares[1][1]
+	_, _, err = reshare.Combine(1, verifier, quorum, verifiers, input)
+	require.Error(t, err)
+	input = received()
+	delete(input, 2)
+	_, _, err = reshare.Combine(1, verifier, quorum, verifiers, input)
+	require.Error(t, err)
+	_, _, err = reshare.Combine(5, verifier, quorum, verifiers, received())
+	require.Error(t, err)
+}
@@ -0,0 +1,49 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+func TestRistretto255FeldmanAllCombinations(t *testing.T) {
+	curve := curves.RISTRETTO255()
+	scheme, err := NewFeldman(3, 5, curve)
+	require.Nil(t, err)
+	require.NotNil(t, scheme)
+
+	secret := curve.Scalar.Hash([]byte("test"))
+	verifiers, shares, err := scheme.Split(secret, crand.Reader)
+	require.Nil(t, err)
+	for _, s := range shares {
+		err = verifiers.Verify(s)
+		require.Nil(t, err)
+	}
+	// There are 5*4*3 possible combinations
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 5; j++ {
+			if i == j {
+				continue
+			}
+			for k := 0; k < 5; k++ {
+				if i == k || j == k {
+					continue
+				}
+
+				rSecret, err := scheme.Combine(shares[i], shares[j], shares[k])
+				require.Nil(t, err)
+				require.NotNil(t, rSecret)
+				require.Equal(t, rSecret, secret)
+			}
+		}
+	}
+}
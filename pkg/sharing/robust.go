@@ -0,0 +1,228 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+// errInconsistentSystem is returned by solveLinear for systems without a solution
+var errInconsistentSystem = fmt.Errorf("linear system has no solution")
+
+// CombineRobust reconstructs the secret from shares of which some may be corrupted, and returns the
+// identifiers of the shares that are not on the sharing polynomial. With n valid shares and a threshold
+// of t, Berlekamp-Welch decoding corrects up to (n-t)/2 corrupted shares. With exactly t shares nothing
+// can be detected. Shares that fail to decode or have an invalid identifier are reported as corrupted.
+func (s Shamir) CombineRobust(shares ...*ShamirShare) (curves.Scalar, []uint32, error) {
+	ids, xs, ys, bad, err := s.robustInputs(shares)
+	if err != nil {
+		return nil, nil, err
+	}
+	poly, err := s.berlekampWelch(xs, ys)
+	if err != nil {
+		return nil, nil, err
+	}
+	corrupted := 0
+	for i, x := range xs {
+		if poly.Evaluate(x).Cmp(ys[i]) != 0 {
+			bad = append(bad, ids[i])
+			corrupted++
+		}
+	}
+	// Beyond (n-t)/2 corrupted shares the decoded polynomial is not unique
+	if corrupted > (len(xs)-int(s.threshold))/2 {
+		return nil, nil, fmt.Errorf("too many corrupted shares")
+	}
+	sort.Slice(bad, func(i, j int) bool { return bad[i] < bad[j] })
+	return poly.Coefficients[0], bad, nil
+}
+
+// robustInputs splits the shares into the identifiers and coordinates of the valid shares and the
+// identifiers of the invalid ones
+func (s Shamir) robustInputs(shares []*ShamirShare) ([]uint32, []curves.Scalar, []curves.Scalar, []uint32, error) {
+	dups := make(map[uint32]bool, len(shares))
+	ids := make([]uint32, 0, len(shares))
+	xs := make([]curves.Scalar, 0, len(shares))
+	ys := make([]curves.Scalar, 0, len(shares))
+	bad := make([]uint32, 0)
+	for _, share := range shares {
+		if share == nil {
+			return nil, nil, nil, nil, fmt.Errorf("invalid share")
+		}
+		if dups[share.Id] {
+			return nil, nil, nil, nil, fmt.Errorf("duplicate share")
+		}
+		dups[share.Id] = true
+		if share.Validate(s.curve) != nil || share.Id > s.limit {
+			bad = append(bad, share.Id)
+			continue
+		}
+		y, _ := s.curve.Scalar.SetBytes(share.Value)
+		ids = append(ids, share.Id)
+		xs = append(xs, s.curve.Scalar.New(int(share.Id)))
+		ys = append(ys, y)
+	}
+	if len(xs) < int(s.threshold) {
+		return nil, nil, nil, nil, fmt.Errorf("invalid number of shares")
+	}
+	return ids, xs, ys, bad, nil
+}
+
+// berlekampWelch finds the polynomial P of degree less than the threshold that agrees with all but at
+// most e = (n-t)/2 of the points. It solves Q(x_i) = y_i·E(x_i) for Q of degree less than t+e and a
+// monic E of degree e, whose roots are the corrupted points, and returns P = Q / E.
+func (s Shamir) berlekampWelch(xs, ys []curves.Scalar) (*Polynomial, error) {
+	k := int(s.threshold)
+	e := (len(xs) - k) / 2
+	columns := k + 2*e
+
+	// Row i: Σ_j q_j x_i^j - y_i Σ_{j<e} e_j x_i^j = y_i x_i^e
+	matrix := make([][]curves.Scalar, len(xs))
+	for i, x := range xs {
+		row := make([]curves.Scalar, columns+1)
+		power := s.curve.Scalar.One()
+		for j := 0; j < k+e; j++ {
+			row[j] = power
+			if j < e {
+				row[k+e+j] = ys[i].Mul(power).Neg()
+			}
+			if j == e {
+				row[columns] = ys[i].Mul(power)
+			}
+			power = power.Mul(x)
+		}
+		matrix[i] = row
+	}
+	solution, err := solveLinear(s.curve, matrix, columns)
+	if err == errInconsistentSystem {
+		return nil, fmt.Errorf("too many corrupted shares")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// P = Q / E, E is monic
+	q := solution[:k+e]
+	eCoeffs := append(solution[k+e:], s.curve.Scalar.One())
+	quotient := make([]curves.Scalar, k)
+	remainder := make([]curves.Scalar, len(q))
+	copy(remainder, q)
+	for i := k - 1; i >= 0; i-- {
+		c := remainder[i+e]
+		quotient[i] = c
+		for j := 0; j <= e; j++ {
+			remainder[i+j] = remainder[i+j].Sub(c.Mul(eCoeffs[j]))
+		}
+	}
+	for _, r := range remainder {
+		if !r.IsZero() {
+			return nil, fmt.Errorf("too many corrupted shares")
+		}
+	}
+	return &Polynomial{Coefficients: quotient}, nil
+}
+
+// solveLinear solves the augmented linear system matrix with the given number of unknowns by Gaussian
+// elimination. Free unknowns are set to zero.
+func solveLinear(curve *curves.Curve, matrix [][]curves.Scalar, unknowns int) ([]curves.Scalar, error) {
+	pivots := make([]int, 0, unknowns)
+	row := 0
+	for col := 0; col < unknowns && row < len(matrix); col++ {
+		pivot := -1
+		for i := row; i < len(matrix); i++ {
+			if !matrix[i][col].IsZero() {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		matrix[row], matrix[pivot] = matrix[pivot], matrix[row]
+		inv, err := matrix[row][col].Invert()
+		if err != nil {
+			return nil, err
+		}
+		for j := col; j <= unknowns; j++ {
+			matrix[row][j] = matrix[row][j].Mul(inv)
+		}
+		for i := range matrix {
+			if i == row || matrix[i][col].IsZero() {
+				continue
+			}
+			factor := matrix[i][col]
+			for j := col; j <= unknowns; j++ {
+				matrix[i][j] = matrix[i][j].Sub(factor.Mul(matrix[row][j]))
+			}
+		}
+		pivots = append(pivots, col)
+		row++
+	}
+	// The remaining rows must be 0 = 0
+	for i := row; i < len(matrix); i++ {
+		if !matrix[i][unknowns].IsZero() {
+			return nil, errInconsistentSystem
+		}
+	}
+	solution := make([]curves.Scalar, unknowns)
+	for i := range solution {
+		solution[i] = curve.Scalar.Zero()
+	}
+	for i, col := range pivots {
+		solution[col] = matrix[i][unknowns]
+	}
+	return solution, nil
+}
+
+// CombineRobust reconstructs the secret from shares of which some may be corrupted, see Shamir.CombineRobust
+func (f Feldman) CombineRobust(shares ...*ShamirShare) (curves.Scalar, []uint32, error) {
+	shamir := &Shamir{
+		threshold: f.Threshold,
+		limit:     f.Limit,
+		curve:     f.Curve,
+	}
+	return shamir.CombineRobust(shares...)
+}
+
+// CombineVerified reconstructs the secret from the shares that match the commitments of verifier, and
+// returns the identifiers of the shares that do not. At least threshold shares must be valid.
+func (f Feldman) CombineVerified(verifier *FeldmanVerifier, shares ...*ShamirShare) (curves.Scalar, []uint32, error) {
+	if verifier == nil || uint32(len(verifier.Commitments)) != f.Threshold {
+		return nil, nil, fmt.Errorf("invalid commitments")
+	}
+	for _, c := range verifier.Commitments {
+		if c == nil || c.CurveName() != f.Curve.Name {
+			return nil, nil, fmt.Errorf("invalid commitments")
+		}
+	}
+	dups := make(map[uint32]bool, len(shares))
+	valid := make([]*ShamirShare, 0, len(shares))
+	bad := make([]uint32, 0)
+	for _, share := range shares {
+		if share == nil {
+			return nil, nil, fmt.Errorf("invalid share")
+		}
+		if dups[share.Id] {
+			return nil, nil, fmt.Errorf("duplicate share")
+		}
+		dups[share.Id] = true
+		if share.Id > f.Limit || verifier.Verify(share) != nil {
+			bad = append(bad, share.Id)
+			continue
+		}
+		valid = append(valid, share)
+	}
+	secret, err := f.Combine(valid...)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(bad, func(i, j int) bool { return bad[i] < bad[j] })
+	return secret, bad, nil
+}
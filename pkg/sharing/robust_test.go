@@ -0,0 +1,115 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+// corrupt replaces the values of the shares with the given identifiers
+func corrupt(curve *curves.Curve, shares []*ShamirShare, ids ...uint32) []*ShamirShare {
+	result := make([]*ShamirShare, len(shares))
+	for i, share := range shares {
+		result[i] = &ShamirShare{Id: share.Id, Value: share.Value}
+		for _, id := range ids {
+			if share.Id == id {
+				result[i].Value = curve.Scalar.Random(crand.Reader).Bytes()
+			}
+		}
+	}
+	return result
+}
+
+func TestShamirCombineRobust(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.ED25519()} {
+		scheme, err := NewShamir(3, 9, curve)
+		require.NoError(t, err)
+		secret := curve.Scalar.Random(crand.Reader)
+		shares, err := scheme.Split(secret, crand.Reader)
+		require.NoError(t, err)
+
+		// 9 shares with threshold 3 correct up to 3 corrupted shares
+		for _, bad := range [][]uint32{{}, {4}, {2, 7}, {1, 5, 9}} {
+			recovered, detected, err := scheme.CombineRobust(corrupt(curve, shares, bad...)...)
+			require.NoError(t, err)
+			require.Equal(t, 0, recovered.Cmp(secret))
+			require.Equal(t, bad, detected)
+		}
+
+		// A subset of 5 shares corrects one
+		recovered, detected, err := scheme.CombineRobust(corrupt(curve, shares[2:7], 5)...)
+		require.NoError(t, err)
+		require.Equal(t, 0, recovered.Cmp(secret))
+		require.Equal(t, []uint32{5}, detected)
+
+		// Too many corrupted shares are refused instead of yielding a wrong secret
+		_, _, err = scheme.CombineRobust(corrupt(curve, shares[2:7], 4, 5)...)
+		require.Error(t, err)
+	}
+}
+
+func TestShamirCombineRobustInvalidShares(t *testing.T) {
+	curve := curves.K256()
+	scheme, err := NewShamir(2, 5, curve)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	shares, err := scheme.Split(secret, crand.Reader)
+	require.NoError(t, err)
+
+	// Undecodable values and identifiers out of range are reported
+	input := corrupt(curve, shares)
+	input[1].Value = []byte{1, 2, 3}
+	input[3].Id = 6
+	recovered, detected, err := scheme.CombineRobust(input...)
+	require.NoError(t, err)
+	require.Equal(t, 0, recovered.Cmp(secret))
+	require.Equal(t, []uint32{2, 6}, detected)
+
+	_, _, err = scheme.CombineRobust(shares[0], shares[0], shares[1])
+	require.Error(t, err)
+	_, _, err = scheme.CombineRobust(shares[0])
+	require.Error(t, err)
+	_, _, err = scheme.CombineRobust(shares[0], nil)
+	require.Error(t, err)
+}
+
+func TestFeldmanCombineVerified(t *testing.T) {
+	curve := curves.K256()
+	scheme, err := NewFeldman(3, 5, curve)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(crand.Reader)
+	verifier, shares, err := scheme.Split(secret, crand.Reader)
+	require.NoError(t, err)
+
+	// Unlike decoding, verification finds any number of bad shares as long as threshold remain
+	recovered, detected, err := scheme.CombineVerified(verifier, corrupt(curve, shares, 1, 4)...)
+	require.NoError(t, err)
+	require.Equal(t, 0, recovered.Cmp(secret))
+	require.Equal(t, []uint32{1, 4}, detected)
+
+	recovered, detected, err = scheme.CombineVerified(verifier, shares[:3]...)
+	require.NoError(t, err)
+	require.Equal(t, 0, recovered.Cmp(secret))
+	require.Empty(t, detected)
+
+	_, _, err = scheme.CombineVerified(verifier, corrupt(curve, shares, 1, 2, 3)...)
+	require.Error(t, err)
+	_, _, err = scheme.CombineVerified(nil, shares...)
+	require.Error(t, err)
+	_, _, err = scheme.CombineVerified(verifier, shares[0], shares[0], shares[1], shares[2])
+	require.Error(t, err)
+
+	recovered, detected, err = scheme.CombineRobust(corrupt(curve, shares, 2)...)
+	require.NoError(t, err)
+	require.Equal(t, 0, recovered.Cmp(secret))
+	require.Equal(t, []uint32{2}, detected)
+}
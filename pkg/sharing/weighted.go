@@ -0,0 +1,270 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+// WeightedShare is the share of a participant of a weighted sharing, one ShamirShare for each unit of
+// its weight
+type WeightedShare struct {
+	Id     uint32         `json:"identifier"`
+	Shares []*ShamirShare `json:"shares"`
+}
+
+// Weighted is a Feldman sharing in which a participant of weight w holds w evaluation points, so any set
+// of participants whose weights add up to the threshold can reconstruct. The evaluation points are
+// assigned consecutively to the participants in increasing order of their ids. Unlike Shamir, the total
+// weight is not limited to 255.
+type Weighted struct {
+	Threshold uint32
+	Weights   map[uint32]uint32
+	Curve     *curves.Curve
+}
+
+// NewWeighted creates a weighted sharing with the weights of the participants indexed by their ids
+func NewWeighted(threshold uint32, weights map[uint32]uint32, curve *curves.Curve) (*Weighted, error) {
+	if threshold < 2 {
+		return nil, fmt.Errorf("threshold cannot be less than 2")
+	}
+	if curve == nil {
+		return nil, fmt.Errorf("invalid curve")
+	}
+	total := uint64(0)
+	copied := make(map[uint32]uint32, len(weights))
+	for id, weight := range weights {
+		if id == 0 {
+			return nil, fmt.Errorf("invalid identifier")
+		}
+		if weight == 0 {
+			return nil, fmt.Errorf("weight of %d cannot be zero", id)
+		}
+		total += uint64(weight)
+		copied[id] = weight
+	}
+	if total > uint64(^uint32(0)) {
+		return nil, fmt.Errorf("total weight is too large")
+	}
+	if total < uint64(threshold) {
+		return nil, fmt.Errorf("total weight cannot be less than threshold")
+	}
+	return &Weighted{threshold, copied, curve}, nil
+}
+
+// ShareIds returns the evaluation points of the participant id
+func (w Weighted) ShareIds(id uint32) ([]uint32, error) {
+	weight, ok := w.Weights[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown participant %d", id)
+	}
+	participants := make([]uint32, 0, len(w.Weights))
+	for p := range w.Weights {
+		participants = append(participants, p)
+	}
+	sort.Slice(participants, func(i, j int) bool { return participants[i] < participants[j] })
+	first := uint32(1)
+	for _, p := range participants {
+		if p == id {
+			break
+		}
+		first += w.Weights[p]
+	}
+	ids := make([]uint32, weight)
+	for i := range ids {
+		ids[i] = first + uint32(i)
+	}
+	return ids, nil
+}
+
+// Split shares secret among the participants, and returns the Feldman commitments and the share of every
+// participant indexed by its id
+func (w Weighted) Split(secret curves.Scalar, reader io.Reader) (*FeldmanVerifier, map[uint32]*WeightedShare, error) {
+	if secret.IsZero() {
+		return nil, nil, fmt.Errorf("invalid secret")
+	}
+	poly := new(Polynomial).Init(secret, w.Threshold, reader)
+	verifier := &FeldmanVerifier{Commitments: make([]curves.Point, w.Threshold)}
+	for i := range verifier.Commitments {
+		verifier.Commitments[i] = w.Curve.ScalarBaseMult(poly.Coefficients[i])
+	}
+	shares := make(map[uint32]*WeightedShare, len(w.Weights))
+	for id := range w.Weights {
+		ids, _ := w.ShareIds(id)
+		share := &WeightedShare{Id: id, Shares: make([]*ShamirShare, len(ids))}
+		for i, x := range ids {
+			share.Shares[i] = &ShamirShare{
+				Id:    x,
+				Value: poly.Evaluate(w.Curve.Scalar.New(int(x))).Bytes(),
+			}
+		}
+		shares[id] = share
+	}
+	return verifier, shares, nil
+}
+
+// Verify checks that share holds the evaluation points of its participant and that they match verifier
+func (w Weighted) Verify(verifier *FeldmanVerifier, share *WeightedShare) error {
+	if verifier == nil || len(verifier.Commitments) == 0 || share == nil {
+		return fmt.Errorf("invalid arguments")
+	}
+	ids, err := w.ShareIds(share.Id)
+	if err != nil {
+		return err
+	}
+	if len(share.Shares) != len(ids) {
+		return fmt.Errorf("participant %d must have %d shares", share.Id, len(ids))
+	}
+	for i, s := range share.Shares {
+		if s == nil || s.Id != ids[i] {
+			return fmt.Errorf("invalid share identifier")
+		}
+		if err = verifier.Verify(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Combine reconstructs the secret from the shares of participants whose weights reach the threshold
+func (w Weighted) Combine(shares ...*WeightedShare) (curves.Scalar, error) {
+	xs, ys, err := w.points(shares)
+	if err != nil {
+		return nil, err
+	}
+	shamir := &Shamir{threshold: w.Threshold, curve: w.Curve}
+	return shamir.interpolate(xs, ys)
+}
+
+// LagrangeCoeffs returns the Lagrange coefficients at 0 of every evaluation point of the participants,
+// indexed by the evaluation point
+func (w Weighted) LagrangeCoeffs(participants []uint32) (map[uint32]curves.Scalar, error) {
+	total := uint32(0)
+	points := make([]uint32, 0)
+	dups := make(map[uint32]bool, len(participants))
+	for _, id := range participants {
+		if dups[id] {
+			return nil, fmt.Errorf("duplicate participant %d", id)
+		}
+		dups[id] = true
+		ids, err := w.ShareIds(id)
+		if err != nil {
+			return nil, err
+		}
+		total += uint32(len(ids))
+		points = append(points, ids...)
+	}
+	if total < w.Threshold {
+		return nil, fmt.Errorf("participants do not reach the threshold")
+	}
+	shamir := &Shamir{threshold: w.Threshold, curve: w.Curve}
+	return shamir.LagrangeCoeffs(points)
+}
+
+// SigningShare returns Σ λ_x·s_x over the evaluation points x of share, where the λ_x are the Lagrange
+// coefficients of the signing participants. The secret is the sum of the signing shares of the
+// participants, so they can be used for FROST signing with Lagrange coefficients of one.
+func (w Weighted) SigningShare(share *WeightedShare, participants []uint32) (curves.Scalar, error) {
+	if share == nil {
+		return nil, fmt.Errorf("invalid share")
+	}
+	coefficients, err := w.LagrangeCoeffs(participants)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := dedup(participants)[share.Id]; !ok {
+		return nil, fmt.Errorf("participant %d is not signing", share.Id)
+	}
+	result := w.Curve.Scalar.Zero()
+	for _, s := range share.Shares {
+		if s == nil {
+			return nil, fmt.Errorf("invalid share")
+		}
+		lambda, ok := coefficients[s.Id]
+		if !ok {
+			return nil, fmt.Errorf("invalid share identifier")
+		}
+		sc, err := w.Curve.Scalar.SetBytes(s.Value)
+		if err != nil {
+			return nil, err
+		}
+		result = result.Add(sc.Mul(lambda))
+	}
+	return result, nil
+}
+
+// PublicSigningShare returns the public key of the SigningShare of the participant id, computed from
+// verifier
+func (w Weighted) PublicSigningShare(verifier *FeldmanVerifier, id uint32, participants []uint32) (curves.Point, error) {
+	if verifier == nil || len(verifier.Commitments) == 0 {
+		return nil, fmt.Errorf("invalid commitments")
+	}
+	coefficients, err := w.LagrangeCoeffs(participants)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := dedup(participants)[id]; !ok {
+		return nil, fmt.Errorf("participant %d is not signing", id)
+	}
+	ids, _ := w.ShareIds(id)
+	result := w.Curve.NewIdentityPoint()
+	for _, x := range ids {
+		result = result.Add(verifier.evaluate(w.Curve, x).Mul(coefficients[x]))
+	}
+	return result, nil
+}
+
+// points checks the shares and returns their evaluation points
+func (w Weighted) points(shares []*WeightedShare) ([]curves.Scalar, []curves.Scalar, error) {
+	dups := make(map[uint32]bool, len(shares))
+	xs := make([]curves.Scalar, 0)
+	ys := make([]curves.Scalar, 0)
+	for _, share := range shares {
+		if share == nil {
+			return nil, nil, fmt.Errorf("invalid share")
+		}
+		if dups[share.Id] {
+			return nil, nil, fmt.Errorf("duplicate share")
+		}
+		dups[share.Id] = true
+		ids, err := w.ShareIds(share.Id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(share.Shares) != len(ids) {
+			return nil, nil, fmt.Errorf("participant %d must have %d shares", share.Id, len(ids))
+		}
+		for i, s := range share.Shares {
+			if s == nil || s.Id != ids[i] {
+				return nil, nil, fmt.Errorf("invalid share identifier")
+			}
+			if err = s.Validate(w.Curve); err != nil {
+				return nil, nil, err
+			}
+			y, _ := w.Curve.Scalar.SetBytes(s.Value)
+			xs = append(xs, w.Curve.Scalar.New(int(s.Id)))
+			ys = append(ys, y)
+		}
+	}
+	if len(xs) < int(w.Threshold) {
+		return nil, nil, fmt.Errorf("invalid number of shares")
+	}
+	return xs, ys, nil
+}
+
+// dedup returns the set of ids
+func dedup(ids []uint32) map[uint32]bool {
+	result := make(map[uint32]bool, len(ids))
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sharing
+
+import (
+	crand "crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+func TestWeightedInvalidArgs(t *testing.T) {
+	curve := curves.K256()
+	_, err := NewWeighted(1, map[uint32]uint32{1: 1, 2: 1}, curve)
+	require.Error(t, err)
+	_, err = NewWeighted(4, map[uint32]uint32{1: 1, 2: 2}, curve)
+	require.Error(t, err)
+	_, err = NewWeighted(2, map[uint32]uint32{1: 1, 2: 0}, curve)
+	require.Error(t, err)
+	_, err = NewWeighted(2, map[uint32]uint32{0: 1, 2: 1}, curve)
+	require.Error(t, err)
+	_, err = NewWeighted(2, map[uint32]uint32{1: 1, 2: 1}, nil)
+	require.Error(t, err)
+	_, err = NewWeighted(2, map[uint32]uint32{1: 1 << 31, 2: 1 << 31}, curve)
+	require.Error(t, err)
+}
+
+func TestWeighted(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.ED25519()} {
+		// Participant 10 has weight 3, 20 and 30 have weight 1, 40 has weight 200
+		weights := map[uint32]uint32{10: 3, 20: 1, 30: 1, 40: 200}
+		scheme, err := NewWeighted(4, weights, curve)
+		require.NoError(t, err)
+		ids, err := scheme.ShareIds(20)
+		require.NoError(t, err)
+		require.Equal(t, []uint32{4}, ids)
+		ids, err = scheme.ShareIds(40)
+		require.NoError(t, err)
+		require.Len(t, ids, 200)
+		require.Equal(t, uint32(205), ids[199])
+		_, err = scheme.ShareIds(50)
+		require.Error(t, err)
+
+		secret := curve.Scalar.Random(crand.Reader)
+		verifier, shares, err := scheme.Split(secret, crand.Reader)
+		require.NoError(t, err)
+		require.Len(t, verifier.Commitments, 4)
+		for _, share := range shares {
+			require.NoError(t, scheme.Verify(verifier, share))
+		}
+
+		for _, set := range [][]uint32{{10, 20}, {10, 30}, {40}, {10, 20, 30}} {
+			subset := make([]*WeightedShare, len(set))
+			for i, id := range set {
+				subset[i] = shares[id]
+			}
+			recovered, err := scheme.Combine(subset...)
+			require.NoError(t, err)
+			require.Equal(t, 0, recovered.Cmp(secret))
+
+			// The signing shares add up to the secret and match their public keys
+			sum := curve.Scalar.Zero()
+			for _, id := range set {
+				share, err := scheme.SigningShare(shares[id], set)
+				require.NoError(t, err)
+				public, err := scheme.PublicSigningShare(verifier, id, set)
+				require.NoError(t, err)
+				require.True(t, curve.ScalarBaseMult(share).Equal(public))
+				sum = sum.Add(share)
+			}
+			require.Equal(t, 0, sum.Cmp(secret))
+		}
+
+		// Weights below the threshold cannot reconstruct
+		_, err = scheme.Combine(shares[20], shares[30])
+		require.Error(t, err)
+		_, err = scheme.LagrangeCoeffs([]uint32{20, 30})
+		require.Error(t, err)
+		_, err = scheme.Combine(shares[10], shares[10])
+		require.Error(t, err)
+		_, err = scheme.SigningShare(shares[30], []uint32{10, 20})
+		require.Error(t, err)
+
+		// A share with a wrong value or evaluation point is rejected
+		bad := &WeightedShare{Id: 10, Shares: []*ShamirShare{shares[10].Shares[0], shares[10].Shares[1], shares[20].Shares[0]}}
+		require.Error(t, scheme.Verify(verifier, bad))
+		bad = &WeightedShare{Id: 20, Shares: []*ShamirShare{{Id: 4, Value: curve.Scalar.Random(crand.Reader).Bytes()}}}
+		require.Error(t, scheme.Verify(verifier, bad))
+	}
+}
@@ -0,0 +1,166 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package bip340 implements Schnorr signatures over secp256k1 as specified in
+// https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki
+// Public keys are x-only and signatures are 64 bytes so they can be used with Taproot.
+package bip340
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+const (
+	// PublicKeySize is the size, in bytes, of an x-only public key
+	PublicKeySize = 32
+	// SecretKeySize is the size, in bytes, of a secret key
+	SecretKeySize = 32
+	// SignatureSize is the size, in bytes, of a signature
+	SignatureSize = 64
+	// AuxRandSize is the size, in bytes, of the auxiliary randomness used when signing
+	AuxRandSize = 32
+)
+
+// PublicKey is the x-only verification key. The underlying point always has an even Y coordinate.
+type PublicKey struct {
+	value curves.Point
+}
+
+// NewPublicKey returns the x-only public key for point. If point has an odd Y coordinate
+// the result is the public key of its negation, as BIP-340 only keeps the X coordinate.
+func NewPublicKey(point curves.Point) (*PublicKey, error) {
+	if point == nil || point.IsIdentity() {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	if _, ok := point.(*curves.PointK256); !ok {
+		return nil, fmt.Errorf("public key must be a secp256k1 point")
+	}
+	if !HasEvenY(point) {
+		point = point.Neg()
+	}
+	return &PublicKey{point}, nil
+}
+
+// Point returns the curve point with even Y coordinate represented by this key
+func (pk PublicKey) Point() curves.Point {
+	return pk.value
+}
+
+// Bytes returns the 32 byte x-only encoding of the public key
+func (pk PublicKey) Bytes() []byte {
+	return xBytes(pk.value)
+}
+
+func (pk PublicKey) MarshalBinary() ([]byte, error) {
+	if pk.value == nil {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	return pk.Bytes(), nil
+}
+
+func (pk *PublicKey) UnmarshalBinary(input []byte) error {
+	pt, err := liftX(input)
+	if err != nil {
+		return err
+	}
+	pk.value = pt
+	return nil
+}
+
+// SecretKey is the signing key
+type SecretKey struct {
+	value curves.Scalar
+}
+
+// NewSecretKey creates a secret key from a secp256k1 scalar
+func NewSecretKey(value curves.Scalar) (*SecretKey, error) {
+	if value == nil || value.IsZero() {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	if _, ok := value.(*curves.ScalarK256); !ok {
+		return nil, fmt.Errorf("secret key must be a secp256k1 scalar")
+	}
+	return &SecretKey{value}, nil
+}
+
+// PublicKey returns the corresponding x-only verification key
+func (sk SecretKey) PublicKey() *PublicKey {
+	pk, _ := NewPublicKey(curves.K256().ScalarBaseMult(sk.value))
+	return pk
+}
+
+func (sk SecretKey) MarshalBinary() ([]byte, error) {
+	if sk.value == nil {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	return sk.value.Bytes(), nil
+}
+
+func (sk *SecretKey) UnmarshalBinary(input []byte) error {
+	if len(input) != SecretKeySize {
+		return fmt.Errorf("invalid byte sequence")
+	}
+	value, err := curves.K256().Scalar.SetBytes(input)
+	if err != nil {
+		return err
+	}
+	if value.IsZero() {
+		return fmt.Errorf("invalid secret key")
+	}
+	sk.value = value
+	return nil
+}
+
+// NewKeys creates a new keypair using a CSPRNG
+func NewKeys() (*PublicKey, *SecretKey, error) {
+	return NewKeysFromReader(crand.Reader)
+}
+
+// NewKeysFromReader creates a new keypair using the specified reader
+func NewKeysFromReader(reader io.Reader) (*PublicKey, *SecretKey, error) {
+	if reader == nil {
+		return nil, nil, fmt.Errorf("invalid reader")
+	}
+	sc := curves.K256().Scalar.Random(reader)
+	sk, err := NewSecretKey(sc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sk.PublicKey(), sk, nil
+}
+
+// HasEvenY returns true if the affine Y coordinate of point is even
+func HasEvenY(point curves.Point) bool {
+	return point.ToAffineCompressed()[0] == 2
+}
+
+// xBytes returns the big-endian encoding of the affine X coordinate of point
+func xBytes(point curves.Point) []byte {
+	return point.ToAffineCompressed()[1:]
+}
+
+// liftX returns the point with X coordinate x and an even Y coordinate
+func liftX(x []byte) (curves.Point, error) {
+	if len(x) != PublicKeySize {
+		return nil, fmt.Errorf("invalid byte sequence")
+	}
+	var buf [PublicKeySize + 1]byte
+	buf[0] = 2
+	copy(buf[1:], x)
+	pt, err := curves.K256().Point.FromAffineCompressed(buf[:])
+	if err != nil {
+		return nil, err
+	}
+	// FromAffineCompressed returns the identity when x is not on the curve
+	if pt.IsIdentity() {
+		return nil, fmt.Errorf("x coordinate is not on the curve")
+	}
+	return pt, nil
+}
@@ -0,0 +1,205 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bip340
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+const (
+	tagAux       = "BIP0340/aux"
+	tagNonce     = "BIP0340/nonce"
+	tagChallenge = "BIP0340/challenge"
+)
+
+// Signature is a BIP-340 signature consisting of the
+// x-only nonce commitment R and the response S
+type Signature struct {
+	R [32]byte
+	S curves.Scalar
+}
+
+func (sig Signature) MarshalBinary() ([]byte, error) {
+	if sig.S == nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	var buf [SignatureSize]byte
+	copy(buf[:32], sig.R[:])
+	copy(buf[32:], sig.S.Bytes())
+	return buf[:], nil
+}
+
+func (sig *Signature) UnmarshalBinary(input []byte) error {
+	if len(input) != SignatureSize {
+		return fmt.Errorf("invalid byte sequence")
+	}
+	s, err := curves.K256().Scalar.SetBytes(input[32:])
+	if err != nil {
+		return err
+	}
+	copy(sig.R[:], input[:32])
+	sig.S = s
+	return nil
+}
+
+// TaggedHash computes SHA256(SHA256(tag) || SHA256(tag) || msg[0] || msg[1] || ...)
+func TaggedHash(tag string, msg ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	_, _ = h.Write(tagHash[:])
+	_, _ = h.Write(tagHash[:])
+	for _, m := range msg {
+		_, _ = h.Write(m)
+	}
+	return h.Sum(nil)
+}
+
+// Challenge computes e = int(hash_BIP0340/challenge(bytes(R) || bytes(P) || m)) mod n
+// where rX and pX are the 32 byte x-only encodings of R and P.
+func Challenge(rX, pX, message []byte) curves.Scalar {
+	e, _ := curves.K256().Scalar.SetBigInt(new(big.Int).SetBytes(TaggedHash(tagChallenge, rX, pX, message)))
+	return e
+}
+
+// Sign signs message using fresh auxiliary randomness from a CSPRNG
+func Sign(sk *SecretKey, message []byte) (*Signature, error) {
+	var auxRand [AuxRandSize]byte
+	if _, err := crand.Read(auxRand[:]); err != nil {
+		return nil, err
+	}
+	return SignWithAuxRand(sk, message, auxRand[:])
+}
+
+// SignWithAuxRand signs message using the specified 32 bytes of auxiliary randomness
+// as described in the default signing algorithm of BIP-340.
+func SignWithAuxRand(sk *SecretKey, message, auxRand []byte) (*Signature, error) {
+	if sk == nil || sk.value == nil || sk.value.IsZero() {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	if len(auxRand) != AuxRandSize {
+		return nil, fmt.Errorf("auxiliary randomness must be %d bytes", AuxRandSize)
+	}
+	curve := curves.K256()
+
+	// d = d' if has_even_y(P) else n - d'
+	d := sk.value
+	P := curve.ScalarBaseMult(d)
+	if !HasEvenY(P) {
+		d = d.Neg()
+		P = P.Neg()
+	}
+	pX := xBytes(P)
+
+	// t = bytes(d) xor hash_BIP0340/aux(a)
+	t := TaggedHash(tagAux, auxRand)
+	dBytes := d.Bytes()
+	for i := range t {
+		t[i] ^= dBytes[i]
+	}
+
+	// k' = int(hash_BIP0340/nonce(t || bytes(P) || m)) mod n
+	k, _ := curve.Scalar.SetBigInt(new(big.Int).SetBytes(TaggedHash(tagNonce, t, pX, message)))
+	if k.IsZero() {
+		return nil, fmt.Errorf("invalid nonce")
+	}
+	// k = k' if has_even_y(R) else n - k'
+	R := curve.ScalarBaseMult(k)
+	if !HasEvenY(R) {
+		k = k.Neg()
+		R = R.Neg()
+	}
+	rX := xBytes(R)
+
+	e := Challenge(rX, pX, message)
+	sig := &Signature{
+		S: e.MulAdd(d, k),
+	}
+	copy(sig.R[:], rX)
+
+	// Check the signature before releasing it to protect against fault attacks
+	if err := Verify(&PublicKey{P}, message, sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// Verify checks that sig is a valid BIP-340 signature over message by pk
+func Verify(pk *PublicKey, message []byte, sig *Signature) error {
+	if pk == nil || pk.value == nil || pk.value.IsIdentity() {
+		return fmt.Errorf("invalid public key")
+	}
+	if sig == nil || sig.S == nil {
+		return fmt.Errorf("invalid signature")
+	}
+	if _, err := liftX(sig.R[:]); err != nil {
+		return fmt.Errorf("invalid signature")
+	}
+	curve := curves.K256()
+	P := pk.value
+	e := Challenge(sig.R[:], xBytes(P), message)
+
+	// R = s⋅G - e⋅P
+	R := curve.ScalarBaseMult(sig.S).Sub(P.Mul(e))
+	if R.IsIdentity() || !HasEvenY(R) {
+		return fmt.Errorf("signature verification failed")
+	}
+	if subtle.ConstantTimeCompare(xBytes(R), sig.R[:]) != 1 {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
+
+// BatchVerify checks that every sigs[i] is a valid signature over messages[i] by pks[i].
+// It is faster than verifying each signature individually but only reports
+// whether all signatures are valid.
+func BatchVerify(pks []*PublicKey, messages [][]byte, sigs []*Signature) error {
+	u := len(pks)
+	if u == 0 || len(messages) != u || len(sigs) != u {
+		return fmt.Errorf("invalid batch length")
+	}
+	curve := curves.K256()
+
+	// s1 + a2⋅s2 + ... + au⋅su
+	lhs := curve.Scalar.Zero()
+	// R1 + a2⋅R2 + ... + au⋅Ru + e1⋅P1 + (a2⋅e2)⋅P2 + ... + (au⋅eu)⋅Pu
+	points := make([]curves.Point, 0, 2*u)
+	scalars := make([]curves.Scalar, 0, 2*u)
+	for i := 0; i < u; i++ {
+		if pks[i] == nil || pks[i].value == nil || pks[i].value.IsIdentity() {
+			return fmt.Errorf("invalid public key at index %d", i)
+		}
+		if sigs[i] == nil || sigs[i].S == nil {
+			return fmt.Errorf("invalid signature at index %d", i)
+		}
+		R, err := liftX(sigs[i].R[:])
+		if err != nil {
+			return fmt.Errorf("invalid signature at index %d", i)
+		}
+		P := pks[i].value
+		e := Challenge(sigs[i].R[:], xBytes(P), messages[i])
+
+		// a1 = 1, every other coefficient is random
+		a := curve.Scalar.One()
+		if i > 0 {
+			a = curve.Scalar.Random(crand.Reader)
+		}
+		lhs = lhs.Add(a.Mul(sigs[i].S))
+		points = append(points, R, P)
+		scalars = append(scalars, a, a.Mul(e))
+	}
+	rhs := curve.Point.SumOfProducts(points, scalars)
+	if rhs == nil || !curve.ScalarBaseMult(lhs).Equal(rhs) {
+		return fmt.Errorf("batch verification failed")
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/sharing"
+	"github.com/coinbase/kryptology/pkg/zkp/schnorr"
+)
+
+// KeyGen encodes the state of one party during one execution of key generation, CGGMP21 §3.1 fig 5 generalized to
+// t-of-n with Feldman VSS. Every party commits to the commitments of its polynomial and to its share of the random
+// identifier rid, then opens them with a schnorr proof of knowledge of its secret and deals the shares.
+// The output holds no auxiliary info, a refresh must be run before presigning.
+type KeyGen struct {
+	id        uint32
+	threshold uint32
+	parties   []uint32
+	curve     *curves.Curve
+
+	seed        [SessionIdSize]byte
+	sessionId   [SessionIdSize]byte
+	polynomial  *sharing.Polynomial
+	verifier    *sharing.FeldmanVerifier
+	rid         [SessionIdSize]byte
+	salt        [SessionIdSize]byte
+	commitments map[uint32][SessionIdSize]byte
+
+	output *Config
+}
+
+// KeyGenRound1Output is the broadcast of the 1st round of key generation.
+type KeyGenRound1Output struct {
+	// Seed is the share of the sender of the session id.
+	Seed [SessionIdSize]byte
+
+	// Commitment is the hash commitment to the Feldman commitments and the rid share of the sender.
+	Commitment [SessionIdSize]byte
+}
+
+// KeyGenRound2Output is the message of the 2nd round of key generation from one party to another.
+type KeyGenRound2Output struct {
+	// Verifier holds the Feldman commitments to the polynomial of the sender. It is broadcast.
+	Verifier *sharing.FeldmanVerifier
+
+	// Rid is the share of the sender of the random identifier and Salt the randomness of its commitment.
+	// They are broadcast.
+	Rid, Salt [SessionIdSize]byte
+
+	// Proof is the proof of knowledge of the secret of the sender. It is broadcast.
+	Proof *schnorr.Proof
+
+	// Share is the share of the recipient of the secret of the sender. It must be sent privately.
+	Share *sharing.ShamirShare
+}
+
+// NewKeyGen creates a party with identifier id that can participate in t-of-n key generation with the given parties.
+func NewKeyGen(curve *curves.Curve, id, threshold uint32, parties []uint32) (*KeyGen, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("curve is nil")
+	}
+	sorted, err := sortedParties(id, parties)
+	if err != nil {
+		return nil, err
+	}
+	if threshold < 2 || threshold > uint32(len(sorted)) {
+		return nil, fmt.Errorf("threshold must be between 2 and the number of parties")
+	}
+	return &KeyGen{
+		id:        id,
+		threshold: threshold,
+		parties:   sorted,
+		curve:     curve,
+	}, nil
+}
+
+// Round1Commit samples the secret polynomial and the rid share of this party and commits to them.
+func (kg *KeyGen) Round1Commit() (*KeyGenRound1Output, error) {
+	for _, b := range [][]byte{kg.seed[:], kg.rid[:], kg.salt[:]} {
+		if _, err := rand.Read(b); err != nil {
+			return nil, errors.Wrap(err, "generating random bytes in key generation round 1")
+		}
+	}
+	kg.polynomial = new(sharing.Polynomial).Init(kg.curve.Scalar.Random(rand.Reader), kg.threshold, rand.Reader)
+	kg.verifier = &sharing.FeldmanVerifier{Commitments: make([]curves.Point, kg.threshold)}
+	for i, coefficient := range kg.polynomial.Coefficients {
+		kg.verifier.Commitments[i] = kg.curve.ScalarBaseMult(coefficient)
+	}
+	return &KeyGenRound1Output{
+		Seed:       kg.seed,
+		Commitment: keyGenCommitment(kg.id, kg.verifier, kg.rid, kg.salt),
+	}, nil
+}
+
+// Round2Decommit derives the session id from the seeds of all parties, opens the commitment of this party with a
+// proof of knowledge of its secret, and deals the shares of the secret.
+func (kg *KeyGen) Round2Decommit(input map[uint32]*KeyGenRound1Output) (map[uint32]*KeyGenRound2Output, error) {
+	peers := otherParties(kg.id, kg.parties)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return nil, err
+	}
+	if kg.polynomial == nil {
+		return nil, fmt.Errorf("round 1 has not been run")
+	}
+	seeds := make([][]byte, len(kg.parties))
+	kg.commitments = make(map[uint32][SessionIdSize]byte, len(peers))
+	for i, id := range kg.parties {
+		if id == kg.id {
+			seeds[i] = kg.seed[:]
+			continue
+		}
+		seed := input[id].Seed
+		seeds[i] = seed[:]
+		kg.commitments[id] = input[id].Commitment
+	}
+	kg.sessionId = hashSession("CGGMP21 key generation", seeds, kg.parties...)
+
+	proof, err := schnorr.NewProver(kg.curve, nil, subSessionId(kg.sessionId, "keygen schnorr", kg.id)).
+		Prove(kg.polynomial.Coefficients[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "proving knowledge of the secret in key generation round 2")
+	}
+	output := make(map[uint32]*KeyGenRound2Output, len(peers))
+	for _, id := range peers {
+		output[id] = &KeyGenRound2Output{
+			Verifier: kg.verifier,
+			Rid:      kg.rid,
+			Salt:     kg.salt,
+			Proof:    proof,
+			Share: &sharing.ShamirShare{
+				Id:    id,
+				Value: kg.polynomial.Evaluate(kg.curve.Scalar.New(int(id))).Bytes(),
+			},
+		}
+	}
+	return output, nil
+}
+
+// Round3Verify checks the openings, proofs and shares of all other parties, and computes the secret key share,
+// the public shares and the public key.
+func (kg *KeyGen) Round3Verify(input map[uint32]*KeyGenRound2Output) error {
+	peers := otherParties(kg.id, kg.parties)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return err
+	}
+	if kg.commitments == nil {
+		return fmt.Errorf("round 2 has not been run")
+	}
+	secretKeyShare := kg.polynomial.Evaluate(kg.curve.Scalar.New(int(kg.id)))
+	publicKey := kg.verifier.Commitments[0]
+	verifiers := []*sharing.FeldmanVerifier{kg.verifier}
+	rid := kg.rid
+	for _, id := range peers {
+		message := input[id]
+		if message.Verifier == nil || !validCommitments(message.Verifier.Commitments, kg.threshold) ||
+			message.Proof == nil || message.Share == nil {
+			return fmt.Errorf("malformed message from party %d", id)
+		}
+		if keyGenCommitment(id, message.Verifier, message.Rid, message.Salt) != kg.commitments[id] {
+			return fmt.Errorf("party %d opened a different commitment", id)
+		}
+		if !message.Proof.Statement.Equal(message.Verifier.Commitments[0]) {
+			return fmt.Errorf("party %d proved knowledge of a different secret", id)
+		}
+		if err := schnorr.Verify(message.Proof, kg.curve, nil, subSessionId(kg.sessionId, "keygen schnorr", id)); err != nil {
+			return errors.Wrapf(err, "verifying the proof of knowledge of party %d", id)
+		}
+		if message.Share.Id != kg.id {
+			return fmt.Errorf("party %d sent a share for party %d", id, message.Share.Id)
+		}
+		if err := message.Verifier.Verify(message.Share); err != nil {
+			return errors.Wrapf(err, "verifying the share of party %d", id)
+		}
+		share, err := kg.curve.Scalar.SetBytes(message.Share.Value)
+		if err != nil {
+			return errors.Wrapf(err, "reading the share of party %d", id)
+		}
+		secretKeyShare = secretKeyShare.Add(share)
+		publicKey = publicKey.Add(message.Verifier.Commitments[0])
+		verifiers = append(verifiers, message.Verifier)
+		xorInto(&rid, message.Rid)
+	}
+
+	publicShares := make(map[uint32]curves.Point, len(kg.parties))
+	for _, id := range kg.parties {
+		x := kg.curve.Scalar.New(int(id))
+		publicShare := kg.curve.Point.Identity()
+		for _, verifier := range verifiers {
+			publicShare = publicShare.Add(evaluateCommitments(verifier.Commitments, x))
+		}
+		publicShares[id] = publicShare
+	}
+	if publicKey.IsIdentity() || !kg.curve.ScalarBaseMult(secretKeyShare).Equal(publicShares[kg.id]) {
+		return fmt.Errorf("inconsistent secret key share")
+	}
+	kg.output = &Config{
+		Id:             kg.id,
+		Threshold:      kg.threshold,
+		Parties:        kg.parties,
+		Sid:            kg.sessionId,
+		Rid:            rid,
+		PublicKey:      publicKey,
+		PublicShares:   publicShares,
+		SecretKeyShare: secretKeyShare,
+	}
+	return nil
+}
+
+// Output returns the output of key generation, nil before round 3 succeeded.
+func (kg *KeyGen) Output() *Config {
+	return kg.output
+}
+
+// keyGenCommitment computes the hash commitment of party id to its Feldman commitments and rid share
+func keyGenCommitment(id uint32, verifier *sharing.FeldmanVerifier, rid, salt [SessionIdSize]byte) [SessionIdSize]byte {
+	values := append([][]byte{rid[:], salt[:]}, pointBytes(verifier.Commitments)...)
+	return hashSession("CGGMP21 key generation commitment", values, id)
+}
@@ -0,0 +1,202 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package participant implements the rounds of the threshold ECDSA protocol of
+// [CGGMP21](https://eprint.iacr.org/2021/060.pdf) for one party: key generation, auxiliary info and key refresh,
+// presigning and one round signing. The secret key is Shamir shared among n parties and any t of them can sign.
+//
+// All rounds take the messages sent by every other party, keyed by the id of the sender, and return the messages
+// to send, keyed by the id of the recipient. Broadcast values are repeated in the message to every recipient, a
+// reliable broadcast channel is assumed. Errors caused by a malformed or invalid message name the party that sent it.
+package participant
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+	"github.com/coinbase/kryptology/pkg/tecdsa/cggmp/proof"
+)
+
+// SessionIdSize is the size in bytes of session ids, seeds and random identifiers
+const SessionIdSize = 32
+
+// Config is the result of key generation or refresh for one party. Key generation only outputs the key shares,
+// the first refresh adds the auxiliary info that presigning requires.
+type Config struct {
+	// Id is the Shamir identifier of this party.
+	Id uint32
+
+	// Threshold is the number of parties needed to sign.
+	Threshold uint32
+
+	// Parties are the ids of all parties holding a share, in ascending order.
+	Parties []uint32
+
+	// Sid is the session id of the key generation or refresh that output this config.
+	Sid [SessionIdSize]byte
+
+	// Rid is the random identifier all parties agreed on, it binds the proofs of later sessions to this config.
+	Rid [SessionIdSize]byte
+
+	// PublicKey is the joint public key.
+	// This value is public.
+	PublicKey curves.Point
+
+	// PublicShares maps the id of every party to the public key of its Shamir share.
+	// This value is public.
+	PublicShares map[uint32]curves.Point
+
+	// SecretKeyShare is the Shamir share of this party of the joint secret key.
+	// This output must be kept secret.
+	SecretKeyShare curves.Scalar
+
+	// PaillierKey is the paillier secret key of this party, nil until the first refresh.
+	// This output must be kept secret.
+	PaillierKey *paillier.SecretKey
+
+	// PaillierKeys maps the id of every party to its paillier public key.
+	PaillierKeys map[uint32]*paillier.PublicKey
+
+	// Pedersen maps the id of every party to its ring-Pedersen parameters, which share the paillier modulus.
+	Pedersen map[uint32]*proof.Pedersen
+}
+
+// hasAuxInfo checks that the config holds the paillier keys and ring-Pedersen parameters of every party
+func (c *Config) hasAuxInfo() bool {
+	if c.PaillierKey == nil {
+		return false
+	}
+	for _, id := range c.Parties {
+		if c.PaillierKeys[id] == nil || c.Pedersen[id] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedParties validates the party ids and returns them in ascending order
+func sortedParties(id uint32, parties []uint32) ([]uint32, error) {
+	sorted := make([]uint32, len(parties))
+	copy(sorted, parties)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	found := false
+	for i, p := range sorted {
+		if p == 0 {
+			return nil, fmt.Errorf("party id must be greater than zero")
+		}
+		if i > 0 && sorted[i-1] == p {
+			return nil, fmt.Errorf("duplicate party id %d", p)
+		}
+		found = found || p == id
+	}
+	if !found {
+		return nil, fmt.Errorf("party %d is not one of the parties", id)
+	}
+	return sorted, nil
+}
+
+// checkSenders returns an error unless input holds exactly one message from every id in expected
+func checkSenders(expected []uint32, length int, has func(uint32) bool) error {
+	if length != len(expected) {
+		return fmt.Errorf("expected messages from %d parties, got %d", len(expected), length)
+	}
+	for _, id := range expected {
+		if !has(id) {
+			return fmt.Errorf("missing message from party %d", id)
+		}
+	}
+	return nil
+}
+
+// otherParties returns all parties except id
+func otherParties(id uint32, parties []uint32) []uint32 {
+	var peers []uint32
+	for _, p := range parties {
+		if p != id {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// hashSession hashes the label, the byte strings and the ids into a session id. Every value is length prefixed.
+func hashSession(label string, values [][]byte, ids ...uint32) [SessionIdSize]byte {
+	hash := sha3.New256()
+	var b [4]byte
+	for _, value := range append([][]byte{[]byte(label)}, values...) {
+		binary.BigEndian.PutUint32(b[:], uint32(len(value)))
+		_, _ = hash.Write(b[:])
+		_, _ = hash.Write(value)
+	}
+	for _, id := range ids {
+		binary.BigEndian.PutUint32(b[:], id)
+		_, _ = hash.Write(b[:])
+	}
+	result := [SessionIdSize]byte{}
+	copy(result[:], hash.Sum(nil))
+	return result
+}
+
+// subSessionId derives the unique session id of a proof from the session id that all parties agreed on.
+func subSessionId(sessionId [SessionIdSize]byte, label string, ids ...uint32) []byte {
+	result := hashSession(label, [][]byte{sessionId[:]}, ids...)
+	return result[:]
+}
+
+// pointBytes encodes points for hash commitments
+func pointBytes(points []curves.Point) [][]byte {
+	encoded := make([][]byte, len(points))
+	for i, p := range points {
+		encoded[i] = p.ToAffineCompressed()
+	}
+	return encoded
+}
+
+// intBytes encodes integers for hash commitments, nil values are encoded like zero
+func intBytes(values ...*big.Int) [][]byte {
+	encoded := make([][]byte, len(values))
+	for i, v := range values {
+		if v != nil {
+			encoded[i] = v.Bytes()
+		}
+	}
+	return encoded
+}
+
+// validCommitments checks that the Feldman commitments are threshold points of the curve
+func validCommitments(commitments []curves.Point, threshold uint32) bool {
+	if len(commitments) != int(threshold) {
+		return false
+	}
+	for _, commitment := range commitments {
+		if commitment == nil || (!commitment.IsIdentity() && !commitment.IsOnCurve()) {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluateCommitments evaluates the polynomial committed to by commitments in the exponent at x
+func evaluateCommitments(commitments []curves.Point, x curves.Scalar) curves.Point {
+	result := commitments[len(commitments)-1]
+	for i := len(commitments) - 2; i >= 0; i-- {
+		result = result.Mul(x).Add(commitments[i])
+	}
+	return result
+}
+
+// xorInto sets dst to dst xor src
+func xorInto(dst *[SessionIdSize]byte, src [SessionIdSize]byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
@@ -0,0 +1,605 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	tt "github.com/coinbase/kryptology/internal"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	"github.com/coinbase/kryptology/pkg/paillier"
+	"github.com/coinbase/kryptology/pkg/sharing"
+)
+
+// testSafePrimes are 1024 bit safe primes, party i uses the paillier key of primes 2(i-1) and 2(i-1)+1
+var testSafePrimes = []*big.Int{
+	tt.B10("94210786053667323206442523040419729883258172350738703980637961803118626748668924192069593010365236618255120977661397310932923345291377692570649198560048403943687994859423283474169530971418656709749020402756179383990602363122039939937953514870699284906666247063852187255623958659551404494107714695311474384687"),
+	tt.B10("130291226847076770981564372061529572170236135412763130013877155698259035960569046218348763182598589633420963942796327547969527085797839549642610021986391589746295634536750785366034581957858065740296991986002552598751827526181747791647357767502200771965093659353354985289411489453223546075843993686648576029043"),
+	tt.B10("172938910323633442195852028319756134734590277522945546987913328782597284762767185925315797321999389252040294991952361905020940252121762387957669654615602135429944435719699091344247805645764550860505536884031064967454028383404046221898300153428182409080298694828920944094158777327533157774919783417586902830043"),
+	tt.B10("135841191929788643010555393808775051922265083622266098277752143441294911675705272940799534437169053045878247274810449617960047255023823301284034559807472662111224710158898548617194658983006262996831617082584649612602010680423107108651221824216065228161009680618243402116924511141821829055830713600437589058643"),
+	tt.B10("179677777376220950493907657233669314916823596507009854134559513388779535023958212632715646194917807302098015450071151245496651913873851032302340489007561121851068326577148680474495447007833318066335149850926605897908761267606415610900931306044455332084757793630487163583451178807470499389106913845684353833379"),
+	tt.B10("147653127360336844448178027222853805809444645720500374788954343695331927468524513989671450440433430392339037667457657655958027740671071573403925974795764987870476118984896439440386146680643457835633462311776946902713168513155240275028008685964121441954481847113848701823211862974120297600518927026940189810103"),
+	tt.B10("167562983031509383478485987630533113343120902430985961468758712448125734458812918541051012669749885569679178971612428577288632429606851871845164719448590160530844833425628143996971699662729056519326776907622035340086832629206691942750594912221135787534670122007438859975313187460872690748138136170080913902203"),
+	tt.B10("151715609132228776595716500435665208768897792993205818803431003524953811539898459230192282642811956896879836518212758893685104146944932088195466999437630114129887975508715417094019351746027667352287673763064246395392591213231796089814648654152625331299642171758052545451706130433176935280325874961374276589763"),
+}
+
+func testPaillierKey(t *testing.T, id uint32) *paillier.SecretKey {
+	t.Helper()
+	sk, err := paillier.NewSecretKey(testSafePrimes[2*(id-1)], testSafePrimes[2*(id-1)+1])
+	require.NoError(t, err)
+	return sk
+}
+
+func runKeyGen(t *testing.T, curve *curves.Curve, threshold uint32, parties []uint32) map[uint32]*Config {
+	t.Helper()
+	keyGens := make(map[uint32]*KeyGen, len(parties))
+	for _, id := range parties {
+		kg, err := NewKeyGen(curve, id, threshold, parties)
+		require.NoError(t, err)
+		keyGens[id] = kg
+	}
+
+	// Every round delivers the message that the sender addressed to each recipient
+	round1 := make(map[uint32]map[uint32]*KeyGenRound1Output, len(parties))
+	for id := range keyGens {
+		round1[id] = make(map[uint32]*KeyGenRound1Output)
+	}
+	for sender, kg := range keyGens {
+		out, err := kg.Round1Commit()
+		require.NoError(t, err)
+		for recipient := range keyGens {
+			if recipient != sender {
+				round1[recipient][sender] = out
+			}
+		}
+	}
+	round2 := make(map[uint32]map[uint32]*KeyGenRound2Output, len(parties))
+	for id := range keyGens {
+		round2[id] = make(map[uint32]*KeyGenRound2Output)
+	}
+	for sender, kg := range keyGens {
+		out, err := kg.Round2Decommit(round1[sender])
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round2[recipient][sender] = message
+		}
+	}
+	configs := make(map[uint32]*Config, len(parties))
+	for id, kg := range keyGens {
+		require.NoError(t, kg.Round3Verify(round2[id]))
+		configs[id] = kg.Output()
+	}
+	return configs
+}
+
+func runRefresh(t *testing.T, curve *curves.Curve, configs map[uint32]*Config) map[uint32]*Config {
+	t.Helper()
+	refreshes := make(map[uint32]*Refresh, len(configs))
+	for id, config := range configs {
+		r, err := NewRefresh(curve, config, testPaillierKey(t, id))
+		require.NoError(t, err)
+		refreshes[id] = r
+	}
+	round1 := make(map[uint32]map[uint32]*RefreshRound1Output, len(configs))
+	for id := range refreshes {
+		round1[id] = make(map[uint32]*RefreshRound1Output)
+	}
+	for sender, r := range refreshes {
+		out, err := r.Round1Commit()
+		require.NoError(t, err)
+		for recipient := range refreshes {
+			if recipient != sender {
+				round1[recipient][sender] = out
+			}
+		}
+	}
+	round2 := make(map[uint32]map[uint32]*RefreshRound2Output, len(configs))
+	for id := range refreshes {
+		round2[id] = make(map[uint32]*RefreshRound2Output)
+	}
+	for sender, r := range refreshes {
+		out, err := r.Round2Decommit(round1[sender])
+		require.NoError(t, err)
+		for recipient := range refreshes {
+			if recipient != sender {
+				round2[recipient][sender] = out
+			}
+		}
+	}
+	round3 := make(map[uint32]map[uint32]*RefreshRound3Output, len(configs))
+	for id := range refreshes {
+		round3[id] = make(map[uint32]*RefreshRound3Output)
+	}
+	for sender, r := range refreshes {
+		out, err := r.Round3Prove(round2[sender])
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round3[recipient][sender] = message
+		}
+	}
+	refreshed := make(map[uint32]*Config, len(configs))
+	for id, r := range refreshes {
+		require.NoError(t, r.Round4Verify(round3[id]))
+		refreshed[id] = r.Output()
+	}
+	return refreshed
+}
+
+func newPresigners(t *testing.T, curve *curves.Curve, configs map[uint32]*Config, signers []uint32) map[uint32]*Presigner {
+	t.Helper()
+	presigners := make(map[uint32]*Presigner, len(signers))
+	for _, id := range signers {
+		p, err := NewPresigner(curve, configs[id], signers)
+		require.NoError(t, err)
+		presigners[id] = p
+	}
+	return presigners
+}
+
+func presignRound1(t *testing.T, presigners map[uint32]*Presigner) map[uint32]map[uint32]*PresignRound1Output {
+	t.Helper()
+	round1 := make(map[uint32]map[uint32]*PresignRound1Output, len(presigners))
+	for id := range presigners {
+		round1[id] = make(map[uint32]*PresignRound1Output)
+	}
+	seeds := make(map[uint32][SessionIdSize]byte, len(presigners))
+	for id, p := range presigners {
+		seed, err := p.GenerateSeed()
+		require.NoError(t, err)
+		seeds[id] = seed
+	}
+	for sender, p := range presigners {
+		in := make(map[uint32][SessionIdSize]byte)
+		for id, seed := range seeds {
+			if id != sender {
+				in[id] = seed
+			}
+		}
+		out, err := p.Round1Encrypt(in)
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round1[recipient][sender] = message
+		}
+	}
+	return round1
+}
+
+func presignRound2(t *testing.T, presigners map[uint32]*Presigner, round1 map[uint32]map[uint32]*PresignRound1Output) map[uint32]map[uint32]*PresignRound2Output {
+	t.Helper()
+	round2 := make(map[uint32]map[uint32]*PresignRound2Output, len(presigners))
+	for id := range presigners {
+		round2[id] = make(map[uint32]*PresignRound2Output)
+	}
+	for sender, p := range presigners {
+		out, err := p.Round2Multiply(round1[sender])
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round2[recipient][sender] = message
+		}
+	}
+	return round2
+}
+
+func presignRound3(t *testing.T, presigners map[uint32]*Presigner, round2 map[uint32]map[uint32]*PresignRound2Output) map[uint32]map[uint32]*PresignRound3Output {
+	t.Helper()
+	round3 := make(map[uint32]map[uint32]*PresignRound3Output, len(presigners))
+	for id := range presigners {
+		round3[id] = make(map[uint32]*PresignRound3Output)
+	}
+	for sender, p := range presigners {
+		out, err := p.Round3Reveal(round2[sender])
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round3[recipient][sender] = message
+		}
+	}
+	return round3
+}
+
+func runPresign(t *testing.T, curve *curves.Curve, configs map[uint32]*Config, signers []uint32) map[uint32]*Presignature {
+	t.Helper()
+	presigners := newPresigners(t, curve, configs, signers)
+	round3 := presignRound3(t, presigners, presignRound2(t, presigners, presignRound1(t, presigners)))
+	presignatures := make(map[uint32]*Presignature, len(signers))
+	for id, p := range presigners {
+		presignature, round4Output, err := p.Round4Presignature(round3[id])
+		require.NoError(t, err)
+		require.Nil(t, round4Output)
+		presignatures[id] = presignature
+	}
+	return presignatures
+}
+
+// presignRound4 runs the identification round after inconsistent shares were revealed in round 3
+func presignRound4(t *testing.T, presigners map[uint32]*Presigner, round3 map[uint32]map[uint32]*PresignRound3Output) map[uint32]map[uint32]*PresignRound4Output {
+	t.Helper()
+	round4 := make(map[uint32]map[uint32]*PresignRound4Output, len(presigners))
+	for id := range presigners {
+		round4[id] = make(map[uint32]*PresignRound4Output)
+	}
+	for sender, p := range presigners {
+		presignature, out, err := p.Round4Presignature(round3[sender])
+		require.NoError(t, err)
+		require.Nil(t, presignature)
+		for recipient, message := range out {
+			round4[recipient][sender] = message
+		}
+	}
+	return round4
+}
+
+func runSign(t *testing.T, curve *curves.Curve, presignatures map[uint32]*Presignature, message []byte,
+	guards map[uint32]protocol.PresignatureGuard) *curves.EcdsaSignature {
+	t.Helper()
+	signers := make(map[uint32]*Signer, len(presignatures))
+	round1 := make(map[uint32]map[uint32]*SignRound1Output, len(presignatures))
+	for id, presignature := range presignatures {
+		signer, err := NewSigner(curve, sha256.New(), presignature, guards[id])
+		require.NoError(t, err)
+		signers[id] = signer
+		round1[id] = make(map[uint32]*SignRound1Output)
+	}
+	for sender, signer := range signers {
+		out, err := signer.Round1Sign(message)
+		require.NoError(t, err)
+		for recipient := range signers {
+			if recipient != sender {
+				round1[recipient][sender] = out
+			}
+		}
+	}
+	var signature *curves.EcdsaSignature
+	for id, signer := range signers {
+		require.NoError(t, signer.Round2Combine(round1[id]))
+		if signature != nil {
+			require.Equal(t, signature, signer.Signature)
+		}
+		signature = signer.Signature
+	}
+	return signature
+}
+
+func verifySignature(t *testing.T, curve *curves.Curve, publicKey curves.Point, message []byte, signature *curves.EcdsaSignature) {
+	t.Helper()
+	ellipticCurve, err := curve.ToEllipticCurve()
+	require.NoError(t, err)
+	uncompressed := publicKey.ToAffineUncompressed()
+	pk := &ecdsa.PublicKey{
+		Curve: ellipticCurve,
+		X:     new(big.Int).SetBytes(uncompressed[1:33]),
+		Y:     new(big.Int).SetBytes(uncompressed[33:]),
+	}
+	digest := sha256.Sum256(message)
+	require.True(t, ecdsa.Verify(pk, digest[:], signature.R, signature.S))
+	require.True(t, signature.S.Cmp(new(big.Int).Rsh(ellipticCurve.Params().N, 1)) <= 0)
+	recovered, err := curves.RecoverPublicKey(curve, digest[:], signature)
+	require.NoError(t, err)
+	require.True(t, publicKey.Equal(recovered))
+}
+
+func TestKeyGen(t *testing.T) {
+	curve := curves.K256()
+	configs := runKeyGen(t, curve, 2, []uint32{1, 2, 3})
+	require.Len(t, configs, 3)
+
+	// Any two shares reconstruct the secret key
+	shares := make([]*sharing.ShamirShare, 0, 2)
+	for _, id := range []uint32{1, 3} {
+		shares = append(shares, &sharing.ShamirShare{Id: id, Value: configs[id].SecretKeyShare.Bytes()})
+	}
+	scheme, err := sharing.NewShamir(2, 3, curve)
+	require.NoError(t, err)
+	secret, err := scheme.Combine(shares...)
+	require.NoError(t, err)
+	for id, config := range configs {
+		require.True(t, curve.ScalarBaseMult(secret).Equal(config.PublicKey))
+		require.Equal(t, configs[1].Rid, config.Rid)
+		require.Equal(t, configs[1].Sid, config.Sid)
+		for _, other := range configs {
+			require.True(t, config.PublicShares[other.Id].Equal(curve.ScalarBaseMult(other.SecretKeyShare)))
+		}
+		require.Nil(t, config.PaillierKey, "party %d", id)
+		require.False(t, config.hasAuxInfo())
+	}
+
+	// Presigning requires auxiliary info
+	_, err = NewPresigner(curve, configs[1], []uint32{1, 2})
+	require.Error(t, err)
+}
+
+func TestKeyGenInvalidMessages(t *testing.T) {
+	curve := curves.P256()
+	parties := []uint32{1, 2, 3}
+	_, err := NewKeyGen(curve, 4, 2, parties)
+	require.Error(t, err)
+	_, err = NewKeyGen(curve, 1, 1, parties)
+	require.Error(t, err)
+	_, err = NewKeyGen(curve, 1, 2, []uint32{1, 2, 2})
+	require.Error(t, err)
+
+	keyGens := make(map[uint32]*KeyGen, len(parties))
+	round1 := make(map[uint32]*KeyGenRound1Output, len(parties))
+	for _, id := range parties {
+		keyGens[id], err = NewKeyGen(curve, id, 2, parties)
+		require.NoError(t, err)
+		round1[id], err = keyGens[id].Round1Commit()
+		require.NoError(t, err)
+	}
+	round2 := make(map[uint32]map[uint32]*KeyGenRound2Output, len(parties))
+	for _, id := range parties {
+		input := map[uint32]*KeyGenRound1Output{}
+		for _, other := range otherParties(id, parties) {
+			input[other] = round1[other]
+		}
+		round2[id], err = keyGens[id].Round2Decommit(input)
+		require.NoError(t, err)
+	}
+
+	// Party 2 opens a different polynomial than it committed to
+	input := map[uint32]*KeyGenRound2Output{2: round2[2][1], 3: round2[3][1]}
+	tampered := *input[2]
+	tampered.Verifier = round2[3][1].Verifier
+	input[2] = &tampered
+	require.EqualError(t, keyGens[1].Round3Verify(input), "party 2 opened a different commitment")
+
+	// Party 3 sends a wrong share
+	input = map[uint32]*KeyGenRound2Output{2: round2[2][1], 3: round2[3][1]}
+	tampered = *input[3]
+	tampered.Share = round2[3][2].Share
+	input[3] = &tampered
+	require.EqualError(t, keyGens[1].Round3Verify(input), "party 3 sent a share for party 2")
+
+	// A missing message is detected
+	require.Error(t, keyGens[1].Round3Verify(map[uint32]*KeyGenRound2Output{2: round2[2][1]}))
+}
+
+func TestCggmp(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the full protocol in short mode")
+	}
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		configs := runKeyGen(t, curve, 2, []uint32{1, 2, 3})
+		refreshed := runRefresh(t, curve, configs)
+		for id, config := range refreshed {
+			require.True(t, config.hasAuxInfo())
+			require.True(t, config.PublicKey.Equal(configs[id].PublicKey))
+			require.False(t, config.SecretKeyShare.Cmp(configs[id].SecretKeyShare) == 0)
+			require.NotEqual(t, configs[id].Sid, config.Sid)
+			require.Equal(t, refreshed[1].Rid, config.Rid)
+		}
+
+		// Any threshold of signers can presign and sign
+		for _, signers := range [][]uint32{{1, 2}, {2, 3}, {1, 2, 3}} {
+			presignatures := runPresign(t, curve, refreshed, signers)
+			copies := make(map[uint32]*Presignature, len(presignatures))
+			for id, presignature := range presignatures {
+				require.True(t, presignature.R.Equal(presignatures[signers[0]].R))
+				copied := *presignature
+				copies[id] = &copied
+			}
+			message := []byte("cggmp21 threshold ecdsa")
+			guards := make(map[uint32]protocol.PresignatureGuard, len(presignatures))
+			for id := range presignatures {
+				guards[id] = protocol.NewMemoryPresignatureGuard()
+			}
+			signature := runSign(t, curve, presignatures, message, guards)
+			verifySignature(t, curve, refreshed[1].PublicKey, message, signature)
+
+			// A presignature signs a single message, and so do its copies
+			for id, presignature := range presignatures {
+				require.Nil(t, presignature.K)
+				_, err := NewSigner(curve, sha256.New(), presignature, guards[id])
+				require.Error(t, err)
+				signer, err := NewSigner(curve, sha256.New(), copies[id], guards[id])
+				require.NoError(t, err)
+				_, err = signer.Round1Sign(message)
+				require.ErrorIs(t, err, protocol.ErrPresignatureUsed)
+			}
+		}
+	}
+}
+
+func TestPresignIdentifiesCheater(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the full protocol in short mode")
+	}
+	curve := curves.K256()
+	configs := runRefresh(t, curve, runKeyGen(t, curve, 2, []uint32{1, 2, 3}))
+	signers := []uint32{1, 2, 3}
+
+	// Party 2 encrypts a different nonce than it proved
+	presigners := newPresigners(t, curve, configs, signers)
+	round1 := presignRound1(t, presigners)
+	round1[1][2].K = round1[3][2].G
+	_, err := presigners[1].Round2Multiply(round1[1])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "party 2")
+
+	// Party 3 multiplies with a different mask than it committed to
+	presigners = newPresigners(t, curve, configs, signers)
+	round2 := presignRound2(t, presigners, presignRound1(t, presigners))
+	round2[1][3].D = round2[1][3].DHat
+	_, err = presigners[1].Round3Reveal(round2[1])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "party 3")
+
+	// Party 2 reveals a wrong share of delta
+	presigners = newPresigners(t, curve, configs, signers)
+	round3 := presignRound3(t, presigners, presignRound2(t, presigners, presignRound1(t, presigners)))
+	round3[1][2].DeltaPoint = round3[1][2].DeltaPoint.Double()
+	_, _, err = presigners[1].Round4Presignature(round3[1])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "party 2")
+
+	// Party 2 reveals a share of delta that is not the plaintext of its multiplications
+	presigners = newPresigners(t, curve, configs, signers)
+	round3 = presignRound3(t, presigners, presignRound2(t, presigners, presignRound1(t, presigners)))
+	presigners[2].delta = presigners[2].delta.Add(curve.Scalar.One())
+	for _, id := range []uint32{1, 3} {
+		round3[id][2].Delta = presigners[2].delta
+	}
+	round4 := presignRound4(t, presigners, round3)
+	for _, id := range []uint32{1, 3} {
+		err = presigners[id].Round5Identify(round4[id])
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "party 2 revealed a share of delta")
+	}
+
+	// Party 2 reveals a commitment to a wrong share of chi
+	presigners = newPresigners(t, curve, configs, signers)
+	round3 = presignRound3(t, presigners, presignRound2(t, presigners, presignRound1(t, presigners)))
+	presigners[2].chiPoint = presigners[2].chiPoint.Add(curve.NewGeneratorPoint())
+	for _, id := range []uint32{1, 3} {
+		round3[id][2].ChiPoint = presigners[2].chiPoint
+	}
+	round4 = presignRound4(t, presigners, round3)
+	for _, id := range []uint32{1, 3} {
+		err = presigners[id].Round5Identify(round4[id])
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "party 2 revealed a share of chi")
+	}
+
+	// Party 3 blames party 2 with a multiplication party 2 did not send
+	received := make(map[uint32]*PresignRound2Output)
+	for id, message := range round4[1][3].Received {
+		received[id] = message
+	}
+	forged := *received[2]
+	forged.D = forged.DHat
+	received[2] = &forged
+	round4[1][3].Received = received
+	err = presigners[1].Round5Identify(round4[1])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "party 3 published a multiplication party 2 did not send")
+}
+
+func TestSignIdentifiesCheater(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the full protocol in short mode")
+	}
+	curve := curves.K256()
+	configs := runRefresh(t, curve, runKeyGen(t, curve, 2, []uint32{1, 2, 3}))
+	presignatures := runPresign(t, curve, configs, []uint32{1, 2, 3})
+
+	// Party 2 sends a share of the signature that does not match its presignature
+	message := []byte("cggmp21 identifiable abort")
+	signers := make(map[uint32]*Signer, len(presignatures))
+	round1 := make(map[uint32]*SignRound1Output, len(presignatures))
+	for id, presignature := range presignatures {
+		signer, err := NewSigner(curve, sha256.New(), presignature, protocol.NewMemoryPresignatureGuard())
+		require.NoError(t, err)
+		signers[id] = signer
+		round1[id], err = signer.Round1Sign(message)
+		require.NoError(t, err)
+	}
+	round1[2] = &SignRound1Output{Sigma: round1[2].Sigma.Add(curve.Scalar.One())}
+	for _, id := range []uint32{1, 3} {
+		input := make(map[uint32]*SignRound1Output)
+		for sender, out := range round1 {
+			if sender != id {
+				input[sender] = out
+			}
+		}
+		err := signers[id].Round2Combine(input)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "party 2")
+	}
+}
+
+func TestPresignSessionsAreFresh(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the full protocol in short mode")
+	}
+	curve := curves.K256()
+	configs := runRefresh(t, curve, runKeyGen(t, curve, 2, []uint32{1, 2}))
+	signers := []uint32{1, 2}
+
+	// Two runs with the same signers have different session ids, so the messages of one are refused by the other
+	first := newPresigners(t, curve, configs, signers)
+	second := newPresigners(t, curve, configs, signers)
+	round1 := presignRound1(t, first)
+	presignRound1(t, second)
+	require.NotEqual(t, first[1].sessionId, second[1].sessionId)
+	_, err := second[1].Round2Multiply(round1[1])
+	require.Error(t, err)
+
+	// The seeds of the other signers are required
+	presigner := newPresigners(t, curve, configs, signers)[1]
+	_, err = presigner.GenerateSeed()
+	require.NoError(t, err)
+	_, err = presigner.Round1Encrypt(nil)
+	require.Error(t, err)
+}
+
+func TestRefreshRejectsSmallPaillierKey(t *testing.T) {
+	curve := curves.K256()
+	configs := runKeyGen(t, curve, 2, []uint32{1, 2, 3})
+	sk, err := paillier.NewSecretKey(tt.B10("1000000007"), tt.B10("1000000009"))
+	require.NoError(t, err)
+	_, err = NewRefresh(curve, configs[1], sk)
+	require.EqualError(t, err, "invalid paillier keys")
+}
+
+// pointWithLargeX returns a point of curve whose x coordinate is larger than the group order
+func pointWithLargeX(t *testing.T, curve *curves.Curve) curves.Point {
+	t.Helper()
+	ellipticCurve, err := curve.ToEllipticCurve()
+	require.NoError(t, err)
+	compressed := make([]byte, 33)
+	compressed[0] = 2
+	for x := new(big.Int).Add(ellipticCurve.Params().N, big.NewInt(1)); ; x.Add(x, big.NewInt(1)) {
+		x.FillBytes(compressed[1:])
+		point, err := curve.Point.FromAffineCompressed(compressed)
+		if err == nil && !point.IsIdentity() {
+			return point
+		}
+	}
+}
+
+// TestSignRecoveryIdOfLargeX signs with a nonce point whose x coordinate is reduced by the group order, with the
+// public key for which the shares form a valid signature
+func TestSignRecoveryIdOfLargeX(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		g := curve.NewGeneratorPoint()
+		presignature := &Presignature{
+			Id:          1,
+			Signers:     []uint32{1, 2},
+			PublicKey:   g,
+			R:           pointWithLargeX(t, curve),
+			K:           curve.Scalar.Random(rand.Reader),
+			Chi:         curve.Scalar.Random(rand.Reader),
+			Gamma:       g,
+			DeltaPoints: map[uint32]curves.Point{1: g, 2: g},
+			ChiPoints:   map[uint32]curves.Point{1: g, 2: g},
+		}
+		signer, err := NewSigner(curve, sha256.New(), presignature, protocol.NewMemoryPresignatureGuard())
+		require.NoError(t, err)
+		message := []byte("large x")
+		out, err := signer.Round1Sign(message)
+		require.NoError(t, err)
+
+		// Q = r^-1 (sR - mG)
+		sigma := curve.Scalar.Random(rand.Reader)
+		digest := sha256.Sum256(message)
+		m, err := curve.Scalar.SetBytes(digest[:])
+		require.NoError(t, err)
+		rInv, err := signer.r.Invert()
+		require.NoError(t, err)
+		presignature.PublicKey = presignature.R.Mul(out.Sigma.Add(sigma)).Sub(curve.ScalarBaseMult(m)).Mul(rInv)
+
+		require.NoError(t, signer.Round2Combine(map[uint32]*SignRound1Output{2: {Sigma: sigma}}))
+		require.Equal(t, 2, signer.Signature.V&2)
+		verifySignature(t, curve, presignature.PublicKey, message, signer.Signature)
+	}
+}
@@ -0,0 +1,849 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+	"github.com/coinbase/kryptology/pkg/sharing"
+	"github.com/coinbase/kryptology/pkg/tecdsa/cggmp/proof"
+)
+
+// Presigner encodes the state of one party during one execution of the three round presigning, CGGMP21 §4.1
+// fig 7. The signers compute the nonce point R = k^-1·G and additive shares of k and k·x, where x is the secret
+// key, with paillier based multiplications between every pair of signers. Every multiplication is proven with
+// Π^aff-g, every encryption with Π^enc and Π^log*.
+type Presigner struct {
+	id      uint32
+	signers []uint32
+	curve   *curves.Curve
+	config  *Config
+
+	// seed is the random contribution of this signer to the session id
+	seed      [SessionIdSize]byte
+	sessionId [SessionIdSize]byte
+	// secretKeyShare is the Shamir share multiplied by its Lagrange coefficient, an additive share of x
+	secretKeyShare curves.Scalar
+	// publicShares are the public keys of the additive shares of the signers
+	publicShares map[uint32]curves.Point
+
+	k, gamma   curves.Scalar
+	rho, nu    *big.Int
+	kCipher    *big.Int
+	gCipher    *big.Int
+	gammaPoint curves.Point
+	// round1Input holds the ciphertexts K_j, G_j of the other signers
+	round1Input map[uint32]*PresignRound1Output
+	// betas are the additive shares of this signer of k_j·γ_i, betaHats of k_j·x_i
+	betas, betaHats map[uint32]curves.Scalar
+	// round2Input and round2Output are the multiplications received from and sent to the other signers, kept to
+	// identify a signer that reveals inconsistent shares
+	round2Input, round2Output map[uint32]*PresignRound2Output
+
+	// bigGamma is the sum Γ of the Γ_j
+	bigGamma   curves.Point
+	delta      curves.Scalar
+	deltaPoint curves.Point
+	chi        curves.Scalar
+	chiPoint   curves.Point
+	// round3Input holds the shares revealed by the other signers
+	round3Input map[uint32]*PresignRound3Output
+}
+
+// PresignRound1Output is the message of the 1st round of presigning from one signer to another.
+type PresignRound1Output struct {
+	// K and G are the encryptions of the nonce share k_i and the mask share γ_i under the paillier key of the sender.
+	// They are broadcast.
+	K, G *big.Int
+
+	// Proof proves that K encrypts a value in range, with the ring-Pedersen parameters of the recipient.
+	Proof *proof.EncProof
+}
+
+// PresignRound2Output is the message of the 2nd round of presigning from one signer to another.
+type PresignRound2Output struct {
+	// Gamma is Γ_i = γ_i·G of the sender. It is broadcast.
+	Gamma curves.Point
+
+	// D = K_j^γ_i · enc_j(β) and F = enc_i(β) mask the product k_j·γ_i for the recipient j.
+	D, F *big.Int
+
+	// DHat = K_j^x_i · enc_j(β^) and FHat = enc_i(β^) mask the product k_j·x_i for the recipient j.
+	DHat, FHat *big.Int
+
+	// Proof and ProofHat prove that D and DHat are computed correctly.
+	Proof, ProofHat *proof.AffGProof
+
+	// LogStarProof proves that G encrypts the discrete logarithm of Gamma.
+	LogStarProof *proof.LogStarProof
+}
+
+// PresignRound3Output is the message of the 3rd round of presigning from one signer to another.
+type PresignRound3Output struct {
+	// Delta is the additive share δ_i of k·γ of the sender. It is broadcast.
+	Delta curves.Scalar
+
+	// DeltaPoint is Δ_i = k_i·Γ of the sender. It is broadcast.
+	DeltaPoint curves.Point
+
+	// ChiPoint is S_i = χ_i·Γ of the sender, which commits to its share of k·x. It is broadcast.
+	ChiPoint curves.Point
+
+	// Proof proves that K encrypts the discrete logarithm of DeltaPoint in base Γ.
+	Proof *proof.LogStarProof
+}
+
+// PresignRound4Output is the message of the 4th round of presigning from one signer to another. It is only sent
+// when the revealed shares are inconsistent, to identify the signer that cheated, CGGMP21 §4.1 fig 7 Output.2.
+type PresignRound4Output struct {
+	// Received are the messages of the 2nd round the sender received from every other signer, with their proofs.
+	// They are broadcast.
+	Received map[uint32]*PresignRound2Output
+
+	// H = enc_i(k_i·γ_i) and HHat = enc_i(k_i·x_i) under the paillier key of the sender. They are broadcast.
+	H, HHat *big.Int
+
+	// HProof and HHatProof prove that H and HHat are the products of K with the discrete logarithms of Γ_i and X_i.
+	HProof, HHatProof *proof.AffGProof
+
+	// DeltaProof proves that δ_i is the plaintext modulo q of H·∏_j D_{i,j}·F_{i,j}^-1.
+	DeltaProof *proof.DecProof
+
+	// ChiCiphertext is enc_i(χ_i mod q). It is broadcast.
+	ChiCiphertext *big.Int
+
+	// ChiProof proves that ChiCiphertext encrypts the discrete logarithm of S_i in base Γ, and ChiDecProof that
+	// HHat·∏_j DHat_{i,j}·FHat_{i,j}^-1·ChiCiphertext^-1 encrypts a multiple of q.
+	ChiProof    *proof.LogStarProof
+	ChiDecProof *proof.DecProof
+}
+
+// NewPresigner creates a party that can compute a presignature with the parties in signers, which must include
+// the id of config and at least threshold parties. The config must hold auxiliary info.
+func NewPresigner(curve *curves.Curve, config *Config, signers []uint32) (*Presigner, error) {
+	if curve == nil || config == nil || config.SecretKeyShare == nil || config.PublicKey == nil {
+		return nil, fmt.Errorf("presigner is not initialized")
+	}
+	if !config.hasAuxInfo() {
+		return nil, fmt.Errorf("config has no auxiliary info, it must be refreshed first")
+	}
+	sorted, err := sortedParties(config.Id, signers)
+	if err != nil {
+		return nil, err
+	}
+	if uint32(len(sorted)) < config.Threshold {
+		return nil, fmt.Errorf("at least %d signers are required", config.Threshold)
+	}
+	for _, id := range sorted {
+		if _, ok := config.PublicShares[id]; !ok {
+			return nil, fmt.Errorf("party %d does not hold a share", id)
+		}
+	}
+	scheme, err := sharing.NewShamir(config.Threshold, uint32(len(config.Parties)), curve)
+	if err != nil {
+		return nil, err
+	}
+	lagrange, err := scheme.LagrangeCoeffs(sorted)
+	if err != nil {
+		return nil, err
+	}
+	publicShares := make(map[uint32]curves.Point, len(sorted))
+	for _, id := range sorted {
+		publicShares[id] = config.PublicShares[id].Mul(lagrange[id])
+	}
+	return &Presigner{
+		id:             config.Id,
+		signers:        sorted,
+		curve:          curve,
+		config:         config,
+		secretKeyShare: config.SecretKeyShare.Mul(lagrange[config.Id]),
+		publicShares:   publicShares,
+	}, nil
+}
+
+// GenerateSeed samples the random seed of this signer, which is broadcast to the other signers before the 1st round.
+// The session id of the presigning is derived from the seeds of all signers, so that it is fresh for every run with
+// the same signers and no message or proof of another run is accepted.
+func (p *Presigner) GenerateSeed() ([SessionIdSize]byte, error) {
+	if _, err := rand.Read(p.seed[:]); err != nil {
+		return p.seed, errors.Wrap(err, "generating the presign seed")
+	}
+	return p.seed, nil
+}
+
+// Round1Encrypt derives the session id from the seeds of the other signers and this one, samples the nonce share
+// k_i and the mask share γ_i, and sends their encryptions to every other signer with a proof that K_i is in range.
+func (p *Presigner) Round1Encrypt(seeds map[uint32][SessionIdSize]byte) (map[uint32]*PresignRound1Output, error) {
+	peers := otherParties(p.id, p.signers)
+	if err := checkSenders(peers, len(seeds), func(id uint32) bool { _, ok := seeds[id]; return ok }); err != nil {
+		return nil, err
+	}
+	if p.seed == [SessionIdSize]byte{} {
+		return nil, fmt.Errorf("the seed has not been generated")
+	}
+	values := [][]byte{p.config.Sid[:], p.config.Rid[:]}
+	for _, id := range p.signers {
+		seed := p.seed
+		if id != p.id {
+			seed = seeds[id]
+		}
+		values = append(values, seed[:])
+	}
+	p.sessionId = hashSession("CGGMP21 presign", values, p.signers...)
+
+	pk := &p.config.PaillierKey.PublicKey
+	p.k = p.curve.Scalar.Random(rand.Reader)
+	p.gamma = p.curve.Scalar.Random(rand.Reader)
+	kCipher, rho, err := pk.Encrypt(p.k.BigInt())
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypting k in presign round 1")
+	}
+	gCipher, nu, err := pk.Encrypt(p.gamma.BigInt())
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypting gamma in presign round 1")
+	}
+	p.kCipher, p.gCipher, p.rho, p.nu = kCipher, gCipher, rho, nu
+
+	output := make(map[uint32]*PresignRound1Output, len(peers))
+	for _, id := range peers {
+		encProof, err := (&proof.EncProofParams{
+			Curve:      p.curve,
+			Pk:         pk,
+			Pedersen:   p.config.Pedersen[id],
+			K:          p.k.BigInt(),
+			Rho:        rho,
+			Ciphertext: kCipher,
+			Sid:        subSessionId(p.sessionId, "presign enc", p.id, id),
+		}).Prove()
+		if err != nil {
+			return nil, errors.Wrapf(err, "proving the range of k to party %d in presign round 1", id)
+		}
+		output[id] = &PresignRound1Output{K: kCipher, G: gCipher, Proof: encProof}
+	}
+	return output, nil
+}
+
+// Round2Multiply checks the range proofs of the other signers and starts the multiplications of their nonce
+// shares with the mask share and the secret key share of this signer.
+func (p *Presigner) Round2Multiply(input map[uint32]*PresignRound1Output) (map[uint32]*PresignRound2Output, error) {
+	peers := otherParties(p.id, p.signers)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return nil, err
+	}
+	if p.k == nil {
+		return nil, fmt.Errorf("round 1 has not been run")
+	}
+	pk := &p.config.PaillierKey.PublicKey
+	for _, id := range peers {
+		message := input[id]
+		if message.K == nil || message.G == nil || message.Proof == nil {
+			return nil, fmt.Errorf("malformed message from party %d", id)
+		}
+		if err := message.Proof.Verify(&proof.EncVerifyParams{
+			Curve:      p.curve,
+			Pk:         p.config.PaillierKeys[id],
+			Pedersen:   p.config.Pedersen[p.id],
+			Ciphertext: message.K,
+			Sid:        subSessionId(p.sessionId, "presign enc", id, p.id),
+		}); err != nil {
+			return nil, errors.Wrapf(err, "verifying the range proof of party %d", id)
+		}
+		if err := core.In(message.G, p.config.PaillierKeys[id].N2); err != nil {
+			return nil, errors.Wrapf(err, "invalid ciphertext from party %d", id)
+		}
+	}
+	p.round1Input = input
+	p.gammaPoint = p.curve.ScalarBaseMult(p.gamma)
+	p.betas = make(map[uint32]curves.Scalar, len(peers))
+	p.betaHats = make(map[uint32]curves.Scalar, len(peers))
+
+	output := make(map[uint32]*PresignRound2Output, len(peers))
+	for _, id := range peers {
+		message := &PresignRound2Output{Gamma: p.gammaPoint}
+		var err error
+		var beta curves.Scalar
+		if message.D, message.F, message.Proof, beta, err = p.multiply(id, p.gamma, p.gammaPoint, "presign aff-g gamma"); err != nil {
+			return nil, errors.Wrapf(err, "multiplying gamma with party %d in presign round 2", id)
+		}
+		p.betas[id] = beta
+		if message.DHat, message.FHat, message.ProofHat, beta, err = p.multiply(id, p.secretKeyShare, p.publicShares[p.id], "presign aff-g x"); err != nil {
+			return nil, errors.Wrapf(err, "multiplying the key share with party %d in presign round 2", id)
+		}
+		p.betaHats[id] = beta
+		if message.LogStarProof, err = (&proof.LogStarProofParams{
+			Curve:      p.curve,
+			Pk:         pk,
+			Pedersen:   p.config.Pedersen[id],
+			X:          p.gamma.BigInt(),
+			Rho:        p.nu,
+			Ciphertext: p.gCipher,
+			Point:      p.gammaPoint,
+			Sid:        subSessionId(p.sessionId, "presign log* gamma", p.id, id),
+		}).Prove(); err != nil {
+			return nil, errors.Wrapf(err, "proving gamma to party %d in presign round 2", id)
+		}
+		output[id] = message
+	}
+	p.round2Output = output
+	return output, nil
+}
+
+// Round3Reveal checks the multiplications of the other signers, computes the shares δ_i of k·γ and χ_i of k·x and
+// reveals δ_i, Δ_i = k_i·Γ and S_i = χ_i·Γ.
+func (p *Presigner) Round3Reveal(input map[uint32]*PresignRound2Output) (map[uint32]*PresignRound3Output, error) {
+	peers := otherParties(p.id, p.signers)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return nil, err
+	}
+	if p.betas == nil {
+		return nil, fmt.Errorf("round 2 has not been run")
+	}
+	bigGamma := p.gammaPoint
+	delta := p.gamma.Mul(p.k)
+	chi := p.secretKeyShare.Mul(p.k)
+	for _, id := range peers {
+		message := input[id]
+		if message.Gamma == nil || !message.Gamma.IsOnCurve() || message.Gamma.IsIdentity() ||
+			core.AnyNil(message.D, message.F, message.DHat, message.FHat) ||
+			message.Proof == nil || message.ProofHat == nil || message.LogStarProof == nil {
+			return nil, fmt.Errorf("malformed message from party %d", id)
+		}
+		alpha, err := p.verifyMultiplication(id, message.D, message.F, message.Proof, message.Gamma, "presign aff-g gamma")
+		if err != nil {
+			return nil, err
+		}
+		alphaHat, err := p.verifyMultiplication(id, message.DHat, message.FHat, message.ProofHat, p.publicShares[id], "presign aff-g x")
+		if err != nil {
+			return nil, err
+		}
+		if err = message.LogStarProof.Verify(&proof.LogStarVerifyParams{
+			Curve:      p.curve,
+			Pk:         p.config.PaillierKeys[id],
+			Pedersen:   p.config.Pedersen[p.id],
+			Ciphertext: p.round1Input[id].G,
+			Point:      message.Gamma,
+			Sid:        subSessionId(p.sessionId, "presign log* gamma", id, p.id),
+		}); err != nil {
+			return nil, errors.Wrapf(err, "verifying the gamma proof of party %d", id)
+		}
+		bigGamma = bigGamma.Add(message.Gamma)
+		delta = delta.Add(alpha).Add(p.betas[id])
+		chi = chi.Add(alphaHat).Add(p.betaHats[id])
+	}
+	p.round2Input = input
+	p.bigGamma = bigGamma
+	p.delta = delta
+	p.chi = chi
+	p.deltaPoint = bigGamma.Mul(p.k)
+	p.chiPoint = bigGamma.Mul(chi)
+
+	output := make(map[uint32]*PresignRound3Output, len(peers))
+	for _, id := range peers {
+		logStarProof, err := (&proof.LogStarProofParams{
+			Curve:      p.curve,
+			Pk:         &p.config.PaillierKey.PublicKey,
+			Pedersen:   p.config.Pedersen[id],
+			X:          p.k.BigInt(),
+			Rho:        p.rho,
+			Ciphertext: p.kCipher,
+			Base:       bigGamma,
+			Point:      p.deltaPoint,
+			Sid:        subSessionId(p.sessionId, "presign log* delta", p.id, id),
+		}).Prove()
+		if err != nil {
+			return nil, errors.Wrapf(err, "proving delta to party %d in presign round 3", id)
+		}
+		output[id] = &PresignRound3Output{Delta: delta, DeltaPoint: p.deltaPoint, ChiPoint: p.chiPoint, Proof: logStarProof}
+	}
+	return output, nil
+}
+
+// Round4Presignature checks the revealed values of the other signers and outputs the presignature of this signer.
+// The nonce of this presigner is erased, it can output a single presignature. If the revealed shares are
+// inconsistent, there is no presignature and the returned messages prove the shares of this signer to the other
+// signers, who pass them to Round5Identify.
+func (p *Presigner) Round4Presignature(input map[uint32]*PresignRound3Output) (*Presignature, map[uint32]*PresignRound4Output, error) {
+	peers := otherParties(p.id, p.signers)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return nil, nil, err
+	}
+	if p.delta == nil || p.k == nil {
+		return nil, nil, fmt.Errorf("round 3 has not been run")
+	}
+	delta := p.delta
+	deltaPoint := p.deltaPoint
+	chiPoint := p.chiPoint
+	deltaPoints := map[uint32]curves.Point{p.id: p.deltaPoint}
+	chiPoints := map[uint32]curves.Point{p.id: p.chiPoint}
+	for _, id := range peers {
+		message := input[id]
+		if message.Delta == nil || message.DeltaPoint == nil || !message.DeltaPoint.IsOnCurve() ||
+			message.ChiPoint == nil || !message.ChiPoint.IsOnCurve() || message.Proof == nil {
+			return nil, nil, fmt.Errorf("malformed message from party %d", id)
+		}
+		if err := message.Proof.Verify(&proof.LogStarVerifyParams{
+			Curve:      p.curve,
+			Pk:         p.config.PaillierKeys[id],
+			Pedersen:   p.config.Pedersen[p.id],
+			Ciphertext: p.round1Input[id].K,
+			Base:       p.bigGamma,
+			Point:      message.DeltaPoint,
+			Sid:        subSessionId(p.sessionId, "presign log* delta", id, p.id),
+		}); err != nil {
+			return nil, nil, errors.Wrapf(err, "verifying the delta proof of party %d", id)
+		}
+		delta = delta.Add(message.Delta)
+		deltaPoint = deltaPoint.Add(message.DeltaPoint)
+		chiPoint = chiPoint.Add(message.ChiPoint)
+		deltaPoints[id] = message.DeltaPoint
+		chiPoints[id] = message.ChiPoint
+	}
+	p.round3Input = input
+	// g^δ = Σ Δ_j = k·Γ and Σ S_j = k·x·Γ = δ·X, otherwise a signer revealed a wrong share of δ or χ
+	if delta.IsZero() || !p.curve.ScalarBaseMult(delta).Equal(deltaPoint) || !p.config.PublicKey.Mul(delta).Equal(chiPoint) {
+		output, err := p.proveShares()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "proving the shares of presign round 3")
+		}
+		return nil, output, nil
+	}
+	deltaInv, err := delta.Invert()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "inverting delta")
+	}
+	presignature := &Presignature{
+		Id:          p.id,
+		Signers:     p.signers,
+		PublicKey:   p.config.PublicKey,
+		R:           p.bigGamma.Mul(deltaInv),
+		K:           p.k,
+		Chi:         p.chi,
+		Gamma:       p.bigGamma,
+		DeltaPoints: deltaPoints,
+		ChiPoints:   chiPoints,
+	}
+	// The nonce now belongs to the presignature only
+	p.k = nil
+	p.chi = nil
+	return presignature, nil, nil
+}
+
+// Round5Identify checks the proofs of the shares revealed by the other signers, after Round4Presignature found
+// them inconsistent, and returns an error naming the signer whose share does not match its multiplications.
+func (p *Presigner) Round5Identify(input map[uint32]*PresignRound4Output) error {
+	peers := otherParties(p.id, p.signers)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return err
+	}
+	if p.round3Input == nil {
+		return fmt.Errorf("round 4 has not been run")
+	}
+	for _, id := range peers {
+		message := input[id]
+		if core.AnyNil(message.H, message.HHat, message.ChiCiphertext) || message.HProof == nil ||
+			message.HHatProof == nil || message.DeltaProof == nil || message.ChiProof == nil || message.ChiDecProof == nil {
+			return fmt.Errorf("malformed message from party %d", id)
+		}
+		for _, sender := range otherParties(id, peers) {
+			received := message.Received[sender]
+			if received == nil || core.AnyNil(received.D, received.F, received.DHat, received.FHat) ||
+				received.Proof == nil || received.ProofHat == nil {
+				return fmt.Errorf("malformed message from party %d", id)
+			}
+		}
+	}
+	// received returns the message of the 2nd round that sender sent to recipient
+	received := func(recipient, sender uint32) *PresignRound2Output {
+		switch p.id {
+		case recipient:
+			return p.round2Input[sender]
+		case sender:
+			return p.round2Output[recipient]
+		default:
+			return input[recipient].Received[sender]
+		}
+	}
+
+	// The multiplications a signer received carry the proofs of their senders, it cannot change them
+	for _, id := range peers {
+		for _, sender := range otherParties(id, peers) {
+			message := received(id, sender)
+			if err := p.verifyReceived(id, sender, message.D, message.F, message.Proof, p.round2Input[sender].Gamma, "presign aff-g gamma"); err != nil {
+				return errors.Wrapf(err, "party %d published a multiplication party %d did not send", id, sender)
+			}
+			if err := p.verifyReceived(id, sender, message.DHat, message.FHat, message.ProofHat, p.publicShares[sender], "presign aff-g x"); err != nil {
+				return errors.Wrapf(err, "party %d published a multiplication party %d did not send", id, sender)
+			}
+		}
+	}
+
+	for _, id := range peers {
+		message := input[id]
+		pk := p.config.PaillierKeys[id]
+		if err := p.verifyProduct(id, message.H, message.HProof, p.round2Input[id].Gamma, "presign aff-g h"); err != nil {
+			return errors.Wrapf(err, "party %d revealed a share of delta that does not match its multiplications", id)
+		}
+		if err := p.verifyProduct(id, message.HHat, message.HHatProof, p.publicShares[id], "presign aff-g h hat"); err != nil {
+			return errors.Wrapf(err, "party %d revealed a share of chi that does not match its multiplications", id)
+		}
+		deltaCiphertext, chiCiphertext, err := p.shareCiphertexts(id, message.H, message.HHat, received)
+		if err != nil {
+			return errors.Wrapf(err, "party %d revealed a share of delta that does not match its multiplications", id)
+		}
+		if err = message.DeltaProof.Verify(&proof.DecVerifyParams{
+			Curve:      p.curve,
+			Pk:         pk,
+			Pedersen:   p.config.Pedersen[p.id],
+			Ciphertext: deltaCiphertext,
+			X:          p.round3Input[id].Delta,
+			Sid:        subSessionId(p.sessionId, "presign dec delta", id, p.id),
+		}); err != nil {
+			return errors.Wrapf(err, "party %d revealed a share of delta that does not match its multiplications", id)
+		}
+		if err = core.In(message.ChiCiphertext, pk.N2); err != nil {
+			return errors.Wrapf(err, "invalid ciphertext from party %d", id)
+		}
+		if err = message.ChiProof.Verify(&proof.LogStarVerifyParams{
+			Curve:      p.curve,
+			Pk:         pk,
+			Pedersen:   p.config.Pedersen[p.id],
+			Ciphertext: message.ChiCiphertext,
+			Base:       p.bigGamma,
+			Point:      p.round3Input[id].ChiPoint,
+			Sid:        subSessionId(p.sessionId, "presign log* chi", id, p.id),
+		}); err != nil {
+			return errors.Wrapf(err, "party %d revealed a share of chi that does not match its multiplications", id)
+		}
+		remainder, err := divide(pk, chiCiphertext, message.ChiCiphertext)
+		if err != nil {
+			return errors.Wrapf(err, "party %d revealed a share of chi that does not match its multiplications", id)
+		}
+		if err = message.ChiDecProof.Verify(&proof.DecVerifyParams{
+			Curve:      p.curve,
+			Pk:         pk,
+			Pedersen:   p.config.Pedersen[p.id],
+			Ciphertext: remainder,
+			X:          p.curve.Scalar.Zero(),
+			Sid:        subSessionId(p.sessionId, "presign dec chi", id, p.id),
+		}); err != nil {
+			return errors.Wrapf(err, "party %d revealed a share of chi that does not match its multiplications", id)
+		}
+	}
+	return fmt.Errorf("inconsistent presign shares, but no party could be identified")
+}
+
+// proveShares proves to every other signer that the shares δ_i and χ_i revealed by this signer are the plaintexts
+// of the products of its multiplications, CGGMP21 §4.1 fig 7 Output.2.
+func (p *Presigner) proveShares() (map[uint32]*PresignRound4Output, error) {
+	sk := p.config.PaillierKey
+	pk := &sk.PublicKey
+	peers := otherParties(p.id, p.signers)
+	h, hRho, err := product(pk, p.kCipher, p.gamma)
+	if err != nil {
+		return nil, err
+	}
+	hHat, hHatRho, err := product(pk, p.kCipher, p.secretKeyShare)
+	if err != nil {
+		return nil, err
+	}
+	received := func(recipient, sender uint32) *PresignRound2Output {
+		if recipient == p.id {
+			return p.round2Input[sender]
+		}
+		return p.round2Output[recipient]
+	}
+	deltaCiphertext, chiCiphertext, err := p.shareCiphertexts(p.id, h, hHat, received)
+	if err != nil {
+		return nil, err
+	}
+	deltaPlaintext, deltaRho, err := decryptWithNonce(sk, deltaCiphertext)
+	if err != nil {
+		return nil, err
+	}
+	chiShare, chiRho, err := pk.Encrypt(p.chi.BigInt())
+	if err != nil {
+		return nil, err
+	}
+	remainder, err := divide(pk, chiCiphertext, chiShare)
+	if err != nil {
+		return nil, err
+	}
+	remainderPlaintext, remainderRho, err := decryptWithNonce(sk, remainder)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(map[uint32]*PresignRound4Output, len(peers))
+	for _, id := range peers {
+		message := &PresignRound4Output{Received: p.round2Input, H: h, HHat: hHat, ChiCiphertext: chiShare}
+		if message.HProof, err = p.proveProduct(id, h, hRho, p.gamma, p.gammaPoint, "presign aff-g h"); err != nil {
+			return nil, err
+		}
+		if message.HHatProof, err = p.proveProduct(id, hHat, hHatRho, p.secretKeyShare, p.publicShares[p.id], "presign aff-g h hat"); err != nil {
+			return nil, err
+		}
+		if message.DeltaProof, err = (&proof.DecProofParams{
+			Curve:      p.curve,
+			Pk:         pk,
+			Pedersen:   p.config.Pedersen[id],
+			Y:          deltaPlaintext,
+			Rho:        deltaRho,
+			Ciphertext: deltaCiphertext,
+			Sid:        subSessionId(p.sessionId, "presign dec delta", p.id, id),
+		}).Prove(); err != nil {
+			return nil, err
+		}
+		if message.ChiProof, err = (&proof.LogStarProofParams{
+			Curve:      p.curve,
+			Pk:         pk,
+			Pedersen:   p.config.Pedersen[id],
+			X:          p.chi.BigInt(),
+			Rho:        chiRho,
+			Ciphertext: chiShare,
+			Base:       p.bigGamma,
+			Point:      p.chiPoint,
+			Sid:        subSessionId(p.sessionId, "presign log* chi", p.id, id),
+		}).Prove(); err != nil {
+			return nil, err
+		}
+		if message.ChiDecProof, err = (&proof.DecProofParams{
+			Curve:      p.curve,
+			Pk:         pk,
+			Pedersen:   p.config.Pedersen[id],
+			Y:          remainderPlaintext,
+			Rho:        remainderRho,
+			Ciphertext: remainder,
+			Sid:        subSessionId(p.sessionId, "presign dec chi", p.id, id),
+		}).Prove(); err != nil {
+			return nil, err
+		}
+		output[id] = message
+	}
+	return output, nil
+}
+
+// shareCiphertexts computes the encryptions of δ_i and χ_i under the paillier key of signer id from the products
+// H, HHat and the multiplications of the 2nd round: H·∏_j D_{i,j}·F_{i,j}^-1 and HHat·∏_j DHat_{i,j}·FHat_{i,j}^-1.
+func (p *Presigner) shareCiphertexts(id uint32, h, hHat *big.Int, received func(recipient, sender uint32) *PresignRound2Output) (*big.Int, *big.Int, error) {
+	pk := p.config.PaillierKeys[id]
+	delta, chi := h, hHat
+	for _, j := range otherParties(id, p.signers) {
+		in, out := received(id, j), received(j, id)
+		var err error
+		if delta, err = pk.Add(delta, in.D); err != nil {
+			return nil, nil, err
+		}
+		if delta, err = divide(pk, delta, out.F); err != nil {
+			return nil, nil, err
+		}
+		if chi, err = pk.Add(chi, in.DHat); err != nil {
+			return nil, nil, err
+		}
+		if chi, err = divide(pk, chi, out.FHat); err != nil {
+			return nil, nil, err
+		}
+	}
+	return delta, chi, nil
+}
+
+// proveProduct proves to signer id that h = K_i^x ρ^N encrypts k_i·x, where point is x·G, with Π^aff-g for an
+// additive term encrypted to 1.
+func (p *Presigner) proveProduct(id uint32, h, rho *big.Int, x curves.Scalar, point curves.Point, label string) (*proof.AffGProof, error) {
+	pk := &p.config.PaillierKey.PublicKey
+	return (&proof.AffGProofParams{
+		Curve:       p.curve,
+		Pk0:         pk,
+		Pk1:         pk,
+		Pedersen:    p.config.Pedersen[id],
+		X:           x.BigInt(),
+		Y:           big.NewInt(0),
+		Rho:         rho,
+		RhoY:        big.NewInt(1),
+		C:           p.kCipher,
+		D:           h,
+		CiphertextY: big.NewInt(1),
+		Point:       point,
+		Sid:         subSessionId(p.sessionId, label, p.id, id),
+	}).Prove()
+}
+
+// verifyProduct checks the proof of signer id that h encrypts k_id·x under its paillier key, where point is x·G.
+func (p *Presigner) verifyProduct(id uint32, h *big.Int, affGProof *proof.AffGProof, point curves.Point, label string) error {
+	pk := p.config.PaillierKeys[id]
+	return affGProof.Verify(&proof.AffGVerifyParams{
+		Curve:       p.curve,
+		Pk0:         pk,
+		Pk1:         pk,
+		Pedersen:    p.config.Pedersen[p.id],
+		C:           p.round1Input[id].K,
+		D:           h,
+		CiphertextY: big.NewInt(1),
+		Point:       point,
+		Sid:         subSessionId(p.sessionId, label, id, p.id),
+	})
+}
+
+// verifyReceived checks the proof that sender multiplied the nonce share of recipient with the discrete logarithm
+// of point, as recipient checked it in Round3Reveal.
+func (p *Presigner) verifyReceived(recipient, sender uint32, d, f *big.Int, affGProof *proof.AffGProof, point curves.Point, label string) error {
+	return affGProof.Verify(&proof.AffGVerifyParams{
+		Curve:       p.curve,
+		Pk0:         p.config.PaillierKeys[recipient],
+		Pk1:         p.config.PaillierKeys[sender],
+		Pedersen:    p.config.Pedersen[recipient],
+		C:           p.round1Input[recipient].K,
+		D:           d,
+		CiphertextY: f,
+		Point:       point,
+		Sid:         subSessionId(p.sessionId, label, sender, recipient),
+	})
+}
+
+// multiply computes the multiplication of the nonce share k_j of signer id with the secret x of this signer:
+// D = K_j^x · enc_j(y), F = enc_i(y) and the proof that they are correct. It returns the additive share -y of
+// this signer of k_j·x.
+func (p *Presigner) multiply(id uint32, x curves.Scalar, point curves.Point, label string) (*big.Int, *big.Int, *proof.AffGProof, curves.Scalar, error) {
+	pk := &p.config.PaillierKey.PublicKey
+	peerPk := p.config.PaillierKeys[id]
+	y, err := randSigned(proof.EllPrime)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	yCipher, rho, err := peerPk.Encrypt(new(big.Int).Mod(y, peerPk.N))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	d, err := peerPk.Mul(x.BigInt(), p.round1Input[id].K)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if d, err = peerPk.Add(d, yCipher); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	f, rhoY, err := pk.Encrypt(new(big.Int).Mod(y, pk.N))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	affGProof, err := (&proof.AffGProofParams{
+		Curve:       p.curve,
+		Pk0:         peerPk,
+		Pk1:         pk,
+		Pedersen:    p.config.Pedersen[id],
+		X:           x.BigInt(),
+		Y:           y,
+		Rho:         rho,
+		RhoY:        rhoY,
+		C:           p.round1Input[id].K,
+		D:           d,
+		CiphertextY: f,
+		Point:       point,
+		Sid:         subSessionId(p.sessionId, label, p.id, id),
+	}).Prove()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	beta, err := proof.ScalarFromInt(p.curve, new(big.Int).Neg(y))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return d, f, affGProof, beta, nil
+}
+
+// verifyMultiplication checks the multiplication of the nonce share of this signer with the secret of signer id,
+// whose public key is point, and returns the additive share α of this signer of the product.
+func (p *Presigner) verifyMultiplication(id uint32, d, f *big.Int, affGProof *proof.AffGProof, point curves.Point, label string) (curves.Scalar, error) {
+	if err := affGProof.Verify(&proof.AffGVerifyParams{
+		Curve:       p.curve,
+		Pk0:         &p.config.PaillierKey.PublicKey,
+		Pk1:         p.config.PaillierKeys[id],
+		Pedersen:    p.config.Pedersen[p.id],
+		C:           p.kCipher,
+		D:           d,
+		CiphertextY: f,
+		Point:       point,
+		Sid:         subSessionId(p.sessionId, label, id, p.id),
+	}); err != nil {
+		return nil, errors.Wrapf(err, "verifying the multiplication proof of party %d", id)
+	}
+	alpha, err := decryptSigned(p.config.PaillierKey, d)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decrypting the multiplication of party %d", id)
+	}
+	return proof.ScalarFromInt(p.curve, alpha)
+}
+
+// product computes c^x ρ^N under pk, an encryption of x times the plaintext of c, and returns it with ρ
+func product(pk *paillier.PublicKey, c *big.Int, x curves.Scalar) (*big.Int, *big.Int, error) {
+	zero, rho, err := pk.Encrypt(big.NewInt(0))
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := pk.Mul(x.BigInt(), c)
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err = pk.Add(h, zero)
+	if err != nil {
+		return nil, nil, err
+	}
+	return h, rho, nil
+}
+
+// divide computes c·d^-1 mod N², an encryption of the difference of the plaintexts of c and d
+func divide(pk *paillier.PublicKey, c, d *big.Int) (*big.Int, error) {
+	if err := core.In(d, pk.N2); err != nil {
+		return nil, err
+	}
+	dInv, err := core.Inv(d, pk.N2)
+	if err != nil {
+		return nil, err
+	}
+	return pk.Add(c, dInv)
+}
+
+// decryptWithNonce decrypts c to its signed plaintext m and recovers the nonce ρ such that c = (1+N)^m ρ^N mod N²:
+// c = ρ^N mod N, so ρ = c^(N^-1 mod φ(N)) mod N.
+func decryptWithNonce(sk *paillier.SecretKey, c *big.Int) (*big.Int, *big.Int, error) {
+	m, err := decryptSigned(sk, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	nInv, err := core.Inv(sk.N, sk.Totient)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, new(big.Int).Exp(new(big.Int).Mod(c, sk.N), nInv, sk.N), nil
+}
+
+// decryptSigned decrypts c to the integer in (-N/2, N/2] it is congruent to
+func decryptSigned(sk *paillier.SecretKey, c *big.Int) (*big.Int, error) {
+	m, err := sk.Decrypt(c)
+	if err != nil {
+		return nil, err
+	}
+	if m.Cmp(new(big.Int).Rsh(sk.N, 1)) > 0 {
+		m.Sub(m, sk.N)
+	}
+	return m, nil
+}
+
+// randSigned samples a uniform integer in [-2^bits, 2^bits]
+func randSigned(bits uint) (*big.Int, error) {
+	bound := new(big.Int).Lsh(core.One, bits)
+	r, err := rand.Int(rand.Reader, new(big.Int).Add(new(big.Int).Lsh(bound, 1), core.One))
+	if err != nil {
+		return nil, err
+	}
+	return r.Sub(r, bound), nil
+}
@@ -0,0 +1,355 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+	"github.com/coinbase/kryptology/pkg/sharing"
+	"github.com/coinbase/kryptology/pkg/tecdsa/cggmp/proof"
+)
+
+// Refresh encodes the state of one party during one execution of auxiliary info and key refresh, CGGMP21 §3.2
+// fig 6. Every party deals a Feldman sharing of zero to rerandomize the key shares, and publishes a new paillier
+// key and ring-Pedersen parameters. The parameters are proven well formed with Π^prm, the paillier moduli with
+// Π^mod and Π^fac. The public key stays the same.
+type Refresh struct {
+	id       uint32
+	curve    *curves.Curve
+	previous *Config
+
+	sessionId   [SessionIdSize]byte
+	paillierKey *paillier.SecretKey
+	pedersen    *proof.Pedersen
+	prmProof    *proof.PrmProof
+	polynomial  *sharing.Polynomial
+	verifier    *sharing.FeldmanVerifier
+	rid         [SessionIdSize]byte
+	salt        [SessionIdSize]byte
+	commitments map[uint32][SessionIdSize]byte
+	round2Input map[uint32]*RefreshRound2Output
+	// verifiers are the Feldman verifiers of the sharings of zero of the other parties
+	verifiers map[uint32]*sharing.FeldmanVerifier
+
+	output *Config
+}
+
+// RefreshRound1Output is the broadcast of the 1st round of refresh.
+type RefreshRound1Output struct {
+	// Commitment is the hash commitment to the values the sender opens in round 2.
+	Commitment [SessionIdSize]byte
+}
+
+// RefreshRound2Output is the broadcast of the 2nd round of refresh.
+type RefreshRound2Output struct {
+	// Commitments are the Feldman commitments to the coefficients of degree 1 and higher of the sharing of zero of
+	// the sender. The commitment to the constant term is the identity and is not sent.
+	Commitments []curves.Point
+
+	// Pedersen are the new ring-Pedersen parameters of the sender, N is its new paillier modulus.
+	Pedersen *proof.Pedersen
+
+	// PrmProof proves that the ring-Pedersen parameters are well formed.
+	PrmProof *proof.PrmProof
+
+	// Rid is the share of the sender of the random identifier and Salt the randomness of its commitment.
+	Rid, Salt [SessionIdSize]byte
+}
+
+// RefreshRound3Output is the message of the 3rd round of refresh from one party to another. It must be sent
+// privately.
+type RefreshRound3Output struct {
+	// Share is the share of the recipient of the sharing of zero of the sender.
+	Share *sharing.ShamirShare
+
+	// ModProof proves that the paillier modulus of the sender is a Paillier-Blum modulus.
+	ModProof *paillier.ModProof
+
+	// FacProof proves that the paillier modulus of the sender has no small factors, with the ring-Pedersen
+	// parameters of the recipient.
+	FacProof *paillier.FacProof
+}
+
+// NewRefresh creates a party that can participate in an auxiliary info and key refresh of config with all the
+// other parties of config. paillierKey is the new paillier key of this party, a new one is generated when it is nil,
+// which takes some time.
+func NewRefresh(curve *curves.Curve, config *Config, paillierKey *paillier.SecretKey) (*Refresh, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("curve is nil")
+	}
+	if config == nil || config.SecretKeyShare == nil || config.PublicKey == nil {
+		return nil, fmt.Errorf("config is not initialized")
+	}
+	if _, err := sortedParties(config.Id, config.Parties); err != nil {
+		return nil, err
+	}
+	for _, id := range config.Parties {
+		if config.PublicShares[id] == nil {
+			return nil, fmt.Errorf("missing public share of party %d", id)
+		}
+	}
+	if paillierKey == nil {
+		var err error
+		if _, paillierKey, err = paillier.NewKeys(); err != nil {
+			return nil, errors.Wrap(err, "generating paillier key")
+		}
+	}
+	if err := checkPaillierModulus(paillierKey.N); err != nil {
+		return nil, err
+	}
+	return &Refresh{
+		id:          config.Id,
+		curve:       curve,
+		previous:    config,
+		sessionId:   hashSession("CGGMP21 refresh", [][]byte{config.Sid[:], config.Rid[:]}, config.Parties...),
+		paillierKey: paillierKey,
+	}, nil
+}
+
+// Round1Commit samples the sharing of zero, the ring-Pedersen parameters and the rid share of this party and
+// commits to them.
+func (r *Refresh) Round1Commit() (*RefreshRound1Output, error) {
+	for _, b := range [][]byte{r.rid[:], r.salt[:]} {
+		if _, err := rand.Read(b); err != nil {
+			return nil, errors.Wrap(err, "generating random bytes in refresh round 1")
+		}
+	}
+	var err error
+	if r.pedersen, r.prmProof, err = proof.NewPedersen(r.paillierKey, subSessionId(r.sessionId, "refresh prm", r.id)); err != nil {
+		return nil, errors.Wrap(err, "generating ring-Pedersen parameters in refresh round 1")
+	}
+	r.polynomial = new(sharing.Polynomial).Init(r.curve.Scalar.Zero(), r.previous.Threshold, rand.Reader)
+	r.verifier = &sharing.FeldmanVerifier{Commitments: make([]curves.Point, r.previous.Threshold)}
+	for i, coefficient := range r.polynomial.Coefficients {
+		r.verifier.Commitments[i] = r.curve.ScalarBaseMult(coefficient)
+	}
+	return &RefreshRound1Output{Commitment: r.commitment(r.id, r.round2Output())}, nil
+}
+
+// Round2Decommit stores the commitments of all other parties and opens the commitment of this party.
+func (r *Refresh) Round2Decommit(input map[uint32]*RefreshRound1Output) (*RefreshRound2Output, error) {
+	peers := otherParties(r.id, r.previous.Parties)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return nil, err
+	}
+	if r.polynomial == nil {
+		return nil, fmt.Errorf("round 1 has not been run")
+	}
+	r.commitments = make(map[uint32][SessionIdSize]byte, len(peers))
+	for _, id := range peers {
+		r.commitments[id] = input[id].Commitment
+	}
+	return r.round2Output(), nil
+}
+
+// Round3Prove checks the openings and the ring-Pedersen parameters of all other parties, then sends every other
+// party its share of zero and the proofs that the paillier modulus of this party is well formed.
+func (r *Refresh) Round3Prove(input map[uint32]*RefreshRound2Output) (map[uint32]*RefreshRound3Output, error) {
+	peers := otherParties(r.id, r.previous.Parties)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return nil, err
+	}
+	if r.commitments == nil {
+		return nil, fmt.Errorf("round 2 has not been run")
+	}
+	rid := r.rid
+	r.verifiers = make(map[uint32]*sharing.FeldmanVerifier, len(peers))
+	for _, id := range peers {
+		message := input[id]
+		verifier := zeroVerifier(r.curve, message.Commitments)
+		if !validCommitments(verifier.Commitments, r.previous.Threshold) || message.Pedersen == nil || message.Pedersen.N == nil || message.Pedersen.S == nil ||
+			message.Pedersen.T == nil || message.PrmProof == nil {
+			return nil, fmt.Errorf("malformed message from party %d", id)
+		}
+		if r.commitment(id, message) != r.commitments[id] {
+			return nil, fmt.Errorf("party %d opened a different commitment", id)
+		}
+		if err := checkPaillierModulus(message.Pedersen.N); err != nil {
+			return nil, errors.Wrapf(err, "party %d", id)
+		}
+		if err := message.PrmProof.Verify(message.Pedersen, subSessionId(r.sessionId, "refresh prm", id)); err != nil {
+			return nil, errors.Wrapf(err, "verifying the ring-Pedersen parameters of party %d", id)
+		}
+		xorInto(&rid, message.Rid)
+		r.verifiers[id] = verifier
+	}
+	r.round2Input = input
+	r.rid = rid
+
+	modProof, err := (&paillier.ModProofParams{
+		SecretKey: r.paillierKey,
+		Sid:       r.proofSessionId("refresh mod", r.id),
+	}).Prove()
+	if err != nil {
+		return nil, errors.Wrap(err, "proving the paillier modulus in refresh round 3")
+	}
+	output := make(map[uint32]*RefreshRound3Output, len(peers))
+	for _, id := range peers {
+		pedersen := input[id].Pedersen
+		facProof, err := (&paillier.FacProofParams{
+			SecretKey: r.paillierKey,
+			NHat:      pedersen.N,
+			S:         pedersen.S,
+			T:         pedersen.T,
+			Sid:       r.proofSessionId("refresh fac", r.id, id),
+		}).Prove()
+		if err != nil {
+			return nil, errors.Wrapf(err, "proving the paillier modulus to party %d in refresh round 3", id)
+		}
+		output[id] = &RefreshRound3Output{
+			Share: &sharing.ShamirShare{
+				Id:    id,
+				Value: r.polynomial.Evaluate(r.curve.Scalar.New(int(id))).Bytes(),
+			},
+			ModProof: modProof,
+			FacProof: facProof,
+		}
+	}
+	return output, nil
+}
+
+// Round4Verify checks the shares and the paillier moduli of all other parties and computes the refreshed config.
+func (r *Refresh) Round4Verify(input map[uint32]*RefreshRound3Output) error {
+	peers := otherParties(r.id, r.previous.Parties)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return err
+	}
+	if r.round2Input == nil {
+		return fmt.Errorf("round 3 has not been run")
+	}
+	secretKeyShare := r.previous.SecretKeyShare.Add(r.polynomial.Evaluate(r.curve.Scalar.New(int(r.id))))
+	verifiers := []*sharing.FeldmanVerifier{r.verifier}
+	paillierKeys := map[uint32]*paillier.PublicKey{r.id: &r.paillierKey.PublicKey}
+	pedersen := map[uint32]*proof.Pedersen{r.id: r.pedersen}
+	for _, id := range peers {
+		message := input[id]
+		if message.Share == nil || message.ModProof == nil || message.FacProof == nil {
+			return fmt.Errorf("malformed message from party %d", id)
+		}
+		params := r.round2Input[id]
+		publicKey, err := paillier.NewPubkey(params.Pedersen.N)
+		if err != nil {
+			return errors.Wrapf(err, "reading the paillier key of party %d", id)
+		}
+		if err = message.ModProof.Verify(&paillier.ModVerifyParams{
+			PublicKey: publicKey,
+			Sid:       r.proofSessionId("refresh mod", id),
+		}); err != nil {
+			return errors.Wrapf(err, "verifying the paillier-blum modulus proof of party %d", id)
+		}
+		if err = message.FacProof.Verify(&paillier.FacVerifyParams{
+			PublicKey: publicKey,
+			NHat:      r.pedersen.N,
+			S:         r.pedersen.S,
+			T:         r.pedersen.T,
+			Sid:       r.proofSessionId("refresh fac", id, r.id),
+		}); err != nil {
+			return errors.Wrapf(err, "verifying the no small factor proof of party %d", id)
+		}
+		if message.Share.Id != r.id {
+			return fmt.Errorf("party %d sent a share for party %d", id, message.Share.Id)
+		}
+		if err = r.verifiers[id].Verify(message.Share); err != nil {
+			return errors.Wrapf(err, "verifying the share of party %d", id)
+		}
+		share, err := r.curve.Scalar.SetBytes(message.Share.Value)
+		if err != nil {
+			return errors.Wrapf(err, "reading the share of party %d", id)
+		}
+		secretKeyShare = secretKeyShare.Add(share)
+		verifiers = append(verifiers, r.verifiers[id])
+		paillierKeys[id] = publicKey
+		pedersen[id] = params.Pedersen
+	}
+
+	publicShares := make(map[uint32]curves.Point, len(r.previous.Parties))
+	for _, id := range r.previous.Parties {
+		x := r.curve.Scalar.New(int(id))
+		publicShare := r.previous.PublicShares[id]
+		for _, verifier := range verifiers {
+			publicShare = publicShare.Add(evaluateCommitments(verifier.Commitments, x))
+		}
+		publicShares[id] = publicShare
+	}
+	if !r.curve.ScalarBaseMult(secretKeyShare).Equal(publicShares[r.id]) {
+		return fmt.Errorf("inconsistent secret key share")
+	}
+	r.output = &Config{
+		Id:             r.id,
+		Threshold:      r.previous.Threshold,
+		Parties:        r.previous.Parties,
+		Sid:            r.sessionId,
+		Rid:            r.rid,
+		PublicKey:      r.previous.PublicKey,
+		PublicShares:   publicShares,
+		SecretKeyShare: secretKeyShare,
+		PaillierKey:    r.paillierKey,
+		PaillierKeys:   paillierKeys,
+		Pedersen:       pedersen,
+	}
+	return nil
+}
+
+// Output returns the refreshed config, nil before round 4 succeeded.
+func (r *Refresh) Output() *Config {
+	return r.output
+}
+
+// round2Output returns the values this party opens in round 2
+func (r *Refresh) round2Output() *RefreshRound2Output {
+	return &RefreshRound2Output{
+		Commitments: r.verifier.Commitments[1:],
+		Pedersen:    r.pedersen,
+		PrmProof:    r.prmProof,
+		Rid:         r.rid,
+		Salt:        r.salt,
+	}
+}
+
+// commitment computes the hash commitment of party id to the values it opens in round 2
+func (r *Refresh) commitment(id uint32, opening *RefreshRound2Output) [SessionIdSize]byte {
+	values := [][]byte{r.sessionId[:], opening.Rid[:], opening.Salt[:]}
+	values = append(values, intBytes(opening.Pedersen.N, opening.Pedersen.S, opening.Pedersen.T)...)
+	values = append(values, intBytes(opening.PrmProof.A...)...)
+	values = append(values, intBytes(opening.PrmProof.Z...)...)
+	values = append(values, pointBytes(opening.Commitments)...)
+	return hashSession("CGGMP21 refresh commitment", values, id)
+}
+
+// zeroVerifier returns the Feldman verifier of a sharing of zero with the given commitments of degree 1 and higher
+func zeroVerifier(curve *curves.Curve, commitments []curves.Point) *sharing.FeldmanVerifier {
+	return &sharing.FeldmanVerifier{Commitments: append([]curves.Point{curve.Point.Identity()}, commitments...)}
+}
+
+// proofSessionId binds the proofs of round 3 to the session id and the rid all parties agreed on
+func (r *Refresh) proofSessionId(label string, ids ...uint32) []byte {
+	result := hashSession(label, [][]byte{r.sessionId[:], r.rid[:]}, ids...)
+	return result[:]
+}
+
+// checkPaillierModulus checks that a paillier modulus has the size of the product of two paillier primes
+//
+// Mitigate possible attack from
+// https://eprint.iacr.org/2021/1621.pdf
+// by checking that paillier keys are the correct size
+func checkPaillierModulus(n *big.Int) error {
+	if n == nil {
+		return fmt.Errorf("paillier modulus cannot be nil")
+	}
+	expKeySize := 2 * paillier.PaillierPrimeBits
+	bitlen := n.BitLen()
+	if bitlen != expKeySize && bitlen != expKeySize-1 {
+		return fmt.Errorf("invalid paillier keys")
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+)
+
+// Presignature is the output of presigning for one signer, CGGMP21 §4.1 fig 7. It holds the values needed to sign
+// a message in one round with the same signers.
+//
+// It contains k_i and χ_i and must be kept secret. Two messages signed with the same nonce reveal the secret key,
+// so Round1Sign records the PresignatureId with the guard of the signer and refuses ids it has already seen.
+type Presignature struct {
+	// Id is the id of the signer who computed the presignature
+	Id uint32
+	// Signers are the ids of all signers, in ascending order
+	Signers   []uint32
+	PublicKey curves.Point
+	// R is the nonce point of the signature
+	R curves.Point
+	// K is the additive share of the nonce k and Chi of k·x
+	K, Chi curves.Scalar
+	// Gamma is Γ = δ·R, DeltaPoints are the Δ_j = k_j·Γ and ChiPoints the S_j = χ_j·Γ of every signer. They
+	// identify a signer whose share of the signature does not match its presignature.
+	Gamma                  curves.Point
+	DeltaPoints, ChiPoints map[uint32]curves.Point
+}
+
+// Signer encodes the state of one signer during the one round signing of CGGMP21 §4.2 fig 8.
+type Signer struct {
+	// Signature is the resulting digital signature and is the output of this protocol.
+	Signature *curves.EcdsaSignature
+
+	curve        *curves.Curve
+	hash         hash.Hash
+	presignature *Presignature
+	guard        protocol.PresignatureGuard
+
+	r           curves.Scalar
+	digestBytes []byte
+	sigma       curves.Scalar
+}
+
+// SignRound1Output is the broadcast of the round of signing.
+type SignRound1Output struct {
+	// Sigma is the share σ_i = k_i·m + r·χ_i of the signature of the sender.
+	Sigma curves.Scalar
+}
+
+// NewSigner creates a signer that can sign one message with presignature, together with the other signers of the
+// presignature. The presignature is recorded with guard when it signs.
+func NewSigner(curve *curves.Curve, hash hash.Hash, presignature *Presignature, guard protocol.PresignatureGuard) (*Signer, error) {
+	if curve == nil || hash == nil || presignature == nil {
+		return nil, fmt.Errorf("signer is not initialized")
+	}
+	if guard == nil {
+		return nil, protocol.ErrNilPresignatureGuard
+	}
+	if presignature.PublicKey == nil || presignature.R == nil || presignature.K == nil || presignature.Chi == nil ||
+		presignature.Gamma == nil {
+		return nil, fmt.Errorf("presignature is incomplete")
+	}
+	if _, err := sortedParties(presignature.Id, presignature.Signers); err != nil {
+		return nil, err
+	}
+	for _, id := range presignature.Signers {
+		if presignature.DeltaPoints[id] == nil || presignature.ChiPoints[id] == nil {
+			return nil, fmt.Errorf("presignature is incomplete")
+		}
+	}
+	return &Signer{curve: curve, hash: hash, presignature: presignature, guard: guard}, nil
+}
+
+// PresignatureId returns the id of the presignature, the hash of the signer id, the signers and R. Every copy of a
+// presignature has the same id.
+func (p *Presignature) PresignatureId() []byte {
+	h := sha256.New()
+	var id [4]byte
+	for _, signer := range append([]uint32{p.Id}, p.Signers...) {
+		binary.BigEndian.PutUint32(id[:], signer)
+		_, _ = h.Write(id[:])
+	}
+	_, _ = h.Write(p.R.ToAffineCompressed())
+	return h.Sum(nil)
+}
+
+// Round1Sign computes the share of this signer of the signature of message, once the guard of the signer has
+// recorded the presignature. The secret values of the presignature are erased.
+func (signer *Signer) Round1Sign(message []byte) (*SignRound1Output, error) {
+	p := signer.presignature
+	if p.K == nil || p.Chi == nil {
+		return nil, fmt.Errorf("presignature has already been used")
+	}
+	if err := protocol.MarkPresignatureUsed(signer.guard, protocol.Cggmp21Sign, p.PresignatureId()); err != nil {
+		return nil, err
+	}
+	if _, err := signer.hash.Write(message); err != nil {
+		return nil, errors.Wrap(err, "writing message to hash")
+	}
+	signer.digestBytes = signer.hash.Sum(nil)
+	digest, err := signer.curve.Scalar.SetBytes(signer.digestBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting digest scalar from bytes")
+	}
+	if signer.r, _, err = affineX(signer.curve, p.R); err != nil {
+		return nil, err
+	}
+	signer.sigma = p.K.Mul(digest).Add(signer.r.Mul(p.Chi))
+	p.K = nil
+	p.Chi = nil
+	return &SignRound1Output{Sigma: signer.sigma}, nil
+}
+
+// Round2Combine combines the shares of the signature of all signers and verifies the signature.
+func (signer *Signer) Round2Combine(input map[uint32]*SignRound1Output) error {
+	p := signer.presignature
+	peers := otherParties(p.Id, p.Signers)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return err
+	}
+	if signer.sigma == nil {
+		return fmt.Errorf("round 1 has not been run")
+	}
+	s := signer.sigma
+	for _, id := range peers {
+		if input[id].Sigma == nil {
+			return fmt.Errorf("malformed message from party %d", id)
+		}
+		s = s.Add(input[id].Sigma)
+	}
+	if s.IsZero() {
+		return fmt.Errorf("signature is zero")
+	}
+	_, rY, err := affineX(signer.curve, p.R)
+	if err != nil {
+		return err
+	}
+	signature := &curves.EcdsaSignature{
+		V: int(rY),
+		R: signer.r.BigInt(),
+		S: s.BigInt(),
+	}
+
+	ellipticCurve, err := signer.curve.ToEllipticCurve()
+	if err != nil {
+		return errors.Wrap(err, "invalid curve")
+	}
+	// bit(1) of the recovery id is set when the x coordinate of R was reduced by the group order
+	if new(big.Int).SetBytes(p.R.ToAffineCompressed()[1:]).Cmp(ellipticCurve.Params().N) >= 0 {
+		signature.V |= 2
+	}
+	// Normalize to low S, negating s negates R so the recovery id flips
+	halfOrder := new(big.Int).Rsh(ellipticCurve.Params().N, 1)
+	if signature.S.Cmp(halfOrder) > 0 {
+		signature.S = s.Neg().BigInt()
+		signature.V ^= 1
+	}
+
+	uncompressed := p.PublicKey.ToAffineUncompressed()
+	if len(uncompressed) != 65 {
+		return errors.New("the uncompressed form must have exactly 65 bytes")
+	}
+	publicKey := &ecdsa.PublicKey{
+		Curve: ellipticCurve,
+		X:     new(big.Int).SetBytes(uncompressed[1:33]),
+		Y:     new(big.Int).SetBytes(uncompressed[33:]),
+	}
+	if !ecdsa.Verify(publicKey, signer.digestBytes, signature.R, signature.S) {
+		return signer.identify(input)
+	}
+	signer.Signature = signature
+	return nil
+}
+
+// identify returns an error naming the first signer whose share σ_j of the signature does not satisfy
+// σ_j·Γ = m·Δ_j + r·S_j, CGGMP21 §4.2 fig 8 Output.2. When every share matches its commitments the signature is
+// valid, so one of them must fail.
+func (signer *Signer) identify(input map[uint32]*SignRound1Output) error {
+	p := signer.presignature
+	digest, err := signer.curve.Scalar.SetBytes(signer.digestBytes)
+	if err != nil {
+		return errors.Wrap(err, "setting digest scalar from bytes")
+	}
+	for _, id := range otherParties(p.Id, p.Signers) {
+		expected := p.DeltaPoints[id].Mul(digest).Add(p.ChiPoints[id].Mul(signer.r))
+		if !p.Gamma.Mul(input[id].Sigma).Equal(expected) {
+			return fmt.Errorf("party %d sent a share of the signature that does not match its presignature", id)
+		}
+	}
+	return fmt.Errorf("final signature failed to verify")
+}
+
+// affineX returns the X coordinate of point modulo the group order and the parity of its Y coordinate
+func affineX(curve *curves.Curve, point curves.Point) (curves.Scalar, byte, error) {
+	affineCompressedForm := point.ToAffineCompressed()
+	if len(affineCompressedForm) != 33 {
+		return nil, 0, errors.New("the compressed form must be exactly 33 bytes")
+	}
+	x, err := curve.Scalar.SetBigInt(new(big.Int).SetBytes(affineCompressedForm[1:]))
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "setting x scalar from big int")
+	}
+	return x, affineCompressedForm[0] & 0x1, nil
+}
@@ -0,0 +1,227 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+)
+
+// AffGProofParams contains the inputs to prove Π^aff-g: D = C^x (1+N0)^y ρ^N0 mod N0² for the ciphertext C
+// under the verifier's paillier key N0, Y = enc(y; ρy) under the prover's paillier key N1 and X = x·G, where
+// x ∈ ±2^ℓ and y ∈ ±2^ℓ'. The ring-Pedersen parameters are the verifier's.
+type AffGProofParams struct {
+	Curve *curves.Curve
+	// Pk0 is the paillier key of the verifier, Pk1 of the prover
+	Pk0, Pk1 *paillier.PublicKey
+	Pedersen *Pedersen
+	X, Y     *big.Int
+	// Rho is the nonce of D, RhoY of Y
+	Rho, RhoY *big.Int
+	C, D      *big.Int
+	// CiphertextY is Y
+	CiphertextY *big.Int
+	Point       curves.Point
+	Sid         []byte
+}
+
+// AffGVerifyParams contains the inputs to verify Π^aff-g
+type AffGVerifyParams struct {
+	Curve       *curves.Curve
+	Pk0, Pk1    *paillier.PublicKey
+	Pedersen    *Pedersen
+	C, D        *big.Int
+	CiphertextY *big.Int
+	Point       curves.Point
+	Sid         []byte
+}
+
+// AffGProof proves that a paillier ciphertext is an affine operation on another ciphertext, with the
+// multiplicative coefficient the discrete logarithm of a point and the additive coefficient encrypted by the
+// prover, CGGMP21 §C.3 Π^aff-g
+type AffGProof struct {
+	A, By, E, S, F, T *big.Int
+	Bx                curves.Point
+	Z1, Z2, Z3, Z4    *big.Int
+	W, Wy             *big.Int
+}
+
+// Prove generates an AffGProof as specified in CGGMP21 §C.3 Π^aff-g
+func (p *AffGProofParams) Prove() (*AffGProof, error) {
+	if p.Curve == nil || p.Point == nil || core.AnyNil(p.X, p.Y, p.Rho, p.RhoY, p.C, p.D, p.CiphertextY) {
+		return nil, fmt.Errorf("invalid params")
+	}
+	if err := validatePublicKey(p.Pk0); err != nil {
+		return nil, err
+	}
+	if err := validatePublicKey(p.Pk1); err != nil {
+		return nil, err
+	}
+	if err := p.Pedersen.validate(); err != nil {
+		return nil, err
+	}
+	nHat := p.Pedersen.N
+
+	// 1. α <- ±2^{ℓ+ε}, β <- ±2^{ℓ'+ε}, r <- Z_N0*, ry <- Z_N1*,
+	// γ, δ <- ±2^{ℓ+ε}·N^, m, μ <- ±2^ℓ·N^
+	values := make([]*big.Int, 6)
+	for i, bound := range []struct {
+		bits  uint
+		scale *big.Int
+	}{
+		{Ell + Epsilon, core.One},
+		{EllPrime + Epsilon, core.One},
+		{Ell + Epsilon, nHat},
+		{Ell + Epsilon, nHat},
+		{Ell, nHat},
+		{Ell, nHat},
+	} {
+		var err error
+		if values[i], err = randSigned(bound.bits, bound.scale); err != nil {
+			return nil, err
+		}
+	}
+	alpha, beta, gamma, delta, m, mu := values[0], values[1], values[2], values[3], values[4], values[5]
+	r, err := randUnit(p.Pk0.N)
+	if err != nil {
+		return nil, err
+	}
+	ry, err := randUnit(p.Pk1.N)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. A = C^α (1+N0)^β r^N0 mod N0², Bx = α·G, By = (1+N1)^β ry^N1 mod N1²,
+	// E = s^α t^γ, S = s^x t^m, F = s^β t^δ, T = s^y t^μ mod N^
+	cAlpha, err := expSigned(p.C, alpha, p.Pk0.N2)
+	if err != nil {
+		return nil, err
+	}
+	alphaScalar, err := ScalarFromInt(p.Curve, alpha)
+	if err != nil {
+		return nil, err
+	}
+	proof := &AffGProof{
+		A:  cAlpha.Mul(cAlpha, encrypt(p.Pk0, beta, r)).Mod(cAlpha, p.Pk0.N2),
+		Bx: p.Curve.ScalarBaseMult(alphaScalar),
+		By: encrypt(p.Pk1, beta, ry),
+	}
+	for _, commitment := range []struct {
+		result *(*big.Int)
+		x, y   *big.Int
+	}{
+		{&proof.E, alpha, gamma},
+		{&proof.S, p.X, m},
+		{&proof.F, beta, delta},
+		{&proof.T, p.Y, mu},
+	} {
+		if *commitment.result, err = p.Pedersen.Commit(commitment.x, commitment.y); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. e <- FS-HASH(N0, N1, N^, s, t, C, D, Y, X, A, Bx, By, E, S, F, T, sid)
+	e, err := affGChallenge(p.Curve, p.Pk0, p.Pk1, p.Pedersen, p.C, p.D, p.CiphertextY, p.Point, proof, p.Sid)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. z1 = α + ex, z2 = β + ey, z3 = γ + em, z4 = δ + eμ, w = rρ^e mod N0, wy = ry ρy^e mod N1
+	proof.Z1 = new(big.Int).Add(alpha, new(big.Int).Mul(e, p.X))
+	proof.Z2 = new(big.Int).Add(beta, new(big.Int).Mul(e, p.Y))
+	proof.Z3 = new(big.Int).Add(gamma, new(big.Int).Mul(e, m))
+	proof.Z4 = new(big.Int).Add(delta, new(big.Int).Mul(e, mu))
+	proof.W = mulExp(r, p.Rho, e, p.Pk0.N)
+	proof.Wy = mulExp(ry, p.RhoY, e, p.Pk1.N)
+	return proof, nil
+}
+
+// Verify checks the AffGProof as specified in CGGMP21 §C.3 Π^aff-g
+func (p AffGProof) Verify(vp *AffGVerifyParams) error {
+	if vp == nil || vp.Curve == nil || vp.Point == nil || core.AnyNil(vp.C, vp.D, vp.CiphertextY) {
+		return fmt.Errorf("proof verify params cannot be nil")
+	}
+	if p.Bx == nil || core.AnyNil(p.A, p.By, p.E, p.S, p.F, p.T, p.Z1, p.Z2, p.Z3, p.Z4, p.W, p.Wy) {
+		return fmt.Errorf("proof values cannot be nil")
+	}
+	if err := validatePublicKey(vp.Pk0); err != nil {
+		return err
+	}
+	if err := validatePublicKey(vp.Pk1); err != nil {
+		return err
+	}
+	if err := vp.Pedersen.validate(); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pk0.N2, vp.C, vp.D, p.A); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pk1.N2, vp.CiphertextY, p.By); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pedersen.N, p.E, p.S, p.F, p.T); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pk0.N, p.W); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pk1.N, p.Wy); err != nil {
+		return err
+	}
+
+	// z1 ∈ ±2^{ℓ+ε}, z2 ∈ ±2^{ℓ'+ε}
+	if !inRange(p.Z1, Ell+Epsilon) {
+		return fmt.Errorf("z1 is out of range")
+	}
+	if !inRange(p.Z2, EllPrime+Epsilon) {
+		return fmt.Errorf("z2 is out of range")
+	}
+	e, err := affGChallenge(vp.Curve, vp.Pk0, vp.Pk1, vp.Pedersen, vp.C, vp.D, vp.CiphertextY, vp.Point, &p, vp.Sid)
+	if err != nil {
+		return err
+	}
+
+	// C^z1 (1+N0)^z2 w^N0 = A D^e mod N0²
+	lhs, err := expSigned(vp.C, p.Z1, vp.Pk0.N2)
+	if err != nil {
+		return err
+	}
+	lhs.Mul(lhs, encrypt(vp.Pk0, p.Z2, p.W)).Mod(lhs, vp.Pk0.N2)
+	if lhs.Cmp(mulExp(p.A, vp.D, e, vp.Pk0.N2)) != 0 {
+		return fmt.Errorf("invalid affine operation")
+	}
+	// z1·G = Bx + e·X
+	z1, err := ScalarFromInt(vp.Curve, p.Z1)
+	if err != nil {
+		return err
+	}
+	eScalar, err := ScalarFromInt(vp.Curve, e)
+	if err != nil {
+		return err
+	}
+	if !vp.Curve.ScalarBaseMult(z1).Equal(p.Bx.Add(vp.Point.Mul(eScalar))) {
+		return fmt.Errorf("invalid discrete logarithm")
+	}
+	// (1+N1)^z2 wy^N1 = By Y^e mod N1²
+	if encrypt(vp.Pk1, p.Z2, p.Wy).Cmp(mulExp(p.By, vp.CiphertextY, e, vp.Pk1.N2)) != 0 {
+		return fmt.Errorf("invalid encryption")
+	}
+	// s^z1 t^z3 = E S^e, s^z2 t^z4 = F T^e mod N^
+	if err := vp.Pedersen.verify(p.Z1, p.Z3, p.E, p.S, e); err != nil {
+		return err
+	}
+	return vp.Pedersen.verify(p.Z2, p.Z4, p.F, p.T, e)
+}
+
+func affGChallenge(curve *curves.Curve, pk0, pk1 *paillier.PublicKey, pedersen *Pedersen, c, d, y *big.Int, point curves.Point, proof *AffGProof, sid []byte) (*big.Int, error) {
+	return challenge(curve, sid, pk0.N, pk1.N, pedersen.N, pedersen.S, pedersen.T, c, d, y, pointInt(point),
+		proof.A, pointInt(proof.Bx), proof.By, proof.E, proof.S, proof.F, proof.T)
+}
@@ -0,0 +1,100 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+func TestAffGProof(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		// The verifier owns sk0 and the pedersen parameters, the prover sk1
+		sk0 := testPaillierKey(t, 0)
+		sk1 := testPaillierKey(t, 2)
+		pedersen := testPedersen(t, 4)
+		sid := []byte("aff-g proof test")
+
+		k := curve.Scalar.Random(crand.Reader).BigInt()
+		c, _, err := sk0.Encrypt(k)
+		require.NoError(t, err)
+		x := curve.Scalar.Random(crand.Reader)
+		y, err := randSigned(EllPrime, big.NewInt(1))
+		require.NoError(t, err)
+
+		// D = C^x (1+N0)^y ρ^N0, Y = enc1(y; ρy)
+		rho, err := randUnit(sk0.N)
+		require.NoError(t, err)
+		rhoY, err := randUnit(sk1.N)
+		require.NoError(t, err)
+		d := new(big.Int).Exp(c, x.BigInt(), sk0.N2)
+		d.Mul(d, encrypt(&sk0.PublicKey, y, rho)).Mod(d, sk0.N2)
+		ciphertextY := encrypt(&sk1.PublicKey, y, rhoY)
+
+		proof, err := (&AffGProofParams{
+			Curve:       curve,
+			Pk0:         &sk0.PublicKey,
+			Pk1:         &sk1.PublicKey,
+			Pedersen:    pedersen,
+			X:           x.BigInt(),
+			Y:           y,
+			Rho:         rho,
+			RhoY:        rhoY,
+			C:           c,
+			D:           d,
+			CiphertextY: ciphertextY,
+			Point:       curve.ScalarBaseMult(x),
+			Sid:         sid,
+		}).Prove()
+		require.NoError(t, err)
+		vp := &AffGVerifyParams{
+			Curve:       curve,
+			Pk0:         &sk0.PublicKey,
+			Pk1:         &sk1.PublicKey,
+			Pedersen:    pedersen,
+			C:           c,
+			D:           d,
+			CiphertextY: ciphertextY,
+			Point:       curve.ScalarBaseMult(x),
+			Sid:         sid,
+		}
+		require.NoError(t, proof.Verify(vp))
+
+		// The verifier decrypts kx + y
+		alpha, err := sk0.Decrypt(d)
+		require.NoError(t, err)
+		expected := new(big.Int).Mul(k, x.BigInt())
+		expected.Add(expected, y).Mod(expected, sk0.N)
+		require.Equal(t, expected, alpha)
+
+		// The proof is bound to the point and the ciphertexts
+		wrong := *vp
+		wrong.Point = curve.ScalarBaseMult(x.Add(curve.Scalar.One()))
+		require.Error(t, proof.Verify(&wrong))
+		wrong = *vp
+		wrong.D = new(big.Int).Mod(new(big.Int).Mul(d, c), sk0.N2)
+		require.Error(t, proof.Verify(&wrong))
+		wrong = *vp
+		wrong.Sid = nil
+		require.Error(t, proof.Verify(&wrong))
+
+		// Tampered proofs are rejected
+		tampered := *proof
+		tampered.Z2 = new(big.Int).Add(tampered.Z2, big.NewInt(1))
+		require.Error(t, tampered.Verify(vp))
+		tampered = *proof
+		tampered.Wy = new(big.Int).Add(tampered.Wy, big.NewInt(1))
+		require.Error(t, tampered.Verify(vp))
+		require.Error(t, AffGProof{}.Verify(vp))
+		require.Error(t, proof.Verify(nil))
+	}
+}
@@ -0,0 +1,168 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+)
+
+// DecProofParams contains the inputs to prove Π^dec: C = (1+N0)^y ρ^N0 mod N0² under the prover's paillier key,
+// where y is the signed plaintext of C, with the ring-Pedersen parameters of the verifier. The public value of the
+// proof is x = y mod q.
+type DecProofParams struct {
+	Curve    *curves.Curve
+	Pk       *paillier.PublicKey
+	Pedersen *Pedersen
+	Y        *big.Int
+	Rho      *big.Int
+	// Ciphertext is C
+	Ciphertext *big.Int
+	Sid        []byte
+}
+
+// DecVerifyParams contains the inputs to verify Π^dec
+type DecVerifyParams struct {
+	Curve      *curves.Curve
+	Pk         *paillier.PublicKey
+	Pedersen   *Pedersen
+	Ciphertext *big.Int
+	// X is the plaintext of the ciphertext modulo q
+	X   curves.Scalar
+	Sid []byte
+}
+
+// DecProof proves that a paillier ciphertext decrypts to a value congruent to a given scalar modulo the order of
+// the curve, CGGMP21 §C.6 Π^dec
+type DecProof struct {
+	S, T, A *big.Int
+	// Gamma is α mod q
+	Gamma     *big.Int
+	Z1, Z2, W *big.Int
+}
+
+// Prove generates a DecProof as specified in CGGMP21 §C.6 Π^dec. The plaintexts decrypted in the identification
+// of CGGMP21 are sums of products and masks larger than 2^ℓ, so α is scaled by N0 rather than bounded by 2^{ℓ+ε}.
+func (p *DecProofParams) Prove() (*DecProof, error) {
+	if p.Curve == nil || core.AnyNil(p.Y, p.Rho, p.Ciphertext) {
+		return nil, fmt.Errorf("invalid params")
+	}
+	if err := validatePublicKey(p.Pk); err != nil {
+		return nil, err
+	}
+	if err := p.Pedersen.validate(); err != nil {
+		return nil, err
+	}
+	q, err := curveOrder(p.Curve)
+	if err != nil {
+		return nil, err
+	}
+	x, err := ScalarFromInt(p.Curve, p.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. α <- ±2^{ℓ+ε}·N0, μ <- ±2^ℓ·N^, ν <- ±2^{ℓ+ε}·N^, r <- Z_N0*
+	alpha, err := randSigned(Ell+Epsilon, p.Pk.N)
+	if err != nil {
+		return nil, err
+	}
+	mu, err := randSigned(Ell, p.Pedersen.N)
+	if err != nil {
+		return nil, err
+	}
+	nu, err := randSigned(Ell+Epsilon, p.Pedersen.N)
+	if err != nil {
+		return nil, err
+	}
+	r, err := randUnit(p.Pk.N)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. S = s^y t^μ, T = s^α t^ν mod N^, A = (1+N0)^α r^N0 mod N0², γ = α mod q
+	proof := &DecProof{
+		A:     encrypt(p.Pk, alpha, r),
+		Gamma: new(big.Int).Mod(alpha, q),
+	}
+	if proof.S, err = p.Pedersen.Commit(p.Y, mu); err != nil {
+		return nil, err
+	}
+	if proof.T, err = p.Pedersen.Commit(alpha, nu); err != nil {
+		return nil, err
+	}
+
+	// 3. e <- FS-HASH(N0, N^, s, t, C, x, S, T, A, γ, sid)
+	e, err := decChallenge(p.Curve, p.Pk, p.Pedersen, p.Ciphertext, x, proof, p.Sid)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. z1 = α + ey, z2 = ν + eμ, w = rρ^e mod N0
+	proof.Z1 = new(big.Int).Add(alpha, new(big.Int).Mul(e, p.Y))
+	proof.Z2 = new(big.Int).Add(nu, new(big.Int).Mul(e, mu))
+	proof.W = mulExp(r, p.Rho, e, p.Pk.N)
+	return proof, nil
+}
+
+// Verify checks the DecProof as specified in CGGMP21 §C.6 Π^dec
+func (p DecProof) Verify(vp *DecVerifyParams) error {
+	if vp == nil || vp.Curve == nil || vp.Ciphertext == nil || vp.X == nil {
+		return fmt.Errorf("proof verify params cannot be nil")
+	}
+	if core.AnyNil(p.S, p.T, p.A, p.Gamma, p.Z1, p.Z2, p.W) {
+		return fmt.Errorf("proof values cannot be nil")
+	}
+	if err := validatePublicKey(vp.Pk); err != nil {
+		return err
+	}
+	if err := vp.Pedersen.validate(); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pk.N2, vp.Ciphertext, p.A); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pedersen.N, p.S, p.T); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pk.N, p.W); err != nil {
+		return err
+	}
+	q, err := curveOrder(vp.Curve)
+	if err != nil {
+		return err
+	}
+	if err = core.In(p.Gamma, q); err != nil {
+		return err
+	}
+	e, err := decChallenge(vp.Curve, vp.Pk, vp.Pedersen, vp.Ciphertext, vp.X, &p, vp.Sid)
+	if err != nil {
+		return err
+	}
+
+	// (1+N0)^z1 w^N0 = A C^e mod N0²
+	if encrypt(vp.Pk, p.Z1, p.W).Cmp(mulExp(p.A, vp.Ciphertext, e, vp.Pk.N2)) != 0 {
+		return fmt.Errorf("invalid encryption")
+	}
+	// z1 = γ + ex mod q
+	rhs := new(big.Int).Mul(e, vp.X.BigInt())
+	rhs.Add(rhs, p.Gamma).Mod(rhs, q)
+	if new(big.Int).Mod(p.Z1, q).Cmp(rhs) != 0 {
+		return fmt.Errorf("invalid plaintext")
+	}
+	// s^z1 t^z2 = T S^e mod N^
+	return vp.Pedersen.verify(p.Z1, p.Z2, p.T, p.S, e)
+}
+
+func decChallenge(curve *curves.Curve, pk *paillier.PublicKey, pedersen *Pedersen, ciphertext *big.Int, x curves.Scalar, proof *DecProof, sid []byte) (*big.Int, error) {
+	return challenge(curve, sid, pk.N, pedersen.N, pedersen.S, pedersen.T, ciphertext, x.BigInt(),
+		proof.S, proof.T, proof.A, proof.Gamma)
+}
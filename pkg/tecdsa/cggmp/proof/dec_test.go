@@ -0,0 +1,90 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+func TestDecProof(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		sk := testPaillierKey(t, 0)
+		pedersen := testPedersen(t, 2)
+		sid := []byte("dec proof test")
+
+		// A negative plaintext larger than the curve order, as the sums of the identification
+		y, err := randSigned(EllPrime, big.NewInt(1))
+		require.NoError(t, err)
+		y.Neg(y.Abs(y))
+		rho, err := randUnit(sk.N)
+		require.NoError(t, err)
+		ciphertext := encrypt(&sk.PublicKey, y, rho)
+		x, err := ScalarFromInt(curve, y)
+		require.NoError(t, err)
+
+		proof, err := (&DecProofParams{
+			Curve:      curve,
+			Pk:         &sk.PublicKey,
+			Pedersen:   pedersen,
+			Y:          y,
+			Rho:        rho,
+			Ciphertext: ciphertext,
+			Sid:        sid,
+		}).Prove()
+		require.NoError(t, err)
+		vp := &DecVerifyParams{
+			Curve:      curve,
+			Pk:         &sk.PublicKey,
+			Pedersen:   pedersen,
+			Ciphertext: ciphertext,
+			X:          x,
+			Sid:        sid,
+		}
+		require.NoError(t, proof.Verify(vp))
+
+		// The proof is bound to the plaintext, the ciphertext and the session
+		wrong := *vp
+		wrong.X = x.Add(curve.Scalar.One())
+		require.Error(t, proof.Verify(&wrong))
+		wrong = *vp
+		wrong.Ciphertext = encrypt(&sk.PublicKey, new(big.Int).Add(y, big.NewInt(1)), rho)
+		require.Error(t, proof.Verify(&wrong))
+		wrong = *vp
+		wrong.Sid = nil
+		require.Error(t, proof.Verify(&wrong))
+
+		// A wrong plaintext cannot be proven
+		lie, err := (&DecProofParams{
+			Curve:      curve,
+			Pk:         &sk.PublicKey,
+			Pedersen:   pedersen,
+			Y:          new(big.Int).Add(y, big.NewInt(1)),
+			Rho:        rho,
+			Ciphertext: ciphertext,
+			Sid:        sid,
+		}).Prove()
+		require.NoError(t, err)
+		wrong = *vp
+		wrong.X = x.Add(curve.Scalar.One())
+		require.Error(t, lie.Verify(&wrong))
+
+		// Tampered proofs are rejected
+		tampered := *proof
+		tampered.Z1 = new(big.Int).Add(tampered.Z1, big.NewInt(1))
+		require.Error(t, tampered.Verify(vp))
+		tampered = *proof
+		tampered.Gamma = new(big.Int).Neg(tampered.Gamma)
+		require.Error(t, tampered.Verify(vp))
+		require.Error(t, DecProof{}.Verify(vp))
+		require.Error(t, proof.Verify(nil))
+	}
+}
@@ -0,0 +1,141 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+)
+
+// EncProofParams contains the inputs to prove Π^enc: K = enc(k; ρ) under the prover's paillier key and
+// k ∈ ±2^ℓ, with the ring-Pedersen parameters of the verifier
+type EncProofParams struct {
+	Curve    *curves.Curve
+	Pk       *paillier.PublicKey
+	Pedersen *Pedersen
+	K        *big.Int
+	Rho      *big.Int
+	// Ciphertext is K
+	Ciphertext *big.Int
+	Sid        []byte
+}
+
+// EncVerifyParams contains the inputs to verify Π^enc
+type EncVerifyParams struct {
+	Curve      *curves.Curve
+	Pk         *paillier.PublicKey
+	Pedersen   *Pedersen
+	Ciphertext *big.Int
+	Sid        []byte
+}
+
+// EncProof proves that a paillier ciphertext encrypts a value in range, CGGMP21 §C.1 Π^enc
+type EncProof struct {
+	S, A, C    *big.Int
+	Z1, Z2, Z3 *big.Int
+}
+
+// Prove generates an EncProof as specified in CGGMP21 §C.1 Π^enc
+func (p *EncProofParams) Prove() (*EncProof, error) {
+	if p.Curve == nil || core.AnyNil(p.K, p.Rho, p.Ciphertext) {
+		return nil, fmt.Errorf("invalid params")
+	}
+	if err := validatePublicKey(p.Pk); err != nil {
+		return nil, err
+	}
+	if err := p.Pedersen.validate(); err != nil {
+		return nil, err
+	}
+
+	// 1. α <- ±2^{ℓ+ε}, μ <- ±2^ℓ·N^, r <- Z_N0*, γ <- ±2^{ℓ+ε}·N^
+	alpha, err := randSigned(Ell+Epsilon, core.One)
+	if err != nil {
+		return nil, err
+	}
+	mu, err := randSigned(Ell, p.Pedersen.N)
+	if err != nil {
+		return nil, err
+	}
+	r, err := randUnit(p.Pk.N)
+	if err != nil {
+		return nil, err
+	}
+	gamma, err := randSigned(Ell+Epsilon, p.Pedersen.N)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. S = s^k t^μ, A = (1+N0)^α r^N0, C = s^α t^γ
+	proof := &EncProof{A: encrypt(p.Pk, alpha, r)}
+	if proof.S, err = p.Pedersen.Commit(p.K, mu); err != nil {
+		return nil, err
+	}
+	if proof.C, err = p.Pedersen.Commit(alpha, gamma); err != nil {
+		return nil, err
+	}
+
+	// 3. e <- FS-HASH(N0, N^, s, t, K, S, A, C, sid)
+	e, err := encChallenge(p.Curve, p.Pk, p.Pedersen, p.Ciphertext, proof, p.Sid)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. z1 = α + ek, z2 = rρ^e mod N0, z3 = γ + eμ
+	proof.Z1 = new(big.Int).Add(alpha, new(big.Int).Mul(e, p.K))
+	proof.Z2 = mulExp(r, p.Rho, e, p.Pk.N)
+	proof.Z3 = new(big.Int).Add(gamma, new(big.Int).Mul(e, mu))
+	return proof, nil
+}
+
+// Verify checks the EncProof as specified in CGGMP21 §C.1 Π^enc
+func (p EncProof) Verify(vp *EncVerifyParams) error {
+	if vp == nil || vp.Curve == nil || vp.Ciphertext == nil {
+		return fmt.Errorf("proof verify params cannot be nil")
+	}
+	if core.AnyNil(p.S, p.A, p.C, p.Z1, p.Z2, p.Z3) {
+		return fmt.Errorf("proof values cannot be nil")
+	}
+	if err := validatePublicKey(vp.Pk); err != nil {
+		return err
+	}
+	if err := vp.Pedersen.validate(); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pk.N2, vp.Ciphertext, p.A); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pedersen.N, p.S, p.C); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pk.N, p.Z2); err != nil {
+		return err
+	}
+
+	// z1 ∈ ±2^{ℓ+ε}
+	if !inRange(p.Z1, Ell+Epsilon) {
+		return fmt.Errorf("z1 is out of range")
+	}
+	e, err := encChallenge(vp.Curve, vp.Pk, vp.Pedersen, vp.Ciphertext, &p, vp.Sid)
+	if err != nil {
+		return err
+	}
+
+	// (1+N0)^z1 z2^N0 = A K^e mod N0²
+	if encrypt(vp.Pk, p.Z1, p.Z2).Cmp(mulExp(p.A, vp.Ciphertext, e, vp.Pk.N2)) != 0 {
+		return fmt.Errorf("invalid encryption")
+	}
+	// s^z1 t^z3 = C S^e mod N^
+	return vp.Pedersen.verify(p.Z1, p.Z3, p.C, p.S, e)
+}
+
+func encChallenge(curve *curves.Curve, pk *paillier.PublicKey, pedersen *Pedersen, ciphertext *big.Int, proof *EncProof, sid []byte) (*big.Int, error) {
+	return challenge(curve, sid, pk.N, pedersen.N, pedersen.S, pedersen.T, ciphertext, proof.S, proof.A, proof.C)
+}
@@ -0,0 +1,76 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+func TestEncProof(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		sk := testPaillierKey(t, 0)
+		pedersen := testPedersen(t, 2)
+		sid := []byte("enc proof test")
+		k := curve.Scalar.Random(crand.Reader).BigInt()
+		ciphertext, rho, err := sk.Encrypt(k)
+		require.NoError(t, err)
+
+		proof, err := (&EncProofParams{
+			Curve:      curve,
+			Pk:         &sk.PublicKey,
+			Pedersen:   pedersen,
+			K:          k,
+			Rho:        rho,
+			Ciphertext: ciphertext,
+			Sid:        sid,
+		}).Prove()
+		require.NoError(t, err)
+		vp := &EncVerifyParams{
+			Curve:      curve,
+			Pk:         &sk.PublicKey,
+			Pedersen:   pedersen,
+			Ciphertext: ciphertext,
+			Sid:        sid,
+		}
+		require.NoError(t, proof.Verify(vp))
+
+		// The proof is bound to the session and the ciphertext
+		require.Error(t, proof.Verify(&EncVerifyParams{Curve: curve, Pk: &sk.PublicKey, Pedersen: pedersen, Ciphertext: ciphertext, Sid: []byte("other")}))
+		other, _, err := sk.Encrypt(k)
+		require.NoError(t, err)
+		require.Error(t, proof.Verify(&EncVerifyParams{Curve: curve, Pk: &sk.PublicKey, Pedersen: pedersen, Ciphertext: other, Sid: sid}))
+
+		// A value out of range cannot be proven
+		large := new(big.Int).Lsh(big.NewInt(1), Ell+Epsilon+1)
+		largeCiphertext, largeRho, err := sk.Encrypt(large)
+		require.NoError(t, err)
+		largeProof, err := (&EncProofParams{
+			Curve:      curve,
+			Pk:         &sk.PublicKey,
+			Pedersen:   pedersen,
+			K:          large,
+			Rho:        largeRho,
+			Ciphertext: largeCiphertext,
+			Sid:        sid,
+		}).Prove()
+		require.NoError(t, err)
+		require.Error(t, largeProof.Verify(&EncVerifyParams{Curve: curve, Pk: &sk.PublicKey, Pedersen: pedersen, Ciphertext: largeCiphertext, Sid: sid}))
+
+		// Tampered proofs are rejected
+		tampered := *proof
+		tampered.Z3 = new(big.Int).Add(tampered.Z3, big.NewInt(1))
+		require.Error(t, tampered.Verify(vp))
+		require.Error(t, EncProof{}.Verify(vp))
+		require.Error(t, proof.Verify(nil))
+	}
+}
@@ -0,0 +1,176 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+)
+
+// LogStarProofParams contains the inputs to prove Π^log*: C = enc(x; ρ) under the prover's paillier key,
+// X = x·G for the base point G and x ∈ ±2^ℓ, with the ring-Pedersen parameters of the verifier
+type LogStarProofParams struct {
+	Curve    *curves.Curve
+	Pk       *paillier.PublicKey
+	Pedersen *Pedersen
+	X        *big.Int
+	Rho      *big.Int
+	// Ciphertext is C
+	Ciphertext *big.Int
+	// Base is G, the generator of the curve when nil
+	Base  curves.Point
+	Point curves.Point
+	Sid   []byte
+}
+
+// LogStarVerifyParams contains the inputs to verify Π^log*
+type LogStarVerifyParams struct {
+	Curve      *curves.Curve
+	Pk         *paillier.PublicKey
+	Pedersen   *Pedersen
+	Ciphertext *big.Int
+	Base       curves.Point
+	Point      curves.Point
+	Sid        []byte
+}
+
+// LogStarProof proves that a paillier ciphertext encrypts the discrete logarithm of a point, and that it is in
+// range, CGGMP21 §C.2 Π^log*
+type LogStarProof struct {
+	S, A, D    *big.Int
+	Y          curves.Point
+	Z1, Z2, Z3 *big.Int
+}
+
+// Prove generates a LogStarProof as specified in CGGMP21 §C.2 Π^log*
+func (p *LogStarProofParams) Prove() (*LogStarProof, error) {
+	if p.Curve == nil || p.Point == nil || core.AnyNil(p.X, p.Rho, p.Ciphertext) {
+		return nil, fmt.Errorf("invalid params")
+	}
+	if err := validatePublicKey(p.Pk); err != nil {
+		return nil, err
+	}
+	if err := p.Pedersen.validate(); err != nil {
+		return nil, err
+	}
+	base := p.Base
+	if base == nil {
+		base = p.Curve.NewGeneratorPoint()
+	}
+
+	// 1. α <- ±2^{ℓ+ε}, μ <- ±2^ℓ·N^, r <- Z_N0*, γ <- ±2^{ℓ+ε}·N^
+	alpha, err := randSigned(Ell+Epsilon, core.One)
+	if err != nil {
+		return nil, err
+	}
+	mu, err := randSigned(Ell, p.Pedersen.N)
+	if err != nil {
+		return nil, err
+	}
+	r, err := randUnit(p.Pk.N)
+	if err != nil {
+		return nil, err
+	}
+	gamma, err := randSigned(Ell+Epsilon, p.Pedersen.N)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. S = s^x t^μ, A = (1+N0)^α r^N0, Y = α·G, D = s^α t^γ
+	alphaScalar, err := ScalarFromInt(p.Curve, alpha)
+	if err != nil {
+		return nil, err
+	}
+	proof := &LogStarProof{
+		A: encrypt(p.Pk, alpha, r),
+		Y: base.Mul(alphaScalar),
+	}
+	if proof.S, err = p.Pedersen.Commit(p.X, mu); err != nil {
+		return nil, err
+	}
+	if proof.D, err = p.Pedersen.Commit(alpha, gamma); err != nil {
+		return nil, err
+	}
+
+	// 3. e <- FS-HASH(N0, N^, s, t, C, G, X, S, A, Y, D, sid)
+	e, err := logStarChallenge(p.Curve, p.Pk, p.Pedersen, p.Ciphertext, base, p.Point, proof, p.Sid)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. z1 = α + ex, z2 = rρ^e mod N0, z3 = γ + eμ
+	proof.Z1 = new(big.Int).Add(alpha, new(big.Int).Mul(e, p.X))
+	proof.Z2 = mulExp(r, p.Rho, e, p.Pk.N)
+	proof.Z3 = new(big.Int).Add(gamma, new(big.Int).Mul(e, mu))
+	return proof, nil
+}
+
+// Verify checks the LogStarProof as specified in CGGMP21 §C.2 Π^log*
+func (p LogStarProof) Verify(vp *LogStarVerifyParams) error {
+	if vp == nil || vp.Curve == nil || vp.Ciphertext == nil || vp.Point == nil {
+		return fmt.Errorf("proof verify params cannot be nil")
+	}
+	if p.Y == nil || core.AnyNil(p.S, p.A, p.D, p.Z1, p.Z2, p.Z3) {
+		return fmt.Errorf("proof values cannot be nil")
+	}
+	if err := validatePublicKey(vp.Pk); err != nil {
+		return err
+	}
+	if err := vp.Pedersen.validate(); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pk.N2, vp.Ciphertext, p.A); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pedersen.N, p.S, p.D); err != nil {
+		return err
+	}
+	if err := inUnits(vp.Pk.N, p.Z2); err != nil {
+		return err
+	}
+	base := vp.Base
+	if base == nil {
+		base = vp.Curve.NewGeneratorPoint()
+	}
+
+	// z1 ∈ ±2^{ℓ+ε}
+	if !inRange(p.Z1, Ell+Epsilon) {
+		return fmt.Errorf("z1 is out of range")
+	}
+	e, err := logStarChallenge(vp.Curve, vp.Pk, vp.Pedersen, vp.Ciphertext, base, vp.Point, &p, vp.Sid)
+	if err != nil {
+		return err
+	}
+
+	// (1+N0)^z1 z2^N0 = A C^e mod N0²
+	if encrypt(vp.Pk, p.Z1, p.Z2).Cmp(mulExp(p.A, vp.Ciphertext, e, vp.Pk.N2)) != 0 {
+		return fmt.Errorf("invalid encryption")
+	}
+	// z1·G = Y + e·X
+	z1, err := ScalarFromInt(vp.Curve, p.Z1)
+	if err != nil {
+		return err
+	}
+	eScalar, err := ScalarFromInt(vp.Curve, e)
+	if err != nil {
+		return err
+	}
+	if !base.Mul(z1).Equal(p.Y.Add(vp.Point.Mul(eScalar))) {
+		return fmt.Errorf("invalid discrete logarithm")
+	}
+	// s^z1 t^z3 = D S^e mod N^
+	return vp.Pedersen.verify(p.Z1, p.Z3, p.D, p.S, e)
+}
+
+func logStarChallenge(curve *curves.Curve, pk *paillier.PublicKey, pedersen *Pedersen, ciphertext *big.Int, base, point curves.Point, proof *LogStarProof, sid []byte) (*big.Int, error) {
+	return challenge(curve, sid, pk.N, pedersen.N, pedersen.S, pedersen.T, ciphertext, pointInt(base), pointInt(point),
+		proof.S, proof.A, pointInt(proof.Y), proof.D)
+}
@@ -0,0 +1,103 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+func TestLogStarProof(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		sk := testPaillierKey(t, 0)
+		pedersen := testPedersen(t, 2)
+		sid := []byte("log* proof test")
+		x := curve.Scalar.Random(crand.Reader)
+		ciphertext, rho, err := sk.Encrypt(x.BigInt())
+		require.NoError(t, err)
+		base := curve.Point.Random(crand.Reader)
+		point := base.Mul(x)
+
+		proof, err := (&LogStarProofParams{
+			Curve:      curve,
+			Pk:         &sk.PublicKey,
+			Pedersen:   pedersen,
+			X:          x.BigInt(),
+			Rho:        rho,
+			Ciphertext: ciphertext,
+			Base:       base,
+			Point:      point,
+			Sid:        sid,
+		}).Prove()
+		require.NoError(t, err)
+		vp := &LogStarVerifyParams{
+			Curve:      curve,
+			Pk:         &sk.PublicKey,
+			Pedersen:   pedersen,
+			Ciphertext: ciphertext,
+			Base:       base,
+			Point:      point,
+			Sid:        sid,
+		}
+		require.NoError(t, proof.Verify(vp))
+
+		// The generator is the default base
+		generatorProof, err := (&LogStarProofParams{
+			Curve:      curve,
+			Pk:         &sk.PublicKey,
+			Pedersen:   pedersen,
+			X:          x.BigInt(),
+			Rho:        rho,
+			Ciphertext: ciphertext,
+			Point:      curve.ScalarBaseMult(x),
+			Sid:        sid,
+		}).Prove()
+		require.NoError(t, err)
+		require.NoError(t, generatorProof.Verify(&LogStarVerifyParams{
+			Curve:      curve,
+			Pk:         &sk.PublicKey,
+			Pedersen:   pedersen,
+			Ciphertext: ciphertext,
+			Point:      curve.ScalarBaseMult(x),
+			Sid:        sid,
+		}))
+
+		// The proof is bound to the point and the base
+		require.Error(t, proof.Verify(&LogStarVerifyParams{
+			Curve:      curve,
+			Pk:         &sk.PublicKey,
+			Pedersen:   pedersen,
+			Ciphertext: ciphertext,
+			Base:       base,
+			Point:      point.Double(),
+			Sid:        sid,
+		}))
+		require.Error(t, proof.Verify(&LogStarVerifyParams{
+			Curve:      curve,
+			Pk:         &sk.PublicKey,
+			Pedersen:   pedersen,
+			Ciphertext: ciphertext,
+			Point:      point,
+			Sid:        sid,
+		}))
+
+		// Tampered proofs are rejected
+		tampered := *proof
+		tampered.Y = tampered.Y.Double()
+		require.Error(t, tampered.Verify(vp))
+		tampered = *proof
+		tampered.Z1 = new(big.Int).Add(tampered.Z1, big.NewInt(1))
+		require.Error(t, tampered.Verify(vp))
+		require.Error(t, LogStarProof{}.Verify(vp))
+		require.Error(t, proof.Verify(nil))
+	}
+}
@@ -0,0 +1,117 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/paillier"
+)
+
+// PrmProofLength is the number of binary challenges m of Π^prm, the proof is sound except with probability 2^-m
+const PrmProofLength = 80
+
+// PrmProof proves that the ring-Pedersen parameter s is in the group generated by t, CGGMP21 §C.4 Π^prm
+type PrmProof struct {
+	A, Z []*big.Int
+}
+
+// NewPedersen creates the ring-Pedersen parameters t = r^2, s = t^λ mod N of the paillier modulus of sk, together
+// with the proof that they are well formed, bound to the session id sid
+func NewPedersen(sk *paillier.SecretKey, sid []byte) (*Pedersen, *PrmProof, error) {
+	if sk == nil || sk.N == nil || sk.Totient == nil {
+		return nil, nil, fmt.Errorf("paillier secret key cannot be nil")
+	}
+	r, err := randUnit(sk.N)
+	if err != nil {
+		return nil, nil, err
+	}
+	lambda, err := core.Rand(sk.Totient)
+	if err != nil {
+		return nil, nil, err
+	}
+	t := new(big.Int).Exp(r, big.NewInt(2), sk.N)
+	params := &Pedersen{
+		N: sk.N,
+		S: new(big.Int).Exp(t, lambda, sk.N),
+		T: t,
+	}
+
+	// 1. a_i <- Z_φ(N), A_i = t^a_i mod N
+	a := make([]*big.Int, PrmProofLength)
+	proof := &PrmProof{
+		A: make([]*big.Int, PrmProofLength),
+		Z: make([]*big.Int, PrmProofLength),
+	}
+	for i := range a {
+		if a[i], err = core.Rand(sk.Totient); err != nil {
+			return nil, nil, err
+		}
+		proof.A[i] = new(big.Int).Exp(t, a[i], sk.N)
+	}
+
+	// 2. e_i <- FS-HASH(N, s, t, A, sid)
+	e, err := prmChallenges(params, proof.A, sid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 3. z_i = a_i + e_i λ mod φ(N)
+	for i := range a {
+		proof.Z[i] = new(big.Int).Set(a[i])
+		if e.Bit(i) == 1 {
+			proof.Z[i].Add(proof.Z[i], lambda).Mod(proof.Z[i], sk.Totient)
+		}
+	}
+	return params, proof, nil
+}
+
+// Verify checks that params are well formed, CGGMP21 §C.4 Π^prm
+func (p PrmProof) Verify(params *Pedersen, sid []byte) error {
+	if err := params.validate(); err != nil {
+		return err
+	}
+	if len(p.A) != PrmProofLength || len(p.Z) != PrmProofLength {
+		return fmt.Errorf("proof must have %d values", PrmProofLength)
+	}
+	if err := inUnits(params.N, p.A...); err != nil {
+		return err
+	}
+	e, err := prmChallenges(params, p.A, sid)
+	if err != nil {
+		return err
+	}
+
+	// t^z_i = A_i s^e_i mod N
+	for i := range p.A {
+		if p.Z[i] == nil || p.Z[i].Sign() < 0 {
+			return fmt.Errorf("invalid response at %d", i)
+		}
+		lhs := new(big.Int).Exp(params.T, p.Z[i], params.N)
+		rhs := new(big.Int).Set(p.A[i])
+		if e.Bit(i) == 1 {
+			rhs.Mul(rhs, params.S).Mod(rhs, params.N)
+		}
+		if lhs.Cmp(rhs) != 0 {
+			return fmt.Errorf("not equal at %d", i)
+		}
+	}
+	return nil
+}
+
+// prmChallenges computes the PrmProofLength binary challenges of Π^prm as the bits of the result
+func prmChallenges(params *Pedersen, a []*big.Int, sid []byte) (*big.Int, error) {
+	values := append([]*big.Int{params.N, params.S, params.T}, a...)
+	values = append(values, new(big.Int).SetBytes(sid), big.NewInt(int64(len(sid))))
+	digest, err := core.FiatShamir(values...)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(digest), nil
+}
@@ -0,0 +1,79 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	tt "github.com/coinbase/kryptology/internal"
+	"github.com/coinbase/kryptology/pkg/paillier"
+)
+
+var testSafePrimes = []*big.Int{
+	tt.B10("94210786053667323206442523040419729883258172350738703980637961803118626748668924192069593010365236618255120977661397310932923345291377692570649198560048403943687994859423283474169530971418656709749020402756179383990602363122039939937953514870699284906666247063852187255623958659551404494107714695311474384687"),
+	tt.B10("130291226847076770981564372061529572170236135412763130013877155698259035960569046218348763182598589633420963942796327547969527085797839549642610021986391589746295634536750785366034581957858065740296991986002552598751827526181747791647357767502200771965093659353354985289411489453223546075843993686648576029043"),
+	tt.B10("172938910323633442195852028319756134734590277522945546987913328782597284762767185925315797321999389252040294991952361905020940252121762387957669654615602135429944435719699091344247805645764550860505536884031064967454028383404046221898300153428182409080298694828920944094158777327533157774919783417586902830043"),
+	tt.B10("135841191929788643010555393808775051922265083622266098277752143441294911675705272940799534437169053045878247274810449617960047255023823301284034559807472662111224710158898548617194658983006262996831617082584649612602010680423107108651221824216065228161009680618243402116924511141821829055830713600437589058643"),
+	tt.B10("179677777376220950493907657233669314916823596507009854134559513388779535023958212632715646194917807302098015450071151245496651913873851032302340489007561121851068326577148680474495447007833318066335149850926605897908761267606415610900931306044455332084757793630487163583451178807470499389106913845684353833379"),
+	tt.B10("147653127360336844448178027222853805809444645720500374788954343695331927468524513989671450440433430392339037667457657655958027740671071573403925974795764987870476118984896439440386146680643457835633462311776946902713168513155240275028008685964121441954481847113848701823211862974120297600518927026940189810103"),
+	tt.B10("167562983031509383478485987630533113343120902430985961468758712448125734458812918541051012669749885569679178971612428577288632429606851871845164719448590160530844833425628143996971699662729056519326776907622035340086832629206691942750594912221135787534670122007438859975313187460872690748138136170080913902203"),
+	tt.B10("151715609132228776595716500435665208768897792993205818803431003524953811539898459230192282642811956896879836518212758893685104146944932088195466999437630114129887975508715417094019351746027667352287673763064246395392591213231796089814648654152625331299642171758052545451706130433176935280325874961374276589763"),
+}
+
+// testPaillierKey returns the paillier key made of the test safe primes i and i+1
+func testPaillierKey(t *testing.T, i int) *paillier.SecretKey {
+	t.Helper()
+	sk, err := paillier.NewSecretKey(testSafePrimes[i], testSafePrimes[i+1])
+	require.NoError(t, err)
+	return sk
+}
+
+// testPedersen returns the ring-Pedersen parameters of the paillier key of testPaillierKey(t, i)
+func testPedersen(t *testing.T, i int) *Pedersen {
+	t.Helper()
+	params, _, err := NewPedersen(testPaillierKey(t, i), nil)
+	require.NoError(t, err)
+	return params
+}
+
+func TestPrmProof(t *testing.T) {
+	sk := testPaillierKey(t, 0)
+	sid := []byte("prm proof test")
+	params, proof, err := NewPedersen(sk, sid)
+	require.NoError(t, err)
+	require.Equal(t, sk.N, params.N)
+	require.NoError(t, proof.Verify(params, sid))
+
+	// The proof survives a JSON round trip
+	data, err := json.Marshal(proof)
+	require.NoError(t, err)
+	restored := new(PrmProof)
+	require.NoError(t, json.Unmarshal(data, restored))
+	require.NoError(t, restored.Verify(params, sid))
+
+	// The proof is bound to the session and the parameters
+	require.Error(t, proof.Verify(params, []byte("other session")))
+	require.Error(t, proof.Verify(&Pedersen{N: params.N, S: params.T, T: params.S}, sid))
+	other, _, err := NewPedersen(sk, sid)
+	require.NoError(t, err)
+	require.Error(t, proof.Verify(other, sid))
+
+	// s outside of the group generated by t is rejected: -1 is not a quadratic residue
+	minusOne := new(big.Int).Sub(params.N, big.NewInt(1))
+	bad := &Pedersen{N: params.N, S: new(big.Int).Mod(new(big.Int).Mul(params.S, minusOne), params.N), T: params.T}
+	require.Error(t, proof.Verify(bad, sid))
+
+	// Malformed proofs are rejected
+	restored.Z = restored.Z[1:]
+	require.Error(t, restored.Verify(params, sid))
+	require.Error(t, PrmProof{}.Verify(params, sid))
+	require.Error(t, proof.Verify(nil, sid))
+}
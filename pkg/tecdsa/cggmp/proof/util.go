@@ -0,0 +1,191 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+)
+
+const (
+	// Ell is the bit length ℓ of the secrets of the range proofs, the size of the curve order
+	Ell = 256
+	// EllPrime is the bit length ℓ' of the masks of the affine operations
+	EllPrime = 5 * Ell
+	// Epsilon is the slackness ε of the ranges
+	Epsilon = 2 * Ell
+)
+
+// Pedersen are the ring-Pedersen parameters (N, s, t) of a verifier, where N is a product of two safe primes and
+// s, t are quadratic residues generating the same group. The range proofs commit to the secrets of the prover with
+// the parameters of the verifier.
+type Pedersen struct {
+	N, S, T *big.Int
+}
+
+// Commit computes s^x t^y mod N, where the exponents can be negative
+func (p *Pedersen) Commit(x, y *big.Int) (*big.Int, error) {
+	sx, err := expSigned(p.S, x, p.N)
+	if err != nil {
+		return nil, err
+	}
+	ty, err := expSigned(p.T, y, p.N)
+	if err != nil {
+		return nil, err
+	}
+	return sx.Mul(sx, ty).Mod(sx, p.N), nil
+}
+
+// verify checks that the commitment s^x t^y equals c d^e mod N
+func (p *Pedersen) verify(x, y, c, d, e *big.Int) error {
+	lhs, err := p.Commit(x, y)
+	if err != nil {
+		return err
+	}
+	if lhs.Cmp(mulExp(c, d, e, p.N)) != 0 {
+		return fmt.Errorf("invalid pedersen commitment")
+	}
+	return nil
+}
+
+// validate checks that the parameters are units of an odd modulus
+func (p *Pedersen) validate() error {
+	if p == nil || core.AnyNil(p.N, p.S, p.T) {
+		return fmt.Errorf("pedersen parameters cannot be nil")
+	}
+	if p.N.Bit(0) == 0 || p.N.Cmp(core.One) <= 0 {
+		return fmt.Errorf("pedersen modulus must be odd")
+	}
+	return inUnits(p.N, p.S, p.T)
+}
+
+// encrypt computes the paillier encryption (1+N)^m r^N mod N² of the signed message m with nonce r
+func encrypt(pk *paillier.PublicKey, m, r *big.Int) *big.Int {
+	// (1+N)^m = 1 + mN mod N²
+	c := new(big.Int).Mod(m, pk.N)
+	c.Mul(c, pk.N).Add(c, core.One)
+	rN := new(big.Int).Exp(r, pk.N, pk.N2)
+	return c.Mul(c, rN).Mod(c, pk.N2)
+}
+
+// mulExp computes c d^e mod n for a non negative e
+func mulExp(c, d, e, n *big.Int) *big.Int {
+	result := new(big.Int).Exp(d, e, n)
+	return result.Mul(result, c).Mod(result, n)
+}
+
+// expSigned computes x^e mod n, inverting x when e is negative
+func expSigned(x, e, n *big.Int) (*big.Int, error) {
+	if e.Sign() >= 0 {
+		return new(big.Int).Exp(x, e, n), nil
+	}
+	xInv, err := core.Inv(x, n)
+	if err != nil {
+		return nil, err
+	}
+	return xInv.Exp(xInv, new(big.Int).Neg(e), n), nil
+}
+
+// randSigned samples a uniform integer in [-2^bits·scale, 2^bits·scale]
+func randSigned(bits uint, scale *big.Int) (*big.Int, error) {
+	bound := new(big.Int).Lsh(scale, bits)
+	r, err := crand.Int(crand.Reader, new(big.Int).Add(new(big.Int).Lsh(bound, 1), core.One))
+	if err != nil {
+		return nil, err
+	}
+	return r.Sub(r, bound), nil
+}
+
+// randUnit samples a uniform unit of Z_n
+func randUnit(n *big.Int) (*big.Int, error) {
+	for {
+		r, err := core.Rand(n)
+		if err != nil {
+			return nil, err
+		}
+		if new(big.Int).GCD(nil, nil, r, n).Cmp(core.One) == 0 {
+			return r, nil
+		}
+	}
+}
+
+// inRange checks that |x| <= 2^bits
+func inRange(x *big.Int, bits uint) bool {
+	return new(big.Int).Abs(x).Cmp(new(big.Int).Lsh(core.One, bits)) <= 0
+}
+
+// inUnits checks that the values are units of Z_n
+func inUnits(n *big.Int, values ...*big.Int) error {
+	for _, v := range values {
+		if v == nil {
+			return fmt.Errorf("proof values cannot be nil")
+		}
+		if err := core.In(v, n); err != nil {
+			return err
+		}
+		if new(big.Int).GCD(nil, nil, v, n).Cmp(core.One) != 0 {
+			return fmt.Errorf("value is not a unit")
+		}
+	}
+	return nil
+}
+
+// validatePublicKey checks that the paillier public key is usable
+func validatePublicKey(pk *paillier.PublicKey) error {
+	if pk == nil || pk.N == nil || pk.N2 == nil {
+		return fmt.Errorf("paillier public key cannot be nil")
+	}
+	if pk.N.Bit(0) == 0 || pk.N.Cmp(core.One) <= 0 {
+		return fmt.Errorf("paillier modulus must be odd")
+	}
+	return nil
+}
+
+// curveOrder returns the order q of curve
+func curveOrder(curve *curves.Curve) (*big.Int, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("curve cannot be nil")
+	}
+	ellipticCurve, err := curve.ToEllipticCurve()
+	if err != nil {
+		return nil, err
+	}
+	return ellipticCurve.Params().N, nil
+}
+
+// ScalarFromInt returns the scalar x mod q of curve for the signed integer x
+func ScalarFromInt(curve *curves.Curve, x *big.Int) (curves.Scalar, error) {
+	q, err := curveOrder(curve)
+	if err != nil {
+		return nil, err
+	}
+	return curve.Scalar.SetBigInt(new(big.Int).Mod(x, q))
+}
+
+// pointInt converts a point to an integer for the Fiat-Shamir hash
+func pointInt(p curves.Point) *big.Int {
+	return new(big.Int).SetBytes(p.ToAffineCompressed())
+}
+
+// challenge computes the Fiat-Shamir challenge e in [0, q) from the session id and the values of a proof
+func challenge(curve *curves.Curve, sid []byte, values ...*big.Int) (*big.Int, error) {
+	q, err := curveOrder(curve)
+	if err != nil {
+		return nil, err
+	}
+	values = append(values, q, new(big.Int).SetBytes(sid), big.NewInt(int64(len(sid))))
+	digest, err := core.FiatShamir(values...)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Mod(new(big.Int).SetBytes(digest), q), nil
+}
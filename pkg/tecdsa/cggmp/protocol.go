@@ -0,0 +1,410 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package cggmp provides the t-of-n threshold ECDSA protocol of [CGGMP21](https://eprint.iacr.org/2021/060.pdf)
+// through the protocol iterator interface: key generation, auxiliary info and key refresh, presigning and one
+// round signing. The rounds are implemented in the participant package and the zero knowledge proofs in the proof
+// package.
+//
+// All protocols output one message per round with a payload for every recipient, keyed by the id of the recipient,
+// or a single payload with key protocol.BroadcastKey. Their inputs hold the payloads the other parties sent to this
+// party in the previous round, keyed by the id of the sender. RouteMessages converts the outputs of all parties of
+// a round to the inputs of the next round. Payloads are gob encoded, only protocol.Version1 is supported.
+package cggmp
+
+import (
+	"hash"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	"github.com/coinbase/kryptology/pkg/paillier"
+	"github.com/coinbase/kryptology/pkg/tecdsa/cggmp/participant"
+)
+
+// Basic protocol interface implementation that calls the next step func in a pre-defined list
+type protoStepper struct {
+	steps []func(input *protocol.Message) (*protocol.Message, error)
+	step  int
+}
+
+// Next runs the next step in the protocol and reports errors or increments the step index
+func (p *protoStepper) Next(input *protocol.Message) (*protocol.Message, error) {
+	if p.complete() {
+		return nil, protocol.ErrProtocolFinished
+	}
+
+	// Run the current protocol step and report any errors
+	output, err := p.steps[p.step](input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Increment the step index and report success
+	p.step++
+	return output, nil
+}
+
+// Reports true if the step index exceeds the number of steps
+func (p *protoStepper) complete() bool { return p.step >= len(p.steps) }
+
+// KeyGen t-of-n CGGMP21 key generation implementation that satisfies the protocol iterator interface.
+type KeyGen struct {
+	protoStepper
+	*participant.KeyGen
+}
+
+// Refresh CGGMP21 auxiliary info and key refresh implementation that satisfies the protocol iterator interface.
+type Refresh struct {
+	protoStepper
+	*participant.Refresh
+}
+
+// Presign CGGMP21 presigning implementation that satisfies the protocol iterator interface.
+type Presign struct {
+	protoStepper
+	*participant.Presigner
+
+	presignature *participant.Presignature
+}
+
+// Sign CGGMP21 one round signing implementation that satisfies the protocol iterator interface.
+type Sign struct {
+	protoStepper
+	*participant.Signer
+}
+
+var (
+	// Static type assertions
+	_ protocol.Iterator = &KeyGen{}
+	_ protocol.Iterator = &Refresh{}
+	_ protocol.Iterator = &Presign{}
+	_ protocol.Iterator = &Sign{}
+)
+
+// NewKeyGen creates a new protocol that can compute a t-of-n key generation as the party with identifier id among
+// parties. The result must be refreshed with Refresh before presigning, to set up the auxiliary info.
+func NewKeyGen(curve *curves.Curve, id, threshold uint32, parties []uint32, version uint) (*KeyGen, error) {
+	kg, err := participant.NewKeyGen(curve, id, threshold, parties)
+	if err != nil {
+		return nil, err
+	}
+	p := &KeyGen{KeyGen: kg}
+	p.steps = []func(*protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			round1Output, err := kg.Round1Commit()
+			if err != nil {
+				return nil, err
+			}
+			output := newProtocolMessage(protocol.Cggmp21KeyGen, "1", version)
+			return output, addPayloadWithKey(output, protocol.BroadcastKey, round1Output)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round2Input := make(map[uint32]*participant.KeyGenRound1Output)
+			err := decodePayloads(input, protocol.Cggmp21KeyGen,
+				func() interface{} { return new(participant.KeyGenRound1Output) },
+				func(id uint32, value interface{}) { round2Input[id] = value.(*participant.KeyGenRound1Output) })
+			if err != nil {
+				return nil, err
+			}
+			round2Output, err := kg.Round2Decommit(round2Input)
+			if err != nil {
+				return nil, err
+			}
+			output := newProtocolMessage(protocol.Cggmp21KeyGen, "2", version)
+			for id, value := range round2Output {
+				if err = addPayload(output, id, value); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round3Input := make(map[uint32]*participant.KeyGenRound2Output)
+			err := decodePayloads(input, protocol.Cggmp21KeyGen,
+				func() interface{} { return new(participant.KeyGenRound2Output) },
+				func(id uint32, value interface{}) { round3Input[id] = value.(*participant.KeyGenRound2Output) })
+			if err != nil {
+				return nil, err
+			}
+			return nil, kg.Round3Verify(round3Input)
+		},
+	}
+	return p, nil
+}
+
+// Result returns the encoded key generation output of this party that can be used to initialize a Refresh protocol.
+func (p *KeyGen) Result(version uint) (*protocol.Message, error) {
+	if !p.complete() {
+		return nil, nil
+	}
+	if p.KeyGen == nil || p.Output() == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return EncodeConfig(p.Output(), version)
+}
+
+// NewRefresh creates a new protocol that can set up the auxiliary info and refresh the shares of a key generation or
+// refresh result. All parties of the key generation must take part. paillierKey is the new paillier key of this
+// party, a new one is generated when it is nil.
+func NewRefresh(curve *curves.Curve, configMessage *protocol.Message, paillierKey *paillier.SecretKey, version uint) (*Refresh, error) {
+	config, err := DecodeConfig(configMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	r, err := participant.NewRefresh(curve, config, paillierKey)
+	if err != nil {
+		return nil, err
+	}
+	p := &Refresh{Refresh: r}
+	p.steps = []func(*protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			round1Output, err := r.Round1Commit()
+			if err != nil {
+				return nil, err
+			}
+			output := newProtocolMessage(protocol.Cggmp21Refresh, "1", version)
+			return output, addPayloadWithKey(output, protocol.BroadcastKey, round1Output)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round2Input := make(map[uint32]*participant.RefreshRound1Output)
+			err := decodePayloads(input, protocol.Cggmp21Refresh,
+				func() interface{} { return new(participant.RefreshRound1Output) },
+				func(id uint32, value interface{}) { round2Input[id] = value.(*participant.RefreshRound1Output) })
+			if err != nil {
+				return nil, err
+			}
+			round2Output, err := r.Round2Decommit(round2Input)
+			if err != nil {
+				return nil, err
+			}
+			output := newProtocolMessage(protocol.Cggmp21Refresh, "2", version)
+			return output, addPayloadWithKey(output, protocol.BroadcastKey, round2Output)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round3Input := make(map[uint32]*participant.RefreshRound2Output)
+			err := decodePayloads(input, protocol.Cggmp21Refresh,
+				func() interface{} { return new(participant.RefreshRound2Output) },
+				func(id uint32, value interface{}) { round3Input[id] = value.(*participant.RefreshRound2Output) })
+			if err != nil {
+				return nil, err
+			}
+			round3Output, err := r.Round3Prove(round3Input)
+			if err != nil {
+				return nil, err
+			}
+			output := newProtocolMessage(protocol.Cggmp21Refresh, "3", version)
+			for id, value := range round3Output {
+				if err = addPayload(output, id, value); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round4Input := make(map[uint32]*participant.RefreshRound3Output)
+			err := decodePayloads(input, protocol.Cggmp21Refresh,
+				func() interface{} { return new(participant.RefreshRound3Output) },
+				func(id uint32, value interface{}) { round4Input[id] = value.(*participant.RefreshRound3Output) })
+			if err != nil {
+				return nil, err
+			}
+			return nil, r.Round4Verify(round4Input)
+		},
+	}
+	return p, nil
+}
+
+// Result returns the encoded refreshed output of this party that can be used to initialize a Presign protocol.
+func (p *Refresh) Result(version uint) (*protocol.Message, error) {
+	if !p.complete() {
+		return nil, nil
+	}
+	if p.Refresh == nil || p.Output() == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return EncodeConfig(p.Output(), version)
+}
+
+// NewPresign creates a new protocol that can compute a presignature together with the parties in signers.
+// Requires a config that was produced by Refresh.
+func NewPresign(curve *curves.Curve, configMessage *protocol.Message, signers []uint32, version uint) (*Presign, error) {
+	config, err := DecodeConfig(configMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	presigner, err := participant.NewPresigner(curve, config, signers)
+	if err != nil {
+		return nil, err
+	}
+	p := &Presign{Presigner: presigner}
+	p.steps = []func(*protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			seed, err := presigner.GenerateSeed()
+			if err != nil {
+				return nil, err
+			}
+			output := newProtocolMessage(protocol.Cggmp21Presign, "seed", version)
+			return output, addPayloadWithKey(output, protocol.BroadcastKey, seed)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			seeds := make(map[uint32][participant.SessionIdSize]byte)
+			err := decodePayloads(input, protocol.Cggmp21Presign,
+				func() interface{} { return new([participant.SessionIdSize]byte) },
+				func(id uint32, value interface{}) { seeds[id] = *value.(*[participant.SessionIdSize]byte) })
+			if err != nil {
+				return nil, err
+			}
+			round1Output, err := presigner.Round1Encrypt(seeds)
+			if err != nil {
+				return nil, err
+			}
+			output := newProtocolMessage(protocol.Cggmp21Presign, "1", version)
+			for id, value := range round1Output {
+				if err = addPayload(output, id, value); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round2Input := make(map[uint32]*participant.PresignRound1Output)
+			err := decodePayloads(input, protocol.Cggmp21Presign,
+				func() interface{} { return new(participant.PresignRound1Output) },
+				func(id uint32, value interface{}) { round2Input[id] = value.(*participant.PresignRound1Output) })
+			if err != nil {
+				return nil, err
+			}
+			round2Output, err := presigner.Round2Multiply(round2Input)
+			if err != nil {
+				return nil, err
+			}
+			output := newProtocolMessage(protocol.Cggmp21Presign, "2", version)
+			for id, value := range round2Output {
+				if err = addPayload(output, id, value); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round3Input := make(map[uint32]*participant.PresignRound2Output)
+			err := decodePayloads(input, protocol.Cggmp21Presign,
+				func() interface{} { return new(participant.PresignRound2Output) },
+				func(id uint32, value interface{}) { round3Input[id] = value.(*participant.PresignRound2Output) })
+			if err != nil {
+				return nil, err
+			}
+			round3Output, err := presigner.Round3Reveal(round3Input)
+			if err != nil {
+				return nil, err
+			}
+			output := newProtocolMessage(protocol.Cggmp21Presign, "3", version)
+			for id, value := range round3Output {
+				if err = addPayload(output, id, value); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round4Input := make(map[uint32]*participant.PresignRound3Output)
+			err := decodePayloads(input, protocol.Cggmp21Presign,
+				func() interface{} { return new(participant.PresignRound3Output) },
+				func(id uint32, value interface{}) { round4Input[id] = value.(*participant.PresignRound3Output) })
+			if err != nil {
+				return nil, err
+			}
+			presignature, round4Output, err := presigner.Round4Presignature(round4Input)
+			if err != nil {
+				return nil, err
+			}
+			if presignature != nil {
+				// The identification round only runs when the revealed shares are inconsistent
+				p.presignature = presignature
+				p.steps = p.steps[:p.step+1]
+				return nil, nil
+			}
+			output := newProtocolMessage(protocol.Cggmp21Presign, "4", version)
+			for id, value := range round4Output {
+				if err = addPayload(output, id, value); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round5Input := make(map[uint32]*participant.PresignRound4Output)
+			err := decodePayloads(input, protocol.Cggmp21Presign,
+				func() interface{} { return new(participant.PresignRound4Output) },
+				func(id uint32, value interface{}) { round5Input[id] = value.(*participant.PresignRound4Output) })
+			if err != nil {
+				return nil, err
+			}
+			return nil, presigner.Round5Identify(round5Input)
+		},
+	}
+	return p, nil
+}
+
+// Result returns the encoded presignature of this party that can be used to initialize a Sign protocol.
+func (p *Presign) Result(version uint) (*protocol.Message, error) {
+	if !p.complete() {
+		return nil, nil
+	}
+	if p.presignature == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return EncodePresignature(p.presignature, version)
+}
+
+// NewSign creates a new protocol that signs message in one round with the signers of the presignature.
+// The presignature is recorded with guard before signing, and copies of a presignature already recorded are refused.
+func NewSign(curve *curves.Curve, hash hash.Hash, message []byte, presignatureMessage *protocol.Message, version uint, guard protocol.PresignatureGuard) (*Sign, error) {
+	presignature, err := DecodePresignature(presignatureMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	signer, err := participant.NewSigner(curve, hash, presignature, guard)
+	if err != nil {
+		return nil, err
+	}
+	p := &Sign{Signer: signer}
+	p.steps = []func(*protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			round1Output, err := signer.Round1Sign(message)
+			if err != nil {
+				return nil, err
+			}
+			output := newProtocolMessage(protocol.Cggmp21Sign, "1", version)
+			return output, addPayloadWithKey(output, protocol.BroadcastKey, round1Output)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round2Input := make(map[uint32]*participant.SignRound1Output)
+			err := decodePayloads(input, protocol.Cggmp21Sign,
+				func() interface{} { return new(participant.SignRound1Output) },
+				func(id uint32, value interface{}) { round2Input[id] = value.(*participant.SignRound1Output) })
+			if err != nil {
+				return nil, err
+			}
+			return nil, signer.Round2Combine(round2Input)
+		},
+	}
+	return p, nil
+}
+
+// Result returns the signature as a *curves.EcdsaSignature if the signing protocol completed successfully.
+// Every signer obtains the signature.
+func (p *Sign) Result(version uint) (*protocol.Message, error) {
+	if !p.complete() {
+		return nil, nil
+	}
+	if p.Signer == nil || p.Signature == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return EncodeSignature(p.Signature, version)
+}
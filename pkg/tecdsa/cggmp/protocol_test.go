@@ -0,0 +1,180 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package cggmp
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	tt "github.com/coinbase/kryptology/internal"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	"github.com/coinbase/kryptology/pkg/paillier"
+)
+
+// testSafePrimes are 1024 bit safe primes, party i uses the paillier key of primes 2(i-1) and 2(i-1)+1
+var testSafePrimes = []*big.Int{
+	tt.B10("94210786053667323206442523040419729883258172350738703980637961803118626748668924192069593010365236618255120977661397310932923345291377692570649198560048403943687994859423283474169530971418656709749020402756179383990602363122039939937953514870699284906666247063852187255623958659551404494107714695311474384687"),
+	tt.B10("130291226847076770981564372061529572170236135412763130013877155698259035960569046218348763182598589633420963942796327547969527085797839549642610021986391589746295634536750785366034581957858065740296991986002552598751827526181747791647357767502200771965093659353354985289411489453223546075843993686648576029043"),
+	tt.B10("172938910323633442195852028319756134734590277522945546987913328782597284762767185925315797321999389252040294991952361905020940252121762387957669654615602135429944435719699091344247805645764550860505536884031064967454028383404046221898300153428182409080298694828920944094158777327533157774919783417586902830043"),
+	tt.B10("135841191929788643010555393808775051922265083622266098277752143441294911675705272940799534437169053045878247274810449617960047255023823301284034559807472662111224710158898548617194658983006262996831617082584649612602010680423107108651221824216065228161009680618243402116924511141821829055830713600437589058643"),
+	tt.B10("179677777376220950493907657233669314916823596507009854134559513388779535023958212632715646194917807302098015450071151245496651913873851032302340489007561121851068326577148680474495447007833318066335149850926605897908761267606415610900931306044455332084757793630487163583451178807470499389106913845684353833379"),
+	tt.B10("147653127360336844448178027222853805809444645720500374788954343695331927468524513989671450440433430392339037667457657655958027740671071573403925974795764987870476118984896439440386146680643457835633462311776946902713168513155240275028008685964121441954481847113848701823211862974120297600518927026940189810103"),
+}
+
+// runProtocol cranks all parties forward one round at a time, routing the messages between them
+func runProtocol(t *testing.T, parties map[uint32]protocol.Iterator) {
+	inputs := make(map[uint32]*protocol.Message, len(parties))
+	for {
+		outputs := make(map[uint32]*protocol.Message, len(parties))
+		finished := 0
+		for id, party := range parties {
+			output, err := party.Next(inputs[id])
+			if err == protocol.ErrProtocolFinished {
+				finished++
+				continue
+			}
+			require.NoError(t, err)
+			outputs[id] = output
+		}
+		if finished == len(parties) {
+			return
+		}
+		require.Zero(t, finished, "parties must finish simultaneously")
+		var err error
+		inputs, err = RouteMessages(outputs)
+		require.NoError(t, err)
+	}
+}
+
+// results runs the protocol of every party and returns their results
+func results(t *testing.T, parties map[uint32]protocol.Iterator) map[uint32]*protocol.Message {
+	runProtocol(t, parties)
+	output := make(map[uint32]*protocol.Message, len(parties))
+	for id, party := range parties {
+		result, err := party.Result(protocol.Version1)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		output[id] = result
+	}
+	return output
+}
+
+func TestCggmpProtocol(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the full protocol in short mode")
+	}
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		ids := []uint32{1, 2, 3}
+		parties := make(map[uint32]protocol.Iterator, len(ids))
+		for _, id := range ids {
+			kg, err := NewKeyGen(curve, id, 2, ids, protocol.Version1)
+			require.NoError(t, err)
+			result, err := kg.Result(protocol.Version1)
+			require.NoError(t, err)
+			require.Nil(t, result)
+			parties[id] = kg
+		}
+		configs := results(t, parties)
+
+		for _, id := range ids {
+			sk, err := paillier.NewSecretKey(testSafePrimes[2*(id-1)], testSafePrimes[2*(id-1)+1])
+			require.NoError(t, err)
+			parties[id], err = NewRefresh(curve, configs[id], sk, protocol.Version1)
+			require.NoError(t, err)
+		}
+		configs = results(t, parties)
+		config, err := DecodeConfig(configs[1])
+		require.NoError(t, err)
+		require.NotNil(t, config.PaillierKey)
+
+		signers := []uint32{1, 3}
+		parties = make(map[uint32]protocol.Iterator, len(signers))
+		for _, id := range signers {
+			parties[id], err = NewPresign(curve, configs[id], signers, protocol.Version1)
+			require.NoError(t, err)
+		}
+		presignatures := results(t, parties)
+
+		message := []byte("cggmp21 protocol iterator")
+		guards := make(map[uint32]protocol.PresignatureGuard, len(signers))
+		for _, id := range signers {
+			guards[id] = protocol.NewMemoryPresignatureGuard()
+			parties[id], err = NewSign(curve, sha256.New(), message, presignatures[id], protocol.Version1, guards[id])
+			require.NoError(t, err)
+		}
+		signatures := results(t, parties)
+		signature, err := DecodeSignature(signatures[1])
+		require.NoError(t, err)
+		other, err := DecodeSignature(signatures[3])
+		require.NoError(t, err)
+		require.Equal(t, signature, other)
+
+		// Results are only decoded as the result of their protocol
+		_, err = DecodeConfig(presignatures[1])
+		require.Error(t, err)
+		_, err = DecodePresignature(configs[1])
+		require.Error(t, err)
+		_, err = DecodeSignature(presignatures[1])
+		require.Error(t, err)
+
+		ellipticCurve, err := curve.ToEllipticCurve()
+		require.NoError(t, err)
+		uncompressed := config.PublicKey.ToAffineUncompressed()
+		publicKey := &ecdsa.PublicKey{
+			Curve: ellipticCurve,
+			X:     new(big.Int).SetBytes(uncompressed[1:33]),
+			Y:     new(big.Int).SetBytes(uncompressed[33:]),
+		}
+		digest := sha256.Sum256(message)
+		require.True(t, ecdsa.Verify(publicKey, digest[:], signature.R, signature.S))
+
+		// The protocols are finished
+		_, err = parties[1].Next(nil)
+		require.Equal(t, protocol.ErrProtocolFinished, err)
+
+		// The stored presignatures cannot sign again
+		sign, err := NewSign(curve, sha256.New(), []byte("another message"), presignatures[1], protocol.Version1, guards[1])
+		require.NoError(t, err)
+		_, err = sign.Next(nil)
+		require.ErrorIs(t, err, protocol.ErrPresignatureUsed)
+	}
+}
+
+func TestCggmpVersions(t *testing.T) {
+	_, err := encodePayload(protocol.Version2, 1)
+	require.Error(t, err)
+	kg, err := NewKeyGen(curves.K256(), 1, 2, []uint32{1, 2}, protocol.Version2)
+	require.NoError(t, err)
+	_, err = kg.Next(nil)
+	require.Error(t, err)
+
+	// A config without auxiliary info cannot presign
+	_, err = NewPresign(curves.K256(), nil, []uint32{1, 2}, protocol.Version1)
+	require.Error(t, err)
+}
+
+func TestRouteMessages(t *testing.T) {
+	outputs := map[uint32]*protocol.Message{
+		1: {Protocol: protocol.Cggmp21Sign, Payloads: map[string][]byte{protocol.BroadcastKey: {1}}},
+		2: {Protocol: protocol.Cggmp21Sign, Payloads: map[string][]byte{"1": {2}}},
+		3: nil,
+	}
+	inputs, err := RouteMessages(outputs)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"2": {2}}, inputs[1].Payloads)
+	require.Equal(t, map[string][]byte{"1": {1}}, inputs[2].Payloads)
+	require.Equal(t, map[string][]byte{"1": {1}}, inputs[3].Payloads)
+
+	outputs[2].Payloads["4"] = []byte{3}
+	_, err = RouteMessages(outputs)
+	require.Error(t, err)
+}
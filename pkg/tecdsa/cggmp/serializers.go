@@ -0,0 +1,233 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package cggmp
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	"github.com/coinbase/kryptology/pkg/tecdsa/cggmp/participant"
+)
+
+// payloadKey is the key of the payload of the results of the protocols
+const payloadKey = "direct"
+
+func registerTypes() {
+	gob.Register(&curves.ScalarK256{})
+	gob.Register(&curves.PointK256{})
+	gob.Register(&curves.ScalarP256{})
+	gob.Register(&curves.PointP256{})
+}
+
+// encodePayload encodes value with gob. Only version 1 is supported.
+func encodePayload(version uint, value interface{}) ([]byte, error) {
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	registerTypes()
+	buf := bytes.NewBuffer([]byte{})
+	enc := gob.NewEncoder(buf)
+	if err := enc.Encode(value); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodePayload decodes a gob encoded payload into value. Only version 1 is supported.
+func decodePayload(version uint, payload []byte, value interface{}) error {
+	if version != protocol.Version1 {
+		return errors.New("only version 1 is supported")
+	}
+	registerTypes()
+	dec := gob.NewDecoder(bytes.NewBuffer(payload))
+	if err := dec.Decode(value); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func newProtocolMessage(protocolName, round string, version uint) *protocol.Message {
+	return &protocol.Message{
+		Protocol: protocolName,
+		Version:  version,
+		Payloads: make(map[string][]byte),
+		Metadata: map[string]string{"round": round},
+	}
+}
+
+// addPayload encodes value as the payload of m to the party with identifier id
+func addPayload(m *protocol.Message, id uint32, value interface{}) error {
+	return addPayloadWithKey(m, strconv.FormatUint(uint64(id), 10), value)
+}
+
+func addPayloadWithKey(m *protocol.Message, key string, value interface{}) error {
+	payload, err := encodePayload(m.Version, value)
+	if err != nil {
+		return err
+	}
+	m.Payloads[key] = payload
+	return nil
+}
+
+// decodePayloads decodes every payload of m with a new value and calls store with the id of its sender.
+func decodePayloads(m *protocol.Message, protocolName string, newValue func() interface{}, store func(id uint32, value interface{})) error {
+	if m == nil {
+		return errors.New("message is nil")
+	}
+	if m.Protocol != protocolName {
+		return fmt.Errorf("expected a %s message, got %s", protocolName, m.Protocol)
+	}
+	for key, payload := range m.Payloads {
+		id, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			return errors.Wrapf(err, "invalid sender %s", key)
+		}
+		value := newValue()
+		if err = decodePayload(m.Version, payload, value); err != nil {
+			return errors.Wrapf(err, "decoding payload of %d", id)
+		}
+		store(uint32(id), value)
+	}
+	return nil
+}
+
+// RouteMessages delivers the messages output by all parties of a CGGMP21 protocol in the same round.
+// The payload party i addresses to party j, or broadcasts, becomes the payload with key i of the input of party j.
+// outputs is keyed by the id of the party that output the message, nil messages are skipped.
+func RouteMessages(outputs map[uint32]*protocol.Message) (map[uint32]*protocol.Message, error) {
+	inputs := make(map[uint32]*protocol.Message, len(outputs))
+	for id := range outputs {
+		inputs[id] = nil
+	}
+	var template *protocol.Message
+	for sender, m := range outputs {
+		if m == nil {
+			continue
+		}
+		template = m
+		senderKey := strconv.FormatUint(uint64(sender), 10)
+		for key, payload := range m.Payloads {
+			var recipients []uint32
+			if key == protocol.BroadcastKey {
+				for id := range outputs {
+					if id != sender {
+						recipients = append(recipients, id)
+					}
+				}
+			} else {
+				id, err := strconv.ParseUint(key, 10, 32)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid recipient %s", key)
+				}
+				if _, ok := outputs[uint32(id)]; !ok {
+					return nil, fmt.Errorf("unknown recipient %d", id)
+				}
+				recipients = []uint32{uint32(id)}
+			}
+			for _, id := range recipients {
+				if inputs[id] == nil {
+					inputs[id] = newRoutedMessage(m)
+				}
+				if _, ok := inputs[id].Payloads[senderKey]; ok {
+					return nil, fmt.Errorf("party %d sent more than one payload to party %d", sender, id)
+				}
+				inputs[id].Payloads[senderKey] = payload
+			}
+		}
+	}
+	// Parties that received nothing still take part in the round
+	for id, m := range inputs {
+		if m == nil && template != nil {
+			inputs[id] = newRoutedMessage(template)
+		}
+	}
+	return inputs, nil
+}
+
+// newRoutedMessage returns an empty input with the protocol, version and metadata of m
+func newRoutedMessage(m *protocol.Message) *protocol.Message {
+	return &protocol.Message{
+		Protocol: m.Protocol,
+		Version:  m.Version,
+		Payloads: make(map[string][]byte),
+		Metadata: m.Metadata,
+	}
+}
+
+// encodeResult encodes value as the result of a protocol
+func encodeResult(protocolName string, value interface{}, version uint) (*protocol.Message, error) {
+	payload, err := encodePayload(version, value)
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.Message{
+		Protocol: protocolName,
+		Version:  version,
+		Payloads: map[string][]byte{payloadKey: payload},
+		Metadata: map[string]string{"round": "output"},
+	}, nil
+}
+
+// decodeResult decodes the result of the protocol protocolName into value
+func decodeResult(m *protocol.Message, protocolName string, value interface{}) error {
+	if m == nil {
+		return errors.New("message is nil")
+	}
+	if m.Protocol != protocolName {
+		return fmt.Errorf("expected a %s result, got %s", protocolName, m.Protocol)
+	}
+	return decodePayload(m.Version, m.Payloads[payloadKey], value)
+}
+
+// EncodeConfig serializes the key generation or refresh output of one party. The config holds the secret key
+// share and paillier key of the party and must be stored securely.
+func EncodeConfig(config *participant.Config, version uint) (*protocol.Message, error) {
+	return encodeResult(protocol.Cggmp21KeyGen, config, version)
+}
+
+// DecodeConfig deserializes the key generation or refresh output of one party.
+func DecodeConfig(m *protocol.Message) (*participant.Config, error) {
+	decoded := new(participant.Config)
+	if err := decodeResult(m, protocol.Cggmp21KeyGen, decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// EncodePresignature serializes the presignature of one signer. The presignature must be stored securely.
+func EncodePresignature(presignature *participant.Presignature, version uint) (*protocol.Message, error) {
+	return encodeResult(protocol.Cggmp21Presign, presignature, version)
+}
+
+// DecodePresignature deserializes the presignature of one signer.
+func DecodePresignature(m *protocol.Message) (*participant.Presignature, error) {
+	decoded := new(participant.Presignature)
+	if err := decodeResult(m, protocol.Cggmp21Presign, decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// EncodeSignature serializes the signature.
+func EncodeSignature(signature *curves.EcdsaSignature, version uint) (*protocol.Message, error) {
+	return encodeResult(protocol.Cggmp21Sign, signature, version)
+}
+
+// DecodeSignature deserializes the signature.
+func DecodeSignature(m *protocol.Message) (*curves.EcdsaSignature, error) {
+	decoded := new(curves.EcdsaSignature)
+	if err := decodeResult(m, protocol.Cggmp21Sign, decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
@@ -28,12 +28,18 @@ type BobDkg struct {
 type AliceSign struct {
 	protoStepper
 	*sign.Alice
+
+	hash    hash.Hash
+	message []byte
 }
 
 // BobSign DKLS sign implementation that satisfies the protocol iterator interface.
 type BobSign struct {
 	protoStepper
 	*sign.Bob
+
+	hash    hash.Hash
+	message []byte
 }
 
 // AliceRefresh DKLS refresh implementation that satisfies the protocol iterator interface.
@@ -60,7 +66,15 @@ var (
 
 // NewAliceDkg creates a new protocol that can compute a DKG as Alice
 func NewAliceDkg(curve *curves.Curve, version uint) *AliceDkg {
-	a := &AliceDkg{Alice: dkg.NewAlice(curve)}
+	a := new(AliceDkg)
+	a.setup(dkg.NewAlice(curve), version)
+	return a
+}
+
+// setup initializes a to run all steps on alice.
+func (a *AliceDkg) setup(alice *dkg.Alice, version uint) {
+	a.Alice = alice
+	a.version = version
 	a.steps = []func(*protocol.Message) (*protocol.Message, error){
 		func(input *protocol.Message) (*protocol.Message, error) {
 			bobSeed, err := decodeDkgRound2Input(input)
@@ -117,7 +131,6 @@ func NewAliceDkg(curve *curves.Curve, version uint) *AliceDkg {
 			return nil, nil
 		},
 	}
-	return a
 }
 
 // Result Returns an encoded version of Alice as sequence of bytes that can be used to initialize an AliceSign protocol.
@@ -136,7 +149,15 @@ func (a *AliceDkg) Result(version uint) (*protocol.Message, error) {
 
 // NewBobDkg Creates a new protocol that can compute a DKG as Bob.
 func NewBobDkg(curve *curves.Curve, version uint) *BobDkg {
-	b := &BobDkg{Bob: dkg.NewBob(curve)}
+	b := new(BobDkg)
+	b.setup(dkg.NewBob(curve), version)
+	return b
+}
+
+// setup initializes b to run all steps on bob.
+func (b *BobDkg) setup(bob *dkg.Bob, version uint) {
+	b.Bob = bob
+	b.version = version
 	b.steps = []func(message *protocol.Message) (*protocol.Message, error){
 		func(*protocol.Message) (*protocol.Message, error) {
 			commitment, err := b.Round1GenerateRandomSeed()
@@ -190,7 +211,6 @@ func NewBobDkg(curve *curves.Curve, version uint) *BobDkg {
 			return encodeDkgRound9Output(opening, version)
 		},
 	}
-	return b
 }
 
 // Result returns an encoded version of Bob as sequence of bytes that can be used to  initialize an BobSign protocol.
@@ -214,7 +234,17 @@ func NewAliceSign(curve *curves.Curve, hash hash.Hash, message []byte, dkgResult
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	a := &AliceSign{Alice: sign.NewAlice(curve, hash, dkgResult)}
+	a := new(AliceSign)
+	a.setup(sign.NewAlice(curve, hash, dkgResult), hash, message, version)
+	return a, nil
+}
+
+// setup initializes a to run all steps on alice.
+func (a *AliceSign) setup(alice *sign.Alice, hash hash.Hash, message []byte, version uint) {
+	a.Alice = alice
+	a.version = version
+	a.hash = hash
+	a.message = message
 	a.steps = []func(message *protocol.Message) (*protocol.Message, error){
 		func(*protocol.Message) (*protocol.Message, error) {
 			aliceCommitment, err := a.Round1GenerateRandomSeed()
@@ -235,7 +265,6 @@ func NewAliceSign(curve *curves.Curve, hash hash.Hash, message []byte, dkgResult
 			return encodeSignRound3Output(round3Output, version)
 		},
 	}
-	return a, nil
 }
 
 // NewBobSign creates a new protocol that can compute a signature as Bob.
@@ -245,7 +274,17 @@ func NewBobSign(curve *curves.Curve, hash hash.Hash, message []byte, dkgResultMe
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	b := &BobSign{Bob: sign.NewBob(curve, hash, dkgResult)}
+	b := new(BobSign)
+	b.setup(sign.NewBob(curve, hash, dkgResult), hash, message, version)
+	return b, nil
+}
+
+// setup initializes b to run all steps on bob.
+func (b *BobSign) setup(bob *sign.Bob, hash hash.Hash, message []byte, version uint) {
+	b.Bob = bob
+	b.version = version
+	b.hash = hash
+	b.message = message
 	b.steps = []func(message *protocol.Message) (*protocol.Message, error){
 		func(input *protocol.Message) (*protocol.Message, error) {
 			commitment, err := decodeSignRound2Input(input)
@@ -270,7 +309,6 @@ func NewBobSign(curve *curves.Curve, hash hash.Hash, message []byte, dkgResultMe
 			return nil, nil
 		},
 	}
-	return b, nil
 }
 
 // Result always returns an error.
@@ -299,7 +337,15 @@ func NewAliceRefresh(curve *curves.Curve, dkgResultMessage *protocol.Message, ve
 		return nil, errors.WithStack(err)
 	}
 
-	a := &AliceRefresh{Alice: refresh.NewAlice(curve, dkgResult)}
+	a := new(AliceRefresh)
+	a.setup(refresh.NewAlice(curve, dkgResult), version)
+	return a, nil
+}
+
+// setup initializes a to run all steps on alice.
+func (a *AliceRefresh) setup(alice *refresh.Alice, version uint) {
+	a.Alice = alice
+	a.version = version
 	a.steps = []func(*protocol.Message) (*protocol.Message, error){
 		func(input *protocol.Message) (*protocol.Message, error) {
 			aliceSeed := a.Round1RefreshGenerateSeed()
@@ -338,7 +384,6 @@ func NewAliceRefresh(curve *curves.Curve, dkgResultMessage *protocol.Message, ve
 			return nil, nil
 		},
 	}
-	return a, nil
 }
 
 // Result Returns an encoded version of Alice as sequence of bytes that can be used to initialize an AliceSign protocol.
@@ -362,7 +407,15 @@ func NewBobRefresh(curve *curves.Curve, dkgResultMessage *protocol.Message, vers
 		return nil, errors.WithStack(err)
 	}
 
-	b := &BobRefresh{Bob: refresh.NewBob(curve, dkgResult)}
+	b := new(BobRefresh)
+	b.setup(refresh.NewBob(curve, dkgResult), version)
+	return b, nil
+}
+
+// setup initializes b to run all steps on bob.
+func (b *BobRefresh) setup(bob *refresh.Bob, version uint) {
+	b.Bob = bob
+	b.version = version
 	b.steps = []func(message *protocol.Message) (*protocol.Message, error){
 		func(input *protocol.Message) (*protocol.Message, error) {
 			round2Input, err := decodeRefreshRound2Input(input)
@@ -398,7 +451,6 @@ func NewBobRefresh(curve *curves.Curve, dkgResultMessage *protocol.Message, vers
 			return encodeRefreshRound6Output(round6Output, version)
 		},
 	}
-	return b, nil
 }
 
 // Result returns an encoded version of Bob as sequence of bytes that can be used to  initialize an BobSign protocol.
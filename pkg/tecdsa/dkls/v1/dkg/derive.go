@@ -0,0 +1,148 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package dkg
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+const (
+	// ChainCodeSize is the size, in bytes, of a BIP32 chain code
+	ChainCodeSize = 32
+	// HardenedKeyStart is the first hardened child index. Hardened children need the
+	// joint secret key and cannot be derived by Alice and Bob locally.
+	HardenedKeyStart = uint32(0x80000000)
+)
+
+// ChildTweak computes the tweak and chain code of the non-hardened child index of publicKey
+// following CKDpub of [BIP32](https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki)
+//
+//	I = HMAC-SHA512(chainCode, serP(publicKey) || ser32(index))
+//
+// The tweak is parse256(I_L) and the child chain code is I_R. The child public key is publicKey + tweak * G.
+func ChildTweak(publicKey curves.Point, chainCode []byte, index uint32) (curves.Scalar, []byte, error) {
+	if publicKey == nil {
+		return nil, nil, errors.New("public key is nil")
+	}
+	curve := curves.GetCurveByName(publicKey.CurveName())
+	if curve == nil || (curve.Name != curves.K256Name && curve.Name != curves.P256Name) {
+		return nil, nil, fmt.Errorf("unsupported curve %s", publicKey.CurveName())
+	}
+	if len(chainCode) != ChainCodeSize {
+		return nil, nil, fmt.Errorf("chain code must be %d bytes", ChainCodeSize)
+	}
+	if index >= HardenedKeyStart {
+		return nil, nil, errors.New("hardened derivation is not supported for two party keys")
+	}
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	mac := hmac.New(sha512.New, chainCode)
+	_, _ = mac.Write(publicKey.ToAffineCompressed())
+	_, _ = mac.Write(indexBytes[:])
+	i := mac.Sum(nil)
+
+	// BIP32 declares the index invalid if parse256(I_L) >= n, SetBytes fails in that case
+	tweak, err := curve.Scalar.SetBytes(i[:32])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid child index %d", index)
+	}
+	return tweak, i[32:], nil
+}
+
+// DerivePublicKey derives the non-hardened child index of publicKey and its chain code.
+// It needs no secret material, so it can be used with the extended public key of the DKG output.
+func DerivePublicKey(publicKey curves.Point, chainCode []byte, index uint32) (curves.Point, []byte, error) {
+	child, childChainCode, _, err := deriveChild(publicKey, chainCode, nil, index)
+	return child, childChainCode, err
+}
+
+// deriveChild applies the non-hardened child index to the public key, chain code and accumulated tweak of an output
+func deriveChild(publicKey curves.Point, chainCode []byte, tweak curves.Scalar, index uint32) (curves.Point, []byte, curves.Scalar, error) {
+	childTweak, childChainCode, err := ChildTweak(publicKey, chainCode, index)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	child := publicKey.Add(publicKey.Generator().Mul(childTweak))
+	if child.IsIdentity() {
+		return nil, nil, nil, fmt.Errorf("invalid child index %d", index)
+	}
+	if tweak != nil {
+		childTweak = childTweak.Add(tweak)
+	}
+	return child, childChainCode, childTweak, nil
+}
+
+// DeriveChild returns Alice's output for the non-hardened child index of the joint public key.
+// Alice derives locally; Bob must derive the same index from his output. The secret key share
+// and seed OT results are shared with the parent, the tweak is applied when signing.
+func (output *AliceOutput) DeriveChild(index uint32) (*AliceOutput, error) {
+	if output == nil || output.PublicKey == nil || output.SecretKeyShare == nil {
+		return nil, errors.New("alice dkg output is not initialized")
+	}
+	publicKey, chainCode, tweak, err := deriveChild(output.PublicKey, output.ChainCode, output.Tweak, index)
+	if err != nil {
+		return nil, err
+	}
+	return &AliceOutput{
+		PublicKey:      publicKey,
+		SecretKeyShare: output.SecretKeyShare,
+		SeedOtResult:   output.SeedOtResult,
+		ChainCode:      chainCode,
+		Tweak:          tweak,
+	}, nil
+}
+
+// DerivePath applies DeriveChild for each index of path in order
+func (output *AliceOutput) DerivePath(path []uint32) (*AliceOutput, error) {
+	child := output
+	for _, index := range path {
+		var err error
+		if child, err = child.DeriveChild(index); err != nil {
+			return nil, err
+		}
+	}
+	return child, nil
+}
+
+// DeriveChild returns Bob's output for the non-hardened child index of the joint public key.
+// Bob derives locally; Alice must derive the same index from her output. The secret key share
+// and seed OT results are shared with the parent, the tweak is applied when signing.
+func (output *BobOutput) DeriveChild(index uint32) (*BobOutput, error) {
+	if output == nil || output.PublicKey == nil || output.SecretKeyShare == nil {
+		return nil, errors.New("bob dkg output is not initialized")
+	}
+	publicKey, chainCode, tweak, err := deriveChild(output.PublicKey, output.ChainCode, output.Tweak, index)
+	if err != nil {
+		return nil, err
+	}
+	return &BobOutput{
+		PublicKey:      publicKey,
+		SecretKeyShare: output.SecretKeyShare,
+		SeedOtResult:   output.SeedOtResult,
+		ChainCode:      chainCode,
+		Tweak:          tweak,
+	}, nil
+}
+
+// DerivePath applies DeriveChild for each index of path in order
+func (output *BobOutput) DerivePath(path []uint32) (*BobOutput, error) {
+	child := output
+	for _, index := range path {
+		var err error
+		if child, err = child.DeriveChild(index); err != nil {
+			return nil, err
+		}
+	}
+	return child, nil
+}
@@ -0,0 +1,77 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package dkg
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+func TestDerivePublicKeyVector(t *testing.T) {
+	// Test vector 2 of BIP32, chain m -> m/0
+	pkBytes, _ := hex.DecodeString("03cbcaa9c98c877a26977d00825c956a238e8dddfbd322cce4f74b0b5bd6ace4a7")
+	chainCode, _ := hex.DecodeString("60499f801b896d83179a4374aeb7822aaeaceaa0db1f85ee3e904c4defbd9689")
+	expectedPk, _ := hex.DecodeString("02fc9e5af0ac8d9b3cecfe2a888e2117ba3d089d8585886c9c826b6b22a98d12ea")
+	expectedChainCode, _ := hex.DecodeString("f0909affaa7ee7abe5dd4e100598d4dc53cd709d5a5c2cac40e7412f232f7c9c")
+
+	pk, err := curves.K256().Point.FromAffineCompressed(pkBytes)
+	require.NoError(t, err)
+	child, childChainCode, err := DerivePublicKey(pk, chainCode, 0)
+	require.NoError(t, err)
+	require.Equal(t, expectedPk, child.ToAffineCompressed())
+	require.Equal(t, expectedChainCode, childChainCode)
+
+	_, _, err = DerivePublicKey(pk, chainCode, HardenedKeyStart)
+	require.Error(t, err)
+	_, _, err = DerivePublicKey(pk, chainCode[:16], 0)
+	require.Error(t, err)
+	_, _, err = DerivePublicKey(curves.ED25519().Point.Generator(), chainCode, 0)
+	require.Error(t, err)
+}
+
+func TestDeriveOutputs(t *testing.T) {
+	curve := curves.K256()
+	skA := curve.Scalar.Random(crand.Reader)
+	skB := curve.Scalar.Random(crand.Reader)
+	pk := curve.ScalarBaseMult(skA.Mul(skB))
+	chainCode := make([]byte, ChainCodeSize)
+	chainCode[0] = 0xcb
+	alice := &AliceOutput{PublicKey: pk, SecretKeyShare: skA, ChainCode: chainCode}
+	bob := &BobOutput{PublicKey: pk, SecretKeyShare: skB, ChainCode: chainCode}
+
+	path := []uint32{44, 60, 0, 0, 7}
+	aliceChild, err := alice.DerivePath(path)
+	require.NoError(t, err)
+	bobChild, err := bob.DerivePath(path)
+	require.NoError(t, err)
+	require.True(t, aliceChild.PublicKey.Equal(bobChild.PublicKey))
+	require.Equal(t, aliceChild.ChainCode, bobChild.ChainCode)
+	require.Equal(t, 0, aliceChild.Tweak.Cmp(bobChild.Tweak))
+
+	// The joint secret of the child is skA * skB + tweak
+	sk := aliceChild.SecretKeyShare.Mul(bobChild.SecretKeyShare).Add(aliceChild.Tweak)
+	require.True(t, curve.ScalarBaseMult(sk).Equal(aliceChild.PublicKey))
+
+	// Public derivation agrees with the parties
+	childPk, cc := pk, chainCode
+	for _, index := range path {
+		childPk, cc, err = DerivePublicKey(childPk, cc, index)
+		require.NoError(t, err)
+	}
+	require.True(t, childPk.Equal(aliceChild.PublicKey))
+	require.Equal(t, cc, aliceChild.ChainCode)
+
+	_, err = (&AliceOutput{}).DeriveChild(0)
+	require.Error(t, err)
+	_, err = alice.DeriveChild(HardenedKeyStart + 1)
+	require.Error(t, err)
+}
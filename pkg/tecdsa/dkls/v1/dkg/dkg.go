@@ -12,10 +12,10 @@ package dkg
 import (
 	"crypto/rand"
 
-	"github.com/gtank/merlin"
 	"github.com/pkg/errors"
 
 	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/transcript"
 	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
 	"github.com/coinbase/kryptology/pkg/ot/extension/kos"
 	"github.com/coinbase/kryptology/pkg/zkp/schnorr"
@@ -36,6 +36,15 @@ type AliceOutput struct {
 	// This output must be kept secret. Although, if it is lost the users can run another OT protocol and obtain
 	// new values to replace it.
 	SeedOtResult *simplest.ReceiverOutput
+
+	// ChainCode is the BIP32 chain code of PublicKey. It is jointly derived during DKG.
+	// This value is public.
+	ChainCode []byte
+
+	// Tweak is the sum of the BIP32 tweaks applied to derive PublicKey from the key output by DKG, so that
+	// the joint secret key is the product of the secret key shares plus Tweak. It is nil for the DKG key.
+	// This value is public.
+	Tweak curves.Scalar
 }
 
 // BobOutput is the result of running DKG for Bob. It contains both the public and secret values that are needed
@@ -53,6 +62,15 @@ type BobOutput struct {
 	// This output must be kept secret. Although, if it is lost the users can run another OT protocol and obtain
 	// new values to replace it.
 	SeedOtResult *simplest.SenderOutput
+
+	// ChainCode is the BIP32 chain code of PublicKey. It is jointly derived during DKG.
+	// This value is public.
+	ChainCode []byte
+
+	// Tweak is the sum of the BIP32 tweaks applied to derive PublicKey from the key output by DKG, so that
+	// the joint secret key is the product of the secret key shares plus Tweak. It is nil for the DKG key.
+	// This value is public.
+	Tweak curves.Scalar
 }
 
 // Alice struct encoding Alice's state during one execution of the overall signing algorithm.
@@ -73,9 +91,12 @@ type Alice struct {
 	// publicKey is the joint public key of Alice and Bob.
 	publicKey curves.Point
 
+	// chainCode is the BIP32 chain code of the joint public key.
+	chainCode []byte
+
 	curve *curves.Curve
 
-	transcript *merlin.Transcript
+	transcript *transcript.Transcript
 }
 
 // Bob struct encoding Bob's state during one execution of the overall signing algorithm.
@@ -98,9 +119,12 @@ type Bob struct {
 	// 32-byte transcript salt which will be used for Alice's schnorr proof
 	aliceSalt [simplest.DigestSize]byte
 
+	// chainCode is the BIP32 chain code of the joint public key.
+	chainCode []byte
+
 	curve *curves.Curve
 
-	transcript *merlin.Transcript
+	transcript *transcript.Transcript
 }
 
 // Round2Output contains the output of the 2nd round of DKG.
@@ -116,7 +140,7 @@ type Round2Output struct {
 func NewAlice(curve *curves.Curve) *Alice {
 	return &Alice{
 		curve:      curve,
-		transcript: merlin.NewTranscript("Coinbase_DKLs_DKG"),
+		transcript: transcript.NewTranscript("Coinbase_DKLs_DKG"),
 	}
 }
 
@@ -125,7 +149,7 @@ func NewAlice(curve *curves.Curve) *Alice {
 func NewBob(curve *curves.Curve) *Bob {
 	return &Bob{
 		curve:      curve,
-		transcript: merlin.NewTranscript("Coinbase_DKLs_DKG"),
+		transcript: transcript.NewTranscript("Coinbase_DKLs_DKG"),
 	}
 }
 
@@ -192,6 +216,7 @@ func (bob *Bob) Round3SchnorrProve(round2Output *Round2Output) (*schnorr.Proof,
 	copy(bob.aliceSalt[:], bob.transcript.ExtractBytes([]byte("salt for alice schnorr"), simplest.DigestSize))
 	bob.secretKeyShare = bob.curve.Scalar.Random(rand.Reader)
 	copy(uniqueSessionId[:], bob.transcript.ExtractBytes([]byte("salt for bob schnorr"), simplest.DigestSize))
+	bob.chainCode = bob.transcript.ExtractBytes([]byte("chain code"), ChainCodeSize)
 	bob.prover = schnorr.NewProver(bob.curve, nil, uniqueSessionId[:])
 	proof, err := bob.prover.Prove(bob.secretKeyShare)
 	if err != nil {
@@ -208,6 +233,7 @@ func (alice *Alice) Round4VerifyAndReveal(proof *schnorr.Proof) (*schnorr.Proof,
 	if err = schnorr.Verify(proof, alice.curve, nil, uniqueSessionId[:]); err != nil {
 		return nil, errors.Wrap(err, "alice's verification of Bob's schnorr proof failed in DKG round 3")
 	}
+	alice.chainCode = alice.transcript.ExtractBytes([]byte("chain code"), ChainCodeSize)
 	alice.publicKey = proof.Statement.Mul(alice.secretKeyShare)
 	return alice.proof, nil
 }
@@ -258,6 +284,7 @@ func (alice *Alice) Output() *AliceOutput {
 		PublicKey:      alice.publicKey,
 		SecretKeyShare: alice.secretKeyShare,
 		SeedOtResult:   alice.receiver.Output,
+		ChainCode:      alice.chainCode,
 	}
 }
 
@@ -268,5 +295,6 @@ func (bob *Bob) Output() *BobOutput {
 		PublicKey:      bob.publicKey,
 		SecretKeyShare: bob.secretKeyShare,
 		SeedOtResult:   bob.sender.Output,
+		ChainCode:      bob.chainCode,
 	}
 }
@@ -0,0 +1,139 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package dkg
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/transcript"
+	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
+	"github.com/coinbase/kryptology/pkg/zkp/schnorr"
+)
+
+func init() {
+	gob.Register(&curves.ScalarK256{})
+	gob.Register(&curves.PointK256{})
+	gob.Register(&curves.ScalarP256{})
+	gob.Register(&curves.PointP256{})
+}
+
+// The schnorr provers are not part of the states below; they are only used in the round that creates them.
+
+type aliceState struct {
+	Proof          *schnorr.Proof
+	Receiver       *simplest.Receiver
+	SecretKeyShare curves.Scalar
+	PublicKey      curves.Point
+	ChainCode      []byte
+	Curve          string
+	Transcript     *transcript.Transcript
+}
+
+type bobState struct {
+	Sender          *simplest.Sender
+	SecretKeyShare  curves.Scalar
+	PublicKey       curves.Point
+	AliceCommitment schnorr.Commitment
+	AliceSalt       [simplest.DigestSize]byte
+	ChainCode       []byte
+	Curve           string
+	Transcript      *transcript.Transcript
+}
+
+// MarshalBinary serializes the state of Alice between two rounds of DKG.
+// The state contains Alice's secret key share and must be kept secret.
+func (alice *Alice) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := gob.NewEncoder(buf).Encode(&aliceState{
+		Proof:          alice.proof,
+		Receiver:       alice.receiver,
+		SecretKeyShare: alice.secretKeyShare,
+		PublicKey:      alice.publicKey,
+		ChainCode:      alice.chainCode,
+		Curve:          alice.curve.Name,
+		Transcript:     alice.transcript,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding alice dkg state")
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the state of Alice serialized by MarshalBinary.
+func (alice *Alice) UnmarshalBinary(data []byte) error {
+	state := new(aliceState)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(state); err != nil {
+		return errors.Wrap(err, "decoding alice dkg state")
+	}
+	curve := curves.GetCurveByName(state.Curve)
+	if curve == nil {
+		return errors.Errorf("unknown curve %s", state.Curve)
+	}
+	if state.Transcript == nil {
+		return errors.New("alice dkg state has no transcript")
+	}
+	*alice = Alice{
+		proof:          state.Proof,
+		receiver:       state.Receiver,
+		secretKeyShare: state.SecretKeyShare,
+		publicKey:      state.PublicKey,
+		chainCode:      state.ChainCode,
+		curve:          curve,
+		transcript:     state.Transcript,
+	}
+	return nil
+}
+
+// MarshalBinary serializes the state of Bob between two rounds of DKG.
+// The state contains Bob's secret key share and must be kept secret.
+func (bob *Bob) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := gob.NewEncoder(buf).Encode(&bobState{
+		Sender:          bob.sender,
+		SecretKeyShare:  bob.secretKeyShare,
+		PublicKey:       bob.publicKey,
+		AliceCommitment: bob.aliceCommitment,
+		AliceSalt:       bob.aliceSalt,
+		ChainCode:       bob.chainCode,
+		Curve:           bob.curve.Name,
+		Transcript:      bob.transcript,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding bob dkg state")
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the state of Bob serialized by MarshalBinary.
+func (bob *Bob) UnmarshalBinary(data []byte) error {
+	state := new(bobState)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(state); err != nil {
+		return errors.Wrap(err, "decoding bob dkg state")
+	}
+	curve := curves.GetCurveByName(state.Curve)
+	if curve == nil {
+		return errors.Errorf("unknown curve %s", state.Curve)
+	}
+	if state.Transcript == nil {
+		return errors.New("bob dkg state has no transcript")
+	}
+	*bob = Bob{
+		sender:          state.Sender,
+		secretKeyShare:  state.SecretKeyShare,
+		publicKey:       state.PublicKey,
+		aliceCommitment: state.AliceCommitment,
+		aliceSalt:       state.AliceSalt,
+		chainCode:       state.ChainCode,
+		curve:           curve,
+		transcript:      state.Transcript,
+	}
+	return nil
+}
@@ -1,7 +1,6 @@
 package v1
 
 import (
-	"bytes"
 	"encoding/gob"
 	"fmt"
 
@@ -35,284 +34,182 @@ func registerTypes() {
 }
 
 func encodeDkgRound1Output(commitment [32]byte, version uint) (*protocol.Message, error) {
-	if version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	registerTypes()
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(&commitment); err != nil {
-		return nil, errors.WithStack(err)
+	payload, err := encodePayload(version, &commitment, func(w *wireWriter) { w.bytes(commitment[:]) })
+	if err != nil {
+		return nil, err
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "1", version), nil
+	return newDkgProtocolMessage(payload, "1", version), nil
 }
 
 func decodeDkgRound2Input(m *protocol.Message) ([32]byte, error) {
-	if m.Version != protocol.Version1 {
-		return [32]byte{}, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := [32]byte{}
-	if err := dec.Decode(&decoded); err != nil {
-		return [32]byte{}, errors.WithStack(err)
+	if err := decodeMessage(m, &decoded, func(r *wireReader) { r.fixed(decoded[:]) }); err != nil {
+		return [32]byte{}, err
 	}
 	return decoded, nil
 }
 
 func encodeDkgRound2Output(output *dkg.Round2Output, version uint) (*protocol.Message, error) {
-	if version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(output); err != nil {
-		return nil, errors.WithStack(err)
+	payload, err := encodePayload(version, output, func(w *wireWriter) { writeDkgRound2Output(w, output) })
+	if err != nil {
+		return nil, err
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "2", version), nil
+	return newDkgProtocolMessage(payload, "2", version), nil
 }
 
 func decodeDkgRound3Input(m *protocol.Message) (*dkg.Round2Output, error) {
-	if m.Version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(dkg.Round2Output)
-	if err := dec.Decode(decoded); err != nil {
-		return nil, errors.WithStack(err)
+	if err := decodeMessage(m, decoded, func(r *wireReader) { readDkgRound2Output(r, decoded) }); err != nil {
+		return nil, err
 	}
 	return decoded, nil
 }
 
 func encodeDkgRound3Output(proof *schnorr.Proof, version uint) (*protocol.Message, error) {
-	if version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(proof); err != nil {
-		return nil, errors.WithStack(err)
+	payload, err := encodePayload(version, proof, func(w *wireWriter) { writeSchnorrProof(w, proof) })
+	if err != nil {
+		return nil, err
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "3", version), nil
+	return newDkgProtocolMessage(payload, "3", version), nil
 }
 
-func decodeDkgRound4Input(m *protocol.Message) (*schnorr.Proof, error) {
-	if m.Version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
+// decodeSchnorrProof decodes the proof sent by rounds 3 to 5
+func decodeSchnorrProof(m *protocol.Message) (*schnorr.Proof, error) {
 	decoded := new(schnorr.Proof)
-	if err := dec.Decode(decoded); err != nil {
-		return nil, errors.WithStack(err)
+	if err := decodeMessage(m, decoded, func(r *wireReader) {
+		if proof := readSchnorrProof(r); proof != nil {
+			*decoded = *proof
+		}
+	}); err != nil {
+		return nil, err
 	}
 	return decoded, nil
 }
 
+func decodeDkgRound4Input(m *protocol.Message) (*schnorr.Proof, error) {
+	return decodeSchnorrProof(m)
+}
+
 func encodeDkgRound4Output(proof *schnorr.Proof, version uint) (*protocol.Message, error) {
-	if version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(proof); err != nil {
-		return nil, errors.WithStack(err)
+	payload, err := encodePayload(version, proof, func(w *wireWriter) { writeSchnorrProof(w, proof) })
+	if err != nil {
+		return nil, err
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "4", version), nil
+	return newDkgProtocolMessage(payload, "4", version), nil
 }
 
 func decodeDkgRound5Input(m *protocol.Message) (*schnorr.Proof, error) {
-	if m.Version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
-	decoded := new(schnorr.Proof)
-	if err := dec.Decode(decoded); err != nil {
-		return nil, errors.WithStack(err)
-	}
-	return decoded, nil
+	return decodeSchnorrProof(m)
 }
 
 func encodeDkgRound5Output(proof *schnorr.Proof, version uint) (*protocol.Message, error) {
-	if version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(proof); err != nil {
-		return nil, errors.WithStack(err)
+	payload, err := encodePayload(version, proof, func(w *wireWriter) { writeSchnorrProof(w, proof) })
+	if err != nil {
+		return nil, err
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "5", version), nil
+	return newDkgProtocolMessage(payload, "5", version), nil
 }
 
 func decodeDkgRound6Input(m *protocol.Message) (*schnorr.Proof, error) {
-	if m.Version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
-	decoded := new(schnorr.Proof)
-	if err := dec.Decode(decoded); err != nil {
-		return nil, errors.WithStack(err)
-	}
-	return decoded, nil
+	return decodeSchnorrProof(m)
 }
 
 func encodeDkgRound6Output(choices []simplest.ReceiversMaskedChoices, version uint) (*protocol.Message, error) {
-	if version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(choices); err != nil {
-		return nil, errors.WithStack(err)
+	payload, err := encodePayload(version, choices, func(w *wireWriter) { writeMaskedChoices(w, choices) })
+	if err != nil {
+		return nil, err
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "6", version), nil
+	return newDkgProtocolMessage(payload, "6", version), nil
 }
 
 func decodeDkgRound7Input(m *protocol.Message) ([]simplest.ReceiversMaskedChoices, error) {
-	if m.Version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
-	decoded := []simplest.ReceiversMaskedChoices{}
-	if err := dec.Decode(&decoded); err != nil {
-		return nil, errors.WithStack(err)
+	var decoded []simplest.ReceiversMaskedChoices
+	if err := decodeMessage(m, &decoded, func(r *wireReader) { decoded = readMaskedChoices(r) }); err != nil {
+		return nil, err
 	}
 	return decoded, nil
 }
 
 func encodeDkgRound7Output(challenge []simplest.OtChallenge, version uint) (*protocol.Message, error) {
-	if version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(challenge); err != nil {
-		return nil, errors.WithStack(err)
+	payload, err := encodePayload(version, challenge, func(w *wireWriter) { writeDigests(w, challenge) })
+	if err != nil {
+		return nil, err
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "7", version), nil
+	return newDkgProtocolMessage(payload, "7", version), nil
 }
 
 func decodeDkgRound8Input(m *protocol.Message) ([]simplest.OtChallenge, error) {
-	if m.Version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
-	decoded := []simplest.OtChallenge{}
-	if err := dec.Decode(&decoded); err != nil {
-		return nil, errors.WithStack(err)
+	var decoded []simplest.OtChallenge
+	if err := decodeMessage(m, &decoded, func(r *wireReader) { decoded = readDigests(r) }); err != nil {
+		return nil, err
 	}
 	return decoded, nil
 }
 
 func encodeDkgRound8Output(responses []simplest.OtChallengeResponse, version uint) (*protocol.Message, error) {
-	if version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(responses); err != nil {
-		return nil, errors.WithStack(err)
+	payload, err := encodePayload(version, responses, func(w *wireWriter) { writeDigests(w, responses) })
+	if err != nil {
+		return nil, err
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "8", version), nil
+	return newDkgProtocolMessage(payload, "8", version), nil
 }
 
 func decodeDkgRound9Input(m *protocol.Message) ([]simplest.OtChallengeResponse, error) {
-	if m.Version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
-	decoded := []simplest.OtChallengeResponse{}
-	if err := dec.Decode(&decoded); err != nil {
-		return nil, errors.WithStack(err)
+	var decoded []simplest.OtChallengeResponse
+	if err := decodeMessage(m, &decoded, func(r *wireReader) { decoded = readDigests(r) }); err != nil {
+		return nil, err
 	}
 	return decoded, nil
 }
 
 func encodeDkgRound9Output(opening []simplest.ChallengeOpening, version uint) (*protocol.Message, error) {
-	if version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(opening); err != nil {
-		return nil, errors.WithStack(err)
+	payload, err := encodePayload(version, opening, func(w *wireWriter) { writeDigestPairs(w, opening) })
+	if err != nil {
+		return nil, err
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "9", version), nil
+	return newDkgProtocolMessage(payload, "9", version), nil
 }
 
 func decodeDkgRound10Input(m *protocol.Message) ([]simplest.ChallengeOpening, error) {
-	if m.Version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
-	decoded := []simplest.ChallengeOpening{}
-	if err := dec.Decode(&decoded); err != nil {
-		return nil, errors.WithStack(err)
+	var decoded []simplest.ChallengeOpening
+	if err := decodeMessage(m, &decoded, func(r *wireReader) { decoded = readDigestPairs(r) }); err != nil {
+		return nil, err
 	}
 	return decoded, nil
 }
 
 // EncodeAliceDkgOutput serializes Alice DKG output based on the protocol version.
 func EncodeAliceDkgOutput(result *dkg.AliceOutput, version uint) (*protocol.Message, error) {
-	if version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	registerTypes()
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(result); err != nil {
-		return nil, errors.WithStack(err)
+	payload, err := encodePayload(version, result, func(w *wireWriter) { writeAliceOutput(w, result) })
+	if err != nil {
+		return nil, err
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "alice-output", version), nil
+	return newDkgProtocolMessage(payload, "alice-output", version), nil
 }
 
 // DecodeAliceDkgResult deserializes Alice DKG output.
 func DecodeAliceDkgResult(m *protocol.Message) (*dkg.AliceOutput, error) {
-	if m.Version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	registerTypes()
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(dkg.AliceOutput)
-	if err := dec.Decode(&decoded); err != nil {
-		return nil, errors.WithStack(err)
+	if err := decodeMessage(m, decoded, func(r *wireReader) { readAliceOutput(r, decoded) }); err != nil {
+		return nil, err
 	}
 	return decoded, nil
 }
 
 // EncodeBobDkgOutput serializes Bob DKG output based on the protocol version.
 func EncodeBobDkgOutput(result *dkg.BobOutput, version uint) (*protocol.Message, error) {
-	if version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	registerTypes()
-	buf := bytes.NewBuffer([]byte{})
-	enc := gob.NewEncoder(buf)
-	if err := enc.Encode(result); err != nil {
-		return nil, errors.WithStack(err)
+	payload, err := encodePayload(version, result, func(w *wireWriter) { writeBobOutput(w, result) })
+	if err != nil {
+		return nil, err
 	}
-	return newDkgProtocolMessage(buf.Bytes(), "bob-output", version), nil
+	return newDkgProtocolMessage(payload, "bob-output", version), nil
 }
 
 // DecodeBobDkgResult deserializes Bob DKG output.
 func DecodeBobDkgResult(m *protocol.Message) (*dkg.BobOutput, error) {
-	if m.Version != protocol.Version1 {
-		return nil, errors.New("only version 1 is supported")
-	}
-	buf := bytes.NewBuffer(m.Payloads[payloadKey])
-	dec := gob.NewDecoder(buf)
 	decoded := new(dkg.BobOutput)
-	if err := dec.Decode(&decoded); err != nil {
-		return nil, errors.WithStack(err)
+	if err := decodeMessage(m, decoded, func(r *wireReader) { readBobOutput(r, decoded) }); err != nil {
+		return nil, err
 	}
 	return decoded, nil
 }
@@ -0,0 +1,381 @@
+package v1
+
+import (
+	"hash"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/multiparty"
+)
+
+// The multi-party protocols output one message per round with a payload for every recipient, keyed by the id of
+// the recipient, or a single payload with key protocol.BroadcastKey. Their inputs hold the payloads the other parties
+// sent to this party in the previous round, keyed by the id of the sender. RouteMultiPartyMessages converts the
+// outputs of all parties of a round to the inputs of the next round.
+
+// MultiPartyDkg t-of-n DKLs DKG implementation that satisfies the protocol iterator interface.
+type MultiPartyDkg struct {
+	protoStepper
+	*multiparty.Dkg
+}
+
+// MultiPartySign t-of-n DKLs sign implementation that satisfies the protocol iterator interface.
+type MultiPartySign struct {
+	protoStepper
+	*multiparty.Signer
+}
+
+// MultiPartyRefresh t-of-n DKLs refresh implementation that satisfies the protocol iterator interface.
+type MultiPartyRefresh struct {
+	protoStepper
+	*multiparty.Dkg
+}
+
+var (
+	// Static type assertions
+	_ protocol.Iterator = &MultiPartyDkg{}
+	_ protocol.Iterator = &MultiPartySign{}
+	_ protocol.Iterator = &MultiPartyRefresh{}
+)
+
+// NewMultiPartyDkg creates a new protocol that can compute a t-of-n DKG as the party with identifier id among parties.
+func NewMultiPartyDkg(curve *curves.Curve, id, threshold uint32, parties []uint32, version uint) (*MultiPartyDkg, error) {
+	d, err := multiparty.NewDkg(curve, id, threshold, parties)
+	if err != nil {
+		return nil, err
+	}
+	p := &MultiPartyDkg{Dkg: d}
+	p.steps = newMultiPartyDkgSteps(d, id, protocol.Dkls19Dkg, version)
+	return p, nil
+}
+
+// Result returns the encoded DKG output of this party that can be used to initialize a MultiPartySign protocol.
+func (p *MultiPartyDkg) Result(version uint) (*protocol.Message, error) {
+	if !p.complete() {
+		return nil, nil
+	}
+	if p.Dkg == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return EncodeMultiPartyDkgOutput(p.Output(), version)
+}
+
+// NewMultiPartyRefresh creates a new protocol that can refresh the shares of a t-of-n DKG result. All parties of
+// the DKG must take part.
+func NewMultiPartyRefresh(curve *curves.Curve, dkgResultMessage *protocol.Message, version uint) (*MultiPartyRefresh, error) {
+	dkgResult, err := DecodeMultiPartyDkgResult(dkgResultMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	d, err := multiparty.NewRefresh(curve, dkgResult)
+	if err != nil {
+		return nil, err
+	}
+	p := &MultiPartyRefresh{Dkg: d}
+	p.steps = newMultiPartyDkgSteps(d, dkgResult.Id, protocol.Dkls19Refresh, version)
+	return p, nil
+}
+
+// Result returns the encoded refreshed output of this party that can be used to initialize a MultiPartySign protocol.
+func (p *MultiPartyRefresh) Result(version uint) (*protocol.Message, error) {
+	if !p.complete() {
+		return nil, nil
+	}
+	if p.Dkg == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return EncodeMultiPartyDkgOutput(p.Output(), version)
+}
+
+// newMultiPartyDkgSteps returns the steps shared by DKG and refresh
+func newMultiPartyDkgSteps(d *multiparty.Dkg, id uint32, protocolName string, version uint) []func(*protocol.Message) (*protocol.Message, error) {
+	var seed [simplest.DigestSize]byte
+	return []func(*protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			var err error
+			if seed, err = d.Round1GenerateRandomSeed(); err != nil {
+				return nil, err
+			}
+			output := newMultiPartyProtocolMessage(protocolName, "1", version)
+			return output, addMultiPartyPayloadWithKey(output, protocol.BroadcastKey, seed, func(w *wireWriter) { w.bytes(seed[:]) })
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			seeds := map[uint32][simplest.DigestSize]byte{id: seed}
+			err := decodeMultiPartyPayloads(input, protocolName, func(id uint32, payload []byte) error {
+				var decoded [simplest.DigestSize]byte
+				if err := decodePayload(input.Version, payload, &decoded, func(r *wireReader) { r.fixed(decoded[:]) }); err != nil {
+					return err
+				}
+				seeds[id] = decoded
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			round2Output, err := d.Round2Share(seeds)
+			if err != nil {
+				return nil, err
+			}
+			output := newMultiPartyProtocolMessage(protocolName, "2", version)
+			for id, value := range round2Output {
+				if err = addMultiPartyPayload(output, id, value, func(w *wireWriter) { writeMultiPartyDkgRound2Output(w, value) }); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round3Input := make(map[uint32]*multiparty.DkgRound2Output)
+			err := decodeMultiPartyPayloads(input, protocolName, func(id uint32, payload []byte) error {
+				decoded := new(multiparty.DkgRound2Output)
+				round3Input[id] = decoded
+				return decodePayload(input.Version, payload, decoded, func(r *wireReader) { readMultiPartyDkgRound2Output(r, decoded) })
+			})
+			if err != nil {
+				return nil, err
+			}
+			round3Output, err := d.Round3Verify(round3Input)
+			if err != nil {
+				return nil, err
+			}
+			output := newMultiPartyProtocolMessage(protocolName, "3", version)
+			for id, value := range round3Output {
+				if err = addMultiPartyPayload(output, id, value, func(w *wireWriter) { writeMaskedChoices(w, value) }); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round4Input := make(map[uint32][]simplest.ReceiversMaskedChoices)
+			err := decodeMultiPartyPayloads(input, protocolName, func(id uint32, payload []byte) error {
+				var decoded []simplest.ReceiversMaskedChoices
+				if err := decodePayload(input.Version, payload, &decoded, func(r *wireReader) { decoded = readMaskedChoices(r) }); err != nil {
+					return err
+				}
+				round4Input[id] = decoded
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			round4Output, err := d.Round4DkgRound3Ot(round4Input)
+			if err != nil {
+				return nil, err
+			}
+			output := newMultiPartyProtocolMessage(protocolName, "4", version)
+			for id, value := range round4Output {
+				if err = addMultiPartyPayload(output, id, value, func(w *wireWriter) { writeDigests(w, value) }); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round5Input := make(map[uint32][]simplest.OtChallenge)
+			err := decodeMultiPartyPayloads(input, protocolName, func(id uint32, payload []byte) error {
+				var decoded []simplest.OtChallenge
+				if err := decodePayload(input.Version, payload, &decoded, func(r *wireReader) { decoded = readDigests(r) }); err != nil {
+					return err
+				}
+				round5Input[id] = decoded
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			round5Output, err := d.Round5DkgRound4Ot(round5Input)
+			if err != nil {
+				return nil, err
+			}
+			output := newMultiPartyProtocolMessage(protocolName, "5", version)
+			for id, value := range round5Output {
+				if err = addMultiPartyPayload(output, id, value, func(w *wireWriter) { writeDigests(w, value) }); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round6Input := make(map[uint32][]simplest.OtChallengeResponse)
+			err := decodeMultiPartyPayloads(input, protocolName, func(id uint32, payload []byte) error {
+				var decoded []simplest.OtChallengeResponse
+				if err := decodePayload(input.Version, payload, &decoded, func(r *wireReader) { decoded = readDigests(r) }); err != nil {
+					return err
+				}
+				round6Input[id] = decoded
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			round6Output, err := d.Round6DkgRound5Ot(round6Input)
+			if err != nil {
+				return nil, err
+			}
+			output := newMultiPartyProtocolMessage(protocolName, "6", version)
+			for id, value := range round6Output {
+				if err = addMultiPartyPayload(output, id, value, func(w *wireWriter) { writeDigestPairs(w, value) }); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round7Input := make(map[uint32][]simplest.ChallengeOpening)
+			err := decodeMultiPartyPayloads(input, protocolName, func(id uint32, payload []byte) error {
+				var decoded []simplest.ChallengeOpening
+				if err := decodePayload(input.Version, payload, &decoded, func(r *wireReader) { decoded = readDigestPairs(r) }); err != nil {
+					return err
+				}
+				round7Input[id] = decoded
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			return nil, d.Round7DkgRound6Ot(round7Input)
+		},
+	}
+}
+
+// NewMultiPartySign creates a new protocol that can compute a signature together with the parties in signers.
+// Requires the dkg state that was produced at the end of MultiPartyDkg or MultiPartyRefresh.
+func NewMultiPartySign(curve *curves.Curve, hash hash.Hash, message []byte, dkgResultMessage *protocol.Message, signers []uint32, version uint) (*MultiPartySign, error) {
+	dkgResult, err := DecodeMultiPartyDkgResult(dkgResultMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	signer, err := multiparty.NewSigner(curve, hash, dkgResult, signers)
+	if err != nil {
+		return nil, err
+	}
+	p := &MultiPartySign{Signer: signer}
+	var seed [simplest.DigestSize]byte
+	p.steps = []func(*protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			var err error
+			if seed, err = p.Round1GenerateRandomSeed(); err != nil {
+				return nil, err
+			}
+			output := newMultiPartyProtocolMessage(protocol.Dkls19Sign, "1", version)
+			return output, addMultiPartyPayloadWithKey(output, protocol.BroadcastKey, seed, func(w *wireWriter) { w.bytes(seed[:]) })
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			seeds := map[uint32][simplest.DigestSize]byte{dkgResult.Id: seed}
+			err := decodeMultiPartyPayloads(input, protocol.Dkls19Sign, func(id uint32, payload []byte) error {
+				var decoded [simplest.DigestSize]byte
+				if err := decodePayload(input.Version, payload, &decoded, func(r *wireReader) { r.fixed(decoded[:]) }); err != nil {
+					return err
+				}
+				seeds[id] = decoded
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			round2Output, err := p.Round2Initialize(seeds)
+			if err != nil {
+				return nil, err
+			}
+			output := newMultiPartyProtocolMessage(protocol.Dkls19Sign, "2", version)
+			for id, value := range round2Output {
+				if err = addMultiPartyPayload(output, id, value, func(w *wireWriter) { writeMultiPartySignRound2Output(w, value) }); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round3Input := make(map[uint32]*multiparty.SignRound2Output)
+			err := decodeMultiPartyPayloads(input, protocol.Dkls19Sign, func(id uint32, payload []byte) error {
+				decoded := new(multiparty.SignRound2Output)
+				round3Input[id] = decoded
+				return decodePayload(input.Version, payload, decoded, func(r *wireReader) { readMultiPartySignRound2Output(r, decoded) })
+			})
+			if err != nil {
+				return nil, err
+			}
+			round3Output, err := p.Round3Multiply(round3Input)
+			if err != nil {
+				return nil, err
+			}
+			output := newMultiPartyProtocolMessage(protocol.Dkls19Sign, "3", version)
+			for id, value := range round3Output {
+				if err = addMultiPartyPayload(output, id, value, func(w *wireWriter) { writeMultiPartySignRound3Output(w, value) }); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round4Input := make(map[uint32]*multiparty.SignRound3Output)
+			err := decodeMultiPartyPayloads(input, protocol.Dkls19Sign, func(id uint32, payload []byte) error {
+				decoded := new(multiparty.SignRound3Output)
+				round4Input[id] = decoded
+				return decodePayload(input.Version, payload, decoded, func(r *wireReader) { readMultiPartySignRound3Output(r, decoded) })
+			})
+			if err != nil {
+				return nil, err
+			}
+			round4Output, err := p.Round4Check(round4Input)
+			if err != nil {
+				return nil, err
+			}
+			output := newMultiPartyProtocolMessage(protocol.Dkls19Sign, "4", version)
+			for id, value := range round4Output {
+				if err = addMultiPartyPayload(output, id, value, func(w *wireWriter) { writeMultiPartySignRound4Output(w, value) }); err != nil {
+					return nil, err
+				}
+			}
+			return output, nil
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round5Input := make(map[uint32]*multiparty.SignRound4Output)
+			err := decodeMultiPartyPayloads(input, protocol.Dkls19Sign, func(id uint32, payload []byte) error {
+				decoded := new(multiparty.SignRound4Output)
+				round5Input[id] = decoded
+				return decodePayload(input.Version, payload, decoded, func(r *wireReader) { readMultiPartySignRound4Output(r, decoded) })
+			})
+			if err != nil {
+				return nil, err
+			}
+			round5Output, err := p.Round5Combine(message, round5Input)
+			if err != nil {
+				return nil, err
+			}
+			output := newMultiPartyProtocolMessage(protocol.Dkls19Sign, "5", version)
+			return output, addMultiPartyPayloadWithKey(output, protocol.BroadcastKey, round5Output, func(w *wireWriter) {
+				writeMultiPartySignRound5Output(w, round5Output)
+			})
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round6Input := make(map[uint32]*multiparty.SignRound5Output)
+			err := decodeMultiPartyPayloads(input, protocol.Dkls19Sign, func(id uint32, payload []byte) error {
+				decoded := new(multiparty.SignRound5Output)
+				round6Input[id] = decoded
+				return decodePayload(input.Version, payload, decoded, func(r *wireReader) { readMultiPartySignRound5Output(r, decoded) })
+			})
+			if err != nil {
+				return nil, err
+			}
+			return nil, p.Round6Final(round6Input)
+		},
+	}
+	return p, nil
+}
+
+// Result returns the signature as a *curves.EcdsaSignature if the signing protocol completed successfully.
+// Every signer obtains the signature.
+func (p *MultiPartySign) Result(version uint) (*protocol.Message, error) {
+	if !p.complete() {
+		return nil, nil
+	}
+	if p.Signer == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return encodeSignature(p.Signature, version)
+}
@@ -0,0 +1,338 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package multiparty
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/gtank/merlin"
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
+	"github.com/coinbase/kryptology/pkg/ot/extension/kos"
+	"github.com/coinbase/kryptology/pkg/sharing"
+	"github.com/coinbase/kryptology/pkg/zkp/schnorr"
+)
+
+// Dkg encodes the state of one party during one execution of DKG or refresh.
+// DKG is a Feldman VSS based Pedersen DKG where every dealer proves knowledge of its secret with a schnorr proof,
+// followed by a seed OT between every pair of parties. Refresh deals sharings of zero instead and redoes the seed OTs.
+type Dkg struct {
+	id        uint32
+	threshold uint32
+	parties   []uint32
+	curve     *curves.Curve
+
+	// previous is the output refreshed by this protocol, nil for DKG
+	previous *Output
+
+	transcript *merlin.Transcript
+	sessionId  [simplest.DigestSize]byte
+	polynomial *sharing.Polynomial
+
+	secretKeyShare curves.Scalar
+	publicKey      curves.Point
+	publicShares   map[uint32]curves.Point
+
+	// otSenders are the seed OT senders with the parties with a lower id
+	otSenders map[uint32]*simplest.Sender
+	// otReceivers are the seed OT receivers with the parties with a higher id
+	otReceivers map[uint32]*simplest.Receiver
+}
+
+// DkgRound2Output is the message of the 2nd round of DKG from one party to another.
+type DkgRound2Output struct {
+	// Verifier holds the Feldman commitments to the polynomial of the sender. It is broadcast.
+	Verifier *sharing.FeldmanVerifier
+
+	// Proof is the proof of knowledge of the secret of the sender. It is broadcast, and nil in refresh.
+	Proof *schnorr.Proof
+
+	// Share is the share of the recipient of the secret of the sender. It must be sent privately.
+	Share *sharing.ShamirShare
+
+	// SeedOtRound1Output is the first message of the seed OT when the sender has the higher id, nil otherwise.
+	SeedOtRound1Output *schnorr.Proof
+}
+
+// NewDkg creates a party with identifier id that can participate in t-of-n DKG with the given parties.
+func NewDkg(curve *curves.Curve, id, threshold uint32, parties []uint32) (*Dkg, error) {
+	if curve == nil {
+		return nil, fmt.Errorf("curve is nil")
+	}
+	sorted, err := sortedParties(id, parties)
+	if err != nil {
+		return nil, err
+	}
+	if threshold < 2 || threshold > uint32(len(sorted)) {
+		return nil, fmt.Errorf("threshold must be between 2 and the number of parties")
+	}
+	return &Dkg{
+		id:          id,
+		threshold:   threshold,
+		parties:     sorted,
+		curve:       curve,
+		transcript:  merlin.NewTranscript("Coinbase_DKLs19_DKG"),
+		otSenders:   make(map[uint32]*simplest.Sender),
+		otReceivers: make(map[uint32]*simplest.Receiver),
+	}, nil
+}
+
+// NewRefresh creates a party that can participate in a refresh of the shares of output with all the other parties
+// of output. The public key stays the same, while the secret key shares and seed OTs are replaced.
+func NewRefresh(curve *curves.Curve, output *Output) (*Dkg, error) {
+	if output == nil || output.SecretKeyShare == nil || output.PublicKey == nil {
+		return nil, fmt.Errorf("dkg output is not initialized")
+	}
+	dkg, err := NewDkg(curve, output.Id, output.Threshold, output.Parties)
+	if err != nil {
+		return nil, err
+	}
+	dkg.previous = output
+	dkg.transcript = merlin.NewTranscript("Coinbase_DKLs19_Refresh")
+	return dkg, nil
+}
+
+// Round1GenerateRandomSeed is the first step of the generation of the unique session id. Every party flips 32 random
+// bytes and broadcasts them. The session id is secure if any party is honest.
+func (dkg *Dkg) Round1GenerateRandomSeed() ([simplest.DigestSize]byte, error) {
+	seed := [simplest.DigestSize]byte{}
+	if _, err := rand.Read(seed[:]); err != nil {
+		return seed, errors.Wrap(err, "generating random bytes in DKG round 1")
+	}
+	return seed, nil
+}
+
+// Round2Share derives the session id from the seeds of all parties (including this one), deals a Feldman sharing
+// of a random secret (of zero when refreshing) and starts the seed OTs where this party is the OT sender.
+func (dkg *Dkg) Round2Share(seeds map[uint32][simplest.DigestSize]byte) (map[uint32]*DkgRound2Output, error) {
+	if err := checkSenders(dkg.parties, len(seeds), func(id uint32) bool { _, ok := seeds[id]; return ok }); err != nil {
+		return nil, err
+	}
+	for _, id := range dkg.parties {
+		seed := seeds[id]
+		dkg.transcript.AppendMessage([]byte(fmt.Sprintf("session_id_%d", id)), seed[:])
+	}
+	copy(dkg.sessionId[:], dkg.transcript.ExtractBytes([]byte("session id"), simplest.DigestSize))
+
+	secret := dkg.curve.Scalar.Random(rand.Reader)
+	if dkg.previous != nil {
+		secret = dkg.curve.Scalar.Zero()
+	}
+	dkg.polynomial = new(sharing.Polynomial).Init(secret, dkg.threshold, rand.Reader)
+	verifier := &sharing.FeldmanVerifier{Commitments: make([]curves.Point, dkg.threshold)}
+	for i, coefficient := range dkg.polynomial.Coefficients {
+		verifier.Commitments[i] = dkg.curve.ScalarBaseMult(coefficient)
+	}
+	var proof *schnorr.Proof
+	if dkg.previous == nil {
+		var err error
+		salt := subSessionId(dkg.sessionId, "dkg schnorr", dkg.id)
+		if proof, err = schnorr.NewProver(dkg.curve, nil, salt[:]).Prove(secret); err != nil {
+			return nil, errors.Wrap(err, "proving knowledge of the secret in DKG round 2")
+		}
+	}
+
+	output := make(map[uint32]*DkgRound2Output, len(dkg.parties)-1)
+	for _, id := range otherParties(dkg.id, dkg.parties) {
+		output[id] = &DkgRound2Output{
+			Verifier: verifier,
+			Proof:    proof,
+			Share: &sharing.ShamirShare{
+				Id:    id,
+				Value: dkg.polynomial.Evaluate(dkg.curve.Scalar.New(int(id))).Bytes(),
+			},
+		}
+	}
+	for _, id := range lowerPeers(dkg.id, dkg.parties) {
+		var err error
+		dkg.otSenders[id], err = simplest.NewSender(dkg.curve, kos.Kappa, subSessionId(dkg.sessionId, "seed ot", id, dkg.id))
+		if err != nil {
+			return nil, errors.Wrapf(err, "constructing seed OT sender for party %d in DKG round 2", id)
+		}
+		if output[id].SeedOtRound1Output, err = dkg.otSenders[id].Round1ComputeAndZkpToPublicKey(); err != nil {
+			return nil, errors.Wrapf(err, "computing round 1 of seed OT for party %d in DKG round 2", id)
+		}
+	}
+	return output, nil
+}
+
+// Round3Verify verifies the shares and proofs of all other parties, computes the secret key share and the public
+// key, and continues the seed OTs where this party is the OT receiver.
+func (dkg *Dkg) Round3Verify(input map[uint32]*DkgRound2Output) (map[uint32][]simplest.ReceiversMaskedChoices, error) {
+	peers := otherParties(dkg.id, dkg.parties)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return nil, err
+	}
+	x := dkg.curve.Scalar.New(int(dkg.id))
+	secretKeyShare := dkg.polynomial.Evaluate(x)
+	publicKey := dkg.curve.ScalarBaseMult(dkg.polynomial.Coefficients[0])
+	verifiers := make([]*sharing.FeldmanVerifier, 0, len(dkg.parties))
+	for _, id := range peers {
+		message := input[id]
+		if message.Verifier == nil || len(message.Verifier.Commitments) != int(dkg.threshold) || message.Share == nil {
+			return nil, fmt.Errorf("malformed message from party %d", id)
+		}
+		for _, commitment := range message.Verifier.Commitments {
+			if commitment == nil || (!commitment.IsIdentity() && !commitment.IsOnCurve()) {
+				return nil, fmt.Errorf("invalid commitment from party %d", id)
+			}
+		}
+		if dkg.previous == nil {
+			salt := subSessionId(dkg.sessionId, "dkg schnorr", id)
+			if message.Proof == nil || !message.Proof.Statement.Equal(message.Verifier.Commitments[0]) {
+				return nil, fmt.Errorf("missing proof of knowledge from party %d", id)
+			}
+			if err := schnorr.Verify(message.Proof, dkg.curve, nil, salt[:]); err != nil {
+				return nil, errors.Wrapf(err, "verifying the proof of knowledge of party %d", id)
+			}
+		} else if !message.Verifier.Commitments[0].IsIdentity() {
+			return nil, fmt.Errorf("party %d did not share zero", id)
+		}
+		if message.Share.Id != dkg.id {
+			return nil, fmt.Errorf("party %d sent a share for party %d", id, message.Share.Id)
+		}
+		if err := message.Verifier.Verify(message.Share); err != nil {
+			return nil, errors.Wrapf(err, "verifying the share of party %d", id)
+		}
+		share, err := dkg.curve.Scalar.SetBytes(message.Share.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading the share of party %d", id)
+		}
+		secretKeyShare = secretKeyShare.Add(share)
+		publicKey = publicKey.Add(message.Verifier.Commitments[0])
+		verifiers = append(verifiers, message.Verifier)
+	}
+
+	dkg.publicShares = make(map[uint32]curves.Point, len(dkg.parties))
+	for _, id := range dkg.parties {
+		x := dkg.curve.Scalar.New(int(id))
+		publicShare := dkg.curve.ScalarBaseMult(dkg.polynomial.Evaluate(x))
+		for _, verifier := range verifiers {
+			publicShare = publicShare.Add(evaluateCommitments(verifier.Commitments, x))
+		}
+		if dkg.previous != nil {
+			previous, ok := dkg.previous.PublicShares[id]
+			if !ok {
+				return nil, fmt.Errorf("missing public share of party %d", id)
+			}
+			publicShare = publicShare.Add(previous)
+		}
+		dkg.publicShares[id] = publicShare
+	}
+	if dkg.previous != nil {
+		secretKeyShare = secretKeyShare.Add(dkg.previous.SecretKeyShare)
+		publicKey = dkg.previous.PublicKey
+	}
+	if publicKey.IsIdentity() || !dkg.curve.ScalarBaseMult(secretKeyShare).Equal(dkg.publicShares[dkg.id]) {
+		return nil, fmt.Errorf("inconsistent secret key share")
+	}
+	dkg.secretKeyShare = secretKeyShare
+	dkg.publicKey = publicKey
+
+	output := make(map[uint32][]simplest.ReceiversMaskedChoices)
+	for _, id := range higherPeers(dkg.id, dkg.parties) {
+		if input[id].SeedOtRound1Output == nil {
+			return nil, fmt.Errorf("missing seed OT message from party %d", id)
+		}
+		receiver, err := simplest.NewReceiver(dkg.curve, kos.Kappa, subSessionId(dkg.sessionId, "seed ot", dkg.id, id))
+		if err != nil {
+			return nil, errors.Wrapf(err, "constructing seed OT receiver for party %d in DKG round 3", id)
+		}
+		dkg.otReceivers[id] = receiver
+		if output[id], err = receiver.Round2VerifySchnorrAndPadTransfer(input[id].SeedOtRound1Output); err != nil {
+			return nil, errors.Wrapf(err, "seed OT round 2 with party %d", id)
+		}
+	}
+	return output, nil
+}
+
+// Round4DkgRound3Ot runs the 3rd round of seed OT with every party with a lower id.
+func (dkg *Dkg) Round4DkgRound3Ot(input map[uint32][]simplest.ReceiversMaskedChoices) (map[uint32][]simplest.OtChallenge, error) {
+	peers := lowerPeers(dkg.id, dkg.parties)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { _, ok := input[id]; return ok }); err != nil {
+		return nil, err
+	}
+	output := make(map[uint32][]simplest.OtChallenge, len(peers))
+	for _, id := range peers {
+		var err error
+		if output[id], err = dkg.otSenders[id].Round3PadTransfer(input[id]); err != nil {
+			return nil, errors.Wrapf(err, "seed OT round 3 with party %d", id)
+		}
+	}
+	return output, nil
+}
+
+// Round5DkgRound4Ot runs the 4th round of seed OT with every party with a higher id.
+func (dkg *Dkg) Round5DkgRound4Ot(input map[uint32][]simplest.OtChallenge) (map[uint32][]simplest.OtChallengeResponse, error) {
+	peers := higherPeers(dkg.id, dkg.parties)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { _, ok := input[id]; return ok }); err != nil {
+		return nil, err
+	}
+	output := make(map[uint32][]simplest.OtChallengeResponse, len(peers))
+	for _, id := range peers {
+		var err error
+		if output[id], err = dkg.otReceivers[id].Round4RespondToChallenge(input[id]); err != nil {
+			return nil, errors.Wrapf(err, "seed OT round 4 with party %d", id)
+		}
+	}
+	return output, nil
+}
+
+// Round6DkgRound5Ot runs the 5th round of seed OT with every party with a lower id.
+func (dkg *Dkg) Round6DkgRound5Ot(input map[uint32][]simplest.OtChallengeResponse) (map[uint32][]simplest.ChallengeOpening, error) {
+	peers := lowerPeers(dkg.id, dkg.parties)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { _, ok := input[id]; return ok }); err != nil {
+		return nil, err
+	}
+	output := make(map[uint32][]simplest.ChallengeOpening, len(peers))
+	for _, id := range peers {
+		var err error
+		if output[id], err = dkg.otSenders[id].Round5Verify(input[id]); err != nil {
+			return nil, errors.Wrapf(err, "seed OT round 5 with party %d", id)
+		}
+	}
+	return output, nil
+}
+
+// Round7DkgRound6Ot runs the 6th and last round of seed OT with every party with a higher id.
+func (dkg *Dkg) Round7DkgRound6Ot(input map[uint32][]simplest.ChallengeOpening) error {
+	peers := higherPeers(dkg.id, dkg.parties)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { _, ok := input[id]; return ok }); err != nil {
+		return err
+	}
+	for _, id := range peers {
+		if err := dkg.otReceivers[id].Round6Verify(input[id]); err != nil {
+			return errors.Wrapf(err, "seed OT round 6 with party %d", id)
+		}
+	}
+	return nil
+}
+
+// Output returns the output of the DKG or refresh. Must be called after round 7. Calling it before that round
+// has undefined behaviour.
+func (dkg *Dkg) Output() *Output {
+	output := &Output{
+		Id:              dkg.id,
+		Threshold:       dkg.threshold,
+		Parties:         dkg.parties,
+		PublicKey:       dkg.publicKey,
+		PublicShares:    dkg.publicShares,
+		SecretKeyShare:  dkg.secretKeyShare,
+		SeedOtSenders:   make(map[uint32]*simplest.SenderOutput, len(dkg.otSenders)),
+		SeedOtReceivers: make(map[uint32]*simplest.ReceiverOutput, len(dkg.otReceivers)),
+	}
+	for id, sender := range dkg.otSenders {
+		output.SeedOtSenders[id] = sender.Output
+	}
+	for id, receiver := range dkg.otReceivers {
+		output.SeedOtReceivers[id] = receiver.Output
+	}
+	return output
+}
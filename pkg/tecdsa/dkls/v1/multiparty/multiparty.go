@@ -0,0 +1,154 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+// Package multiparty implements the t-of-n generalization of DKLs threshold ECDSA of
+// [DKLs19](https://eprint.iacr.org/2019/523.pdf). The secret key is Shamir shared among n parties and any t
+// of them can sign. Every pair of parties runs a seed OT during DKG, which is later extended with KOS to run the
+// two party multiplication of DKLs18 between every pair of signers.
+//
+// For a pair of parties with ids i < j, party i is the multiplication sender (the seed OT receiver) and party j
+// is the multiplication receiver (the seed OT sender).
+//
+// All rounds take the messages sent by every other party, keyed by the id of the sender, and return the messages
+// to send, keyed by the id of the recipient. Broadcast values are repeated in the message to every recipient, a
+// reliable broadcast channel is assumed.
+package multiparty
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
+)
+
+// Output is the result of running DKG or refresh for one party. It contains both the public and secret values
+// that are needed for signing.
+type Output struct {
+	// Id is the Shamir identifier of this party.
+	Id uint32
+
+	// Threshold is the number of parties needed to sign.
+	Threshold uint32
+
+	// Parties are the ids of all parties holding a share, in ascending order.
+	Parties []uint32
+
+	// PublicKey is the joint public key.
+	// This value is public.
+	PublicKey curves.Point
+
+	// PublicShares maps the id of every party to the public key of its Shamir share.
+	// This value is public.
+	PublicShares map[uint32]curves.Point
+
+	// SecretKeyShare is the Shamir share of this party of the joint secret key.
+	// This output must be kept secret. If t or more parties lose it, the users will lose access and cannot create
+	// signatures.
+	SecretKeyShare curves.Scalar
+
+	// SeedOtSenders are the seed OT outputs with every party with a lower id, for whom this party is the seed OT
+	// sender. This output must be kept secret, a refresh produces new values to replace it.
+	SeedOtSenders map[uint32]*simplest.SenderOutput
+
+	// SeedOtReceivers are the seed OT outputs with every party with a higher id, for whom this party is the seed OT
+	// receiver. This output must be kept secret, a refresh produces new values to replace it.
+	SeedOtReceivers map[uint32]*simplest.ReceiverOutput
+}
+
+// sortedParties validates the party ids and returns them in ascending order
+func sortedParties(id uint32, parties []uint32) ([]uint32, error) {
+	sorted := make([]uint32, len(parties))
+	copy(sorted, parties)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	found := false
+	for i, p := range sorted {
+		if p == 0 {
+			return nil, fmt.Errorf("party id must be greater than zero")
+		}
+		if i > 0 && sorted[i-1] == p {
+			return nil, fmt.Errorf("duplicate party id %d", p)
+		}
+		found = found || p == id
+	}
+	if !found {
+		return nil, fmt.Errorf("party %d is not one of the parties", id)
+	}
+	return sorted, nil
+}
+
+// checkSenders returns an error unless input holds exactly one message from every id in expected
+func checkSenders(expected []uint32, length int, has func(uint32) bool) error {
+	if length != len(expected) {
+		return fmt.Errorf("expected messages from %d parties, got %d", len(expected), length)
+	}
+	for _, id := range expected {
+		if !has(id) {
+			return fmt.Errorf("missing message from party %d", id)
+		}
+	}
+	return nil
+}
+
+// lowerPeers returns the parties with a lower id than id
+func lowerPeers(id uint32, parties []uint32) []uint32 {
+	var peers []uint32
+	for _, p := range parties {
+		if p < id {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// higherPeers returns the parties with a higher id than id
+func higherPeers(id uint32, parties []uint32) []uint32 {
+	var peers []uint32
+	for _, p := range parties {
+		if p > id {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// otherParties returns all parties except id
+func otherParties(id uint32, parties []uint32) []uint32 {
+	var peers []uint32
+	for _, p := range parties {
+		if p != id {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// subSessionId derives the unique session id of a sub-protocol from the session id that all parties agreed on.
+func subSessionId(sessionId [simplest.DigestSize]byte, label string, ids ...uint32) [simplest.DigestSize]byte {
+	hash := sha3.New256()
+	_, _ = hash.Write(sessionId[:])
+	_, _ = hash.Write([]byte(label))
+	for _, id := range ids {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], id)
+		_, _ = hash.Write(b[:])
+	}
+	result := [simplest.DigestSize]byte{}
+	copy(result[:], hash.Sum(nil))
+	return result
+}
+
+// evaluateCommitments evaluates the polynomial committed to by commitments in the exponent at x
+func evaluateCommitments(commitments []curves.Point, x curves.Scalar) curves.Point {
+	result := commitments[len(commitments)-1]
+	for i := len(commitments) - 2; i >= 0; i-- {
+		result = result.Mul(x).Add(commitments[i])
+	}
+	return result
+}
@@ -0,0 +1,392 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package multiparty
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
+	"github.com/coinbase/kryptology/pkg/sharing"
+)
+
+func runDkg(t *testing.T, parties map[uint32]*Dkg) map[uint32]*Output {
+	seeds := make(map[uint32][simplest.DigestSize]byte, len(parties))
+	for id, p := range parties {
+		seed, err := p.Round1GenerateRandomSeed()
+		require.NoError(t, err)
+		seeds[id] = seed
+	}
+
+	// Every round delivers the message that the sender addressed to each recipient
+	round2 := make(map[uint32]map[uint32]*DkgRound2Output, len(parties))
+	for id := range parties {
+		round2[id] = make(map[uint32]*DkgRound2Output)
+	}
+	for sender, p := range parties {
+		out, err := p.Round2Share(seeds)
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round2[recipient][sender] = message
+		}
+	}
+	round3 := make(map[uint32]map[uint32][]simplest.ReceiversMaskedChoices, len(parties))
+	for id := range parties {
+		round3[id] = make(map[uint32][]simplest.ReceiversMaskedChoices)
+	}
+	for sender, p := range parties {
+		out, err := p.Round3Verify(round2[sender])
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round3[recipient][sender] = message
+		}
+	}
+	round4 := make(map[uint32]map[uint32][]simplest.OtChallenge, len(parties))
+	for id := range parties {
+		round4[id] = make(map[uint32][]simplest.OtChallenge)
+	}
+	for sender, p := range parties {
+		out, err := p.Round4DkgRound3Ot(round3[sender])
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round4[recipient][sender] = message
+		}
+	}
+	round5 := make(map[uint32]map[uint32][]simplest.OtChallengeResponse, len(parties))
+	for id := range parties {
+		round5[id] = make(map[uint32][]simplest.OtChallengeResponse)
+	}
+	for sender, p := range parties {
+		out, err := p.Round5DkgRound4Ot(round4[sender])
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round5[recipient][sender] = message
+		}
+	}
+	round6 := make(map[uint32]map[uint32][]simplest.ChallengeOpening, len(parties))
+	for id := range parties {
+		round6[id] = make(map[uint32][]simplest.ChallengeOpening)
+	}
+	for sender, p := range parties {
+		out, err := p.Round6DkgRound5Ot(round5[sender])
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round6[recipient][sender] = message
+		}
+	}
+	outputs := make(map[uint32]*Output, len(parties))
+	for id, p := range parties {
+		require.NoError(t, p.Round7DkgRound6Ot(round6[id]))
+		outputs[id] = p.Output()
+	}
+	return outputs
+}
+
+// runDkgRound2 runs DKG up to round 2 and returns the inputs of round 3 of every party
+func runDkgRound2(t *testing.T, parties map[uint32]*Dkg) map[uint32]map[uint32]*DkgRound2Output {
+	seeds := make(map[uint32][simplest.DigestSize]byte, len(parties))
+	for id, p := range parties {
+		seed, err := p.Round1GenerateRandomSeed()
+		require.NoError(t, err)
+		seeds[id] = seed
+	}
+	round2 := make(map[uint32]map[uint32]*DkgRound2Output, len(parties))
+	for id := range parties {
+		round2[id] = make(map[uint32]*DkgRound2Output)
+	}
+	for sender, p := range parties {
+		out, err := p.Round2Share(seeds)
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round2[recipient][sender] = message
+		}
+	}
+	return round2
+}
+
+func newDkgParties(t *testing.T, curve *curves.Curve, threshold, n uint32) map[uint32]*Dkg {
+	ids := make([]uint32, n)
+	for i := range ids {
+		ids[i] = uint32(i + 1)
+	}
+	parties := make(map[uint32]*Dkg, n)
+	for _, id := range ids {
+		p, err := NewDkg(curve, id, threshold, ids)
+		require.NoError(t, err)
+		parties[id] = p
+	}
+	return parties
+}
+
+// runSignRounds runs the first four rounds of signing, calling tamper if not nil after round 2, and returns the
+// signers and the round 4 messages received by every signer
+func runSignRounds(t *testing.T, curve *curves.Curve, outputs map[uint32]*Output, signerIds []uint32, tamper func(map[uint32]*Signer)) (map[uint32]*Signer, map[uint32]map[uint32]*SignRound4Output) {
+	signers := make(map[uint32]*Signer, len(signerIds))
+	for _, id := range signerIds {
+		s, err := NewSigner(curve, sha256.New(), outputs[id], signerIds)
+		require.NoError(t, err)
+		signers[id] = s
+	}
+	seeds := make(map[uint32][simplest.DigestSize]byte, len(signers))
+	for id, s := range signers {
+		seed, err := s.Round1GenerateRandomSeed()
+		require.NoError(t, err)
+		seeds[id] = seed
+	}
+	round2 := make(map[uint32]map[uint32]*SignRound2Output, len(signers))
+	round3 := make(map[uint32]map[uint32]*SignRound3Output, len(signers))
+	round4 := make(map[uint32]map[uint32]*SignRound4Output, len(signers))
+	for id := range signers {
+		round2[id] = make(map[uint32]*SignRound2Output)
+		round3[id] = make(map[uint32]*SignRound3Output)
+		round4[id] = make(map[uint32]*SignRound4Output)
+	}
+	for sender, s := range signers {
+		out, err := s.Round2Initialize(seeds)
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round2[recipient][sender] = message
+		}
+	}
+	if tamper != nil {
+		tamper(signers)
+	}
+	for sender, s := range signers {
+		out, err := s.Round3Multiply(round2[sender])
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round3[recipient][sender] = message
+		}
+	}
+	for sender, s := range signers {
+		out, err := s.Round4Check(round3[sender])
+		require.NoError(t, err)
+		for recipient, message := range out {
+			round4[recipient][sender] = message
+		}
+	}
+	return signers, round4
+}
+
+func runSign(t *testing.T, curve *curves.Curve, outputs map[uint32]*Output, signerIds []uint32, message []byte) map[uint32]*curves.EcdsaSignature {
+	signers, round4 := runSignRounds(t, curve, outputs, signerIds, nil)
+	round5 := make(map[uint32]*SignRound5Output, len(signers))
+	for id, s := range signers {
+		out, err := s.Round5Combine(message, round4[id])
+		require.NoError(t, err)
+		round5[id] = out
+	}
+	signatures := make(map[uint32]*curves.EcdsaSignature, len(signers))
+	for id, s := range signers {
+		input := make(map[uint32]*SignRound5Output, len(signers)-1)
+		for peer, out := range round5 {
+			if peer != id {
+				input[peer] = out
+			}
+		}
+		require.NoError(t, s.Round6Final(input))
+		signatures[id] = s.Signature
+	}
+	return signatures
+}
+
+func verifySignature(t *testing.T, curve *curves.Curve, publicKey curves.Point, message []byte, signature *curves.EcdsaSignature) {
+	ellipticCurve, err := curve.ToEllipticCurve()
+	require.NoError(t, err)
+	uncompressed := publicKey.ToAffineUncompressed()
+	pk := &ecdsa.PublicKey{
+		Curve: ellipticCurve,
+		X:     new(big.Int).SetBytes(uncompressed[1:33]),
+		Y:     new(big.Int).SetBytes(uncompressed[33:]),
+	}
+	digest := sha256.Sum256(message)
+	require.True(t, ecdsa.Verify(pk, digest[:], signature.R, signature.S))
+	require.True(t, signature.S.Cmp(new(big.Int).Rsh(ellipticCurve.Params().N, 1)) <= 0)
+	recovered, err := curves.RecoverPublicKey(curve, digest[:], signature)
+	require.NoError(t, err)
+	require.True(t, publicKey.Equal(recovered))
+}
+
+func TestDkgSignRefresh(t *testing.T) {
+	for _, test := range []struct {
+		curve        *curves.Curve
+		threshold, n uint32
+		signers      [][]uint32
+	}{
+		{curves.K256(), 2, 3, [][]uint32{{1, 2}, {1, 3}, {2, 3}}},
+		{curves.P256(), 2, 3, [][]uint32{{3, 1}}},
+		{curves.K256(), 3, 5, [][]uint32{{1, 3, 5}, {2, 3, 4, 5}}},
+	} {
+		t.Run(fmt.Sprintf("%s %d-of-%d", test.curve.Name, test.threshold, test.n), func(t *testing.T) {
+			outputs := runDkg(t, newDkgParties(t, test.curve, test.threshold, test.n))
+			publicKey := outputs[1].PublicKey
+			for id, output := range outputs {
+				require.True(t, publicKey.Equal(output.PublicKey))
+				require.True(t, test.curve.ScalarBaseMult(output.SecretKeyShare).Equal(outputs[1].PublicShares[id]))
+				require.Len(t, output.SeedOtSenders, int(id-1))
+				require.Len(t, output.SeedOtReceivers, int(test.n-id))
+			}
+
+			// Any threshold of shares reconstructs the secret key of the public key
+			scheme, err := sharing.NewShamir(test.threshold, test.n, test.curve)
+			require.NoError(t, err)
+			shares := make([]*sharing.ShamirShare, 0, test.threshold)
+			for id := uint32(1); id <= test.threshold; id++ {
+				shares = append(shares, &sharing.ShamirShare{Id: id, Value: outputs[id].SecretKeyShare.Bytes()})
+			}
+			secretKey, err := scheme.Combine(shares...)
+			require.NoError(t, err)
+			require.True(t, test.curve.ScalarBaseMult(secretKey).Equal(publicKey))
+
+			message := []byte("multi-party DKLs")
+			for _, signerIds := range test.signers {
+				for _, signature := range runSign(t, test.curve, outputs, signerIds, message) {
+					verifySignature(t, test.curve, publicKey, message, signature)
+				}
+			}
+
+			// Refresh replaces every share and keeps the public key
+			refreshers := make(map[uint32]*Dkg, len(outputs))
+			for id, output := range outputs {
+				refreshers[id], err = NewRefresh(test.curve, output)
+				require.NoError(t, err)
+			}
+			refreshed := runDkg(t, refreshers)
+			for id, output := range refreshed {
+				require.True(t, publicKey.Equal(output.PublicKey))
+				require.NotEqual(t, 0, output.SecretKeyShare.Cmp(outputs[id].SecretKeyShare))
+			}
+			for _, signature := range runSign(t, test.curve, refreshed, test.signers[0], message) {
+				verifySignature(t, test.curve, publicKey, message, signature)
+			}
+		})
+	}
+}
+
+func TestDkgRejectsBadShares(t *testing.T) {
+	parties := newDkgParties(t, curves.K256(), 2, 3)
+	input := runDkgRound2(t, parties)[1]
+	share := *input[2].Share
+	input[2].Share = &sharing.ShamirShare{Id: 1, Value: curves.K256().Scalar.One().Bytes()}
+	_, err := parties[1].Round3Verify(input)
+	require.Error(t, err)
+
+	input[2].Share = &share
+	delete(input, 3)
+	_, err = parties[1].Round3Verify(input)
+	require.Error(t, err)
+}
+
+func TestNewBadInput(t *testing.T) {
+	_, err := NewDkg(curves.K256(), 4, 2, []uint32{1, 2, 3})
+	require.Error(t, err)
+	_, err = NewDkg(curves.K256(), 1, 4, []uint32{1, 2, 3})
+	require.Error(t, err)
+	_, err = NewDkg(curves.K256(), 1, 2, []uint32{1, 2, 2})
+	require.Error(t, err)
+	_, err = NewDkg(curves.K256(), 0, 2, []uint32{0, 1, 2})
+	require.Error(t, err)
+
+	outputs := runDkg(t, newDkgParties(t, curves.K256(), 3, 3))
+	_, err = NewSigner(curves.K256(), sha256.New(), outputs[1], []uint32{1, 2})
+	require.Error(t, err)
+	_, err = NewSigner(curves.K256(), sha256.New(), outputs[1], []uint32{1, 2, 4})
+	require.Error(t, err)
+	_, err = NewSigner(curves.K256(), sha256.New(), outputs[1], []uint32{2, 3, 1})
+	require.NoError(t, err)
+}
+
+func TestSignDetectsInconsistentMultiplication(t *testing.T) {
+	curve := curves.K256()
+	outputs := runDkg(t, newDkgParties(t, curve, 3, 3))
+	signerIds := []uint32{1, 2, 3}
+	message := []byte("multi-party DKLs")
+	for _, test := range []struct {
+		name   string
+		tamper func(*Signer)
+		reason string
+	}{
+		{"instance key", func(s *Signer) { s.k = s.k.Add(curve.Scalar.One()) }, "instance key share"},
+		{"secret key", func(s *Signer) { s.secretKeyShare = s.secretKeyShare.Add(curve.Scalar.One()) }, "secret key share"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			// After round 2, party 2 inputs a different value to its multiplications with party 3 only
+			signers, round4 := runSignRounds(t, curve, outputs, signerIds, func(signers map[uint32]*Signer) {
+				test.tamper(signers[2])
+			})
+			_, err := signers[1].Round5Combine(message, round4[1])
+			require.NoError(t, err)
+			_, err = signers[3].Round5Combine(message, round4[3])
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "party 2")
+			require.Contains(t, err.Error(), test.reason)
+		})
+	}
+
+	// A forged consistency check value is rejected as well
+	signers, round4 := runSignRounds(t, curve, outputs, signerIds, nil)
+	round4[1][3].GammaSk = round4[1][3].GammaSk.Add(curve.NewGeneratorPoint())
+	_, err := signers[1].Round5Combine(message, round4[1])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "party 3")
+}
+
+// pointWithLargeX returns a point of curve whose x coordinate is larger than the group order
+func pointWithLargeX(t *testing.T, curve *curves.Curve) curves.Point {
+	t.Helper()
+	ellipticCurve, err := curve.ToEllipticCurve()
+	require.NoError(t, err)
+	compressed := make([]byte, 33)
+	compressed[0] = 2
+	for x := new(big.Int).Add(ellipticCurve.Params().N, big.NewInt(1)); ; x.Add(x, big.NewInt(1)) {
+		x.FillBytes(compressed[1:])
+		point, err := curve.Point.FromAffineCompressed(compressed)
+		if err == nil && !point.IsIdentity() {
+			return point
+		}
+	}
+}
+
+// TestRound6FinalRecoveryIdOfLargeX combines the shares of a nonce point whose x coordinate is reduced by the group
+// order, with the public key for which the shares form a valid signature
+func TestRound6FinalRecoveryIdOfLargeX(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		message := []byte("large x")
+		digest := sha256.Sum256(message)
+		signer := &Signer{
+			id:           1,
+			signers:      []uint32{1, 2},
+			curve:        curve,
+			output:       &Output{},
+			r:            pointWithLargeX(t, curve),
+			digestBytes:  digest[:],
+			round5Output: &SignRound5Output{U: curve.Scalar.Random(rand.Reader), W: curve.Scalar.Random(rand.Reader)},
+		}
+		share := &SignRound5Output{U: curve.Scalar.Random(rand.Reader), W: curve.Scalar.Random(rand.Reader)}
+
+		// Q = r^-1 (sR - mG)
+		s := signer.round5Output.W.Add(share.W).Div(signer.round5Output.U.Add(share.U))
+		m, err := curve.Scalar.SetBytes(digest[:])
+		require.NoError(t, err)
+		r, _, err := signer.affineX(signer.r)
+		require.NoError(t, err)
+		rInv, err := r.Invert()
+		require.NoError(t, err)
+		signer.output.PublicKey = signer.r.Mul(s).Sub(curve.ScalarBaseMult(m)).Mul(rInv)
+
+		require.NoError(t, signer.Round6Final(map[uint32]*SignRound5Output{2: share}))
+		require.Equal(t, 2, signer.Signature.V&2)
+		verifySignature(t, curve, signer.output.PublicKey, message, signer.Signature)
+	}
+}
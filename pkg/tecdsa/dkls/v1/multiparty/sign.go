@@ -0,0 +1,484 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package multiparty
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"fmt"
+	"hash"
+	"math/big"
+
+	"github.com/gtank/merlin"
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
+	"github.com/coinbase/kryptology/pkg/ot/extension/kos"
+	"github.com/coinbase/kryptology/pkg/sharing"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/sign"
+	"github.com/coinbase/kryptology/pkg/zkp/schnorr"
+)
+
+// pairMultiplications is the number of multiplications every pair of signers i < j runs:
+// 1. k_i * phi_j
+// 2. phi_i * k_j
+// 3. sk_i * phi_j
+// 4. phi_i * sk_j
+// where k is the instance key, phi the inversion mask and sk the secret key, each additively shared among the signers.
+const pairMultiplications = 4
+
+// Signer encodes the state of one party during one execution of the signing protocol.
+// Every signer computes additive shares of u = k * phi and w = phi * (H(m) + r * sk) and obtains the signature
+// s = w / u. Before the shares of w are revealed, every signer checks that the instance key share and secret key
+// share that each other signer input to their multiplications match its R_j and its public key share.
+// At the end of the joint computation, every signer obtains the signature.
+type Signer struct {
+	// Signature is the resulting digital signature and is the output of this protocol.
+	Signature *curves.EcdsaSignature
+
+	id      uint32
+	signers []uint32
+	curve   *curves.Curve
+	hash    hash.Hash
+	output  *Output
+
+	// secretKeyShare is the Shamir share multiplied by its Lagrange coefficient, an additive share of sk
+	secretKeyShare curves.Scalar
+	k              curves.Scalar
+	phi            curves.Scalar
+	proof          *schnorr.Proof
+	commitments    map[uint32]schnorr.Commitment
+
+	// lagrange are the Lagrange coefficients of the signers
+	lagrange map[uint32]curves.Scalar
+	// instanceKeys are the instance key shares R_j = k_j * G of the other signers
+	instanceKeys map[uint32]curves.Point
+	// r is the joint instance key R = k * G
+	r curves.Point
+	// u and v are the additive shares of k * phi and sk * phi of this signer
+	u, v         curves.Scalar
+	digestBytes  []byte
+	round5Output *SignRound5Output
+
+	transcript *merlin.Transcript
+	sessionId  [simplest.DigestSize]byte
+
+	// multiplySenders are the multiplications with the signers with a higher id
+	multiplySenders map[uint32][pairMultiplications]*sign.MultiplySender
+	// multiplyReceivers are the multiplications with the signers with a lower id
+	multiplyReceivers map[uint32][pairMultiplications]*sign.MultiplyReceiver
+}
+
+// SignRound2Output is the message of the 2nd round of signing from one signer to another.
+type SignRound2Output struct {
+	// Commitment is the commitment to the proof of the instance key share of the sender. It is broadcast.
+	Commitment schnorr.Commitment
+
+	// KosRound1Outputs are the first messages of the multiplications when the sender has the higher id, nil otherwise.
+	KosRound1Outputs []*kos.Round1Output
+}
+
+// SignRound3Output is the message of the 3rd round of signing from one signer to another.
+type SignRound3Output struct {
+	// Proof opens the commitment to the instance key share R_i = k_i * G of the sender. It is broadcast.
+	Proof *schnorr.Proof
+
+	// MultiplyRound2Outputs are the second messages of the multiplications when the sender has the lower id,
+	// nil otherwise.
+	MultiplyRound2Outputs []*sign.MultiplyRound2Output
+}
+
+// SignRound4Output is the message of the 4th round of signing from one signer to another.
+// In the multiplications with the recipient, the sender inputs its instance key share k_j, committed to by
+// R_j = k_j * G, and its secret key share sk_j, committed to by its Lagrange-weighted public share. For the output
+// share d_j of the sender in such a multiplication with the inversion mask phi_i of the recipient, who holds the
+// output share c_i, Gamma = d_j * G must equal phi_i * R_j - c_i * G, resp. phi_i * sk_j * G - c_i * G.
+// This is the consistency check of Protocol 3.6 of [DKLs23](https://eprint.iacr.org/2023/765.pdf).
+type SignRound4Output struct {
+	// GammaK is Gamma for the multiplication of the instance key share of the sender.
+	GammaK curves.Point
+
+	// GammaSk is Gamma for the multiplication of the secret key share of the sender.
+	GammaSk curves.Point
+}
+
+// SignRound5Output is the broadcast of the 5th round of signing.
+type SignRound5Output struct {
+	// U is the additive share of k * phi of the sender.
+	U curves.Scalar
+
+	// W is the additive share of phi * (H(m) + r * sk) of the sender.
+	W curves.Scalar
+}
+
+// NewSigner creates a party that can participate in a signing session with the parties in signers, which must
+// include the id of output and at least threshold parties.
+func NewSigner(curve *curves.Curve, hash hash.Hash, output *Output, signers []uint32) (*Signer, error) {
+	if curve == nil || hash == nil || output == nil || output.SecretKeyShare == nil || output.PublicKey == nil {
+		return nil, fmt.Errorf("signer is not initialized")
+	}
+	sorted, err := sortedParties(output.Id, signers)
+	if err != nil {
+		return nil, err
+	}
+	if uint32(len(sorted)) < output.Threshold {
+		return nil, fmt.Errorf("at least %d signers are required", output.Threshold)
+	}
+	for _, id := range sorted {
+		if _, ok := output.PublicShares[id]; !ok {
+			return nil, fmt.Errorf("party %d does not hold a share", id)
+		}
+		if _, ok := output.SeedOtSenders[id]; id < output.Id && !ok {
+			return nil, fmt.Errorf("missing seed OT with party %d", id)
+		}
+		if _, ok := output.SeedOtReceivers[id]; id > output.Id && !ok {
+			return nil, fmt.Errorf("missing seed OT with party %d", id)
+		}
+	}
+	scheme, err := sharing.NewShamir(output.Threshold, uint32(len(output.Parties)), curve)
+	if err != nil {
+		return nil, err
+	}
+	lagrange, err := scheme.LagrangeCoeffs(sorted)
+	if err != nil {
+		return nil, err
+	}
+	// The consistency checks rely on the public shares of the signers adding up to the public key
+	publicKey := curve.NewIdentityPoint()
+	for _, id := range sorted {
+		publicKey = publicKey.Add(output.PublicShares[id].Mul(lagrange[id]))
+	}
+	if !publicKey.Equal(output.PublicKey) {
+		return nil, fmt.Errorf("public shares do not match the public key")
+	}
+	return &Signer{
+		id:                output.Id,
+		signers:           sorted,
+		curve:             curve,
+		hash:              hash,
+		output:            output,
+		secretKeyShare:    output.SecretKeyShare.Mul(lagrange[output.Id]),
+		commitments:       make(map[uint32]schnorr.Commitment, len(sorted)-1),
+		lagrange:          lagrange,
+		instanceKeys:      make(map[uint32]curves.Point, len(sorted)-1),
+		transcript:        merlin.NewTranscript("Coinbase_DKLs19_Sign"),
+		multiplySenders:   make(map[uint32][pairMultiplications]*sign.MultiplySender),
+		multiplyReceivers: make(map[uint32][pairMultiplications]*sign.MultiplyReceiver),
+	}, nil
+}
+
+// Round1GenerateRandomSeed is the first step of the generation of the unique session id. Every signer flips 32
+// random bytes and broadcasts them.
+func (signer *Signer) Round1GenerateRandomSeed() ([simplest.DigestSize]byte, error) {
+	seed := [simplest.DigestSize]byte{}
+	if _, err := rand.Read(seed[:]); err != nil {
+		return seed, errors.Wrap(err, "generating random bytes in sign round 1")
+	}
+	return seed, nil
+}
+
+// Round2Initialize derives the session id from the seeds of all signers (including this one), samples the instance
+// key share and inversion mask, commits to the instance key share and starts the multiplications with every signer
+// with a lower id.
+func (signer *Signer) Round2Initialize(seeds map[uint32][simplest.DigestSize]byte) (map[uint32]*SignRound2Output, error) {
+	if err := checkSenders(signer.signers, len(seeds), func(id uint32) bool { _, ok := seeds[id]; return ok }); err != nil {
+		return nil, err
+	}
+	for _, id := range signer.signers {
+		seed := seeds[id]
+		signer.transcript.AppendMessage([]byte(fmt.Sprintf("session_id_%d", id)), seed[:])
+	}
+	copy(signer.sessionId[:], signer.transcript.ExtractBytes([]byte("session id"), simplest.DigestSize))
+
+	signer.k = signer.curve.Scalar.Random(rand.Reader)
+	signer.phi = signer.curve.Scalar.Random(rand.Reader)
+	salt := subSessionId(signer.sessionId, "instance key", signer.id)
+	var commitment schnorr.Commitment
+	var err error
+	signer.proof, commitment, err = schnorr.NewProver(signer.curve, nil, salt[:]).ProveCommit(signer.k)
+	if err != nil {
+		return nil, errors.Wrap(err, "committing to the instance key in sign round 2")
+	}
+
+	output := make(map[uint32]*SignRound2Output, len(signer.signers)-1)
+	for _, id := range otherParties(signer.id, signer.signers) {
+		output[id] = &SignRound2Output{Commitment: commitment}
+	}
+	inputs := [pairMultiplications]curves.Scalar{signer.phi, signer.k, signer.phi, signer.secretKeyShare}
+	for _, id := range lowerPeers(signer.id, signer.signers) {
+		receivers := [pairMultiplications]*sign.MultiplyReceiver{}
+		output[id].KosRound1Outputs = make([]*kos.Round1Output, pairMultiplications)
+		for m := range receivers {
+			receivers[m], err = sign.NewMultiplyReceiver(signer.output.SeedOtSenders[id], signer.curve, subSessionId(signer.sessionId, "multiply", id, signer.id, uint32(m)))
+			if err != nil {
+				return nil, errors.Wrapf(err, "creating multiply receiver %d with party %d", m, id)
+			}
+			if output[id].KosRound1Outputs[m], err = receivers[m].Round1Initialize(inputs[m]); err != nil {
+				return nil, errors.Wrapf(err, "multiply round 1 initialize %d with party %d", m, id)
+			}
+		}
+		signer.multiplyReceivers[id] = receivers
+	}
+	return output, nil
+}
+
+// Round3Multiply stores the commitments of all other signers, reveals the instance key share of this signer and
+// responds to the multiplications of every signer with a higher id.
+func (signer *Signer) Round3Multiply(input map[uint32]*SignRound2Output) (map[uint32]*SignRound3Output, error) {
+	peers := otherParties(signer.id, signer.signers)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return nil, err
+	}
+	output := make(map[uint32]*SignRound3Output, len(peers))
+	for _, id := range peers {
+		signer.commitments[id] = input[id].Commitment
+		output[id] = &SignRound3Output{Proof: signer.proof}
+	}
+	inputs := [pairMultiplications]curves.Scalar{signer.k, signer.phi, signer.secretKeyShare, signer.phi}
+	for _, id := range higherPeers(signer.id, signer.signers) {
+		if len(input[id].KosRound1Outputs) != pairMultiplications {
+			return nil, fmt.Errorf("missing multiplication messages from party %d", id)
+		}
+		senders := [pairMultiplications]*sign.MultiplySender{}
+		output[id].MultiplyRound2Outputs = make([]*sign.MultiplyRound2Output, pairMultiplications)
+		for m := range senders {
+			if input[id].KosRound1Outputs[m] == nil {
+				return nil, fmt.Errorf("missing multiplication message from party %d", id)
+			}
+			var err error
+			senders[m], err = sign.NewMultiplySender(signer.output.SeedOtReceivers[id], signer.curve, subSessionId(signer.sessionId, "multiply", signer.id, id, uint32(m)))
+			if err != nil {
+				return nil, errors.Wrapf(err, "creating multiply sender %d with party %d", m, id)
+			}
+			if output[id].MultiplyRound2Outputs[m], err = senders[m].Round2Multiply(inputs[m], input[id].KosRound1Outputs[m]); err != nil {
+				return nil, errors.Wrapf(err, "multiply round 2 %d with party %d", m, id)
+			}
+		}
+		signer.multiplySenders[id] = senders
+	}
+	return output, nil
+}
+
+// Round4Check verifies the instance key shares of all other signers and finishes the multiplications with every
+// signer with a lower id. It returns the consistency check values of this signer for every other signer.
+func (signer *Signer) Round4Check(input map[uint32]*SignRound3Output) (map[uint32]*SignRound4Output, error) {
+	peers := otherParties(signer.id, signer.signers)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return nil, err
+	}
+	r := signer.proof.Statement
+	for _, id := range peers {
+		proof := input[id].Proof
+		salt := subSessionId(signer.sessionId, "instance key", id)
+		if proof == nil {
+			return nil, fmt.Errorf("missing instance key proof from party %d", id)
+		}
+		if err := schnorr.DecommitVerify(proof, signer.commitments[id], signer.curve, nil, salt[:]); err != nil {
+			return nil, errors.Wrapf(err, "verifying the instance key of party %d", id)
+		}
+		signer.instanceKeys[id] = proof.Statement
+		r = r.Add(proof.Statement)
+	}
+	if r.IsIdentity() {
+		return nil, fmt.Errorf("instance key is the identity")
+	}
+
+	u := signer.k.Mul(signer.phi)
+	v := signer.secretKeyShare.Mul(signer.phi)
+	output := make(map[uint32]*SignRound4Output, len(peers))
+	for id, senders := range signer.multiplySenders {
+		if senders[0] == nil {
+			return nil, fmt.Errorf("missing multiplication with party %d", id)
+		}
+		u = u.Add(senders[0].OutputAdditiveShare()).Add(senders[1].OutputAdditiveShare())
+		v = v.Add(senders[2].OutputAdditiveShare()).Add(senders[3].OutputAdditiveShare())
+		// As the sender, k and sk are the inputs of the 1st and 3rd multiplications
+		output[id] = &SignRound4Output{
+			GammaK:  signer.curve.ScalarBaseMult(senders[0].OutputAdditiveShare()),
+			GammaSk: signer.curve.ScalarBaseMult(senders[2].OutputAdditiveShare()),
+		}
+	}
+	for _, id := range lowerPeers(signer.id, signer.signers) {
+		receivers := signer.multiplyReceivers[id]
+		if len(input[id].MultiplyRound2Outputs) != pairMultiplications {
+			return nil, fmt.Errorf("missing multiplication messages from party %d", id)
+		}
+		for m, receiver := range receivers {
+			if input[id].MultiplyRound2Outputs[m] == nil {
+				return nil, fmt.Errorf("missing multiplication message from party %d", id)
+			}
+			if err := receiver.Round3Multiply(input[id].MultiplyRound2Outputs[m]); err != nil {
+				return nil, errors.Wrapf(err, "multiply round 3 %d with party %d", m, id)
+			}
+		}
+		u = u.Add(receivers[0].OutputAdditiveShare()).Add(receivers[1].OutputAdditiveShare())
+		v = v.Add(receivers[2].OutputAdditiveShare()).Add(receivers[3].OutputAdditiveShare())
+		// As the receiver, k and sk are the inputs of the 2nd and 4th multiplications
+		output[id] = &SignRound4Output{
+			GammaK:  signer.curve.ScalarBaseMult(receivers[1].OutputAdditiveShare()),
+			GammaSk: signer.curve.ScalarBaseMult(receivers[3].OutputAdditiveShare()),
+		}
+	}
+	signer.r = r
+	signer.u = u
+	signer.v = v
+	return output, nil
+}
+
+// Round5Combine runs the consistency checks of the multiplications with every other signer, and returns an error
+// naming the first signer whose inputs do not match its instance key share or its public key share. Only then it
+// broadcasts the shares of u and w for message.
+func (signer *Signer) Round5Combine(message []byte, input map[uint32]*SignRound4Output) (*SignRound5Output, error) {
+	peers := otherParties(signer.id, signer.signers)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return nil, err
+	}
+	if signer.u == nil {
+		return nil, fmt.Errorf("round 4 has not been run")
+	}
+	for _, id := range peers {
+		// The output shares of this signer in the multiplications of phi with k_j and sk_j
+		var kShare, skShare curves.Scalar
+		if id > signer.id {
+			kShare = signer.multiplySenders[id][1].OutputAdditiveShare()
+			skShare = signer.multiplySenders[id][3].OutputAdditiveShare()
+		} else {
+			kShare = signer.multiplyReceivers[id][0].OutputAdditiveShare()
+			skShare = signer.multiplyReceivers[id][2].OutputAdditiveShare()
+		}
+		if err := signer.checkConsistency(id, input[id], kShare, skShare); err != nil {
+			return nil, err
+		}
+	}
+
+	digest, err := signer.digest(message)
+	if err != nil {
+		return nil, err
+	}
+	rX, _, err := signer.affineX(signer.r)
+	if err != nil {
+		return nil, err
+	}
+	signer.round5Output = &SignRound5Output{
+		U: signer.u,
+		W: digest.Mul(signer.phi).Add(rX.Mul(signer.v)),
+	}
+	return signer.round5Output, nil
+}
+
+// checkConsistency checks the consistency check values of the party id against its instance key share and its
+// Lagrange-weighted public share, given the output shares of this signer in the corresponding multiplications
+func (signer *Signer) checkConsistency(id uint32, check *SignRound4Output, kShare, skShare curves.Scalar) error {
+	if check.GammaK == nil || check.GammaSk == nil {
+		return fmt.Errorf("malformed message from party %d", id)
+	}
+	expected := signer.instanceKeys[id].Mul(signer.phi).Sub(signer.curve.ScalarBaseMult(kShare))
+	if !check.GammaK.Equal(expected) {
+		return fmt.Errorf("party %d multiplied an instance key share that does not match its commitment", id)
+	}
+	publicShare := signer.output.PublicShares[id].Mul(signer.lagrange[id])
+	expected = publicShare.Mul(signer.phi).Sub(signer.curve.ScalarBaseMult(skShare))
+	if !check.GammaSk.Equal(expected) {
+		return fmt.Errorf("party %d multiplied a secret key share that does not match its public share", id)
+	}
+	return nil
+}
+
+// Round6Final combines the shares of u and w of all signers into the signature and verifies it.
+func (signer *Signer) Round6Final(input map[uint32]*SignRound5Output) error {
+	peers := otherParties(signer.id, signer.signers)
+	if err := checkSenders(peers, len(input), func(id uint32) bool { return input[id] != nil }); err != nil {
+		return err
+	}
+	if signer.round5Output == nil {
+		return fmt.Errorf("round 5 has not been run")
+	}
+	var u, w curves.Scalar
+	for _, id := range signer.signers {
+		share := signer.round5Output
+		if id != signer.id {
+			share = input[id]
+		}
+		if share == nil || share.U == nil || share.W == nil {
+			return fmt.Errorf("malformed message from party %d", id)
+		}
+		if u == nil {
+			u, w = share.U, share.W
+		} else {
+			u, w = u.Add(share.U), w.Add(share.W)
+		}
+	}
+	if u.IsZero() {
+		return fmt.Errorf("k * phi is zero")
+	}
+	rX, rY, err := signer.affineX(signer.r)
+	if err != nil {
+		return err
+	}
+	s := w.Div(u)
+	signer.Signature = &curves.EcdsaSignature{
+		V: int(rY),
+		R: rX.BigInt(),
+		S: s.BigInt(),
+	}
+
+	ellipticCurve, err := signer.curve.ToEllipticCurve()
+	if err != nil {
+		return errors.Wrap(err, "invalid curve")
+	}
+	// bit(1) of the recovery id is set when the x coordinate of R was reduced by the group order
+	if new(big.Int).SetBytes(signer.r.ToAffineCompressed()[1:]).Cmp(ellipticCurve.Params().N) >= 0 {
+		signer.Signature.V |= 2
+	}
+	// Normalize to low S, negating s negates R so the recovery id flips
+	halfOrder := new(big.Int).Rsh(ellipticCurve.Params().N, 1)
+	if signer.Signature.S.Cmp(halfOrder) > 0 {
+		signer.Signature.S = s.Neg().BigInt()
+		signer.Signature.V ^= 1
+	}
+
+	uncompressed := signer.output.PublicKey.ToAffineUncompressed()
+	if len(uncompressed) != 65 {
+		return errors.New("the uncompressed form must have exactly 65 bytes")
+	}
+	publicKey := &ecdsa.PublicKey{
+		Curve: ellipticCurve,
+		X:     new(big.Int).SetBytes(uncompressed[1:33]),
+		Y:     new(big.Int).SetBytes(uncompressed[33:]),
+	}
+	if !ecdsa.Verify(publicKey, signer.digestBytes, signer.Signature.R, signer.Signature.S) {
+		return fmt.Errorf("final signature failed to verify")
+	}
+	return nil
+}
+
+// digest hashes message and returns the digest as a scalar
+func (signer *Signer) digest(message []byte) (curves.Scalar, error) {
+	if _, err := signer.hash.Write(message); err != nil {
+		return nil, errors.Wrap(err, "writing message to hash")
+	}
+	signer.digestBytes = signer.hash.Sum(nil)
+	digest, err := signer.curve.Scalar.SetBytes(signer.digestBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting digest scalar from bytes")
+	}
+	return digest, nil
+}
+
+// affineX returns the X coordinate of point modulo the group order and the parity of its Y coordinate
+func (signer *Signer) affineX(point curves.Point) (curves.Scalar, byte, error) {
+	affineCompressedForm := point.ToAffineCompressed()
+	if len(affineCompressedForm) != 33 {
+		return nil, 0, errors.New("the compressed form must be exactly 33 bytes")
+	}
+	x, err := signer.curve.Scalar.SetBigInt(new(big.Int).SetBytes(affineCompressedForm[1:]))
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "setting x scalar from big int")
+	}
+	return x, affineCompressedForm[0] & 0x1, nil
+}
@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+)
+
+// runMultiPartyProtocol cranks all parties forward one round at a time, routing the messages between them
+func runMultiPartyProtocol(t *testing.T, parties map[uint32]protocol.Iterator) {
+	inputs := make(map[uint32]*protocol.Message, len(parties))
+	for {
+		outputs := make(map[uint32]*protocol.Message, len(parties))
+		finished := 0
+		for id, party := range parties {
+			output, err := party.Next(inputs[id])
+			if err == protocol.ErrProtocolFinished {
+				finished++
+				continue
+			}
+			require.NoError(t, err)
+			outputs[id] = output
+		}
+		if finished == len(parties) {
+			return
+		}
+		require.Zero(t, finished, "parties must finish simultaneously")
+		var err error
+		inputs, err = RouteMultiPartyMessages(outputs)
+		require.NoError(t, err)
+	}
+}
+
+func multiPartyDkgV1(t *testing.T, curve *curves.Curve, threshold uint32, ids []uint32, version uint) map[uint32]*protocol.Message {
+	dkgs := make(map[uint32]*MultiPartyDkg, len(ids))
+	parties := make(map[uint32]protocol.Iterator, len(ids))
+	for _, id := range ids {
+		d, err := NewMultiPartyDkg(curve, id, threshold, ids, version)
+		require.NoError(t, err)
+		dkgs[id] = d
+		parties[id] = d
+	}
+	runMultiPartyProtocol(t, parties)
+	results := make(map[uint32]*protocol.Message, len(ids))
+	for id, d := range dkgs {
+		result, err := d.Result(version)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		results[id] = result
+	}
+	return results
+}
+
+func multiPartySignV1(t *testing.T, curve *curves.Curve, results map[uint32]*protocol.Message, signers []uint32, message []byte, version uint) {
+	signs := make(map[uint32]*MultiPartySign, len(signers))
+	parties := make(map[uint32]protocol.Iterator, len(signers))
+	for _, id := range signers {
+		s, err := NewMultiPartySign(curve, sha256.New(), message, results[id], signers, version)
+		require.NoError(t, err)
+		signs[id] = s
+		parties[id] = s
+	}
+	runMultiPartyProtocol(t, parties)
+
+	output, err := DecodeMultiPartyDkgResult(results[signers[0]])
+	require.NoError(t, err)
+	ellipticCurve, err := curve.ToEllipticCurve()
+	require.NoError(t, err)
+	uncompressed := output.PublicKey.ToAffineUncompressed()
+	publicKey := &ecdsa.PublicKey{
+		Curve: ellipticCurve,
+		X:     new(big.Int).SetBytes(uncompressed[1:33]),
+		Y:     new(big.Int).SetBytes(uncompressed[33:]),
+	}
+	digest := sha256.Sum256(message)
+	for _, s := range signs {
+		result, err := s.Result(version)
+		require.NoError(t, err)
+		signature, err := DecodeSignature(result)
+		require.NoError(t, err)
+		require.True(t, ecdsa.Verify(publicKey, digest[:], signature.R, signature.S))
+	}
+}
+
+func TestMultiPartyProto(t *testing.T) {
+	for _, version := range []uint{protocol.Version1, protocol.Version2} {
+		testMultiPartyProto(t, version)
+	}
+}
+
+func testMultiPartyProto(t *testing.T, version uint) {
+	curve := curves.K256()
+	ids := []uint32{1, 2, 3}
+	results := multiPartyDkgV1(t, curve, 2, ids, version)
+	message := []byte("multi-party iterators")
+	multiPartySignV1(t, curve, results, []uint32{1, 3}, message, version)
+
+	refreshes := make(map[uint32]*MultiPartyRefresh, len(ids))
+	parties := make(map[uint32]protocol.Iterator, len(ids))
+	for _, id := range ids {
+		r, err := NewMultiPartyRefresh(curve, results[id], version)
+		require.NoError(t, err)
+		refreshes[id] = r
+		parties[id] = r
+	}
+	runMultiPartyProtocol(t, parties)
+	refreshed := make(map[uint32]*protocol.Message, len(ids))
+	for id, r := range refreshes {
+		result, err := r.Result(version)
+		require.NoError(t, err)
+		refreshed[id] = result
+	}
+	multiPartySignV1(t, curve, refreshed, []uint32{2, 3}, message, version)
+}
+
+func TestRouteMultiPartyMessages(t *testing.T) {
+	outputs := map[uint32]*protocol.Message{
+		1: {Protocol: protocol.Dkls19Dkg, Version: protocol.Version1, Payloads: map[string][]byte{protocol.BroadcastKey: {1}}},
+		2: {Protocol: protocol.Dkls19Dkg, Version: protocol.Version1, Payloads: map[string][]byte{"3": {2}}},
+		3: {Protocol: protocol.Dkls19Dkg, Version: protocol.Version1, Payloads: map[string][]byte{}},
+	}
+	inputs, err := RouteMultiPartyMessages(outputs)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{}, inputs[1].Payloads)
+	require.Equal(t, map[string][]byte{"1": {1}}, inputs[2].Payloads)
+	require.Equal(t, map[string][]byte{"1": {1}, "2": {2}}, inputs[3].Payloads)
+
+	outputs[2].Payloads["4"] = []byte{3}
+	_, err = RouteMultiPartyMessages(outputs)
+	require.Error(t, err)
+}
@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/multiparty"
+)
+
+func newMultiPartyProtocolMessage(protocolName, round string, version uint) *protocol.Message {
+	return &protocol.Message{
+		Protocol: protocolName,
+		Version:  version,
+		Payloads: make(map[string][]byte),
+		Metadata: map[string]string{"round": round},
+	}
+}
+
+// addMultiPartyPayload encodes value as the payload of m to the party with identifier id
+func addMultiPartyPayload(m *protocol.Message, id uint32, value interface{}, write func(w *wireWriter)) error {
+	return addMultiPartyPayloadWithKey(m, strconv.FormatUint(uint64(id), 10), value, write)
+}
+
+func addMultiPartyPayloadWithKey(m *protocol.Message, key string, value interface{}, write func(w *wireWriter)) error {
+	payload, err := encodePayload(m.Version, value, write)
+	if err != nil {
+		return err
+	}
+	m.Payloads[key] = payload
+	return nil
+}
+
+// decodeMultiPartyPayloads calls decode with the id of the sender of every payload of m.
+func decodeMultiPartyPayloads(m *protocol.Message, protocolName string, decode func(id uint32, payload []byte) error) error {
+	if m == nil {
+		return errors.New("message is nil")
+	}
+	if m.Protocol != protocolName {
+		return fmt.Errorf("expected a %s message, got %s", protocolName, m.Protocol)
+	}
+	for key, payload := range m.Payloads {
+		id, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			return errors.Wrapf(err, "invalid sender %s", key)
+		}
+		if err = decode(uint32(id), payload); err != nil {
+			return errors.Wrapf(err, "decoding payload of %d", id)
+		}
+	}
+	return nil
+}
+
+// RouteMultiPartyMessages delivers the messages output by all parties of a multi-party protocol in the same round.
+// The payload party i addresses to party j, or broadcasts, becomes the payload with key i of the input of party j.
+// outputs is keyed by the id of the party that output the message, nil messages are skipped.
+func RouteMultiPartyMessages(outputs map[uint32]*protocol.Message) (map[uint32]*protocol.Message, error) {
+	inputs := make(map[uint32]*protocol.Message, len(outputs))
+	for id := range outputs {
+		inputs[id] = nil
+	}
+	for sender, m := range outputs {
+		if m == nil {
+			continue
+		}
+		senderKey := strconv.FormatUint(uint64(sender), 10)
+		for key, payload := range m.Payloads {
+			var recipients []uint32
+			if key == protocol.BroadcastKey {
+				for id := range outputs {
+					if id != sender {
+						recipients = append(recipients, id)
+					}
+				}
+			} else {
+				id, err := strconv.ParseUint(key, 10, 32)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid recipient %s", key)
+				}
+				if _, ok := outputs[uint32(id)]; !ok {
+					return nil, fmt.Errorf("unknown recipient %d", id)
+				}
+				recipients = []uint32{uint32(id)}
+			}
+			for _, id := range recipients {
+				if inputs[id] == nil {
+					inputs[id] = &protocol.Message{
+						Protocol: m.Protocol,
+						Version:  m.Version,
+						Payloads: make(map[string][]byte),
+						Metadata: m.Metadata,
+					}
+				}
+				if _, ok := inputs[id].Payloads[senderKey]; ok {
+					return nil, fmt.Errorf("party %d sent more than one payload to party %d", sender, id)
+				}
+				inputs[id].Payloads[senderKey] = payload
+			}
+		}
+	}
+	// Parties that received nothing still take part in the round
+	for id, m := range inputs {
+		if m == nil {
+			for _, output := range outputs {
+				if output != nil {
+					inputs[id] = &protocol.Message{
+						Protocol: output.Protocol,
+						Version:  output.Version,
+						Payloads: make(map[string][]byte),
+						Metadata: output.Metadata,
+					}
+					break
+				}
+			}
+		}
+	}
+	return inputs, nil
+}
+
+// EncodeMultiPartyDkgOutput serializes the DKG or refresh output of one party of t-of-n DKLs.
+func EncodeMultiPartyDkgOutput(result *multiparty.Output, version uint) (*protocol.Message, error) {
+	payload, err := encodePayload(version, result, func(w *wireWriter) { writeMultiPartyOutput(w, result) })
+	if err != nil {
+		return nil, err
+	}
+	return &protocol.Message{
+		Protocol: protocol.Dkls19Dkg,
+		Version:  version,
+		Payloads: map[string][]byte{payloadKey: payload},
+		Metadata: map[string]string{"round": "output"},
+	}, nil
+}
+
+// DecodeMultiPartyDkgResult deserializes the DKG or refresh output of one party of t-of-n DKLs.
+func DecodeMultiPartyDkgResult(m *protocol.Message) (*multiparty.Output, error) {
+	decoded := new(multiparty.Output)
+	if err := decodeMessage(m, decoded, func(r *wireReader) { readMultiPartyOutput(r, decoded) }); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"hash"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/sign"
+)
+
+// Presigning splits signing into an offline phase that runs the whole interactive protocol before the message is
+// known, and an online phase of a single message from Alice to Bob. The offline phase is run by the AlicePresign and
+// BobPresign iterators, whose results are the encoded presignatures of each party. Once the message is known, Alice
+// calls AliceSignOnline and sends its output to Bob, who completes the signature with BobSignOnline.
+//
+// A presignature signs at most one message; signing two messages with it reveals the secret key. The online functions
+// record the id of the presignature with a protocol.PresignatureGuard before signing and refuse ids that were already
+// recorded, so a second decoded copy of a presignature cannot sign either. A guard is required. The
+// protocol.MemoryPresignatureGuard only remembers the current process; callers that load stored presignatures from
+// several processes must pass a guard backed by storage shared between them.
+
+// AlicePresign DKLS presign implementation that satisfies the protocol iterator interface.
+type AlicePresign struct {
+	protoStepper
+	*sign.Alice
+}
+
+// BobPresign DKLS presign implementation that satisfies the protocol iterator interface.
+type BobPresign struct {
+	protoStepper
+	*sign.Bob
+}
+
+var (
+	// Static type assertions
+	_ protocol.Iterator = &AlicePresign{}
+	_ protocol.Iterator = &BobPresign{}
+)
+
+// NewAlicePresign creates a new protocol that can compute a presignature as Alice.
+// Requires dkg state that was produced at the end of DKG.Output().
+func NewAlicePresign(curve *curves.Curve, dkgResultMessage *protocol.Message, version uint) (*AlicePresign, error) {
+	dkgResult, err := DecodeAliceDkgResult(dkgResultMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// The hash of the message is only needed by the online step
+	a := new(AlicePresign)
+	a.setup(sign.NewAlice(curve, nil, dkgResult), version)
+	return a, nil
+}
+
+// setup initializes a to run all steps on alice.
+func (a *AlicePresign) setup(alice *sign.Alice, version uint) {
+	a.Alice = alice
+	a.version = version
+	a.steps = []func(message *protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			aliceCommitment, err := a.Round1GenerateRandomSeed()
+			if err != nil {
+				return nil, err
+			}
+			return encodeSignRound1Output(aliceCommitment, version)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round2Output, err := decodeSignRound3Input(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			round3Output, err := a.Round3Presign(round2Output)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			return encodeSignRound3Output(round3Output, version)
+		},
+	}
+}
+
+// NewBobPresign creates a new protocol that can compute a presignature as Bob.
+// Requires dkg state that was produced at the end of DKG.Output().
+func NewBobPresign(curve *curves.Curve, dkgResultMessage *protocol.Message, version uint) (*BobPresign, error) {
+	dkgResult, err := DecodeBobDkgResult(dkgResultMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	b := new(BobPresign)
+	b.setup(sign.NewBob(curve, nil, dkgResult), version)
+	return b, nil
+}
+
+// setup initializes b to run all steps on bob.
+func (b *BobPresign) setup(bob *sign.Bob, version uint) {
+	b.Bob = bob
+	b.version = version
+	b.steps = []func(message *protocol.Message) (*protocol.Message, error){
+		func(input *protocol.Message) (*protocol.Message, error) {
+			commitment, err := decodeSignRound2Input(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			round2Output, err := b.Round2Initialize(commitment)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			return encodeSignRound2Output(round2Output, version)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round4Input, err := decodeSignRound4Input(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if err = b.Round4Presign(round4Input); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			return nil, nil
+		},
+	}
+}
+
+// Result returns the encoded presignature of Alice if the presign protocol completed successfully.
+func (a *AlicePresign) Result(version uint) (*protocol.Message, error) {
+	if !a.complete() {
+		return nil, nil
+	}
+	if a.Alice == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return EncodeAlicePresignature(a.Presignature, version)
+}
+
+// Result returns the encoded presignature of Bob if the presign protocol completed successfully.
+func (b *BobPresign) Result(version uint) (*protocol.Message, error) {
+	if !b.complete() {
+		return nil, nil
+	}
+	if b.Bob == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return EncodeBobPresignature(b.Presignature, version)
+}
+
+// AliceSignOnline computes Alice's part of the signature of message from her presignature, whose id is recorded with
+// guard. The returned message must be sent to Bob.
+func AliceSignOnline(hash hash.Hash, message []byte, presignature *sign.AlicePresignature, version uint, guard protocol.PresignatureGuard) (*protocol.Message, error) {
+	if presignature == nil {
+		return nil, errors.New("presignature is nil")
+	}
+	output, err := presignature.SignOnline(hash, message, guard)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return encodeSignOnlineOutput(output, version)
+}
+
+// BobSignOnline completes the signature of message from Bob's presignature and the message output by AliceSignOnline.
+// The id of the presignature is recorded with guard. The result can be decoded with DecodeSignature.
+func BobSignOnline(hash hash.Hash, message []byte, presignature *sign.BobPresignature, aliceMessage *protocol.Message, guard protocol.PresignatureGuard) (*protocol.Message, error) {
+	if presignature == nil {
+		return nil, errors.New("presignature is nil")
+	}
+	aliceOutput, err := decodeSignOnlineOutput(aliceMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	signature, err := presignature.SignOnline(hash, message, aliceOutput, guard)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return encodeSignature(signature, aliceMessage.Version)
+}
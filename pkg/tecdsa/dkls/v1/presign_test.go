@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+)
+
+func TestPresignProto(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		aliceDkg := NewAliceDkg(curve, protocol.Version1)
+		bobDkg := NewBobDkg(curve, protocol.Version1)
+		aErr, bErr := runIteratedProtocol(bobDkg, aliceDkg)
+		require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+		require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+		aliceDkgResultMessage, err := aliceDkg.Result(protocol.Version1)
+		require.NoError(t, err)
+		bobDkgResultMessage, err := bobDkg.Result(protocol.Version1)
+		require.NoError(t, err)
+
+		// Offline
+		alicePresign, err := NewAlicePresign(curve, aliceDkgResultMessage, protocol.Version1)
+		require.NoError(t, err)
+		bobPresign, err := NewBobPresign(curve, bobDkgResultMessage, protocol.Version1)
+		require.NoError(t, err)
+		aErr, bErr = runIteratedProtocol(alicePresign, bobPresign)
+		require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+		require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+		alicePresignatureMessage, err := alicePresign.Result(protocol.Version1)
+		require.NoError(t, err)
+		bobPresignatureMessage, err := bobPresign.Result(protocol.Version1)
+		require.NoError(t, err)
+
+		// Online, from the stored presignatures
+		alicePresignature, err := DecodeAlicePresignature(alicePresignatureMessage)
+		require.NoError(t, err)
+		bobPresignature, err := DecodeBobPresignature(bobPresignatureMessage)
+		require.NoError(t, err)
+		msg := []byte("Presigned before the message was known.")
+		aliceGuard := protocol.NewMemoryPresignatureGuard()
+		bobGuard := protocol.NewMemoryPresignatureGuard()
+		aliceMessage, err := AliceSignOnline(sha3.New256(), msg, alicePresignature, protocol.Version1, aliceGuard)
+		require.NoError(t, err)
+		signatureMessage, err := BobSignOnline(sha3.New256(), msg, bobPresignature, aliceMessage, bobGuard)
+		require.NoError(t, err)
+		signature, err := DecodeSignature(signatureMessage)
+		require.NoError(t, err)
+
+		hash := sha3.New256()
+		_, err = hash.Write(msg)
+		require.NoError(t, err)
+		unCompressedAffinePublicKey := aliceDkg.Output().PublicKey.ToAffineUncompressed()
+		ecCurve, err := curve.ToEllipticCurve()
+		require.NoError(t, err)
+		publicKey := &curves.EcPoint{
+			Curve: ecCurve,
+			X:     new(big.Int).SetBytes(unCompressedAffinePublicKey[1:33]),
+			Y:     new(big.Int).SetBytes(unCompressedAffinePublicKey[33:]),
+		}
+		require.True(t, curves.VerifyEcdsa(publicKey, hash.Sum(nil), signature))
+		recovered, err := curves.RecoverPublicKey(curve, hash.Sum(nil), signature)
+		require.NoError(t, err)
+		require.True(t, aliceDkg.Output().PublicKey.Equal(recovered))
+
+		// The used presignatures refuse a second message, and so do copies decoded again from storage
+		_, err = AliceSignOnline(sha3.New256(), []byte("second"), alicePresignature, protocol.Version1, aliceGuard)
+		require.Error(t, err)
+		_, err = BobSignOnline(sha3.New256(), msg, bobPresignature, aliceMessage, bobGuard)
+		require.Error(t, err)
+		alicePresignature, err = DecodeAlicePresignature(alicePresignatureMessage)
+		require.NoError(t, err)
+		_, err = AliceSignOnline(sha3.New256(), []byte("second"), alicePresignature, protocol.Version1, aliceGuard)
+		require.ErrorIs(t, err, protocol.ErrPresignatureUsed)
+		bobPresignature, err = DecodeBobPresignature(bobPresignatureMessage)
+		require.NoError(t, err)
+		_, err = BobSignOnline(sha3.New256(), msg, bobPresignature, aliceMessage, bobGuard)
+		require.ErrorIs(t, err, protocol.ErrPresignatureUsed)
+	}
+}
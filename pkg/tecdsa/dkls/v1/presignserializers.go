@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/sign"
+)
+
+func encodeSignOnlineOutput(output *sign.SignOnlineOutput, version uint) (*protocol.Message, error) {
+	payload, err := encodePayload(version, output, func(w *wireWriter) { writeSignOnlineOutput(w, output) })
+	if err != nil {
+		return nil, err
+	}
+	return newSignProtocolMessage(payload, "online", version), nil
+}
+
+func decodeSignOnlineOutput(m *protocol.Message) (*sign.SignOnlineOutput, error) {
+	decoded := new(sign.SignOnlineOutput)
+	if err := decodeMessage(m, decoded, func(r *wireReader) { readSignOnlineOutput(r, decoded) }); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// EncodeAlicePresignature serializes the presignature of Alice.
+func EncodeAlicePresignature(presignature *sign.AlicePresignature, version uint) (*protocol.Message, error) {
+	payload, err := encodePayload(version, presignature, func(w *wireWriter) { writeAlicePresignature(w, presignature) })
+	if err != nil {
+		return nil, err
+	}
+	return newSignProtocolMessage(payload, "presignature", version), nil
+}
+
+// DecodeAlicePresignature deserializes the presignature of Alice.
+func DecodeAlicePresignature(m *protocol.Message) (*sign.AlicePresignature, error) {
+	decoded := new(sign.AlicePresignature)
+	if err := decodeMessage(m, decoded, func(r *wireReader) { readAlicePresignature(r, decoded) }); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// EncodeBobPresignature serializes the presignature of Bob.
+func EncodeBobPresignature(presignature *sign.BobPresignature, version uint) (*protocol.Message, error) {
+	payload, err := encodePayload(version, presignature, func(w *wireWriter) { writeBobPresignature(w, presignature) })
+	if err != nil {
+		return nil, err
+	}
+	return newSignProtocolMessage(payload, "presignature", version), nil
+}
+
+// DecodeBobPresignature deserializes the presignature of Bob.
+func DecodeBobPresignature(m *protocol.Message) (*sign.BobPresignature, error) {
+	decoded := new(sign.BobPresignature)
+	if err := decodeMessage(m, decoded, func(r *wireReader) { readBobPresignature(r, decoded) }); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
@@ -10,10 +10,22 @@ import (
 type protoStepper struct {
 	steps []func(input *protocol.Message) (*protocol.Message, error)
 	step  int
+
+	// version is the version of the messages output by the steps.
+	version uint
+
+	// suspended is set once the state was serialized, see MarshalBinary in snapshot.go.
+	suspended bool
+
+	// guard records restored snapshots; nil means the process wide guard.
+	guard SnapshotGuard
 }
 
 // Next runs the next step in the protocol and reports errors or increments the step index
 func (p *protoStepper) Next(input *protocol.Message) (*protocol.Message, error) {
+	if p.suspended {
+		return nil, ErrSuspended
+	}
 	if p.complete() {
 		return nil, protocol.ErrProtocolFinished
 	}
@@ -245,6 +245,42 @@ func TestDkgSignProto(t *testing.T) {
 	})
 }
 
+// DKG > Derive > Sign > Refresh > Sign
+func TestDkgDeriveSignProto(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		aliceDkg := NewAliceDkg(curve, protocol.Version1)
+		bobDkg := NewBobDkg(curve, protocol.Version1)
+		aErr, bErr := runIteratedProtocol(bobDkg, aliceDkg)
+		require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+		require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+
+		// Each party derives the child key locally
+		path := []uint32{44, 0, 0, 0, 3}
+		aliceChild, err := aliceDkg.Output().DerivePath(path)
+		require.NoError(t, err)
+		bobChild, err := bobDkg.Output().DerivePath(path)
+		require.NoError(t, err)
+		require.True(t, aliceChild.PublicKey.Equal(bobChild.PublicKey))
+
+		// The extended public key alone derives the same child
+		publicKey, chainCode := aliceDkg.Output().PublicKey, aliceDkg.Output().ChainCode
+		for _, index := range path {
+			publicKey, chainCode, err = dkg.DerivePublicKey(publicKey, chainCode, index)
+			require.NoError(t, err)
+		}
+		require.True(t, publicKey.Equal(aliceChild.PublicKey))
+
+		aliceChildMessage, err := EncodeAliceDkgOutput(aliceChild, protocol.Version1)
+		require.NoError(t, err)
+		bobChildMessage, err := EncodeBobDkgOutput(bobChild, protocol.Version1)
+		require.NoError(t, err)
+		signV1(t, curve, aliceChildMessage, bobChildMessage)
+
+		aliceRefreshed, bobRefreshed := refreshV1(t, curve, aliceChildMessage, bobChildMessage)
+		signV1(t, curve, aliceRefreshed, bobRefreshed)
+	}
+}
+
 // Decode > NewDklsSign > Sign > Output
 // NOTE: this cold-start test ensures backwards compatibility with durable,
 // encoding DKG state that may exist within production systems like test
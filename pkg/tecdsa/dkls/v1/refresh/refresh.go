@@ -16,10 +16,10 @@ package refresh
 import (
 	"crypto/rand"
 
-	"github.com/gtank/merlin"
 	"github.com/pkg/errors"
 
 	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/transcript"
 	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
 	"github.com/coinbase/kryptology/pkg/ot/extension/kos"
 	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/dkg"
@@ -38,9 +38,13 @@ type Alice struct {
 	// publicKey is the joint public key of Alice and Bob.
 	publicKey curves.Point
 
+	// chainCode and tweak are carried over from the DKG output unchanged.
+	chainCode []byte
+	tweak     curves.Scalar
+
 	curve *curves.Curve
 
-	transcript *merlin.Transcript
+	transcript *transcript.Transcript
 }
 
 // Bob struct encoding Bob's state during one execution of the overall signing algorithm.
@@ -55,9 +59,13 @@ type Bob struct {
 	// publicKey is the joint public key of Alice and Bob.
 	publicKey curves.Point
 
+	// chainCode and tweak are carried over from the DKG output unchanged.
+	chainCode []byte
+	tweak     curves.Scalar
+
 	curve *curves.Curve
 
-	transcript *merlin.Transcript
+	transcript *transcript.Transcript
 }
 
 type RefreshRound2Output struct {
@@ -71,7 +79,9 @@ func NewAlice(curve *curves.Curve, dkgOutput *dkg.AliceOutput) *Alice {
 		curve:          curve,
 		secretKeyShare: dkgOutput.SecretKeyShare,
 		publicKey:      dkgOutput.PublicKey,
-		transcript:     merlin.NewTranscript("Coinbase_DKLs_Refresh"),
+		chainCode:      dkgOutput.ChainCode,
+		tweak:          dkgOutput.Tweak,
+		transcript:     transcript.NewTranscript("Coinbase_DKLs_Refresh"),
 	}
 }
 
@@ -81,7 +91,9 @@ func NewBob(curve *curves.Curve, dkgOutput *dkg.BobOutput) *Bob {
 		curve:          curve,
 		secretKeyShare: dkgOutput.SecretKeyShare,
 		publicKey:      dkgOutput.PublicKey,
-		transcript:     merlin.NewTranscript("Coinbase_DKLs_Refresh"),
+		chainCode:      dkgOutput.ChainCode,
+		tweak:          dkgOutput.Tweak,
+		transcript:     transcript.NewTranscript("Coinbase_DKLs_Refresh"),
 	}
 }
 
@@ -165,6 +177,8 @@ func (alice *Alice) Output() *dkg.AliceOutput {
 		PublicKey:      alice.publicKey,
 		SecretKeyShare: alice.secretKeyShare,
 		SeedOtResult:   alice.receiver.Output,
+		ChainCode:      alice.chainCode,
+		Tweak:          alice.tweak,
 	}
 }
 
@@ -173,5 +187,7 @@ func (bob *Bob) Output() *dkg.BobOutput {
 		PublicKey:      bob.publicKey,
 		SecretKeyShare: bob.secretKeyShare,
 		SeedOtResult:   bob.sender.Output,
+		ChainCode:      bob.chainCode,
+		Tweak:          bob.tweak,
 	}
 }
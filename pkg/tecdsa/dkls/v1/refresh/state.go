@@ -0,0 +1,133 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package refresh
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/transcript"
+	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
+)
+
+func init() {
+	gob.Register(&curves.ScalarK256{})
+	gob.Register(&curves.PointK256{})
+	gob.Register(&curves.ScalarP256{})
+	gob.Register(&curves.PointP256{})
+}
+
+type aliceState struct {
+	Receiver       *simplest.Receiver
+	SecretKeyShare curves.Scalar
+	PublicKey      curves.Point
+	ChainCode      []byte
+	Tweak          curves.Scalar
+	Curve          string
+	Transcript     *transcript.Transcript
+}
+
+type bobState struct {
+	Sender         *simplest.Sender
+	SecretKeyShare curves.Scalar
+	PublicKey      curves.Point
+	ChainCode      []byte
+	Tweak          curves.Scalar
+	Curve          string
+	Transcript     *transcript.Transcript
+}
+
+// MarshalBinary serializes the state of Alice between two rounds of refresh.
+// The state contains Alice's secret key share and must be kept secret.
+func (alice *Alice) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := gob.NewEncoder(buf).Encode(&aliceState{
+		Receiver:       alice.receiver,
+		SecretKeyShare: alice.secretKeyShare,
+		PublicKey:      alice.publicKey,
+		ChainCode:      alice.chainCode,
+		Tweak:          alice.tweak,
+		Curve:          alice.curve.Name,
+		Transcript:     alice.transcript,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding alice refresh state")
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the state of Alice serialized by MarshalBinary.
+func (alice *Alice) UnmarshalBinary(data []byte) error {
+	state := new(aliceState)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(state); err != nil {
+		return errors.Wrap(err, "decoding alice refresh state")
+	}
+	curve := curves.GetCurveByName(state.Curve)
+	if curve == nil {
+		return errors.Errorf("unknown curve %s", state.Curve)
+	}
+	if state.Transcript == nil {
+		return errors.New("alice refresh state has no transcript")
+	}
+	*alice = Alice{
+		receiver:       state.Receiver,
+		secretKeyShare: state.SecretKeyShare,
+		publicKey:      state.PublicKey,
+		chainCode:      state.ChainCode,
+		tweak:          state.Tweak,
+		curve:          curve,
+		transcript:     state.Transcript,
+	}
+	return nil
+}
+
+// MarshalBinary serializes the state of Bob between two rounds of refresh.
+// The state contains Bob's secret key share and must be kept secret.
+func (bob *Bob) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := gob.NewEncoder(buf).Encode(&bobState{
+		Sender:         bob.sender,
+		SecretKeyShare: bob.secretKeyShare,
+		PublicKey:      bob.publicKey,
+		ChainCode:      bob.chainCode,
+		Tweak:          bob.tweak,
+		Curve:          bob.curve.Name,
+		Transcript:     bob.transcript,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding bob refresh state")
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary restores the state of Bob serialized by MarshalBinary.
+func (bob *Bob) UnmarshalBinary(data []byte) error {
+	state := new(bobState)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(state); err != nil {
+		return errors.Wrap(err, "decoding bob refresh state")
+	}
+	curve := curves.GetCurveByName(state.Curve)
+	if curve == nil {
+		return errors.Errorf("unknown curve %s", state.Curve)
+	}
+	if state.Transcript == nil {
+		return errors.New("bob refresh state has no transcript")
+	}
+	*bob = Bob{
+		sender:         state.Sender,
+		secretKeyShare: state.SecretKeyShare,
+		publicKey:      state.PublicKey,
+		chainCode:      state.ChainCode,
+		tweak:          state.Tweak,
+		curve:          curve,
+		transcript:     state.Transcript,
+	}
+	return nil
+}
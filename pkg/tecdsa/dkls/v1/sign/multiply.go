@@ -12,12 +12,12 @@ import (
 	"fmt"
 	"math/big"
 
-	"github.com/gtank/merlin"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
 
 	"github.com/coinbase/kryptology/internal"
 	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/transcript"
 	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
 	"github.com/coinbase/kryptology/pkg/ot/extension/kos"
 )
@@ -33,7 +33,7 @@ type MultiplySender struct {
 	outputAdditiveShare curves.Scalar // ultimate output share of mult.
 	gadget              [kos.L]curves.Scalar
 	curve               *curves.Curve
-	transcript          *merlin.Transcript
+	transcript          *transcript.Transcript
 	uniqueSessionId     [simplest.DigestSize]byte
 }
 
@@ -44,7 +44,7 @@ type MultiplyReceiver struct {
 	omega               [kos.COtBlockSizeBytes]byte // this is used as an intermediate result during the course of mult.
 	gadget              [kos.L]curves.Scalar
 	curve               *curves.Curve
-	transcript          *merlin.Transcript
+	transcript          *transcript.Transcript
 	uniqueSessionId     [simplest.DigestSize]byte
 }
 
@@ -82,12 +82,12 @@ func NewMultiplySender(seedOtResults *simplest.ReceiverOutput, curve *curves.Cur
 		return nil, errors.Wrap(err, "error generating gadget vector in new multiply sender")
 	}
 
-	transcript := merlin.NewTranscript("Coinbase_DKLs_Multiply")
-	transcript.AppendMessage([]byte("session_id"), uniqueSessionId[:])
+	t := transcript.NewTranscript("Coinbase_DKLs_Multiply")
+	t.AppendMessage([]byte("session_id"), uniqueSessionId[:])
 	return &MultiplySender{
 		cOtSender:       sender,
 		curve:           curve,
-		transcript:      transcript,
+		transcript:      t,
 		uniqueSessionId: uniqueSessionId,
 		gadget:          gadget,
 	}, nil
@@ -102,17 +102,27 @@ func NewMultiplyReceiver(seedOtResults *simplest.SenderOutput, curve *curves.Cur
 	if err != nil {
 		return nil, errors.Wrap(err, "error generating gadget vector in new multiply receiver")
 	}
-	transcript := merlin.NewTranscript("Coinbase_DKLs_Multiply")
-	transcript.AppendMessage([]byte("session_id"), uniqueSessionId[:])
+	t := transcript.NewTranscript("Coinbase_DKLs_Multiply")
+	t.AppendMessage([]byte("session_id"), uniqueSessionId[:])
 	return &MultiplyReceiver{
 		cOtReceiver:     receiver,
 		curve:           curve,
-		transcript:      transcript,
+		transcript:      t,
 		uniqueSessionId: uniqueSessionId,
 		gadget:          gadget,
 	}, nil
 }
 
+// OutputAdditiveShare returns the sender's additive share of the product. It is set by Round2Multiply.
+func (sender *MultiplySender) OutputAdditiveShare() curves.Scalar {
+	return sender.outputAdditiveShare
+}
+
+// OutputAdditiveShare returns the receiver's additive share of the product. It is set by Round3Multiply.
+func (receiver *MultiplyReceiver) OutputAdditiveShare() curves.Scalar {
+	return receiver.outputAdditiveShare
+}
+
 // MultiplyRound2Output is the output of the second round of the multiplication protocol.
 type MultiplyRound2Output struct {
 	COTRound2Output *kos.Round2Output
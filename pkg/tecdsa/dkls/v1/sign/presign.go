@@ -0,0 +1,252 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sign
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"hash"
+	"math/big"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
+)
+
+const (
+	// alicePresignatureGuardName and bobPresignatureGuardName separate the ids of the presignatures of Alice and Bob,
+	// which are equal, in a protocol.PresignatureGuard.
+	alicePresignatureGuardName = protocol.Dkls18Sign + "-Alice"
+	bobPresignatureGuardName   = protocol.Dkls18Sign + "-Bob"
+)
+
+// AlicePresignature is Alice's share of a signature that only lacks the message. It is the output of
+// `Alice.Round3Presign` and is consumed by `AlicePresignature.SignOnline`.
+// A presignature must be used to sign at most one message: signing two messages with the same nonce reveals the
+// secret key. SignOnline records Id with a protocol.PresignatureGuard and refuses presignatures that were already used.
+type AlicePresignature struct {
+	// Id is derived from the signing transcript and is the same for Alice and Bob.
+	Id [simplest.DigestSize]byte
+
+	// R is the nonce point R = k . G of the signature.
+	R curves.Point
+
+	// InverseNonceShare is Alice's additive share of 1/k.
+	InverseNonceShare curves.Scalar
+
+	// KeyShare is Alice's additive share of skA * skB / k.
+	KeyShare curves.Scalar
+
+	// Mask is the hash of gamma_2 that masks Alice's share of the signature.
+	Mask curves.Scalar
+
+	// Tweak is the tweak of the derived key, the joint secret key is skA * skB + tweak.
+	Tweak curves.Scalar
+}
+
+// BobPresignature is Bob's share of a signature that only lacks the message. It is the output of
+// `Bob.Round4Presign` and is consumed by `BobPresignature.SignOnline`.
+// The same single use rules as for `AlicePresignature` apply.
+type BobPresignature struct {
+	// Id is derived from the signing transcript and is the same for Alice and Bob.
+	Id [simplest.DigestSize]byte
+
+	// PublicKey is the joint public key the signature is verified against.
+	PublicKey curves.Point
+
+	// R is the nonce point R = k . G of the signature.
+	R curves.Point
+
+	// InverseNonceShare is Bob's additive share of 1/k, theta in the paper.
+	InverseNonceShare curves.Scalar
+
+	// KeyShare is Bob's additive share of skA * skB / k.
+	KeyShare curves.Scalar
+
+	// Mask is the hash of gamma_2 that Alice used to mask her share of the signature.
+	Mask curves.Scalar
+
+	// Tweak is the tweak of the derived key, the joint secret key is skA * skB + tweak.
+	Tweak curves.Scalar
+}
+
+// SignOnlineOutput is the only message of the online signing step, sent from Alice to Bob.
+type SignOnlineOutput struct {
+	// PresignatureId is the id of the presignature Alice signed with.
+	PresignatureId [simplest.DigestSize]byte
+
+	// EtaSig is the Eta_{Sig} from the paper.
+	EtaSig curves.Scalar
+}
+
+// rXOf returns the X coordinate of r as a scalar
+func rXOf(curve *curves.Curve, r curves.Point) (curves.Scalar, error) {
+	affineCompressedForm := r.ToAffineCompressed()
+	if len(affineCompressedForm) != 33 {
+		return nil, errors.New("the compressed form must be exactly 33 bytes")
+	}
+	// Discard the leading byte and reduce the rest, the X coordinate, modulo the group order.
+	rX, err := curve.Scalar.SetBigInt(new(big.Int).SetBytes(affineCompressedForm[1:]))
+	if err != nil {
+		return nil, errors.Wrap(err, "setting rX scalar from bytes")
+	}
+	return rX, nil
+}
+
+// curveOf returns the curve of a presignature
+func curveOf(r curves.Point) (*curves.Curve, error) {
+	if r == nil {
+		return nil, errors.New("presignature is not initialized")
+	}
+	curve := curves.GetCurveByName(r.CurveName())
+	if curve == nil {
+		return nil, fmt.Errorf("unsupported curve %s", r.CurveName())
+	}
+	return curve, nil
+}
+
+// SignOnline computes Alice's share of the signature of message from the presignature, to be sent to Bob.
+// The id of the presignature is recorded with guard, which must not be nil, and any copy of the presignature checked
+// against the same guard is refused. The secret shares of the presignature are erased.
+func (p *AlicePresignature) SignOnline(hash hash.Hash, message []byte, guard protocol.PresignatureGuard) (*SignOnlineOutput, error) {
+	if p.InverseNonceShare == nil || p.KeyShare == nil || p.Mask == nil {
+		return nil, errors.New("presignature has already been used")
+	}
+	if err := protocol.MarkPresignatureUsed(guard, alicePresignatureGuardName, p.Id[:]); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return p.sign(hash, message)
+}
+
+// sign computes Alice's share of the signature of message and erases the secret shares of the presignature, without
+// recording it with a guard. Interactive signing calls it directly, as its presignature never leaves memory.
+func (p *AlicePresignature) sign(hash hash.Hash, message []byte) (*SignOnlineOutput, error) {
+	curve, err := curveOf(p.R)
+	if err != nil {
+		return nil, err
+	}
+	if p.InverseNonceShare == nil || p.KeyShare == nil || p.Mask == nil {
+		return nil, errors.New("presignature has already been used")
+	}
+	inverseNonceShare, keyShare, mask := p.InverseNonceShare, p.KeyShare, p.Mask
+	p.InverseNonceShare, p.KeyShare, p.Mask = nil, nil, nil
+
+	if _, err = hash.Write(message); err != nil {
+		return nil, errors.Wrap(err, "writing message to hash in alice sign online")
+	}
+	hOfMAsInteger, err := curve.Scalar.SetBytes(hash.Sum(nil))
+	if err != nil {
+		return nil, errors.Wrap(err, "setting hOfMAsInteger scalar from bytes")
+	}
+	rX, err := rXOf(curve, p.R)
+	if err != nil {
+		return nil, err
+	}
+	// For derived keys sk = skA * skB + tweak, so the message coefficient of the shares of 1/k becomes H(m) + rX * tweak
+	hOfMAsInteger = hOfMAsInteger.Add(rX.Mul(p.Tweak))
+	sigA := hOfMAsInteger.Mul(inverseNonceShare).Add(rX.Mul(keyShare))
+	return &SignOnlineOutput{
+		PresignatureId: p.Id,
+		EtaSig:         mask.Add(sigA),
+	}, nil
+}
+
+// SignOnline completes the signature of message from the presignature and Alice's online output, and verifies it.
+// The id of the presignature is recorded with guard, as in AlicePresignature.SignOnline, once Alice's output was
+// checked to belong to it. The secret shares of the presignature are erased.
+func (p *BobPresignature) SignOnline(hash hash.Hash, message []byte, aliceOutput *SignOnlineOutput, guard protocol.PresignatureGuard) (*curves.EcdsaSignature, error) {
+	if err := p.checkAliceOutput(aliceOutput); err != nil {
+		return nil, err
+	}
+	if p.InverseNonceShare == nil || p.KeyShare == nil || p.Mask == nil {
+		return nil, errors.New("presignature has already been used")
+	}
+	if err := protocol.MarkPresignatureUsed(guard, bobPresignatureGuardName, p.Id[:]); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return p.sign(hash, message, aliceOutput)
+}
+
+// checkAliceOutput checks that aliceOutput was computed from the presignature of Alice that matches this one
+func (p *BobPresignature) checkAliceOutput(aliceOutput *SignOnlineOutput) error {
+	if aliceOutput == nil || aliceOutput.EtaSig == nil {
+		return errors.New("alice's online output is missing")
+	}
+	if aliceOutput.PresignatureId != p.Id {
+		return errors.New("alice signed with a different presignature")
+	}
+	return nil
+}
+
+// sign completes the signature of message and erases the secret shares of the presignature, without recording it
+// with a guard. Interactive signing calls it directly, as its presignature never leaves memory.
+func (p *BobPresignature) sign(hash hash.Hash, message []byte, aliceOutput *SignOnlineOutput) (*curves.EcdsaSignature, error) {
+	if err := p.checkAliceOutput(aliceOutput); err != nil {
+		return nil, err
+	}
+	curve, err := curveOf(p.R)
+	if err != nil {
+		return nil, err
+	}
+	if p.InverseNonceShare == nil || p.KeyShare == nil || p.Mask == nil {
+		return nil, errors.New("presignature has already been used")
+	}
+	inverseNonceShare, keyShare, mask := p.InverseNonceShare, p.KeyShare, p.Mask
+	p.InverseNonceShare, p.KeyShare, p.Mask = nil, nil, nil
+
+	affineCompressedForm := p.R.ToAffineCompressed()
+	if len(affineCompressedForm) != 33 {
+		return nil, errors.New("the compressed form must be exactly 33 bytes")
+	}
+	ellipticCurve, err := curve.ToEllipticCurve()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid curve")
+	}
+	halfOrder := new(big.Int).Rsh(ellipticCurve.Params().N, 1)
+	rY := affineCompressedForm[0] & 0x1 // this is bit(0) of Y coordinate
+	capitalR, err := rXOf(curve, p.R)
+	if err != nil {
+		return nil, err
+	}
+	signature := &curves.EcdsaSignature{
+		R: capitalR.BigInt(),
+		V: int(rY),
+	}
+	// bit(1) of the recovery id is set when the x coordinate of R was reduced by the group order
+	if new(big.Int).SetBytes(affineCompressedForm[1:]).Cmp(ellipticCurve.Params().N) >= 0 {
+		signature.V |= 2
+	}
+	if _, err = hash.Write(message); err != nil {
+		return nil, errors.Wrap(err, "writing message to hash in bob sign online")
+	}
+	digestBytes := hash.Sum(nil)
+	digest, err := curve.Scalar.SetBytes(digestBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting digest scalar from bytes")
+	}
+	// For derived keys sk = skA * skB + tweak, so the message coefficient of the shares of 1/k becomes H(m) + r * tweak
+	sigB := digest.Add(capitalR.Mul(p.Tweak)).Mul(inverseNonceShare).Add(capitalR.Mul(keyShare))
+	scalarS := sigB.Add(aliceOutput.EtaSig.Sub(mask))
+	signature.S = scalarS.BigInt()
+	if signature.S.Cmp(halfOrder) > 0 {
+		signature.S = scalarS.Neg().BigInt()
+		signature.V ^= 1
+	}
+	// now verify the signature
+	unCompressedAffinePublicKey := p.PublicKey.ToAffineUncompressed()
+	if len(unCompressedAffinePublicKey) != 65 {
+		return nil, errors.New("the uncompressed form must have exactly 65 bytes")
+	}
+	x := new(big.Int).SetBytes(unCompressedAffinePublicKey[1:33])
+	y := new(big.Int).SetBytes(unCompressedAffinePublicKey[33:])
+	if !ecdsa.Verify(&ecdsa.PublicKey{Curve: ellipticCurve, X: x, Y: y}, digestBytes, signature.R, signature.S) {
+		return nil, fmt.Errorf("final signature failed to verify")
+	}
+	return signature, nil
+}
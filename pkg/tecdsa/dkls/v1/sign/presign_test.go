@@ -0,0 +1,119 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sign
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
+	"github.com/coinbase/kryptology/pkg/ot/extension/kos"
+	"github.com/coinbase/kryptology/pkg/ot/ottest"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/dkg"
+)
+
+func presign(t *testing.T, curve *curves.Curve, aliceOutput *dkg.AliceOutput, bobOutput *dkg.BobOutput) (*AlicePresignature, *BobPresignature) {
+	alice := NewAlice(curve, sha3.New256(), aliceOutput)
+	bob := NewBob(curve, sha3.New256(), bobOutput)
+	seed, err := alice.Round1GenerateRandomSeed()
+	require.NoError(t, err)
+	round2Output, err := bob.Round2Initialize(seed)
+	require.NoError(t, err)
+	round3Output, err := alice.Round3Presign(round2Output)
+	require.NoError(t, err)
+	require.Nil(t, round3Output.EtaSig)
+	require.NoError(t, bob.Round4Presign(round3Output))
+	require.Equal(t, alice.Presignature.Id, bob.Presignature.Id)
+	require.True(t, alice.Presignature.R.Equal(bob.Presignature.R))
+	return alice.Presignature, bob.Presignature
+}
+
+func TestPresign(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		hashKeySeed := [simplest.DigestSize]byte{}
+		_, err := rand.Read(hashKeySeed[:])
+		require.NoError(t, err)
+		baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, hashKeySeed)
+		require.NoError(t, err)
+
+		secretKeyShareA := curve.Scalar.Random(rand.Reader)
+		secretKeyShareB := curve.Scalar.Random(rand.Reader)
+		publicKey := curve.ScalarBaseMult(secretKeyShareA.Mul(secretKeyShareB))
+		aliceOutput := &dkg.AliceOutput{SeedOtResult: baseOtReceiverOutput, SecretKeyShare: secretKeyShareA, PublicKey: publicKey}
+		bobOutput := &dkg.BobOutput{SeedOtResult: baseOtSenderOutput, SecretKeyShare: secretKeyShareB, PublicKey: publicKey}
+
+		alicePresignature, bobPresignature := presign(t, curve, aliceOutput, bobOutput)
+		message := []byte("A presigned message.")
+		aliceCopy, bobCopy := *alicePresignature, *bobPresignature
+
+		// Signing requires a guard
+		_, err = alicePresignature.SignOnline(sha3.New256(), message, nil)
+		require.ErrorIs(t, err, protocol.ErrNilPresignatureGuard)
+		require.NotNil(t, alicePresignature.KeyShare)
+
+		guard := protocol.NewMemoryPresignatureGuard()
+		onlineOutput, err := alicePresignature.SignOnline(sha3.New256(), message, guard)
+		require.NoError(t, err)
+		signature, err := bobPresignature.SignOnline(sha3.New256(), message, onlineOutput, guard)
+		require.NoError(t, err, "curve: %s", curve.Name)
+		require.NotNil(t, signature)
+
+		// A used presignature refuses to sign again, even the same message
+		_, err = alicePresignature.SignOnline(sha3.New256(), []byte("Another message."), guard)
+		require.Error(t, err)
+		_, err = bobPresignature.SignOnline(sha3.New256(), message, onlineOutput, guard)
+		require.Error(t, err)
+
+		// Copies of the used presignatures are refused by the guard
+		_, err = aliceCopy.SignOnline(sha3.New256(), []byte("Another message."), guard)
+		require.ErrorIs(t, err, protocol.ErrPresignatureUsed)
+		_, err = bobCopy.SignOnline(sha3.New256(), message, onlineOutput, guard)
+		require.ErrorIs(t, err, protocol.ErrPresignatureUsed)
+
+		// Bob refuses online outputs of other presignatures without using his own
+		alicePresignature, bobPresignature = presign(t, curve, aliceOutput, bobOutput)
+		otherAlicePresignature, _ := presign(t, curve, aliceOutput, bobOutput)
+		onlineOutput, err = otherAlicePresignature.SignOnline(sha3.New256(), message, guard)
+		require.NoError(t, err)
+		_, err = bobPresignature.SignOnline(sha3.New256(), message, onlineOutput, guard)
+		require.Error(t, err)
+		require.NotNil(t, bobPresignature.KeyShare)
+		onlineOutput, err = alicePresignature.SignOnline(sha3.New256(), message, guard)
+		require.NoError(t, err)
+		_, err = bobPresignature.SignOnline(sha3.New256(), message, onlineOutput, guard)
+		require.NoError(t, err)
+	}
+}
+
+func TestRXOfReducesX(t *testing.T) {
+	curve := curves.K256()
+	ellipticCurve, err := curve.ToEllipticCurve()
+	require.NoError(t, err)
+	n := ellipticCurve.Params().N
+
+	// Find a point whose x coordinate is not less than the group order
+	x := new(big.Int).Set(n)
+	var r curves.Point
+	for r == nil {
+		compressed := make([]byte, 33)
+		compressed[0] = 2
+		x.FillBytes(compressed[1:])
+		if r, err = curve.Point.FromAffineCompressed(compressed); err != nil {
+			r = nil
+			x.Add(x, big.NewInt(1))
+		}
+	}
+	rX, err := rXOf(curve, r)
+	require.NoError(t, err)
+	require.Equal(t, new(big.Int).Sub(x, n), rX.BigInt())
+}
@@ -10,17 +10,14 @@
 package sign
 
 import (
-	"crypto/ecdsa"
 	"crypto/rand"
-	"fmt"
 	"hash"
-	"math/big"
 
-	"github.com/gtank/merlin"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
 
 	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/transcript"
 	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
 	"github.com/coinbase/kryptology/pkg/ot/extension/kos"
 	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/dkg"
@@ -32,12 +29,16 @@ const multiplicationCount = 2
 // Alice struct encoding Alice's state during one execution of the overall signing algorithm.
 // At the end of the joint computation, Alice will not possess the signature.
 type Alice struct {
+	// Presignature is the message independent output of Round3Presign, consumed by its SignOnline.
+	Presignature *AlicePresignature
+
 	hash           hash.Hash // which hash function should we use to compute message (i.e, teh digest)
 	seedOtResults  *simplest.ReceiverOutput
 	secretKeyShare curves.Scalar // the witness
 	publicKey      curves.Point
+	tweak          curves.Scalar // the joint secret key is skA * skB + tweak
 	curve          *curves.Curve
-	transcript     *merlin.Transcript
+	transcript     *transcript.Transcript
 }
 
 // Bob struct encoding Bob's state during one execution of the overall signing algorithm.
@@ -46,11 +47,15 @@ type Bob struct {
 	// Signature is the resulting digital signature and is the output of this protocol.
 	Signature *curves.EcdsaSignature
 
+	// Presignature is the message independent output of Round4Presign, consumed by its SignOnline.
+	Presignature *BobPresignature
+
 	hash           hash.Hash // which hash function should we use to compute message
 	seedOtResults  *simplest.SenderOutput
 	secretKeyShare curves.Scalar
 	publicKey      curves.Point
-	transcript     *merlin.Transcript
+	tweak          curves.Scalar // the joint secret key is skA * skB + tweak
+	transcript     *transcript.Transcript
 	// multiplyReceivers are 2 receivers that are used to perform the two multiplications needed:
 	// 1. (phi + 1/kA) * (1/kB)
 	// 2. skA/KA * skB/kB
@@ -68,7 +73,8 @@ func NewAlice(curve *curves.Curve, hash hash.Hash, dkgOutput *dkg.AliceOutput) *
 		curve:          curve,
 		secretKeyShare: dkgOutput.SecretKeyShare,
 		publicKey:      dkgOutput.PublicKey,
-		transcript:     merlin.NewTranscript("Coinbase_DKLs_Sign"),
+		tweak:          tweakOrZero(curve, dkgOutput.Tweak),
+		transcript:     transcript.NewTranscript("Coinbase_DKLs_Sign"),
 	}
 }
 
@@ -81,8 +87,22 @@ func NewBob(curve *curves.Curve, hash hash.Hash, dkgOutput *dkg.BobOutput) *Bob
 		curve:          curve,
 		secretKeyShare: dkgOutput.SecretKeyShare,
 		publicKey:      dkgOutput.PublicKey,
-		transcript:     merlin.NewTranscript("Coinbase_DKLs_Sign"),
+		tweak:          tweakOrZero(curve, dkgOutput.Tweak),
+		transcript:     transcript.NewTranscript("Coinbase_DKLs_Sign"),
+	}
+}
+
+// tweakOrZero returns the tweak of a derived DKG output, or zero for the key output by DKG
+func tweakOrZero(curve *curves.Curve, tweak curves.Scalar) curves.Scalar {
+	if tweak == nil {
+		return curve.Scalar.Zero()
 	}
+	return tweak
+}
+
+// untweakedPublicKey returns skA * skB * G, the public key the multiplication consistency checks are made against
+func untweakedPublicKey(curve *curves.Curve, publicKey curves.Point, tweak curves.Scalar) curves.Point {
+	return publicKey.Sub(curve.ScalarBaseMult(tweak))
 }
 
 // SignRound2Output is the output of the 3rd round of the protocol.
@@ -182,6 +202,22 @@ func (bob *Bob) Round2Initialize(aliceSeed [simplest.DigestSize]byte) (*SignRoun
 // then to use the _output_ of the multiplication (which she already possesses as of the end of her computation),
 // and use that to compute some final values which will help Bob compute the final signature.
 func (alice *Alice) Round3Sign(message []byte, round2Output *SignRound2Output) (*SignRound3Output, error) {
+	round3Output, err := alice.Round3Presign(round2Output)
+	if err != nil {
+		return nil, errors.Wrap(err, "presigning in alice round 3 sign")
+	}
+	onlineOutput, err := alice.Presignature.sign(alice.hash, message)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing presignature in alice round 3 sign")
+	}
+	round3Output.EtaSig = onlineOutput.EtaSig
+	return round3Output, nil
+}
+
+// Round3Presign is Round3Sign without the message dependent step. It finishes the instance key / nonce and the
+// multiplications, and stores Alice's shares of them in `Alice.Presignature`. The returned output has no EtaSig;
+// that is computed later by `AlicePresignature.SignOnline` once the message is known.
+func (alice *Alice) Round3Presign(round2Output *SignRound2Output) (*SignRound3Output, error) {
 	alice.transcript.AppendMessage([]byte("session_id_bob"), round2Output.Seed[:])
 
 	multiplySenders := [multiplicationCount]*MultiplySender{}
@@ -233,26 +269,8 @@ func (alice *Alice) Round3Sign(message []byte, round2Output *SignRound2Output) (
 		return nil, errors.Wrap(err, "setting hashGamma1 scalar from bytes")
 	}
 	round3Output.EtaPhi = hashGamma1.Add(phi)
-	if _, err = alice.hash.Write(message); err != nil {
-		return nil, errors.Wrap(err, "writing message to hash in alice round 4 sign")
-	}
-	digest := alice.hash.Sum(nil)
-	hOfMAsInteger, err := alice.curve.Scalar.SetBytes(digest)
-	if err != nil {
-		return nil, errors.Wrap(err, "setting hOfMAsInteger scalar from bytes")
-	}
-	affineCompressedForm := r.ToAffineCompressed()
-	if len(affineCompressedForm) != 33 {
-		return nil, errors.New("the compressed form must be exactly 33 bytes")
-	}
-	// Discard the leading byte and parse the rest as the X coordinate.
-	rX, err := alice.curve.Scalar.SetBytes(affineCompressedForm[1:])
-	if err != nil {
-		return nil, errors.Wrap(err, "setting rX scalar from bytes")
-	}
 
-	sigA := hOfMAsInteger.Mul(multiplySenders[0].outputAdditiveShare).Add(rX.Mul(multiplySenders[1].outputAdditiveShare))
-	gamma2 := alice.publicKey.Mul(multiplySenders[0].outputAdditiveShare)
+	gamma2 := untweakedPublicKey(alice.curve, alice.publicKey, alice.tweak).Mul(multiplySenders[0].outputAdditiveShare)
 	other = alice.curve.ScalarBaseMult(multiplySenders[1].outputAdditiveShare.Neg())
 	gamma2 = gamma2.Add(other)
 	hashGamma2Bytes := sha3.Sum256(gamma2.ToAffineCompressed())
@@ -260,7 +278,14 @@ func (alice *Alice) Round3Sign(message []byte, round2Output *SignRound2Output) (
 	if err != nil {
 		return nil, errors.Wrap(err, "setting hashGamma2 scalar from bytes")
 	}
-	round3Output.EtaSig = hashGamma2.Add(sigA)
+	alice.Presignature = &AlicePresignature{
+		R:                 r,
+		InverseNonceShare: multiplySenders[0].outputAdditiveShare,
+		KeyShare:          multiplySenders[1].outputAdditiveShare,
+		Mask:              hashGamma2,
+		Tweak:             alice.tweak,
+	}
+	copy(alice.Presignature.Id[:], alice.transcript.ExtractBytes([]byte("presignature id"), simplest.DigestSize))
 	return round3Output, nil
 }
 
@@ -270,6 +295,24 @@ func (alice *Alice) Round3Sign(message []byte, round2Output *SignRound2Output) (
 // Bob then move's onto the remainder of Alice's message, which contains extraneous data used to finish the signature.
 // Using this data, Bob completes the signature, which gets stored in `Bob.Sig`. Bob also verifies it.
 func (bob *Bob) Round4Final(message []byte, round3Output *SignRound3Output) error {
+	if err := bob.Round4Presign(round3Output); err != nil {
+		return errors.Wrap(err, "presigning in bob round 4 final")
+	}
+	signature, err := bob.Presignature.sign(bob.hash, message, &SignOnlineOutput{
+		PresignatureId: bob.Presignature.Id,
+		EtaSig:         round3Output.EtaSig,
+	})
+	if err != nil {
+		return errors.Wrap(err, "signing presignature in bob round 4 final")
+	}
+	bob.Signature = signature
+	return nil
+}
+
+// Round4Presign is Round4Final without the message dependent step. Bob finishes the multiplications, verifies R and
+// stores his shares in `Bob.Presignature`. The EtaSig of round3Output, if any, is ignored; the signature is completed
+// by `BobPresignature.SignOnline` with the output of `AlicePresignature.SignOnline`.
+func (bob *Bob) Round4Presign(round3Output *SignRound3Output) error {
 	if err := bob.multiplyReceivers[0].Round3Multiply(round3Output.MultiplyRound2Outputs[0]); err != nil {
 		return errors.Wrap(err, "error in round 3 multiply 0 within sign round 5")
 	}
@@ -291,20 +334,6 @@ func (bob *Bob) Round4Final(message []byte, round3Output *SignRound3Output) erro
 	if err = schnorr.Verify(round3Output.RSchnorrProof, bob.curve, bob.dB, uniqueSessionId[:]); err != nil {
 		return errors.Wrap(err, "bob's verification of alice's schnorr proof re: r failed")
 	}
-	zero := bob.curve.Scalar.Zero()
-	affineCompressedForm := r.ToAffineCompressed()
-	if len(affineCompressedForm) != 33 {
-		return errors.New("the compressed form must be exactly 33 bytes")
-	}
-	rY := affineCompressedForm[0] & 0x1 // this is bit(0) of Y coordinate
-	rX, err := bob.curve.Scalar.SetBytes(affineCompressedForm[1:])
-	if err != nil {
-		return errors.Wrap(err, "setting rX scalar from bytes")
-	}
-	bob.Signature = &curves.EcdsaSignature{
-		R: rX.Add(zero).BigInt(), // slight trick here; add it to 0 just to mod it by q (now it's mod p!)
-		V: int(rY),
-	}
 	gamma1 := r.Mul(bob.multiplyReceivers[0].outputAdditiveShare)
 	gamma1HashedBytes := sha3.Sum256(gamma1.ToAffineCompressed())
 	gamma1Hashed, err := bob.curve.Scalar.SetBytes(gamma1HashedBytes[:])
@@ -313,46 +342,22 @@ func (bob *Bob) Round4Final(message []byte, round3Output *SignRound3Output) erro
 	}
 	phi := round3Output.EtaPhi.Sub(gamma1Hashed)
 	theta := bob.multiplyReceivers[0].outputAdditiveShare.Sub(phi.Div(bob.kB))
-	if _, err = bob.hash.Write(message); err != nil {
-		return errors.Wrap(err, "writing message to hash in Bob sign round 5 final")
-	}
-	digestBytes := bob.hash.Sum(nil)
-	digest, err := bob.curve.Scalar.SetBytes(digestBytes)
-	if err != nil {
-		return errors.Wrap(err, "setting digest scalar from bytes")
-	}
-	capitalR, err := bob.curve.Scalar.SetBigInt(bob.Signature.R)
-	if err != nil {
-		return errors.Wrap(err, "setting capitalR scalar from big int")
-	}
-	sigB := digest.Mul(theta).Add(capitalR.Mul(bob.multiplyReceivers[1].outputAdditiveShare))
 	gamma2 := bob.curve.ScalarBaseMult(bob.multiplyReceivers[1].outputAdditiveShare)
-	other := bob.publicKey.Mul(theta.Neg())
+	other := untweakedPublicKey(bob.curve, bob.publicKey, bob.tweak).Mul(theta.Neg())
 	gamma2 = gamma2.Add(other)
 	gamma2HashedBytes := sha3.Sum256(gamma2.ToAffineCompressed())
 	gamma2Hashed, err := bob.curve.Scalar.SetBytes(gamma2HashedBytes[:])
 	if err != nil {
 		return errors.Wrap(err, "setting gamma2Hashed scalar from bytes")
 	}
-	scalarS := sigB.Add(round3Output.EtaSig.Sub(gamma2Hashed))
-	bob.Signature.S = scalarS.BigInt()
-	if bob.Signature.S.Bit(255) == 1 {
-		bob.Signature.S = scalarS.Neg().BigInt()
-		bob.Signature.V ^= 1
-	}
-	// now verify the signature
-	unCompressedAffinePublicKey := bob.publicKey.ToAffineUncompressed()
-	if len(unCompressedAffinePublicKey) != 65 {
-		return errors.New("the uncompressed form must have exactly 65 bytes")
-	}
-	x := new(big.Int).SetBytes(unCompressedAffinePublicKey[1:33])
-	y := new(big.Int).SetBytes(unCompressedAffinePublicKey[33:])
-	ellipticCurve, err := bob.curve.ToEllipticCurve()
-	if err != nil {
-		return errors.Wrap(err, "invalid curve")
-	}
-	if !ecdsa.Verify(&ecdsa.PublicKey{Curve: ellipticCurve, X: x, Y: y}, digestBytes, bob.Signature.R, bob.Signature.S) {
-		return fmt.Errorf("final signature failed to verify")
+	bob.Presignature = &BobPresignature{
+		PublicKey:         bob.publicKey,
+		R:                 r,
+		InverseNonceShare: theta,
+		KeyShare:          bob.multiplyReceivers[1].outputAdditiveShare,
+		Mask:              gamma2Hashed,
+		Tweak:             bob.tweak,
 	}
+	copy(bob.Presignature.Id[:], bob.transcript.ExtractBytes([]byte("presignature id"), simplest.DigestSize))
 	return nil
 }
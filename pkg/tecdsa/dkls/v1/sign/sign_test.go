@@ -49,6 +49,11 @@ func TestSign(t *testing.T) {
 		require.NoError(t, err)
 		err = bob.Round4Final(message, round4Output)
 		require.NoError(t, err, "curve: %s", curve.Name)
+
+		digest := sha3.Sum256(message)
+		recovered, err := curves.RecoverPublicKey(curve, digest[:], bob.Signature)
+		require.NoError(t, err)
+		require.True(t, publicKey.Equal(recovered), "curve: %s", curve.Name)
 	}
 }
 
@@ -0,0 +1,225 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package sign
+
+import (
+	"bytes"
+	"encoding/gob"
+	"hash"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/transcript"
+	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
+	"github.com/coinbase/kryptology/pkg/ot/extension/kos"
+)
+
+func init() {
+	gob.Register(&curves.ScalarK256{})
+	gob.Register(&curves.PointK256{})
+	gob.Register(&curves.ScalarP256{})
+	gob.Register(&curves.PointP256{})
+}
+
+type multiplyReceiverState struct {
+	COtReceiver         *kos.Receiver
+	OutputAdditiveShare curves.Scalar
+	Omega               [kos.COtBlockSizeBytes]byte
+	Curve               string
+	Transcript          *transcript.Transcript
+	UniqueSessionId     [simplest.DigestSize]byte
+}
+
+type aliceState struct {
+	Presignature   *AlicePresignature
+	SeedOtResults  *simplest.ReceiverOutput
+	SecretKeyShare curves.Scalar
+	PublicKey      curves.Point
+	Tweak          curves.Scalar
+	Curve          string
+	Transcript     *transcript.Transcript
+}
+
+type bobState struct {
+	Signature      *curves.EcdsaSignature
+	Presignature   *BobPresignature
+	SeedOtResults  *simplest.SenderOutput
+	SecretKeyShare curves.Scalar
+	PublicKey      curves.Point
+	Tweak          curves.Scalar
+	Transcript     *transcript.Transcript
+	// MultiplyReceivers is empty before round 2.
+	MultiplyReceivers []*MultiplyReceiver
+	KB                curves.Scalar
+	DB                curves.Point
+	Curve             string
+}
+
+func curveByName(name string) (*curves.Curve, error) {
+	curve := curves.GetCurveByName(name)
+	if curve == nil {
+		return nil, errors.Errorf("unknown curve %s", name)
+	}
+	return curve, nil
+}
+
+func encodeState(state interface{}, name string) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(state); err != nil {
+		return nil, errors.Wrapf(err, "encoding %s", name)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeState(data []byte, state interface{}, name string) error {
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(state); err != nil {
+		return errors.Wrapf(err, "decoding %s", name)
+	}
+	return nil
+}
+
+// MarshalBinary serializes the state of the receiver between the rounds of the multiplication.
+func (receiver *MultiplyReceiver) MarshalBinary() ([]byte, error) {
+	return encodeState(&multiplyReceiverState{
+		COtReceiver:         receiver.cOtReceiver,
+		OutputAdditiveShare: receiver.outputAdditiveShare,
+		Omega:               receiver.omega,
+		Curve:               receiver.curve.Name,
+		Transcript:          receiver.transcript,
+		UniqueSessionId:     receiver.uniqueSessionId,
+	}, "multiply receiver")
+}
+
+// UnmarshalBinary restores the state of the receiver serialized by MarshalBinary.
+func (receiver *MultiplyReceiver) UnmarshalBinary(data []byte) error {
+	state := new(multiplyReceiverState)
+	if err := decodeState(data, state, "multiply receiver"); err != nil {
+		return err
+	}
+	curve, err := curveByName(state.Curve)
+	if err != nil {
+		return err
+	}
+	if state.COtReceiver == nil || state.Transcript == nil {
+		return errors.New("multiply receiver is incomplete")
+	}
+	gadget, err := generateGadgetVector(curve)
+	if err != nil {
+		return errors.Wrap(err, "error generating gadget vector in restored multiply receiver")
+	}
+	*receiver = MultiplyReceiver{
+		cOtReceiver:         state.COtReceiver,
+		outputAdditiveShare: state.OutputAdditiveShare,
+		omega:               state.Omega,
+		gadget:              gadget,
+		curve:               curve,
+		transcript:          state.Transcript,
+		uniqueSessionId:     state.UniqueSessionId,
+	}
+	return nil
+}
+
+// MarshalBinary serializes the state of Alice between the rounds of signing or presigning. The hash function is not
+// serialized. The state contains Alice's secret key share and must be kept secret.
+func (alice *Alice) MarshalBinary() ([]byte, error) {
+	return encodeState(&aliceState{
+		Presignature:   alice.Presignature,
+		SeedOtResults:  alice.seedOtResults,
+		SecretKeyShare: alice.secretKeyShare,
+		PublicKey:      alice.publicKey,
+		Tweak:          alice.tweak,
+		Curve:          alice.curve.Name,
+		Transcript:     alice.transcript,
+	}, "alice sign state")
+}
+
+// RestoreAlice restores Alice from the output of MarshalBinary, with the hash function hash.
+func RestoreAlice(hash hash.Hash, data []byte) (*Alice, error) {
+	state := new(aliceState)
+	if err := decodeState(data, state, "alice sign state"); err != nil {
+		return nil, err
+	}
+	curve, err := curveByName(state.Curve)
+	if err != nil {
+		return nil, err
+	}
+	if state.SeedOtResults == nil || state.Transcript == nil {
+		return nil, errors.New("alice sign state is incomplete")
+	}
+	return &Alice{
+		Presignature:   state.Presignature,
+		hash:           hash,
+		seedOtResults:  state.SeedOtResults,
+		secretKeyShare: state.SecretKeyShare,
+		publicKey:      state.PublicKey,
+		tweak:          state.Tweak,
+		curve:          curve,
+		transcript:     state.Transcript,
+	}, nil
+}
+
+// MarshalBinary serializes the state of Bob between the rounds of signing or presigning. The hash function is not
+// serialized. The state contains Bob's secret key share and nonce and must be kept secret.
+func (bob *Bob) MarshalBinary() ([]byte, error) {
+	state := &bobState{
+		Signature:      bob.Signature,
+		Presignature:   bob.Presignature,
+		SeedOtResults:  bob.seedOtResults,
+		SecretKeyShare: bob.secretKeyShare,
+		PublicKey:      bob.publicKey,
+		Tweak:          bob.tweak,
+		Transcript:     bob.transcript,
+		KB:             bob.kB,
+		DB:             bob.dB,
+		Curve:          bob.curve.Name,
+	}
+	if bob.multiplyReceivers[0] != nil {
+		state.MultiplyReceivers = bob.multiplyReceivers[:]
+	}
+	return encodeState(state, "bob sign state")
+}
+
+// RestoreBob restores Bob from the output of MarshalBinary, with the hash function hash.
+func RestoreBob(hash hash.Hash, data []byte) (*Bob, error) {
+	state := new(bobState)
+	if err := decodeState(data, state, "bob sign state"); err != nil {
+		return nil, err
+	}
+	curve, err := curveByName(state.Curve)
+	if err != nil {
+		return nil, err
+	}
+	if state.SeedOtResults == nil || state.Transcript == nil {
+		return nil, errors.New("bob sign state is incomplete")
+	}
+	bob := &Bob{
+		Signature:      state.Signature,
+		Presignature:   state.Presignature,
+		hash:           hash,
+		seedOtResults:  state.SeedOtResults,
+		secretKeyShare: state.SecretKeyShare,
+		publicKey:      state.PublicKey,
+		tweak:          state.Tweak,
+		transcript:     state.Transcript,
+		kB:             state.KB,
+		dB:             state.DB,
+		curve:          curve,
+	}
+	if len(state.MultiplyReceivers) != 0 {
+		if len(state.MultiplyReceivers) != multiplicationCount {
+			return nil, errors.Errorf("expected %d multiply receivers, got %d", multiplicationCount, len(state.MultiplyReceivers))
+		}
+		for i, receiver := range state.MultiplyReceivers {
+			if receiver == nil {
+				return nil, errors.Errorf("multiply receiver %d is missing", i)
+			}
+			bob.multiplyReceivers[i] = receiver
+		}
+	}
+	return bob, nil
+}
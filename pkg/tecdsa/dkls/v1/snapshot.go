@@ -0,0 +1,368 @@
+package v1
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding"
+	"encoding/gob"
+	"fmt"
+	"hash"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/dkg"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/refresh"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/sign"
+)
+
+// The two party iterators can be suspended between rounds with MarshalBinary, and resumed, possibly in another
+// process, with UnmarshalBinary. The state of an iterator holds its secret key share, nonces and OT state, so a
+// snapshot must be kept as secret as the key share.
+//
+// Running the same round twice from one state reuses its nonces and OT state, which can leak the secret key share.
+// Hence, MarshalBinary suspends the iterator, which refuses to run further rounds, so that the snapshot is the only
+// way to continue the protocol. Moreover, each snapshot has a random id, and UnmarshalBinary refuses to restore a
+// snapshot whose id was already restored, as recorded by the iterator's SnapshotGuard. The default guard only
+// remembers the snapshots restored in the current process; a deployment where snapshots can be restored by several
+// processes must set a guard backed by shared storage with SetSnapshotGuard before calling UnmarshalBinary.
+
+var (
+	// ErrSuspended is returned by iterators whose state was serialized with MarshalBinary.
+	ErrSuspended = fmt.Errorf("the protocol was suspended")
+
+	// ErrSnapshotRestored is returned when restoring a snapshot that was already restored.
+	ErrSnapshotRestored = fmt.Errorf("the snapshot was already restored")
+)
+
+// SnapshotGuard records the ids of the snapshots that were restored.
+type SnapshotGuard interface {
+	// MarkRestored records that the snapshot with the given id is being restored. It returns ErrSnapshotRestored if
+	// the id was already recorded. Guards shared between processes must check and record the id atomically.
+	MarkRestored(id []byte) error
+}
+
+// MemorySnapshotGuard is a SnapshotGuard that records the restored snapshots in memory.
+type MemorySnapshotGuard struct {
+	mutex    sync.Mutex
+	restored map[string]bool
+}
+
+// NewMemorySnapshotGuard creates an empty MemorySnapshotGuard.
+func NewMemorySnapshotGuard() *MemorySnapshotGuard {
+	return &MemorySnapshotGuard{restored: make(map[string]bool)}
+}
+
+// MarkRestored implements SnapshotGuard.
+func (g *MemorySnapshotGuard) MarkRestored(id []byte) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.restored[string(id)] {
+		return ErrSnapshotRestored
+	}
+	g.restored[string(id)] = true
+	return nil
+}
+
+// defaultSnapshotGuard is used by iterators without a guard of their own.
+var defaultSnapshotGuard = NewMemorySnapshotGuard()
+
+// SetSnapshotGuard sets the guard that UnmarshalBinary uses to refuse restoring a snapshot twice.
+func (p *protoStepper) SetSnapshotGuard(guard SnapshotGuard) {
+	p.guard = guard
+}
+
+const (
+	snapshotAliceDkg     = "AliceDkg"
+	snapshotBobDkg       = "BobDkg"
+	snapshotAliceSign    = "AliceSign"
+	snapshotBobSign      = "BobSign"
+	snapshotAliceRefresh = "AliceRefresh"
+	snapshotBobRefresh   = "BobRefresh"
+	snapshotAlicePresign = "AlicePresign"
+	snapshotBobPresign   = "BobPresign"
+)
+
+// snapshot is the serialized state of an iterator.
+type snapshot struct {
+	Id      [32]byte
+	Kind    string
+	Version uint
+	Step    int
+	State   []byte
+
+	// Message and Digest, the hash of Message, are only set by the sign iterators. The hash function itself can't
+	// be serialized, so restored sign iterators use a hash function that always outputs Digest.
+	Message []byte
+	Digest  []byte
+}
+
+// marshal serializes the step of p and the state, and suspends p.
+func (p *protoStepper) marshal(s *snapshot, state encoding.BinaryMarshaler) ([]byte, error) {
+	if p.suspended {
+		return nil, ErrSuspended
+	}
+	var err error
+	if s.State, err = state.MarshalBinary(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if _, err = rand.Read(s.Id[:]); err != nil {
+		return nil, errors.Wrap(err, "generating snapshot id")
+	}
+	s.Version = p.version
+	s.Step = p.step
+	buf := new(bytes.Buffer)
+	if err = gob.NewEncoder(buf).Encode(s); err != nil {
+		return nil, errors.Wrap(err, "encoding snapshot")
+	}
+	p.suspended = true
+	return buf.Bytes(), nil
+}
+
+// decodeSnapshot deserializes a snapshot of the given kind.
+func decodeSnapshot(data []byte, kind string) (*snapshot, error) {
+	s := new(snapshot)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(s); err != nil {
+		return nil, errors.Wrap(err, "decoding snapshot")
+	}
+	if s.Kind != kind {
+		return nil, fmt.Errorf("snapshot of %s can't be restored as %s", s.Kind, kind)
+	}
+	return s, nil
+}
+
+// markRestored records that s is restored with the guard of p, and fails if s was restored before.
+func (p *protoStepper) markRestored(s *snapshot) error {
+	if p.guard == nil {
+		p.guard = defaultSnapshotGuard
+	}
+	return p.guard.MarkRestored(s.Id[:])
+}
+
+// resume continues p, which was set up again, at the step of s. The snapshot is only recorded as restored once
+// it is known to be valid, so that an invalid snapshot does not use up its id. p stays suspended on failure.
+func (p *protoStepper) resume(s *snapshot) error {
+	if s.Step < 0 || s.Step > len(p.steps) {
+		p.suspended = true
+		return fmt.Errorf("invalid step %d in snapshot", s.Step)
+	}
+	if err := p.markRestored(s); err != nil {
+		p.suspended = true
+		return err
+	}
+	p.step = s.Step
+	p.suspended = false
+	return nil
+}
+
+// digest returns the hash of message, using a reset hash function.
+func digest(h hash.Hash, message []byte) ([]byte, error) {
+	h.Reset()
+	if _, err := h.Write(message); err != nil {
+		return nil, errors.Wrap(err, "writing message to hash")
+	}
+	d := h.Sum(nil)
+	h.Reset()
+	return d, nil
+}
+
+// digestHash is the hash function of restored sign iterators. It ignores its input and outputs the digest of the
+// message that was computed before the iterator was suspended.
+type digestHash []byte
+
+func (d digestHash) Write(p []byte) (int, error) { return len(p), nil }
+func (d digestHash) Sum(b []byte) []byte         { return append(b, d...) }
+func (d digestHash) Reset()                      {}
+func (d digestHash) Size() int                   { return len(d) }
+func (d digestHash) BlockSize() int              { return 1 }
+
+// MarshalBinary serializes the state of the DKG and suspends a.
+func (a *AliceDkg) MarshalBinary() ([]byte, error) {
+	if a.Alice == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return a.marshal(&snapshot{Kind: snapshotAliceDkg}, a.Alice)
+}
+
+// UnmarshalBinary restores a DKG that was suspended by MarshalBinary.
+func (a *AliceDkg) UnmarshalBinary(data []byte) error {
+	s, err := decodeSnapshot(data, snapshotAliceDkg)
+	if err != nil {
+		return err
+	}
+	alice := new(dkg.Alice)
+	if err = alice.UnmarshalBinary(s.State); err != nil {
+		return err
+	}
+	a.setup(alice, s.Version)
+	return a.resume(s)
+}
+
+// MarshalBinary serializes the state of the DKG and suspends b.
+func (b *BobDkg) MarshalBinary() ([]byte, error) {
+	if b.Bob == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return b.marshal(&snapshot{Kind: snapshotBobDkg}, b.Bob)
+}
+
+// UnmarshalBinary restores a DKG that was suspended by MarshalBinary.
+func (b *BobDkg) UnmarshalBinary(data []byte) error {
+	s, err := decodeSnapshot(data, snapshotBobDkg)
+	if err != nil {
+		return err
+	}
+	bob := new(dkg.Bob)
+	if err = bob.UnmarshalBinary(s.State); err != nil {
+		return err
+	}
+	b.setup(bob, s.Version)
+	return b.resume(s)
+}
+
+// MarshalBinary serializes the state of the signing, including the message and its hash, and suspends a.
+func (a *AliceSign) MarshalBinary() ([]byte, error) {
+	if a.Alice == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	if a.suspended {
+		return nil, ErrSuspended
+	}
+	d, err := digest(a.hash, a.message)
+	if err != nil {
+		return nil, err
+	}
+	return a.marshal(&snapshot{Kind: snapshotAliceSign, Message: a.message, Digest: d}, a.Alice)
+}
+
+// UnmarshalBinary restores a signing that was suspended by MarshalBinary.
+func (a *AliceSign) UnmarshalBinary(data []byte) error {
+	s, err := decodeSnapshot(data, snapshotAliceSign)
+	if err != nil {
+		return err
+	}
+	alice, err := sign.RestoreAlice(digestHash(s.Digest), s.State)
+	if err != nil {
+		return err
+	}
+	a.setup(alice, digestHash(s.Digest), s.Message, s.Version)
+	return a.resume(s)
+}
+
+// MarshalBinary serializes the state of the signing, including the message and its hash, and suspends b.
+func (b *BobSign) MarshalBinary() ([]byte, error) {
+	if b.Bob == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	if b.suspended {
+		return nil, ErrSuspended
+	}
+	d, err := digest(b.hash, b.message)
+	if err != nil {
+		return nil, err
+	}
+	return b.marshal(&snapshot{Kind: snapshotBobSign, Message: b.message, Digest: d}, b.Bob)
+}
+
+// UnmarshalBinary restores a signing that was suspended by MarshalBinary.
+func (b *BobSign) UnmarshalBinary(data []byte) error {
+	s, err := decodeSnapshot(data, snapshotBobSign)
+	if err != nil {
+		return err
+	}
+	bob, err := sign.RestoreBob(digestHash(s.Digest), s.State)
+	if err != nil {
+		return err
+	}
+	b.setup(bob, digestHash(s.Digest), s.Message, s.Version)
+	return b.resume(s)
+}
+
+// MarshalBinary serializes the state of the refresh and suspends a.
+func (a *AliceRefresh) MarshalBinary() ([]byte, error) {
+	if a.Alice == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return a.marshal(&snapshot{Kind: snapshotAliceRefresh}, a.Alice)
+}
+
+// UnmarshalBinary restores a refresh that was suspended by MarshalBinary.
+func (a *AliceRefresh) UnmarshalBinary(data []byte) error {
+	s, err := decodeSnapshot(data, snapshotAliceRefresh)
+	if err != nil {
+		return err
+	}
+	alice := new(refresh.Alice)
+	if err = alice.UnmarshalBinary(s.State); err != nil {
+		return err
+	}
+	a.setup(alice, s.Version)
+	return a.resume(s)
+}
+
+// MarshalBinary serializes the state of the refresh and suspends b.
+func (b *BobRefresh) MarshalBinary() ([]byte, error) {
+	if b.Bob == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return b.marshal(&snapshot{Kind: snapshotBobRefresh}, b.Bob)
+}
+
+// UnmarshalBinary restores a refresh that was suspended by MarshalBinary.
+func (b *BobRefresh) UnmarshalBinary(data []byte) error {
+	s, err := decodeSnapshot(data, snapshotBobRefresh)
+	if err != nil {
+		return err
+	}
+	bob := new(refresh.Bob)
+	if err = bob.UnmarshalBinary(s.State); err != nil {
+		return err
+	}
+	b.setup(bob, s.Version)
+	return b.resume(s)
+}
+
+// MarshalBinary serializes the state of the presigning and suspends a.
+func (a *AlicePresign) MarshalBinary() ([]byte, error) {
+	if a.Alice == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return a.marshal(&snapshot{Kind: snapshotAlicePresign}, a.Alice)
+}
+
+// UnmarshalBinary restores a presigning that was suspended by MarshalBinary.
+func (a *AlicePresign) UnmarshalBinary(data []byte) error {
+	s, err := decodeSnapshot(data, snapshotAlicePresign)
+	if err != nil {
+		return err
+	}
+	alice, err := sign.RestoreAlice(nil, s.State)
+	if err != nil {
+		return err
+	}
+	a.setup(alice, s.Version)
+	return a.resume(s)
+}
+
+// MarshalBinary serializes the state of the presigning and suspends b.
+func (b *BobPresign) MarshalBinary() ([]byte, error) {
+	if b.Bob == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return b.marshal(&snapshot{Kind: snapshotBobPresign}, b.Bob)
+}
+
+// UnmarshalBinary restores a presigning that was suspended by MarshalBinary.
+func (b *BobPresign) UnmarshalBinary(data []byte) error {
+	s, err := decodeSnapshot(data, snapshotBobPresign)
+	if err != nil {
+		return err
+	}
+	bob, err := sign.RestoreBob(nil, s.State)
+	if err != nil {
+		return err
+	}
+	b.setup(bob, s.Version)
+	return b.resume(s)
+}
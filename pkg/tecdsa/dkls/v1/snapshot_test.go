@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+)
+
+type snapshotIterator interface {
+	protocol.Iterator
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// resume suspends the iterator and restores it from its snapshot into restored
+func resume(t *testing.T, iterator, restored snapshotIterator) snapshotIterator {
+	t.Helper()
+	data, err := iterator.MarshalBinary()
+	require.NoError(t, err)
+	_, err = iterator.Next(nil)
+	require.ErrorIs(t, err, ErrSuspended)
+	require.NoError(t, restored.UnmarshalBinary(data))
+	return restored
+}
+
+// runSuspendedProtocol is runIteratedProtocol where both parties are suspended and restored before every round
+func runSuspendedProtocol(t *testing.T, first, second snapshotIterator, newFirst, newSecond func() snapshotIterator) (snapshotIterator, snapshotIterator) {
+	t.Helper()
+	var (
+		message *protocol.Message
+		aErr    error
+		bErr    error
+	)
+	for aErr != protocol.ErrProtocolFinished || bErr != protocol.ErrProtocolFinished {
+		first = resume(t, first, newFirst())
+		message, bErr = first.Next(message)
+		if bErr != protocol.ErrProtocolFinished {
+			require.NoError(t, bErr)
+		}
+		second = resume(t, second, newSecond())
+		message, aErr = second.Next(message)
+		if aErr != protocol.ErrProtocolFinished {
+			require.NoError(t, aErr)
+		}
+	}
+	return resume(t, first, newFirst()), resume(t, second, newSecond())
+}
+
+func TestSnapshot(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		bob, alice := runSuspendedProtocol(t, NewBobDkg(curve, protocol.Version2), NewAliceDkg(curve, protocol.Version2),
+			func() snapshotIterator { return new(BobDkg) }, func() snapshotIterator { return new(AliceDkg) })
+		aliceResult, err := alice.Result(protocol.Version2)
+		require.NoError(t, err)
+		bobResult, err := bob.Result(protocol.Version2)
+		require.NoError(t, err)
+
+		aliceRefresh, err := NewAliceRefresh(curve, aliceResult, protocol.Version1)
+		require.NoError(t, err)
+		bobRefresh, err := NewBobRefresh(curve, bobResult, protocol.Version1)
+		require.NoError(t, err)
+		alice, bob = runSuspendedProtocol(t, aliceRefresh, bobRefresh,
+			func() snapshotIterator { return new(AliceRefresh) }, func() snapshotIterator { return new(BobRefresh) })
+		aliceResult, err = alice.Result(protocol.Version2)
+		require.NoError(t, err)
+		bobResult, err = bob.Result(protocol.Version2)
+		require.NoError(t, err)
+
+		// The restored hash function outputs the digest of the message
+		msg := []byte("signed across restarts")
+		aliceSign, err := NewAliceSign(curve, sha3.New256(), msg, aliceResult, protocol.Version2)
+		require.NoError(t, err)
+		bobSign, err := NewBobSign(curve, sha3.New256(), msg, bobResult, protocol.Version2)
+		require.NoError(t, err)
+		_, bob = runSuspendedProtocol(t, aliceSign, bobSign,
+			func() snapshotIterator { return new(AliceSign) }, func() snapshotIterator { return new(BobSign) })
+		signatureMessage, err := bob.Result(protocol.Version2)
+		require.NoError(t, err)
+		signature, err := DecodeSignature(signatureMessage)
+		require.NoError(t, err)
+		require.NotNil(t, signature)
+
+		alicePresign, err := NewAlicePresign(curve, aliceResult, protocol.Version2)
+		require.NoError(t, err)
+		bobPresign, err := NewBobPresign(curve, bobResult, protocol.Version2)
+		require.NoError(t, err)
+		alice, bob = runSuspendedProtocol(t, alicePresign, bobPresign,
+			func() snapshotIterator { return new(AlicePresign) }, func() snapshotIterator { return new(BobPresign) })
+		alicePresignatureMessage, err := alice.Result(protocol.Version2)
+		require.NoError(t, err)
+		bobPresignatureMessage, err := bob.Result(protocol.Version2)
+		require.NoError(t, err)
+		alicePresignature, err := DecodeAlicePresignature(alicePresignatureMessage)
+		require.NoError(t, err)
+		bobPresignature, err := DecodeBobPresignature(bobPresignatureMessage)
+		require.NoError(t, err)
+		aliceMessage, err := AliceSignOnline(sha3.New256(), msg, alicePresignature, protocol.Version2, protocol.NewMemoryPresignatureGuard())
+		require.NoError(t, err)
+		_, err = BobSignOnline(sha3.New256(), msg, bobPresignature, aliceMessage, protocol.NewMemoryPresignatureGuard())
+		require.NoError(t, err)
+	}
+}
+
+func TestSnapshotRestoreOnce(t *testing.T) {
+	curve := curves.K256()
+	bob := NewBobDkg(curve, protocol.Version2)
+	alice := NewAliceDkg(curve, protocol.Version2)
+	message, err := bob.Next(nil)
+	require.NoError(t, err)
+	_, err = alice.Next(message)
+	require.NoError(t, err)
+
+	data, err := bob.MarshalBinary()
+	require.NoError(t, err)
+	// Only one snapshot can be taken of a state
+	_, err = bob.MarshalBinary()
+	require.ErrorIs(t, err, ErrSuspended)
+
+	// A snapshot with an invalid step is refused without being recorded as restored
+	s, err := decodeSnapshot(data, snapshotBobDkg)
+	require.NoError(t, err)
+	s.Step = 100
+	buf := new(bytes.Buffer)
+	require.NoError(t, gob.NewEncoder(buf).Encode(s))
+	guard := NewMemorySnapshotGuard()
+	invalid := new(BobDkg)
+	invalid.SetSnapshotGuard(guard)
+	require.Error(t, invalid.UnmarshalBinary(buf.Bytes()))
+	_, err = invalid.Next(nil)
+	require.ErrorIs(t, err, ErrSuspended)
+
+	restored := new(BobDkg)
+	restored.SetSnapshotGuard(guard)
+	require.NoError(t, restored.UnmarshalBinary(data))
+	again := new(BobDkg)
+	again.SetSnapshotGuard(guard)
+	require.ErrorIs(t, again.UnmarshalBinary(data), ErrSnapshotRestored)
+	_, err = again.Next(nil)
+	require.ErrorIs(t, err, ErrSuspended)
+
+	// The process wide guard applies when none is set
+	data, err = restored.MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, new(BobDkg).UnmarshalBinary(data))
+	require.ErrorIs(t, new(BobDkg).UnmarshalBinary(data), ErrSnapshotRestored)
+
+	// Snapshots are restored as the same iterator only
+	data, err = alice.MarshalBinary()
+	require.NoError(t, err)
+	require.Error(t, new(BobDkg).UnmarshalBinary(data))
+	require.Error(t, new(AliceDkg).UnmarshalBinary(data[:len(data)-1]))
+	require.NoError(t, new(AliceDkg).UnmarshalBinary(data))
+}
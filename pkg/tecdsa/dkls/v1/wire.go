@@ -0,0 +1,864 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+	"github.com/coinbase/kryptology/pkg/ot/base/simplest"
+	"github.com/coinbase/kryptology/pkg/ot/extension/kos"
+	"github.com/coinbase/kryptology/pkg/sharing"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/dkg"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/multiparty"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/refresh"
+	"github.com/coinbase/kryptology/pkg/tecdsa/dkls/v1/sign"
+	"github.com/coinbase/kryptology/pkg/zkp/schnorr"
+)
+
+// Version1 payloads are gob encoded. Version2 payloads use the wire format below, which is deterministic and does not
+// depend on Go. A payload is
+//
+//	payload = curve body
+//	curve   = bytes, the name of the curve of the scalars and points in body, empty if there are none
+//
+// and body is the concatenation of the fields of the encoded value, in the order documented by its write function.
+// Fields are encoded as
+//
+//	uint32   4 bytes, big endian
+//	bool     1 byte, 0 or 1
+//	bytes    uint32 length, followed by length bytes
+//	[n]byte  bytes, of length n
+//	big      bytes, the minimal big endian encoding of a non-negative integer
+//	scalar   bytes, the fixed length big endian encoding of the scalar, empty for nil
+//	point    bytes, the SEC1 compressed encoding of the point, 0x00 for the identity, empty for nil
+//	list     uint32 count, followed by count items
+//	*T       bool, true if T follows
+//
+// Maps are lists of entries sorted by key. Decoding fails on trailing bytes.
+
+// encodePayload encodes value with gob in version 1 and with write in version 2
+func encodePayload(version uint, value interface{}, write func(w *wireWriter)) ([]byte, error) {
+	switch version {
+	case protocol.Version1:
+		registerTypes()
+		buf := bytes.NewBuffer([]byte{})
+		enc := gob.NewEncoder(buf)
+		if err := enc.Encode(value); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return buf.Bytes(), nil
+	case protocol.Version2:
+		w := new(wireWriter)
+		write(w)
+		return w.finish()
+	default:
+		return nil, errors.New("only versions 1 and 2 are supported")
+	}
+}
+
+// decodePayload decodes payload into value with gob in version 1 and with read in version 2
+func decodePayload(version uint, payload []byte, value interface{}, read func(r *wireReader)) error {
+	switch version {
+	case protocol.Version1:
+		registerTypes()
+		dec := gob.NewDecoder(bytes.NewBuffer(payload))
+		if err := dec.Decode(value); err != nil {
+			return errors.WithStack(err)
+		}
+		return nil
+	case protocol.Version2:
+		r, err := newWireReader(payload)
+		if err != nil {
+			return err
+		}
+		read(r)
+		return r.finish()
+	default:
+		return errors.New("only versions 1 and 2 are supported")
+	}
+}
+
+// decodeMessage decodes the two party payload of m
+func decodeMessage(m *protocol.Message, value interface{}, read func(r *wireReader)) error {
+	if m == nil {
+		return errors.New("message is nil")
+	}
+	return decodePayload(m.Version, m.Payloads[payloadKey], value, read)
+}
+
+// wireWriter appends the fields of a version 2 payload. The curve is taken from the scalars and points written.
+type wireWriter struct {
+	body  bytes.Buffer
+	curve string
+	err   error
+}
+
+func (w *wireWriter) finish() ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	out := new(wireWriter)
+	out.bytes([]byte(w.curve))
+	out.body.Write(w.body.Bytes())
+	return out.body.Bytes(), nil
+}
+
+func (w *wireWriter) setCurve(name string) {
+	if w.curve == "" {
+		w.curve = name
+	} else if w.curve != name && w.err == nil {
+		w.err = fmt.Errorf("payload mixes curves %s and %s", w.curve, name)
+	}
+}
+
+func (w *wireWriter) uint32(v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	w.body.Write(b[:])
+}
+
+func (w *wireWriter) bool(v bool) {
+	if v {
+		w.body.WriteByte(1)
+	} else {
+		w.body.WriteByte(0)
+	}
+}
+
+func (w *wireWriter) bytes(v []byte) {
+	w.uint32(uint32(len(v)))
+	w.body.Write(v)
+}
+
+func (w *wireWriter) big(v *big.Int) {
+	if v == nil || v.Sign() < 0 {
+		if w.err == nil {
+			w.err = errors.New("cannot encode a nil or negative integer")
+		}
+		return
+	}
+	w.bytes(v.Bytes())
+}
+
+func (w *wireWriter) scalar(s curves.Scalar) {
+	if s == nil {
+		w.bytes(nil)
+		return
+	}
+	w.setCurve(s.Point().CurveName())
+	w.bytes(s.Bytes())
+}
+
+func (w *wireWriter) point(p curves.Point) {
+	switch {
+	case p == nil:
+		w.bytes(nil)
+	case p.IsIdentity():
+		w.setCurve(p.CurveName())
+		w.bytes([]byte{0})
+	default:
+		w.setCurve(p.CurveName())
+		w.bytes(p.ToAffineCompressed())
+	}
+}
+
+// wireReader reads the fields of a version 2 payload. The first error is kept and reported by finish.
+type wireReader struct {
+	data  []byte
+	curve *curves.Curve
+	err   error
+}
+
+func newWireReader(payload []byte) (*wireReader, error) {
+	r := &wireReader{data: payload}
+	name := r.bytes()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(name) > 0 {
+		if r.curve = curves.GetCurveByName(string(name)); r.curve == nil {
+			return nil, fmt.Errorf("unsupported curve %s", name)
+		}
+	}
+	return r, nil
+}
+
+func (r *wireReader) finish() error {
+	if r.err == nil && len(r.data) != 0 {
+		r.err = fmt.Errorf("%d trailing bytes", len(r.data))
+	}
+	return r.err
+}
+
+func (r *wireReader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *wireReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.data) {
+		r.fail(errors.New("payload is too short"))
+		return nil
+	}
+	out := r.data[:n]
+	r.data = r.data[n:]
+	return out
+}
+
+func (r *wireReader) uint32() uint32 {
+	b := r.next(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *wireReader) bool() bool {
+	b := r.next(1)
+	if b == nil {
+		return false
+	}
+	if b[0] > 1 {
+		r.fail(errors.New("invalid bool"))
+	}
+	return b[0] == 1
+}
+
+// count reads the length of a list of items of at least itemSize bytes each
+func (r *wireReader) count(itemSize int) int {
+	n := int(r.uint32())
+	if r.err == nil && n*itemSize > len(r.data) {
+		r.fail(errors.New("payload is too short"))
+		return 0
+	}
+	return n
+}
+
+func (r *wireReader) bytes() []byte {
+	n := r.uint32()
+	if r.err == nil && uint64(n) > uint64(len(r.data)) {
+		r.fail(errors.New("payload is too short"))
+	}
+	b := r.next(int(n))
+	if len(b) == 0 {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// fixed reads bytes of exactly len(dst) bytes into dst
+func (r *wireReader) fixed(dst []byte) {
+	b := r.bytes()
+	if r.err == nil && len(b) != len(dst) {
+		r.fail(fmt.Errorf("expected %d bytes, got %d", len(dst), len(b)))
+	}
+	copy(dst, b)
+}
+
+func (r *wireReader) big() *big.Int {
+	return new(big.Int).SetBytes(r.bytes())
+}
+
+func (r *wireReader) scalar() curves.Scalar {
+	b := r.bytes()
+	if r.err != nil || len(b) == 0 {
+		return nil
+	}
+	if r.curve == nil {
+		r.fail(errors.New("payload has no curve"))
+		return nil
+	}
+	s, err := r.curve.Scalar.SetBytes(b)
+	if err != nil {
+		r.fail(errors.Wrap(err, "invalid scalar"))
+		return nil
+	}
+	return s
+}
+
+func (r *wireReader) point() curves.Point {
+	b := r.bytes()
+	if r.err != nil || len(b) == 0 {
+		return nil
+	}
+	if r.curve == nil {
+		r.fail(errors.New("payload has no curve"))
+		return nil
+	}
+	if len(b) == 1 && b[0] == 0 {
+		return r.curve.Point.Identity()
+	}
+	p, err := r.curve.Point.FromAffineCompressed(b)
+	if err != nil {
+		r.fail(errors.Wrap(err, "invalid point"))
+		return nil
+	}
+	return p
+}
+
+// writeSchnorrProof writes C scalar, S scalar and Statement point
+func writeSchnorrProof(w *wireWriter, proof *schnorr.Proof) {
+	w.bool(proof != nil)
+	if proof != nil {
+		w.scalar(proof.C)
+		w.scalar(proof.S)
+		w.point(proof.Statement)
+	}
+}
+
+func readSchnorrProof(r *wireReader) *schnorr.Proof {
+	if !r.bool() {
+		return nil
+	}
+	return &schnorr.Proof{C: r.scalar(), S: r.scalar(), Statement: r.point()}
+}
+
+func writeDigests(w *wireWriter, digests [][simplest.DigestSize]byte) {
+	w.uint32(uint32(len(digests)))
+	for i := range digests {
+		w.bytes(digests[i][:])
+	}
+}
+
+func readDigests(r *wireReader) [][simplest.DigestSize]byte {
+	digests := make([][simplest.DigestSize]byte, r.count(4+simplest.DigestSize))
+	for i := range digests {
+		r.fixed(digests[i][:])
+	}
+	return digests
+}
+
+func writeDigestPairs(w *wireWriter, pairs [][2][simplest.DigestSize]byte) {
+	w.uint32(uint32(len(pairs)))
+	for i := range pairs {
+		w.bytes(pairs[i][0][:])
+		w.bytes(pairs[i][1][:])
+	}
+}
+
+func readDigestPairs(r *wireReader) [][2][simplest.DigestSize]byte {
+	pairs := make([][2][simplest.DigestSize]byte, r.count(2*(4+simplest.DigestSize)))
+	for i := range pairs {
+		r.fixed(pairs[i][0][:])
+		r.fixed(pairs[i][1][:])
+	}
+	return pairs
+}
+
+func writeMaskedChoices(w *wireWriter, choices []simplest.ReceiversMaskedChoices) {
+	w.uint32(uint32(len(choices)))
+	for _, choice := range choices {
+		w.bytes(choice)
+	}
+}
+
+func readMaskedChoices(r *wireReader) []simplest.ReceiversMaskedChoices {
+	choices := make([]simplest.ReceiversMaskedChoices, r.count(4))
+	for i := range choices {
+		choices[i] = r.bytes()
+	}
+	return choices
+}
+
+// writeSenderOutput writes OneTimePadEncryptionKeys as a list of pairs of [32]byte
+func writeSenderOutput(w *wireWriter, output *simplest.SenderOutput) {
+	w.bool(output != nil)
+	if output != nil {
+		keys := make([][2][simplest.DigestSize]byte, len(output.OneTimePadEncryptionKeys))
+		for i := range keys {
+			keys[i] = output.OneTimePadEncryptionKeys[i]
+		}
+		writeDigestPairs(w, keys)
+	}
+}
+
+func readSenderOutput(r *wireReader) *simplest.SenderOutput {
+	if !r.bool() {
+		return nil
+	}
+	pairs := readDigestPairs(r)
+	output := &simplest.SenderOutput{OneTimePadEncryptionKeys: make([]simplest.OneTimePadEncryptionKeys, len(pairs))}
+	for i := range pairs {
+		output.OneTimePadEncryptionKeys[i] = pairs[i]
+	}
+	return output
+}
+
+// writeReceiverOutput writes PackedRandomChoiceBits bytes, RandomChoiceBits as bytes of one byte per bit and
+// OneTimePadDecryptionKey as a list of [32]byte
+func writeReceiverOutput(w *wireWriter, output *simplest.ReceiverOutput) {
+	w.bool(output != nil)
+	if output == nil {
+		return
+	}
+	w.bytes(output.PackedRandomChoiceBits)
+	bits := make([]byte, len(output.RandomChoiceBits))
+	for i, bit := range output.RandomChoiceBits {
+		bits[i] = byte(bit)
+	}
+	w.bytes(bits)
+	keys := make([][simplest.DigestSize]byte, len(output.OneTimePadDecryptionKey))
+	for i := range keys {
+		keys[i] = output.OneTimePadDecryptionKey[i]
+	}
+	writeDigests(w, keys)
+}
+
+func readReceiverOutput(r *wireReader) *simplest.ReceiverOutput {
+	if !r.bool() {
+		return nil
+	}
+	output := &simplest.ReceiverOutput{PackedRandomChoiceBits: r.bytes()}
+	bits := r.bytes()
+	output.RandomChoiceBits = make([]int, len(bits))
+	for i, bit := range bits {
+		if bit > 1 {
+			r.fail(errors.New("invalid choice bit"))
+		}
+		output.RandomChoiceBits[i] = int(bit)
+	}
+	keys := readDigests(r)
+	output.OneTimePadDecryptionKey = make([]simplest.OneTimePadDecryptionKey, len(keys))
+	for i := range keys {
+		output.OneTimePadDecryptionKey[i] = keys[i]
+	}
+	return output
+}
+
+// writeDkgRound2Output writes Seed [32]byte and Commitment bytes
+func writeDkgRound2Output(w *wireWriter, output *dkg.Round2Output) {
+	w.bytes(output.Seed[:])
+	w.bytes(output.Commitment)
+}
+
+func readDkgRound2Output(r *wireReader, output *dkg.Round2Output) {
+	r.fixed(output.Seed[:])
+	output.Commitment = r.bytes()
+}
+
+// writeAliceOutput writes PublicKey point, SecretKeyShare scalar, SeedOtResult *receiver output, ChainCode bytes
+// and Tweak scalar
+func writeAliceOutput(w *wireWriter, output *dkg.AliceOutput) {
+	w.point(output.PublicKey)
+	w.scalar(output.SecretKeyShare)
+	writeReceiverOutput(w, output.SeedOtResult)
+	w.bytes(output.ChainCode)
+	w.scalar(output.Tweak)
+}
+
+func readAliceOutput(r *wireReader, output *dkg.AliceOutput) {
+	output.PublicKey = r.point()
+	output.SecretKeyShare = r.scalar()
+	output.SeedOtResult = readReceiverOutput(r)
+	output.ChainCode = r.bytes()
+	output.Tweak = r.scalar()
+}
+
+// writeBobOutput writes PublicKey point, SecretKeyShare scalar, SeedOtResult *sender output, ChainCode bytes
+// and Tweak scalar
+func writeBobOutput(w *wireWriter, output *dkg.BobOutput) {
+	w.point(output.PublicKey)
+	w.scalar(output.SecretKeyShare)
+	writeSenderOutput(w, output.SeedOtResult)
+	w.bytes(output.ChainCode)
+	w.scalar(output.Tweak)
+}
+
+func readBobOutput(r *wireReader, output *dkg.BobOutput) {
+	output.PublicKey = r.point()
+	output.SecretKeyShare = r.scalar()
+	output.SeedOtResult = readSenderOutput(r)
+	output.ChainCode = r.bytes()
+	output.Tweak = r.scalar()
+}
+
+// writeRefreshRound2Output writes SeedOTRound1Output *schnorr proof and BobMultiplier scalar
+func writeRefreshRound2Output(w *wireWriter, output *refresh.RefreshRound2Output) {
+	writeSchnorrProof(w, output.SeedOTRound1Output)
+	w.scalar(output.BobMultiplier)
+}
+
+func readRefreshRound2Output(r *wireReader, output *refresh.RefreshRound2Output) {
+	output.SeedOTRound1Output = readSchnorrProof(r)
+	output.BobMultiplier = r.scalar()
+}
+
+// writeKosRound1Output writes U as the list of its rows, each of them bytes, followed by WPrime and VPrime [32]byte
+func writeKosRound1Output(w *wireWriter, output *kos.Round1Output) {
+	w.bool(output != nil)
+	if output == nil {
+		return
+	}
+	w.uint32(uint32(len(output.U)))
+	for i := range output.U {
+		w.bytes(output.U[i][:])
+	}
+	w.bytes(output.WPrime[:])
+	w.bytes(output.VPrime[:])
+}
+
+func readKosRound1Output(r *wireReader) *kos.Round1Output {
+	if !r.bool() {
+		return nil
+	}
+	output := new(kos.Round1Output)
+	if n := r.uint32(); r.err == nil && int(n) != len(output.U) {
+		r.fail(fmt.Errorf("expected %d rows, got %d", len(output.U), n))
+		return nil
+	}
+	for i := range output.U {
+		r.fixed(output.U[i][:])
+	}
+	r.fixed(output.WPrime[:])
+	r.fixed(output.VPrime[:])
+	return output
+}
+
+// writeMultiplyRound2Output writes the L x 2 scalars Tau of the cOT output row by row as *list, R as a list of L
+// scalars and U scalar
+func writeMultiplyRound2Output(w *wireWriter, output *sign.MultiplyRound2Output) {
+	w.bool(output != nil)
+	if output == nil {
+		return
+	}
+	w.bool(output.COTRound2Output != nil)
+	if output.COTRound2Output != nil {
+		tau := output.COTRound2Output.Tau
+		w.uint32(uint32(len(tau) * len(tau[0])))
+		for i := range tau {
+			for j := range tau[i] {
+				w.scalar(tau[i][j])
+			}
+		}
+	}
+	w.uint32(uint32(len(output.R)))
+	for _, s := range output.R {
+		w.scalar(s)
+	}
+	w.scalar(output.U)
+}
+
+func readMultiplyRound2Output(r *wireReader) *sign.MultiplyRound2Output {
+	if !r.bool() {
+		return nil
+	}
+	output := new(sign.MultiplyRound2Output)
+	if r.bool() {
+		output.COTRound2Output = new(kos.Round2Output)
+		tau := &output.COTRound2Output.Tau
+		if n := r.uint32(); r.err == nil && int(n) != len(tau)*len(tau[0]) {
+			r.fail(fmt.Errorf("expected %d scalars, got %d", len(tau)*len(tau[0]), n))
+			return nil
+		}
+		for i := range tau {
+			for j := range tau[i] {
+				tau[i][j] = r.scalar()
+			}
+		}
+	}
+	if n := r.uint32(); r.err == nil && int(n) != len(output.R) {
+		r.fail(fmt.Errorf("expected %d scalars, got %d", len(output.R), n))
+		return nil
+	}
+	for i := range output.R {
+		output.R[i] = r.scalar()
+	}
+	output.U = r.scalar()
+	return output
+}
+
+// writeSignRound2Output writes KosRound1Outputs as a list of *cOT outputs, DB point and Seed [32]byte
+func writeSignRound2Output(w *wireWriter, output *sign.SignRound2Output) {
+	w.uint32(uint32(len(output.KosRound1Outputs)))
+	for _, kosOutput := range output.KosRound1Outputs {
+		writeKosRound1Output(w, kosOutput)
+	}
+	w.point(output.DB)
+	w.bytes(output.Seed[:])
+}
+
+func readSignRound2Output(r *wireReader, output *sign.SignRound2Output) {
+	if n := r.uint32(); r.err == nil && int(n) != len(output.KosRound1Outputs) {
+		r.fail(fmt.Errorf("expected %d multiplications, got %d", len(output.KosRound1Outputs), n))
+		return
+	}
+	for i := range output.KosRound1Outputs {
+		output.KosRound1Outputs[i] = readKosRound1Output(r)
+	}
+	output.DB = r.point()
+	r.fixed(output.Seed[:])
+}
+
+// writeSignRound3Output writes MultiplyRound2Outputs as a list of *multiply outputs, RSchnorrProof *schnorr proof,
+// RPrime point, EtaPhi scalar and EtaSig scalar
+func writeSignRound3Output(w *wireWriter, output *sign.SignRound3Output) {
+	w.uint32(uint32(len(output.MultiplyRound2Outputs)))
+	for _, multiplyOutput := range output.MultiplyRound2Outputs {
+		writeMultiplyRound2Output(w, multiplyOutput)
+	}
+	writeSchnorrProof(w, output.RSchnorrProof)
+	w.point(output.RPrime)
+	w.scalar(output.EtaPhi)
+	w.scalar(output.EtaSig)
+}
+
+func readSignRound3Output(r *wireReader, output *sign.SignRound3Output) {
+	if n := r.uint32(); r.err == nil && int(n) != len(output.MultiplyRound2Outputs) {
+		r.fail(fmt.Errorf("expected %d multiplications, got %d", len(output.MultiplyRound2Outputs), n))
+		return
+	}
+	for i := range output.MultiplyRound2Outputs {
+		output.MultiplyRound2Outputs[i] = readMultiplyRound2Output(r)
+	}
+	output.RSchnorrProof = readSchnorrProof(r)
+	output.RPrime = r.point()
+	output.EtaPhi = r.scalar()
+	output.EtaSig = r.scalar()
+}
+
+// writeSignature writes V uint32, R big and S big
+func writeSignature(w *wireWriter, signature *curves.EcdsaSignature) {
+	w.uint32(uint32(signature.V))
+	w.big(signature.R)
+	w.big(signature.S)
+}
+
+func readSignature(r *wireReader, signature *curves.EcdsaSignature) {
+	signature.V = int(r.uint32())
+	signature.R = r.big()
+	signature.S = r.big()
+}
+
+// writeSignOnlineOutput writes PresignatureId [32]byte and EtaSig scalar
+func writeSignOnlineOutput(w *wireWriter, output *sign.SignOnlineOutput) {
+	w.bytes(output.PresignatureId[:])
+	w.scalar(output.EtaSig)
+}
+
+func readSignOnlineOutput(r *wireReader, output *sign.SignOnlineOutput) {
+	r.fixed(output.PresignatureId[:])
+	output.EtaSig = r.scalar()
+}
+
+// writeAlicePresignature writes Id [32]byte, R point and InverseNonceShare, KeyShare, Mask and Tweak scalars
+func writeAlicePresignature(w *wireWriter, presignature *sign.AlicePresignature) {
+	w.bytes(presignature.Id[:])
+	w.point(presignature.R)
+	w.scalar(presignature.InverseNonceShare)
+	w.scalar(presignature.KeyShare)
+	w.scalar(presignature.Mask)
+	w.scalar(presignature.Tweak)
+}
+
+func readAlicePresignature(r *wireReader, presignature *sign.AlicePresignature) {
+	r.fixed(presignature.Id[:])
+	presignature.R = r.point()
+	presignature.InverseNonceShare = r.scalar()
+	presignature.KeyShare = r.scalar()
+	presignature.Mask = r.scalar()
+	presignature.Tweak = r.scalar()
+}
+
+// writeBobPresignature writes Id [32]byte, PublicKey and R points and InverseNonceShare, KeyShare, Mask and Tweak
+// scalars
+func writeBobPresignature(w *wireWriter, presignature *sign.BobPresignature) {
+	w.bytes(presignature.Id[:])
+	w.point(presignature.PublicKey)
+	w.point(presignature.R)
+	w.scalar(presignature.InverseNonceShare)
+	w.scalar(presignature.KeyShare)
+	w.scalar(presignature.Mask)
+	w.scalar(presignature.Tweak)
+}
+
+func readBobPresignature(r *wireReader, presignature *sign.BobPresignature) {
+	r.fixed(presignature.Id[:])
+	presignature.PublicKey = r.point()
+	presignature.R = r.point()
+	presignature.InverseNonceShare = r.scalar()
+	presignature.KeyShare = r.scalar()
+	presignature.Mask = r.scalar()
+	presignature.Tweak = r.scalar()
+}
+
+// writeMultiPartyDkgRound2Output writes the Feldman commitments of Verifier as *list of points, Proof *schnorr proof,
+// Share as *(uint32 id, bytes value) and SeedOtRound1Output *schnorr proof
+func writeMultiPartyDkgRound2Output(w *wireWriter, output *multiparty.DkgRound2Output) {
+	w.bool(output.Verifier != nil)
+	if output.Verifier != nil {
+		w.uint32(uint32(len(output.Verifier.Commitments)))
+		for _, commitment := range output.Verifier.Commitments {
+			w.point(commitment)
+		}
+	}
+	writeSchnorrProof(w, output.Proof)
+	w.bool(output.Share != nil)
+	if output.Share != nil {
+		w.uint32(output.Share.Id)
+		w.bytes(output.Share.Value)
+	}
+	writeSchnorrProof(w, output.SeedOtRound1Output)
+}
+
+func readMultiPartyDkgRound2Output(r *wireReader, output *multiparty.DkgRound2Output) {
+	if r.bool() {
+		output.Verifier = &sharing.FeldmanVerifier{Commitments: make([]curves.Point, r.count(4))}
+		for i := range output.Verifier.Commitments {
+			output.Verifier.Commitments[i] = r.point()
+		}
+	}
+	output.Proof = readSchnorrProof(r)
+	if r.bool() {
+		output.Share = &sharing.ShamirShare{Id: r.uint32(), Value: r.bytes()}
+	}
+	output.SeedOtRound1Output = readSchnorrProof(r)
+}
+
+// writeMultiPartySignRound2Output writes Commitment bytes and KosRound1Outputs as a list of *cOT outputs
+func writeMultiPartySignRound2Output(w *wireWriter, output *multiparty.SignRound2Output) {
+	w.bytes(output.Commitment)
+	w.uint32(uint32(len(output.KosRound1Outputs)))
+	for _, kosOutput := range output.KosRound1Outputs {
+		writeKosRound1Output(w, kosOutput)
+	}
+}
+
+func readMultiPartySignRound2Output(r *wireReader, output *multiparty.SignRound2Output) {
+	output.Commitment = r.bytes()
+	if n := r.count(1); n > 0 {
+		output.KosRound1Outputs = make([]*kos.Round1Output, n)
+		for i := range output.KosRound1Outputs {
+			output.KosRound1Outputs[i] = readKosRound1Output(r)
+		}
+	}
+}
+
+// writeMultiPartySignRound3Output writes Proof *schnorr proof and MultiplyRound2Outputs as a list of *multiply outputs
+func writeMultiPartySignRound3Output(w *wireWriter, output *multiparty.SignRound3Output) {
+	writeSchnorrProof(w, output.Proof)
+	w.uint32(uint32(len(output.MultiplyRound2Outputs)))
+	for _, multiplyOutput := range output.MultiplyRound2Outputs {
+		writeMultiplyRound2Output(w, multiplyOutput)
+	}
+}
+
+func readMultiPartySignRound3Output(r *wireReader, output *multiparty.SignRound3Output) {
+	output.Proof = readSchnorrProof(r)
+	if n := r.count(1); n > 0 {
+		output.MultiplyRound2Outputs = make([]*sign.MultiplyRound2Output, n)
+		for i := range output.MultiplyRound2Outputs {
+			output.MultiplyRound2Outputs[i] = readMultiplyRound2Output(r)
+		}
+	}
+}
+
+// writeMultiPartySignRound4Output writes GammaK and GammaSk points
+func writeMultiPartySignRound4Output(w *wireWriter, output *multiparty.SignRound4Output) {
+	w.point(output.GammaK)
+	w.point(output.GammaSk)
+}
+
+func readMultiPartySignRound4Output(r *wireReader, output *multiparty.SignRound4Output) {
+	output.GammaK = r.point()
+	output.GammaSk = r.point()
+}
+
+// writeMultiPartySignRound5Output writes U and W scalars
+func writeMultiPartySignRound5Output(w *wireWriter, output *multiparty.SignRound5Output) {
+	w.scalar(output.U)
+	w.scalar(output.W)
+}
+
+func readMultiPartySignRound5Output(r *wireReader, output *multiparty.SignRound5Output) {
+	output.U = r.scalar()
+	output.W = r.scalar()
+}
+
+// sortIds sorts party ids in ascending order
+func sortIds(ids []uint32) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
+
+// writeMultiPartyOutput writes Id and Threshold uint32, Parties as a list of uint32, PublicKey point, PublicShares
+// as a map of uint32 to point, SecretKeyShare scalar, SeedOtSenders as a map of uint32 to *sender output and
+// SeedOtReceivers as a map of uint32 to *receiver output
+func writeMultiPartyOutput(w *wireWriter, output *multiparty.Output) {
+	w.uint32(output.Id)
+	w.uint32(output.Threshold)
+	w.uint32(uint32(len(output.Parties)))
+	for _, id := range output.Parties {
+		w.uint32(id)
+	}
+	w.point(output.PublicKey)
+	ids := make([]uint32, 0, len(output.PublicShares))
+	for id := range output.PublicShares {
+		ids = append(ids, id)
+	}
+	sortIds(ids)
+	w.uint32(uint32(len(ids)))
+	for _, id := range ids {
+		w.uint32(id)
+		w.point(output.PublicShares[id])
+	}
+	w.scalar(output.SecretKeyShare)
+	ids = ids[:0]
+	for id := range output.SeedOtSenders {
+		ids = append(ids, id)
+	}
+	sortIds(ids)
+	w.uint32(uint32(len(ids)))
+	for _, id := range ids {
+		w.uint32(id)
+		writeSenderOutput(w, output.SeedOtSenders[id])
+	}
+	ids = ids[:0]
+	for id := range output.SeedOtReceivers {
+		ids = append(ids, id)
+	}
+	sortIds(ids)
+	w.uint32(uint32(len(ids)))
+	for _, id := range ids {
+		w.uint32(id)
+		writeReceiverOutput(w, output.SeedOtReceivers[id])
+	}
+}
+
+func readMultiPartyOutput(r *wireReader, output *multiparty.Output) {
+	output.Id = r.uint32()
+	output.Threshold = r.uint32()
+	output.Parties = make([]uint32, r.count(4))
+	for i := range output.Parties {
+		output.Parties[i] = r.uint32()
+	}
+	output.PublicKey = r.point()
+	n := r.count(8)
+	output.PublicShares = make(map[uint32]curves.Point, n)
+	for i := 0; i < n; i++ {
+		id := r.uint32()
+		output.PublicShares[id] = r.point()
+	}
+	output.SecretKeyShare = r.scalar()
+	n = r.count(5)
+	output.SeedOtSenders = make(map[uint32]*simplest.SenderOutput, n)
+	for i := 0; i < n; i++ {
+		id := r.uint32()
+		output.SeedOtSenders[id] = readSenderOutput(r)
+	}
+	n = r.count(5)
+	output.SeedOtReceivers = make(map[uint32]*simplest.ReceiverOutput, n)
+	for i := 0; i < n; i++ {
+		id := r.uint32()
+		output.SeedOtReceivers[id] = readReceiverOutput(r)
+	}
+}
@@ -0,0 +1,159 @@
+package v1
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+)
+
+// DKG > Sign > Refresh > Sign > Presign, all in version 2
+func TestProtoVersion2(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		aliceDkg := NewAliceDkg(curve, protocol.Version2)
+		bobDkg := NewBobDkg(curve, protocol.Version2)
+		aErr, bErr := runIteratedProtocol(bobDkg, aliceDkg)
+		require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+		require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+		aliceResult, err := aliceDkg.Result(protocol.Version2)
+		require.NoError(t, err)
+		bobResult, err := bobDkg.Result(protocol.Version2)
+		require.NoError(t, err)
+		signVersion2(t, curve, aliceResult, bobResult)
+
+		aliceRefresh, err := NewAliceRefresh(curve, aliceResult, protocol.Version2)
+		require.NoError(t, err)
+		bobRefresh, err := NewBobRefresh(curve, bobResult, protocol.Version2)
+		require.NoError(t, err)
+		aErr, bErr = runIteratedProtocol(aliceRefresh, bobRefresh)
+		require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+		require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+		aliceResult, err = aliceRefresh.Result(protocol.Version2)
+		require.NoError(t, err)
+		bobResult, err = bobRefresh.Result(protocol.Version2)
+		require.NoError(t, err)
+		signVersion2(t, curve, aliceResult, bobResult)
+
+		alicePresign, err := NewAlicePresign(curve, aliceResult, protocol.Version2)
+		require.NoError(t, err)
+		bobPresign, err := NewBobPresign(curve, bobResult, protocol.Version2)
+		require.NoError(t, err)
+		aErr, bErr = runIteratedProtocol(alicePresign, bobPresign)
+		require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+		require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+		alicePresignatureMessage, err := alicePresign.Result(protocol.Version2)
+		require.NoError(t, err)
+		bobPresignatureMessage, err := bobPresign.Result(protocol.Version2)
+		require.NoError(t, err)
+		alicePresignature, err := DecodeAlicePresignature(alicePresignatureMessage)
+		require.NoError(t, err)
+		bobPresignature, err := DecodeBobPresignature(bobPresignatureMessage)
+		require.NoError(t, err)
+		msg := []byte("presigned in version 2")
+		aliceMessage, err := AliceSignOnline(sha3.New256(), msg, alicePresignature, protocol.Version2, protocol.NewMemoryPresignatureGuard())
+		require.NoError(t, err)
+		_, err = BobSignOnline(sha3.New256(), msg, bobPresignature, aliceMessage, protocol.NewMemoryPresignatureGuard())
+		require.NoError(t, err)
+	}
+}
+
+func signVersion2(t *testing.T, curve *curves.Curve, aliceResult, bobResult *protocol.Message) {
+	t.Helper()
+	msg := []byte("signed in version 2")
+	aliceSign, err := NewAliceSign(curve, sha3.New256(), msg, aliceResult, protocol.Version2)
+	require.NoError(t, err)
+	bobSign, err := NewBobSign(curve, sha3.New256(), msg, bobResult, protocol.Version2)
+	require.NoError(t, err)
+	aErr, bErr := runIteratedProtocol(aliceSign, bobSign)
+	require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+	require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+	signatureMessage, err := bobSign.Result(protocol.Version2)
+	require.NoError(t, err)
+	signature, err := DecodeSignature(signatureMessage)
+	require.NoError(t, err)
+	require.Equal(t, bobSign.Signature, signature)
+}
+
+func TestWireDkgOutput(t *testing.T) {
+	curve := curves.K256()
+	aliceDkg := NewAliceDkg(curve, protocol.Version1)
+	bobDkg := NewBobDkg(curve, protocol.Version1)
+	aErr, bErr := runIteratedProtocol(bobDkg, aliceDkg)
+	require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+	require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+	output, err := aliceDkg.Output().DeriveChild(7)
+	require.NoError(t, err)
+
+	// The encoding is deterministic and round trips
+	message, err := EncodeAliceDkgOutput(output, protocol.Version2)
+	require.NoError(t, err)
+	again, err := EncodeAliceDkgOutput(output, protocol.Version2)
+	require.NoError(t, err)
+	require.Equal(t, message.Payloads[payloadKey], again.Payloads[payloadKey])
+	decoded, err := DecodeAliceDkgResult(message)
+	require.NoError(t, err)
+	require.True(t, output.PublicKey.Equal(decoded.PublicKey))
+	require.Equal(t, 0, output.Tweak.Cmp(decoded.Tweak))
+	require.Equal(t, output.SeedOtResult, decoded.SeedOtResult)
+	again, err = EncodeAliceDkgOutput(decoded, protocol.Version2)
+	require.NoError(t, err)
+	require.Equal(t, message.Payloads[payloadKey], again.Payloads[payloadKey])
+
+	// Malformed payloads are rejected
+	payload := message.Payloads[payloadKey]
+	message.Payloads[payloadKey] = append(append([]byte{}, payload...), 0)
+	_, err = DecodeAliceDkgResult(message)
+	require.Error(t, err)
+	message.Payloads[payloadKey] = payload[:len(payload)-1]
+	_, err = DecodeAliceDkgResult(message)
+	require.Error(t, err)
+
+	// Version 1 is still gob
+	message, err = EncodeAliceDkgOutput(output, protocol.Version1)
+	require.NoError(t, err)
+	decoded, err = DecodeAliceDkgResult(message)
+	require.NoError(t, err)
+	require.True(t, output.PublicKey.Equal(decoded.PublicKey))
+	require.Equal(t, output.SeedOtResult, decoded.SeedOtResult)
+
+	_, err = EncodeAliceDkgOutput(output, protocol.Version0)
+	require.Error(t, err)
+}
+
+func TestWireSignature(t *testing.T) {
+	message, err := encodeSignature(&curves.EcdsaSignature{V: 1, R: big.NewInt(1), S: big.NewInt(0x0203)}, protocol.Version2)
+	require.NoError(t, err)
+	require.Equal(t, []byte{
+		0, 0, 0, 0, // no curve
+		0, 0, 0, 1, // V
+		0, 0, 0, 1, 1, // R
+		0, 0, 0, 2, 2, 3, // S
+	}, message.Payloads[payloadKey])
+}
+
+func TestWireIdentity(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		w := new(wireWriter)
+		w.point(curve.Point.Identity())
+		w.point(curve.Point.Generator())
+		w.point(nil)
+		payload, err := w.finish()
+		require.NoError(t, err)
+		r, err := newWireReader(payload)
+		require.NoError(t, err)
+		require.True(t, r.point().IsIdentity())
+		require.True(t, r.point().Equal(curve.Point.Generator()))
+		require.Nil(t, r.point())
+		require.NoError(t, r.finish())
+	}
+
+	w := new(wireWriter)
+	w.scalar(curves.K256().Scalar.One())
+	w.scalar(curves.P256().Scalar.One())
+	_, err := w.finish()
+	require.Error(t, err)
+}
@@ -39,6 +39,7 @@ type ParticipantDataJson struct {
 	// Public values set to all signing participants
 	EcdsaPublicKey    *curves.EcPoint
 	DealerParams      *ProofParams
+	DealerParamsProof []byte
 	ParticipantParams map[uint32]*ProofParams
 	PublicShares      map[uint32]*PublicShare
 	EncryptKeys       map[uint32]*paillier.PublicKey
@@ -53,9 +54,14 @@ func (pd ParticipantData) MarshalJSON() ([]byte, error) {
 		PublicShares:   pd.PublicShares,
 		EncryptKeys:    pd.EncryptKeys,
 	}
-	if pd.KeyGenType.IsTrustedDealer() {
-		data.DealerParams = pd.KeyGenType.GetProofParams(0)
-	} else {
+	switch kgt := pd.KeyGenType.(type) {
+	case TrustedDealerKeyGenType:
+		data.DealerParams = kgt.ProofParams
+		data.DealerParamsProof = kgt.ProofParamsProof
+	case *TrustedDealerKeyGenType:
+		data.DealerParams = kgt.ProofParams
+		data.DealerParamsProof = kgt.ProofParamsProof
+	default:
 		data.ParticipantParams = pd.KeyGenType.(DistributedKeyGenType).ProofParams
 	}
 	return json.Marshal(data)
@@ -68,7 +74,8 @@ func (pd *ParticipantData) UnmarshalJSON(bytes []byte) error {
 	}
 	if data.DealerParams != nil {
 		pd.KeyGenType = TrustedDealerKeyGenType{
-			ProofParams: data.DealerParams,
+			ProofParams:      data.DealerParams,
+			ProofParamsProof: data.DealerParamsProof,
 		}
 	} else {
 		pd.KeyGenType = DistributedKeyGenType{
@@ -18,6 +18,9 @@ type KeyGenType interface {
 // will be used by all participants
 type TrustedDealerKeyGenType struct {
 	ProofParams *ProofParams
+	// ProofParamsProof is the JSON encoding of the proof.RingPedersenProof
+	// that ProofParams are well formed. Participants refuse to sign without it.
+	ProofParamsProof []byte
 }
 
 // DistributedKeyGenType means each participant has their
@@ -212,9 +212,7 @@ func benchSign(b *testing.B, hash []byte, curve elliptic.Curve, verify curves.Ec
 		keysMap[i], _ = paillier.NewSecretKey(keyPrimesArray[i-1].p, keyPrimesArray[i-1].q)
 		pubKeys[i] = &keysMap[i].PublicKey
 	}
-	proofParams := &dealer.TrustedDealerKeyGenType{
-		ProofParams: dealerParams,
-	}
+	proofParams := dealerKeyGenType(b, curve)
 
 	signersMap := make(map[uint32]*Signer, threshold)
 	for i, k := range keysMap {
@@ -365,9 +363,7 @@ func BenchmarkSign2p(b *testing.B) {
 			1: paillier1,
 			2: paillier2,
 		},
-		proofParams: &dealer.TrustedDealerKeyGenType{
-			ProofParams: dealerParams,
-		},
+		proofParams: dealerKeyGenType(b, k256),
 	}
 
 	b.ResetTimer()
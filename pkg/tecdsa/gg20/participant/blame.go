@@ -0,0 +1,259 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/dealer"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/proof"
+)
+
+// Check names the verification that a cosigner's message failed
+type Check string
+
+const (
+	// CheckMessage is failed by a message that is missing or malformed
+	CheckMessage Check = "message"
+	// CheckRange1Proof is failed by the range proof of a round 1 ciphertext
+	CheckRange1Proof Check = "range1 proof"
+	// CheckRange2Proof is failed by the MtA response to k_i γ_j in round 2
+	CheckRange2Proof Check = "range2 proof"
+	// CheckRange3Proof is failed by the MtAwc response to k_i w_j in round 2
+	CheckRange3Proof Check = "range3 proof"
+	// CheckDecommitment is failed by a round 4 witness that does not open the round 1 commitment to Γ_j
+	CheckDecommitment Check = "decommitment"
+	// CheckPdlProof is failed by the proof of consistency of \overline{R_j} in round 5
+	CheckPdlProof Check = "pdl proof"
+	// CheckSignatureShare is failed by a share s_j of the signature that does not match \overline{R_j} and S_j
+	CheckSignatureShare Check = "signature share"
+)
+
+// Evidence holds the values over which a check was evaluated, as the accuser received them.
+// The messages are not signed by their sender, so the evidence does not prove that the culprit sent them.
+type Evidence interface {
+	// Verify repeats the check. It returns nil when the check fails again on the values of the evidence.
+	Verify() error
+}
+
+// BlameError is returned by a signing round when the message of a cosigner fails a check.
+// It identifies the cosigner, so that the signing can be restarted without it. The accusation is only
+// trustworthy if the messages are delivered over authenticated channels that the sender cannot repudiate;
+// the evidence is what the accuser says it received and a dishonest accuser can forge it.
+type BlameError struct {
+	// Culprit is the id of the cosigner who sent the message
+	Culprit uint32
+	// Round is the signing round in which the check failed
+	Round uint
+	Check Check
+	// Evidence is nil for CheckMessage
+	Evidence Evidence
+	Err      error
+}
+
+func (e *BlameError) Error() string {
+	return fmt.Sprintf("round %d: cosigner %d failed %s check: %v", e.Round, e.Culprit, e.Check, e.Err)
+}
+
+func (e *BlameError) Unwrap() error {
+	return e.Err
+}
+
+// Verify repeats the failed check on the evidence. It shows that the accuser's check was correct for the values
+// in the evidence, not that the culprit sent them.
+func (e *BlameError) Verify() error {
+	if e.Evidence == nil {
+		return fmt.Errorf("no evidence for %s check", e.Check)
+	}
+	return e.Evidence.Verify()
+}
+
+func blame(culprit uint32, round uint, check Check, evidence Evidence, err error) *BlameError {
+	return &BlameError{
+		Culprit:  culprit,
+		Round:    round,
+		Check:    check,
+		Evidence: evidence,
+		Err:      err,
+	}
+}
+
+// errAccusationFalse is returned by Verify when the evidence passes the check
+var errAccusationFalse = fmt.Errorf("evidence passes the check")
+
+// Range1Evidence is the evidence for CheckRange1Proof
+type Range1Evidence struct {
+	Curve elliptic.Curve
+	// Pk is the paillier public key of the culprit
+	Pk *paillier.PublicKey
+	// DealerParams are the proof parameters of the accuser
+	DealerParams *dealer.ProofParams
+	Ctxt         *big.Int
+	Proof        *proof.Range1Proof
+}
+
+func (e *Range1Evidence) Verify() error {
+	if e.Proof == nil {
+		return nil
+	}
+	if e.Proof.Verify(&proof.Proof1Params{
+		Curve:        e.Curve,
+		Pk:           e.Pk,
+		DealerParams: e.DealerParams,
+		C:            e.Ctxt,
+	}) != nil {
+		return nil
+	}
+	return errAccusationFalse
+}
+
+// ResponseEvidence is the evidence for CheckRange2Proof and CheckRange3Proof
+type ResponseEvidence struct {
+	Curve elliptic.Curve
+	// Pk is the paillier public key of the accuser, to whom the response was sent
+	Pk *paillier.PublicKey
+	// DealerParams are the proof parameters of the accuser
+	DealerParams *dealer.ProofParams
+	// C1 is the round 1 ciphertext of the accuser
+	C1 *big.Int
+	// B is the public share W_j of the culprit for CheckRange3Proof, nil otherwise
+	B     *curves.EcPoint
+	Proof *proof.ResponseProof
+}
+
+func (e *ResponseEvidence) Verify() error {
+	if e.Proof == nil {
+		return nil
+	}
+	vp := &proof.ResponseVerifyParams{
+		Curve:        e.Curve,
+		DealerParams: e.DealerParams,
+		C1:           e.C1,
+		B:            e.B,
+	}
+	var err error
+	if e.B == nil {
+		err = e.Proof.Verify(e.Pk, vp)
+	} else {
+		err = e.Proof.VerifyWc(e.Pk, vp)
+	}
+	if err != nil {
+		return nil
+	}
+	return errAccusationFalse
+}
+
+// DecommitmentEvidence is the evidence for CheckDecommitment
+type DecommitmentEvidence struct {
+	Curve elliptic.Curve
+	// Commitment is the round 1 commitment of the culprit
+	Commitment core.Commitment
+	// Witness is the round 4 witness of the culprit
+	Witness *core.Witness
+}
+
+func (e *DecommitmentEvidence) Verify() error {
+	if e.Witness == nil {
+		return nil
+	}
+	ok, err := core.Open(e.Commitment, *e.Witness)
+	if err != nil || !ok {
+		return nil
+	}
+	if _, err = curves.PointFromBytesUncompressed(e.Curve, e.Witness.Msg); err != nil {
+		return nil
+	}
+	return errAccusationFalse
+}
+
+// PdlEvidence is the evidence for CheckPdlProof
+type PdlEvidence struct {
+	Curve elliptic.Curve
+	// Pk is the paillier public key of the culprit
+	Pk *paillier.PublicKey
+	// DealerParams are the proof parameters of the accuser
+	DealerParams *dealer.ProofParams
+	// Rbar is \overline{R_j} broadcast by the culprit
+	Rbar *curves.EcPoint
+	// R is the nonce point computed in round 5
+	R *curves.EcPoint
+	// C is the round 1 ciphertext of the culprit
+	C     *big.Int
+	Proof *proof.PdlProof
+}
+
+func (e *PdlEvidence) Verify() error {
+	if e.Proof == nil {
+		return nil
+	}
+	if e.Proof.Verify(&proof.PdlVerifyParams{
+		Curve:        e.Curve,
+		Pk:           e.Pk,
+		DealerParams: e.DealerParams,
+		PointX:       e.Rbar,
+		PointR:       e.R,
+		C:            e.C,
+	}) != nil {
+		return nil
+	}
+	return errAccusationFalse
+}
+
+// SignatureShareEvidence is the evidence for CheckSignatureShare
+type SignatureShareEvidence struct {
+	Curve elliptic.Curve
+	// Hash is the hash of the signed message
+	Hash []byte
+	// R is the nonce point of the signature
+	R *curves.EcPoint
+	// Rbar is \overline{R_j} and S is S_j, broadcast by the culprit in round 5
+	Rbar, S *curves.EcPoint
+	// Share is the share s_j of the signature broadcast by the culprit in round 6
+	Share *big.Int
+}
+
+func (e *SignatureShareEvidence) Verify() error {
+	if e.check() != nil {
+		return nil
+	}
+	return errAccusationFalse
+}
+
+// check returns nil when R^{s_j} = \overline{R_j}^m S_j^r, with m the hash and r the x coordinate of R
+func (e *SignatureShareEvidence) check() error {
+	if e.Curve == nil || e.R == nil || e.Rbar == nil || e.S == nil || e.Share == nil {
+		return fmt.Errorf("evidence values cannot be nil")
+	}
+	n := e.Curve.Params().N
+	m := new(big.Int).Mod(new(big.Int).SetBytes(e.Hash), n)
+	r := new(big.Int).Mod(e.R.X, n)
+	lhs, err := e.R.ScalarMult(e.Share)
+	if err != nil {
+		return err
+	}
+	rbarM, err := e.Rbar.ScalarMult(m)
+	if err != nil {
+		return err
+	}
+	sR, err := e.S.ScalarMult(r)
+	if err != nil {
+		return err
+	}
+	rhs, err := rbarM.Add(sR)
+	if err != nil {
+		return err
+	}
+	if !lhs.Equals(rhs) {
+		return fmt.Errorf("share of the signature does not match its commitments")
+	}
+	return nil
+}
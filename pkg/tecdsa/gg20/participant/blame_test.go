@@ -0,0 +1,202 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/proof"
+)
+
+func requireBlame(t *testing.T, err error, culprit uint32, round uint, check Check) *BlameError {
+	t.Helper()
+	var blameErr *BlameError
+	require.True(t, errors.As(err, &blameErr), "expected a blame error, got %v", err)
+	require.Equal(t, culprit, blameErr.Culprit)
+	require.Equal(t, round, blameErr.Round)
+	require.Equal(t, check, blameErr.Check)
+	return blameErr
+}
+
+// TestBlame runs the signing rounds of 3 signers where signer 1 first receives a bad message from signer 2
+// in every round, then the honest messages
+func TestBlame(t *testing.T) {
+	curve := btcec.S256()
+	hash, err := core.Hash(make([]byte, 32), curve)
+	require.NoError(t, err)
+	_, signers := setupSignersMap(t, curve, 3, 5, false, k256Verifier, false)
+	for i := range signers {
+		var cosigners []uint32
+		for j := range signers {
+			if i != j {
+				cosigners = append(cosigners, j)
+			}
+		}
+		require.NoError(t, signers[i].setCosigners(cosigners))
+	}
+
+	// Round 2: signer 2's range proof is presented with signer 3's ciphertext
+	r1 := make(map[uint32]*Round1Bcast)
+	for i, s := range signers {
+		r1[i], _, err = s.SignRound1()
+		require.NoError(t, err)
+	}
+	_, err = signers[1].SignRound2(map[uint32]*Round1Bcast{
+		2: {Identifier: 2, C: r1[2].C, Ctxt: r1[3].Ctxt, Proof: r1[2].Proof},
+		3: r1[3],
+	}, nil)
+	blameErr := requireBlame(t, err, 2, 2, CheckRange1Proof)
+	require.NoError(t, blameErr.Verify())
+	// The accusation is false for the honest message
+	blameErr.Evidence.(*Range1Evidence).Ctxt = r1[2].Ctxt
+	require.Error(t, blameErr.Verify())
+
+	r2 := make(map[uint32]map[uint32]*P2PSend)
+	for i, s := range signers {
+		in := make(map[uint32]*Round1Bcast)
+		for j := range signers {
+			if i != j {
+				in[j] = r1[j]
+			}
+		}
+		r2[i], err = s.SignRound2(in, nil)
+		require.NoError(t, err)
+	}
+
+	// Round 3: signer 2 sends the responses meant for signer 3, then a bad MtAwc response only
+	_, err = signers[1].SignRound3(map[uint32]*P2PSend{2: r2[2][3], 3: r2[3][1]})
+	blameErr = requireBlame(t, err, 2, 3, CheckRange2Proof)
+	require.NoError(t, blameErr.Verify())
+	_, err = signers[1].SignRound3(map[uint32]*P2PSend{
+		2: {Proof2: r2[2][1].Proof2, Proof3: r2[2][3].Proof3},
+		3: r2[3][1],
+	})
+	blameErr = requireBlame(t, err, 2, 3, CheckRange3Proof)
+	require.NoError(t, blameErr.Verify())
+	blameErr.Evidence.(*ResponseEvidence).Proof = r2[2][1].Proof3.(*proof.ResponseProof)
+	require.Error(t, blameErr.Verify())
+	_, err = signers[1].SignRound3(map[uint32]*P2PSend{2: nil, 3: r2[3][1]})
+	blameErr = requireBlame(t, err, 2, 3, CheckMessage)
+	require.Error(t, blameErr.Verify())
+
+	r3 := make(map[uint32]*Round3Bcast)
+	for i, s := range signers {
+		in := make(map[uint32]*P2PSend)
+		for j := range signers {
+			if i != j {
+				in[j] = r2[j][i]
+			}
+		}
+		r3[i], err = s.SignRound3(in)
+		require.NoError(t, err)
+	}
+	r4 := make(map[uint32]*Round4Bcast)
+	for i, s := range signers {
+		in := make(map[uint32]*Round3Bcast)
+		for j := range signers {
+			if i != j {
+				in[j] = r3[j]
+			}
+		}
+		r4[i], err = s.SignRound4(in)
+		require.NoError(t, err)
+	}
+
+	// Round 5: signer 2 decommits with the witness of signer 3
+	_, _, err = signers[1].SignRound5(map[uint32]*Round4Bcast{2: r4[3], 3: r4[3]})
+	blameErr = requireBlame(t, err, 2, 5, CheckDecommitment)
+	require.NoError(t, blameErr.Verify())
+	blameErr.Evidence.(*DecommitmentEvidence).Witness = r4[2].Witness
+	require.Error(t, blameErr.Verify())
+
+	r5 := make(map[uint32]*Round5Bcast)
+	for i, s := range signers {
+		in := make(map[uint32]*Round4Bcast)
+		for j := range signers {
+			if i != j {
+				in[j] = r4[j]
+			}
+		}
+		r5[i], _, err = s.SignRound5(in)
+		require.NoError(t, err)
+	}
+
+	// Round 6: signer 2 proves the consistency of signer 3's \overline{R_j}
+	_, err = signers[1].SignRound6Full(hash.Bytes(), map[uint32]*Round5Bcast{
+		2: {Rbar: r5[2].Rbar, S: r5[2].S, Proof: r5[3].Proof},
+		3: r5[3],
+	}, nil)
+	blameErr = requireBlame(t, err, 2, 6, CheckPdlProof)
+	require.NoError(t, blameErr.Verify())
+	blameErr.Evidence.(*PdlEvidence).Proof = r5[2].Proof
+	require.Error(t, blameErr.Verify())
+
+	r6 := make(map[uint32]*Round6FullBcast)
+	for i, s := range signers {
+		in := make(map[uint32]*Round5Bcast)
+		for j := range signers {
+			if i != j {
+				in[j] = r5[j]
+			}
+		}
+		r6[i], err = s.SignRound6Full(hash.Bytes(), in, nil)
+		require.NoError(t, err)
+	}
+
+	_, err = signers[1].SignOutput(map[uint32]*Round6FullBcast{2: {}, 3: r6[3]})
+	requireBlame(t, err, 2, 7, CheckMessage)
+
+	// Output: signer 2 sends a share of the signature that does not match \overline{R_j} and S_j
+	_, err = signers[1].SignOutput(map[uint32]*Round6FullBcast{2: r6[3], 3: r6[3]})
+	blameErr = requireBlame(t, err, 2, 7, CheckSignatureShare)
+	require.NoError(t, blameErr.Verify())
+	blameErr.Evidence.(*SignatureShareEvidence).Share = r6[2].sElement
+	require.Error(t, blameErr.Verify())
+
+	_, err = signers[1].SignOutput(map[uint32]*Round6FullBcast{2: r6[2], 3: r6[3]})
+	require.NoError(t, err)
+}
+
+// TestUnattributableAborts checks that the aborts on values of every cosigner, V != g and S != y in round 6, are
+// not blamed on a cosigner. The shares δ_j and S_j are not proven, so a wrong one cannot be attributed.
+func TestUnattributableAborts(t *testing.T) {
+	curve := btcec.S256()
+	hash, err := core.Hash(make([]byte, 32), curve)
+	require.NoError(t, err)
+	var blameErr *BlameError
+
+	// Signer 2 broadcasts a wrong δ_j
+	_, signers := setupSignersMap(t, curve, 3, 5, false, k256Verifier, false)
+	r3 := runSignRounds1To3(t, signers)
+	r3[2].deltaElement = new(big.Int).Add(r3[2].deltaElement, big.NewInt(1))
+	signers[2].state.deltai = r3[2].deltaElement
+	r5, _ := runSignRounds4To5(t, signers, r3)
+	_, err = signers[1].SignRound6Full(hash.Bytes(), map[uint32]*Round5Bcast{2: r5[2], 3: r5[3]}, nil)
+	require.EqualError(t, err, "V != g")
+	require.False(t, errors.As(err, &blameErr))
+
+	// Signer 2 broadcasts a wrong S_j
+	_, signers = setupSignersMap(t, curve, 3, 5, false, k256Verifier, false)
+	r5, _ = runSignRounds1To5(t, signers)
+	wrong := *r5[2]
+	wrong.S, err = r5[2].S.Add(r5[3].S)
+	require.NoError(t, err)
+	_, err = signers[1].SignRound6Full(hash.Bytes(), map[uint32]*Round5Bcast{2: &wrong, 3: r5[3]}, nil)
+	require.EqualError(t, err, "S != y")
+	require.False(t, errors.As(err, &blameErr))
+
+	// A missing S_j is blamed on its sender
+	wrong.S = nil
+	_, err = signers[1].SignRound6Full(hash.Bytes(), map[uint32]*Round5Bcast{2: &wrong, 3: r5[3]}, nil)
+	requireBlame(t, err, 2, 6, CheckMessage)
+}
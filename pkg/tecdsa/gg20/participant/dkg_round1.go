@@ -7,6 +7,7 @@
 package participant
 
 import (
+	"crypto/elliptic"
 	"fmt"
 	"math/big"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/coinbase/kryptology/pkg/core"
 	"github.com/coinbase/kryptology/pkg/paillier"
 	"github.com/coinbase/kryptology/pkg/sharing/v1"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/dealer"
 	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/proof"
 )
 
@@ -77,6 +79,37 @@ func (dp *DkgParticipant) DkgRound1(threshold, total uint32) (*DkgRound1Bcast, e
 		return nil, err
 	}
 
+	// Step 5-14
+	proofParams, proof1, proof2, err := newProofParams(dp.Curve, core.GenerateSafePrime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 16:
+	// Store Di, ski, pki, tildeNi, h1i, h2i, [vi0...vit], [xi1,...xin] locally
+	dp.state.D = Di
+	dp.state.Sk = ski
+	dp.state.Pk = pki
+	dp.state.N = proofParams.N
+	dp.state.H1 = proofParams.H1
+	dp.state.H2 = proofParams.H2
+	dp.state.V = V
+	dp.state.X = X
+	dp.state.Threshold = threshold
+	dp.state.Limit = total
+
+	// used in Round 2
+	dp.Round = 2
+
+	// Step 15: EchoBroadcast Ci, pki, tildeNi, h1i, h2i, proof1, proof2
+	return &DkgRound1Bcast{
+		dp.id, Ci, pki, proofParams.H1, proofParams.H2, proofParams.N, proof1, proof2,
+	}, nil
+}
+
+// newProofParams computes steps 5-14 of [spec] fig 5: DistKeyGenRound1, the proof params
+// N~, h1, h2 of a participant and the proofs that they are well formed
+func newProofParams(curve elliptic.Curve, genSafePrime func(uint) (*big.Int, error)) (*dealer.ProofParams, *proof.CdlProof, *proof.CdlProof, error) {
 	// Step 5-6: Choose 1024-bit safe primes Pi, Qi, Pi=2pi+1, Qi=2qi+1 where Pi, Qi, pi, qi are primes
 	values := make(chan *big.Int, 2)
 	errors := make(chan error, 2)
@@ -85,7 +118,7 @@ func (dp *DkgParticipant) DkgRound1(threshold, total uint32) (*DkgRound1Bcast, e
 	for Pi == Qi {
 		for range []int{1, 2} {
 			go func() {
-				value, err := core.GenerateSafePrime(paillier.PaillierPrimeBits)
+				value, err := genSafePrime(paillier.PaillierPrimeBits)
 				values <- value
 				errors <- err
 			}()
@@ -93,96 +126,19 @@ func (dp *DkgParticipant) DkgRound1(threshold, total uint32) (*DkgRound1Bcast, e
 
 		for _, err := range []error{<-errors, <-errors} {
 			if err != nil {
-				return nil, err
+				return nil, nil, nil, err
 			}
 		}
 
 		Pi, Qi = <-values, <-values
 	}
 
-	// Step 7: Compute tildeNi = Pi*Qi
-	tildeNi := new(big.Int).Mul(Pi, Qi)
-
-	// Step 8-9: Sample f, alpha from Z_tildeNi*
-	f, err := core.Rand(tildeNi)
-	if err != nil {
-		return nil, err
-	}
-
-	alpha, err := core.Rand(tildeNi)
-	if err != nil {
-		return nil, err
-	}
-
-	// Step 10: Compute beta = alpha^-1 mod pi*qi
-	// Compute pi = (Pi-1)/2, qi = (Qi-1)/2
-	pi := new(big.Int).Rsh(Pi, 1)
-	qi := new(big.Int).Rsh(Qi, 1)
-
-	// Compute pi*qi
-	pq := new(big.Int).Mul(pi, qi)
-
-	// Compute beta
-	beta := new(big.Int).ModInverse(alpha, pq)
-
-	// Step 11-12: h1i = f^2 mod tildeNi, h2i = h1i^alpha mod tildeNi
-	h1i, err := core.Mul(f, f, tildeNi)
-	if err != nil {
-		return nil, err
-	}
-	h2i := new(big.Int).Exp(h1i, alpha, tildeNi)
-
-	// Step 13-14:
-	cdlParams1 := proof.CdlProofParams{
-		Curve:   dp.Curve,
-		Pi:      pi,
-		Qi:      qi,
-		H1:      h1i,
-		H2:      h2i,
-		ScalarX: alpha,
-		N:       tildeNi,
-	}
-
-	cdlParams2 := proof.CdlProofParams{
-		Curve:   dp.Curve,
-		Pi:      pi,
-		Qi:      qi,
-		H1:      h2i,
-		H2:      h1i,
-		ScalarX: beta,
-		N:       tildeNi,
-	}
-
+	// Step 7-14: tildeNi = Pi*Qi, h1i = f^2, h2i = h1i^alpha and the proofs
 	// proof1 <- ProveCompositeDL(g, q, pi, qi, h1i, h2i, alpha, tildeNi)
-	proof1, err := cdlParams1.Prove()
-	if err != nil {
-		return nil, err
-	}
-
 	// proof2 <- ProveCompositeDl(g, q, pi, qi, h2i, h1i, beta, tildeNi)
-	proof2, err := cdlParams2.Prove()
+	params, rpProof, err := proof.NewRingPedersenParamsWithPrimes(curve, Pi, Qi)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
-
-	// Step 16:
-	// Store Di, ski, pki, tildeNi, h1i, h2i, [vi0...vit], [xi1,...xin] locally
-	dp.state.D = Di
-	dp.state.Sk = ski
-	dp.state.Pk = pki
-	dp.state.N = tildeNi
-	dp.state.H1 = h1i
-	dp.state.H2 = h2i
-	dp.state.V = V
-	dp.state.X = X
-	dp.state.Threshold = threshold
-	dp.state.Limit = total
-
-	// used in Round 2
-	dp.Round = 2
-
-	// Step 15: EchoBroadcast Ci, pki, tildeNi, h1i, h2i, proof1, proof2
-	return &DkgRound1Bcast{
-		dp.id, Ci, pki, h1i, h2i, tildeNi, proof1, proof2,
-	}, nil
+	return params, rpProof.H2InH1, rpProof.H1InH2, nil
 }
@@ -7,6 +7,7 @@
 package participant
 
 import (
+	"crypto/elliptic"
 	"fmt"
 
 	"github.com/coinbase/kryptology/internal"
@@ -58,48 +59,20 @@ func (dp *DkgParticipant) DkgRound2(params map[uint32]*DkgRound1Bcast) (*DkgRoun
 	// Initiate P2P channel to other parties
 	p2PSend := make(map[uint32]*DkgRound2P2PSend)
 
-	// Initiate two CdlVerifyParams that will be used in CDL verification.
-	cdlParams1 := proof.CdlVerifyParams{
-		Curve: dp.Curve,
-	}
-	cdlParams2 := proof.CdlVerifyParams{
-		Curve: dp.Curve,
-	}
-
 	dp.state.otherParticipantData = make(map[uint32]*dkgParticipantData)
 
 	// For j = [1...n]
-	expKeySize := 2 * paillier.PaillierPrimeBits
 	for id, param := range params {
 		// If i = j, Continue
 		if id == dp.id {
 			continue
 		}
 
-		// Mitigate possible attack from
-		// https://eprint.iacr.org/2021/1621.pdf
-		// by checking that paillier keys are the correct size
-		// See section 5
-		bitlen := param.Pki.N.BitLen()
-		if bitlen != expKeySize &&
-			bitlen != expKeySize-1 {
-			return nil, nil, fmt.Errorf("invalid paillier keys")
-		}
-
-		// If VerifyCompositeDL(pi_1j^CDL, g, q, h1j, h2j, tildeN_j) = False, Abort
-		cdlParams1.H1 = param.H1i
-		cdlParams1.H2 = param.H2i
-		cdlParams1.N = param.Ni
-		if err := param.Proof1i.Verify(&cdlParams1); err != nil {
-			return nil, nil, err
-		}
-
-		// If VerifyCompositeDL(pi_2j^CDL, g, q, h2j, h1j, tildeN_j) = False, Abort
-		// Note the position of h1j and h2j, they are reversed in the second verification!
-		cdlParams2.H1 = param.H2i
-		cdlParams2.H2 = param.H1i
-		cdlParams2.N = param.Ni
-		if err := param.Proof2i.Verify(&cdlParams2); err != nil {
+		if err := verifyParticipantKeys(dp.Curve, param.Pki, &dealer.ProofParams{
+			N:  param.Ni,
+			H1: param.H1i,
+			H2: param.H2i,
+		}, param.Proof1i, param.Proof2i); err != nil {
 			return nil, nil, err
 		}
 
@@ -132,3 +105,26 @@ func (dp *DkgParticipant) DkgRound2(params map[uint32]*DkgRound1Bcast) (*DkgRoun
 	}, p2PSend, nil
 
 }
+
+// verifyParticipantKeys checks the paillier public key and the proof params of a participant
+// with the proofs of [spec] fig 5: DistKeyGenRound2
+func verifyParticipantKeys(curve elliptic.Curve, pk *paillier.PublicKey, params *dealer.ProofParams, proof1, proof2 *proof.CdlProof) error {
+	if pk == nil || pk.N == nil || params == nil || proof1 == nil || proof2 == nil {
+		return internal.ErrNilArguments
+	}
+
+	// Mitigate possible attack from
+	// https://eprint.iacr.org/2021/1621.pdf
+	// by checking that paillier keys are the correct size
+	// See section 5
+	expKeySize := 2 * paillier.PaillierPrimeBits
+	bitlen := pk.N.BitLen()
+	if bitlen != expKeySize &&
+		bitlen != expKeySize-1 {
+		return fmt.Errorf("invalid paillier keys")
+	}
+
+	// If VerifyCompositeDL(pi_1j^CDL, g, q, h1j, h2j, tildeN_j) = False, Abort
+	// If VerifyCompositeDL(pi_2j^CDL, g, q, h2j, h1j, tildeN_j) = False, Abort
+	return proof.RingPedersenProof{H2InH1: proof1, H1InH2: proof2}.Verify(curve, params)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/coinbase/kryptology/pkg/paillier"
 	"github.com/coinbase/kryptology/pkg/sharing/v1"
 	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/dealer"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/proof"
 )
 
 // Participant is a tECDSA player that receives information from a trusted dealer
@@ -170,6 +171,9 @@ type state struct {
 
 	// Round 6 variables
 	si *big.Int
+	// Rbarj are the \overline{R_j} and Sj the S_j of every signer, used to identify a bad share of the signature
+	Rbarj map[uint32]*curves.EcPoint
+	Sj    map[uint32]*curves.EcPoint
 }
 
 // convertToAdditive takes all the publicShares and changes them to their additive form
@@ -243,7 +247,8 @@ func (p Participant) convertToAdditive(curve elliptic.Curve, publicSharesMap map
 }
 
 // PrepareToSign creates a Signer out of a Participant. The expected co-signers for the signing rounds are
-// expected to be exactly those included in the publicSharesMap
+// expected to be exactly those included in the publicSharesMap. The proof params of a trusted dealer
+// must come with the proof that they are well formed.
 func (p Participant) PrepareToSign(pubKey *curves.EcPoint,
 	verify curves.EcdsaVerify,
 	curve elliptic.Curve,
@@ -253,6 +258,9 @@ func (p Participant) PrepareToSign(pubKey *curves.EcPoint,
 	if pubKey == nil || verify == nil || curve == nil || keyGenType == nil || len(publicSharesMap) < 1 {
 		return nil, internal.ErrNilArguments
 	}
+	if err := proof.VerifyDealerParams(curve, keyGenType); err != nil {
+		return nil, err
+	}
 	signer, err := p.convertToAdditive(curve, publicSharesMap)
 	if err != nil {
 		return nil, err
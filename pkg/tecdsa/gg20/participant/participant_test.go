@@ -418,9 +418,7 @@ func TestSerializeParticipantData(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	keyGenType := dealer.TrustedDealerKeyGenType{
-		ProofParams: dealerParams,
-	}
+	keyGenType := *dealerKeyGenType(t, curve)
 
 	p := dealer.ParticipantData{
 		EcdsaPublicKey: ecdsaPk,
@@ -449,3 +447,22 @@ func TestSerializeParticipantData(t *testing.T) {
 		require.Equal(t, p.PublicShares, p2.PublicShares)
 	}
 }
+
+func TestNewSignerVerifiesDealerParams(t *testing.T) {
+	data := setupParticipantData(t, 2, 3)
+	cosigners := []uint32{1, 2}
+	_, err := NewSigner(data[1], cosigners)
+	require.NoError(t, err)
+
+	// Dealer params without their proof are rejected
+	params := data[1].KeyGenType.GetProofParams(0)
+	data[1].KeyGenType = dealer.TrustedDealerKeyGenType{ProofParams: params}
+	_, err = NewSigner(data[1], cosigners)
+	require.Error(t, err)
+
+	// The proof of other params is rejected
+	other := dealerKeyGenType(t, elliptic.P256())
+	data[1].KeyGenType = dealer.TrustedDealerKeyGenType{ProofParams: params, ProofParamsProof: other.ProofParamsProof}
+	_, err = NewSigner(data[1], cosigners)
+	require.Error(t, err)
+}
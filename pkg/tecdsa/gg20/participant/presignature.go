@@ -0,0 +1,171 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+)
+
+const (
+	// roundPresigned is the round of a signer that has handed its nonce to a presignature.
+	// No signing round can be run in it.
+	roundPresigned = 8
+
+	// presignatureGuardName separates the ids of GG20 presignatures in a protocol.PresignatureGuard
+	presignatureGuardName = "GG20-Sign"
+)
+
+// Presignature is the output of the offline part of round 6, see [spec] §6.fig 6.
+// It holds the values needed to sign a message with one broadcast, and is serialized with encoding/json
+// so that it can be stored until a message is to be signed.
+//
+// A presignature must be used to sign at most one message: signing two messages with the same nonce reveals the
+// secret key. It contains k_i and σ_i and must be kept secret. Copies made by storing it are tracked by
+// PresignatureId, which SignOnline checks against a guard.
+type Presignature struct {
+	// Id is the id of the signer who computed the presignature
+	Id uint32
+	// Cosigners are the ids of the other signers
+	Cosigners []uint32
+	PublicKey *curves.EcPoint
+	// R is the nonce point of the signature
+	R     *curves.EcPoint
+	K     *big.Int
+	Sigma *big.Int
+	// Rbar are the \overline{R_j} and S the S_j of every signer, which identify the sender of a wrong share of the
+	// signature
+	Rbar map[uint32]*curves.EcPoint
+	S    map[uint32]*curves.EcPoint
+
+	// Set by SignOnline for SignOutput
+	si      *big.Int
+	msgHash []byte
+}
+
+// PresignRound6Offline performs the offline part of round 6, see [spec] §6.fig 6.SignRound6Offline,
+// and returns the presignature of the signer. The signer cannot run any further round, the signature
+// is completed with Presignature.SignOnline and Presignature.SignOutput.
+func (signer *Signer) PresignRound6Offline(in map[uint32]*Round5Bcast, p2p map[uint32]*Round5P2PSend) (*Presignature, error) {
+	if err := signer.verifyStateMap(6, in); err != nil {
+		return nil, err
+	}
+
+	if !signer.state.keyGenType.IsTrustedDealer() {
+		if err := signer.verifyStateMap(6, p2p); err != nil {
+			return nil, err
+		}
+	}
+
+	// Steps 1-6
+	if err := signer.signRound6Offline(in, p2p); err != nil {
+		return nil, err
+	}
+
+	cosigners := make([]uint32, 0, len(signer.state.cosigners))
+	for id := range signer.state.cosigners {
+		if id != signer.id {
+			cosigners = append(cosigners, id)
+		}
+	}
+	presignature := &Presignature{
+		Id:        signer.id,
+		Cosigners: cosigners,
+		PublicKey: signer.PublicKey,
+		R:         signer.state.R,
+		K:         signer.state.ki,
+		Sigma:     signer.state.sigmai,
+		Rbar:      signer.state.Rbarj,
+		S:         signer.state.Sj,
+	}
+
+	// The nonce now belongs to the presignature only
+	signer.state.ki = nil
+	signer.state.sigmai = nil
+	signer.Round = roundPresigned
+	return presignature, nil
+}
+
+// PresignatureId returns the id of the presignature, the hash of the signer id, the sorted ids of all the signers
+// and R. It is the same for every copy of the presignature and differs between signers and nonces.
+func (p *Presignature) PresignatureId() ([]byte, error) {
+	if p.R == nil || p.R.X == nil || p.R.Y == nil {
+		return nil, fmt.Errorf("presignature is incomplete")
+	}
+	signers := append([]uint32{p.Id}, p.Cosigners...)
+	sort.Slice(signers, func(i, j int) bool { return signers[i] < signers[j] })
+	h := sha256.New()
+	var id [4]byte
+	binary.BigEndian.PutUint32(id[:], p.Id)
+	_, _ = h.Write(id[:])
+	for _, signer := range signers {
+		binary.BigEndian.PutUint32(id[:], signer)
+		_, _ = h.Write(id[:])
+	}
+	_, _ = h.Write(p.R.Bytes())
+	return h.Sum(nil), nil
+}
+
+// SignOnline performs the online part of round 6, see [spec] §6.fig 6.SignRound6Online, and returns the
+// share s_i of the signature of hash to broadcast to the cosigners. The PresignatureId is recorded with guard,
+// which must not be nil, and any copy of the presignature checked against the same guard is refused. The secret
+// values of the presignature are erased.
+func (p *Presignature) SignOnline(hash []byte, guard protocol.PresignatureGuard) (*Round6FullBcast, error) {
+	if p.si != nil {
+		return nil, fmt.Errorf("presignature has already been used")
+	}
+	if p.PublicKey == nil || p.K == nil || p.Sigma == nil {
+		return nil, fmt.Errorf("presignature is incomplete")
+	}
+	id, err := p.PresignatureId()
+	if err != nil {
+		return nil, err
+	}
+	if err = protocol.MarkPresignatureUsed(guard, presignatureGuardName, id); err != nil {
+		return nil, err
+	}
+	r := new(big.Int).Mod(p.R.X, p.PublicKey.Curve.Params().N)
+	si, err := signShare(p.PublicKey.Curve, hash, p.K, r, p.Sigma)
+	if err != nil {
+		return nil, err
+	}
+	p.K = nil
+	p.Sigma = nil
+	p.si = si
+	p.msgHash = hash
+	return &Round6FullBcast{si}, nil
+}
+
+// SignOutput performs the signature aggregation step in [spec] §5.fig 5 with the shares s_j
+// broadcast by the cosigners, after SignOnline. The signature is checked with verify.
+func (p *Presignature) SignOutput(in map[uint32]*Round6FullBcast, verify curves.EcdsaVerify) (*curves.EcdsaSignature, error) {
+	if p.si == nil {
+		return nil, fmt.Errorf("presignature has not signed a message")
+	}
+	if verify == nil {
+		return nil, fmt.Errorf("verify function cannot be nil")
+	}
+	cosigners := make(map[uint32]bool, len(p.Cosigners))
+	for _, id := range p.Cosigners {
+		cosigners[id] = true
+		if _, ok := in[id]; !ok {
+			return nil, fmt.Errorf("missing input from cosigner id=%v", id)
+		}
+	}
+	for id := range in {
+		if id != p.Id && !cosigners[id] {
+			return nil, fmt.Errorf("cosigner id=%v is not valid", id)
+		}
+	}
+	return outputSignature(p.PublicKey.Curve, p.Id, p.si, p.R, p.Rbar, p.S, in, verify, p.PublicKey, p.msgHash)
+}
@@ -0,0 +1,196 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"crypto/elliptic"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/core/protocol"
+)
+
+// runSignRounds1To5 runs signing rounds 1 to 5 between all the signers
+func runSignRounds1To5(t *testing.T, signers map[uint32]*Signer) (map[uint32]*Round5Bcast, map[uint32]map[uint32]*Round5P2PSend) {
+	t.Helper()
+	return runSignRounds4To5(t, signers, runSignRounds1To3(t, signers))
+}
+
+// runSignRounds1To3 runs signing rounds 1 to 3 between all the signers
+func runSignRounds1To3(t *testing.T, signers map[uint32]*Signer) map[uint32]*Round3Bcast {
+	t.Helper()
+	var err error
+	for i := range signers {
+		var cosigners []uint32
+		for j := range signers {
+			if i != j {
+				cosigners = append(cosigners, j)
+			}
+		}
+		require.NoError(t, signers[i].setCosigners(cosigners))
+	}
+
+	r1 := make(map[uint32]*Round1Bcast)
+	r1P2p := make(map[uint32]map[uint32]*Round1P2PSend)
+	for i, s := range signers {
+		r1[i], r1P2p[i], err = s.SignRound1()
+		require.NoError(t, err)
+	}
+	r2 := make(map[uint32]map[uint32]*P2PSend)
+	for i, s := range signers {
+		in := make(map[uint32]*Round1Bcast)
+		inP2p := make(map[uint32]*Round1P2PSend)
+		for j := range signers {
+			if i != j {
+				in[j] = r1[j]
+				inP2p[j] = r1P2p[j][i]
+			}
+		}
+		r2[i], err = s.SignRound2(in, inP2p)
+		require.NoError(t, err)
+	}
+	r3 := make(map[uint32]*Round3Bcast)
+	for i, s := range signers {
+		in := make(map[uint32]*P2PSend)
+		for j := range signers {
+			if i != j {
+				in[j] = r2[j][i]
+			}
+		}
+		r3[i], err = s.SignRound3(in)
+		require.NoError(t, err)
+	}
+	return r3
+}
+
+// runSignRounds4To5 runs signing rounds 4 and 5 between all the signers with the round 3 broadcasts r3
+func runSignRounds4To5(t *testing.T, signers map[uint32]*Signer, r3 map[uint32]*Round3Bcast) (map[uint32]*Round5Bcast, map[uint32]map[uint32]*Round5P2PSend) {
+	t.Helper()
+	var err error
+	r4 := make(map[uint32]*Round4Bcast)
+	for i, s := range signers {
+		in := make(map[uint32]*Round3Bcast)
+		for j := range signers {
+			if i != j {
+				in[j] = r3[j]
+			}
+		}
+		r4[i], err = s.SignRound4(in)
+		require.NoError(t, err)
+	}
+	r5 := make(map[uint32]*Round5Bcast)
+	r5P2p := make(map[uint32]map[uint32]*Round5P2PSend)
+	for i, s := range signers {
+		in := make(map[uint32]*Round4Bcast)
+		for j := range signers {
+			if i != j {
+				in[j] = r4[j]
+			}
+		}
+		r5[i], r5P2p[i], err = s.SignRound5(in)
+		require.NoError(t, err)
+	}
+	return r5, r5P2p
+}
+
+func TestPresignature(t *testing.T) {
+	for _, test := range []struct {
+		curve  elliptic.Curve
+		verify curves.EcdsaVerify
+	}{
+		{btcec.S256(), k256Verifier},
+		{elliptic.P256(), ecdsaVerifier},
+	} {
+		for _, useDistributed := range []bool{false, true} {
+			_, signers := setupSignersMap(t, test.curve, 3, 5, false, test.verify, useDistributed)
+			r5, r5P2p := runSignRounds1To5(t, signers)
+
+			presignatures := make(map[uint32]*Presignature)
+			stored := make(map[uint32][]byte)
+			for i, s := range signers {
+				in := make(map[uint32]*Round5Bcast)
+				var inP2p map[uint32]*Round5P2PSend
+				if useDistributed {
+					inP2p = make(map[uint32]*Round5P2PSend)
+				}
+				for j := range signers {
+					if i != j {
+						in[j] = r5[j]
+						if useDistributed {
+							inP2p[j] = r5P2p[j][i]
+						}
+					}
+				}
+				presignature, err := s.PresignRound6Offline(in, inP2p)
+				require.NoError(t, err)
+
+				// The signer cannot sign with the nonce of the presignature
+				_, err = s.SignRound6Full(make([]byte, 32), in, inP2p)
+				require.Error(t, err)
+
+				// Presignatures are restored from storage
+				data, err := json.Marshal(presignature)
+				require.NoError(t, err)
+				presignatures[i] = new(Presignature)
+				require.NoError(t, json.Unmarshal(data, presignatures[i]))
+				stored[i] = data
+			}
+
+			hash, err := core.Hash([]byte("presigned message"), test.curve)
+			require.NoError(t, err)
+			guard := protocol.NewMemoryPresignatureGuard()
+			r6 := make(map[uint32]*Round6FullBcast)
+			for i, p := range presignatures {
+				r6[i], err = p.SignOnline(hash.Bytes(), guard)
+				require.NoError(t, err)
+				require.Nil(t, p.K)
+				require.Nil(t, p.Sigma)
+
+				// A presignature signs a single message
+				_, err = p.SignOnline(hash.Bytes(), guard)
+				require.Error(t, err)
+			}
+			for i, p := range presignatures {
+				in := make(map[uint32]*Round6FullBcast)
+				for j := range presignatures {
+					if i != j {
+						in[j] = r6[j]
+					}
+				}
+				// A wrong share of the signature identifies its sender
+				for j := range in {
+					tampered := make(map[uint32]*Round6FullBcast)
+					for k, share := range in {
+						tampered[k] = share
+					}
+					tampered[j] = &Round6FullBcast{new(big.Int).Add(in[j].sElement, big.NewInt(1))}
+					_, err = p.SignOutput(tampered, test.verify)
+					requireBlame(t, err, j, 7, CheckSignatureShare)
+					break
+				}
+
+				signature, err := p.SignOutput(in, test.verify)
+				require.NoError(t, err)
+				require.NotNil(t, signature)
+			}
+
+			// Copies restored again from storage are refused by the guard
+			for i := range presignatures {
+				copied := new(Presignature)
+				require.NoError(t, json.Unmarshal(stored[i], copied))
+				_, err = copied.SignOnline(hash.Bytes(), guard)
+				require.ErrorIs(t, err, protocol.ErrPresignatureUsed)
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/coinbase/kryptology/internal"
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+	"github.com/coinbase/kryptology/pkg/sharing/v1"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/dealer"
+)
+
+// RefreshParticipant is a player in the proactive refresh of GG20 key shares. Every player adds a sharing of zero
+// to its Shamir share, so the shares change but the public key does not. A player can also replace its paillier
+// key and proof params.
+type RefreshParticipant struct {
+	Curve elliptic.Curve
+	Round uint
+	id    uint32
+	state *refreshState
+}
+
+// refreshState encapsulates all the values used in the refresh rounds state machine
+type refreshState struct {
+	data      *dealer.ParticipantData
+	threshold uint32
+	rotate    bool
+	// genSafePrime generates the primes of the new paillier key and proof params
+	genSafePrime func(uint) (*big.Int, error)
+
+	// Round 1 variables
+	// The zero sharing of this participant
+	V  []*v1.ShareVerifier
+	X  []*v1.ShamirShare
+	Sk *paillier.SecretKey
+	// ProofParams are the new proof params if rotate is set
+	ProofParams *dealer.ProofParams
+
+	// Round 2 variables
+	otherParticipantData map[uint32]*dkgParticipantData
+	share                *v1.ShamirShare
+	publicShares         map[uint32]*dealer.PublicShare
+}
+
+// NewRefreshParticipant creates a participant in the refresh of the key shares data, shared with threshold.
+// The public shares of data must be a sharing of the public key of degree exactly threshold-1.
+// All the participants of data.PublicShares take part in the refresh. When rotate is set, the participant
+// replaces its paillier key and proof params.
+func NewRefreshParticipant(data *dealer.ParticipantData, threshold uint32, rotate bool) (*RefreshParticipant, error) {
+	if data == nil || data.SecretKeyShare == nil || data.SecretKeyShare.ShamirShare == nil ||
+		data.EcdsaPublicKey == nil || data.DecryptKey == nil || data.KeyGenType == nil {
+		return nil, internal.ErrNilArguments
+	}
+	total := uint32(len(data.PublicShares))
+	if threshold < 2 || threshold > total {
+		return nil, fmt.Errorf("invalid threshold %d for %d participants", threshold, total)
+	}
+	// The zero sharing is evaluated at 1,...,n
+	for id := uint32(1); id <= total; id++ {
+		if data.PublicShares[id] == nil || data.PublicShares[id].Point == nil || data.EncryptKeys[id] == nil {
+			return nil, fmt.Errorf("missing public data of participant %d", id)
+		}
+	}
+	if data.Id != data.SecretKeyShare.Identifier || data.Id < 1 || data.Id > total {
+		return nil, fmt.Errorf("invalid participant id %d", data.Id)
+	}
+	if err := verifyThreshold(data, threshold); err != nil {
+		return nil, err
+	}
+	return &RefreshParticipant{
+		Curve: data.EcdsaPublicKey.Curve,
+		Round: 1,
+		id:    data.Id,
+		state: &refreshState{
+			data:         data,
+			threshold:    threshold,
+			rotate:       rotate,
+			genSafePrime: core.GenerateSafePrime,
+		},
+	}, nil
+}
+
+// verifyThreshold checks that the public shares of data are a sharing of the public key of degree
+// exactly threshold-1. Otherwise the refresh would keep the old threshold of the shares instead.
+func verifyThreshold(data *dealer.ParticipantData, threshold uint32) error {
+	curve := data.EcdsaPublicKey.Curve
+	total := uint32(len(data.PublicShares))
+
+	// The public key and the first threshold-1 public shares define the sharing polynomial
+	points := map[uint32]*curves.EcPoint{0: data.EcdsaPublicKey}
+	for id := uint32(1); id < threshold; id++ {
+		points[id] = data.PublicShares[id].Point
+	}
+	for id := threshold; id <= total; id++ {
+		p, err := interpolatePublicShares(curve, points, id)
+		if err != nil {
+			return err
+		}
+		if !p.Equals(data.PublicShares[id].Point) {
+			return fmt.Errorf("public shares are not a sharing of threshold %d", threshold)
+		}
+	}
+
+	// A polynomial of lower degree goes through the last of these points as well
+	delete(points, threshold-1)
+	p, err := interpolatePublicShares(curve, points, threshold-1)
+	if err != nil {
+		return err
+	}
+	if p.Equals(data.PublicShares[threshold-1].Point) {
+		return fmt.Errorf("public shares are a sharing of threshold less than %d", threshold)
+	}
+	return nil
+}
+
+// interpolatePublicShares evaluates at x the polynomial in the exponent that goes through the points
+func interpolatePublicShares(curve elliptic.Curve, points map[uint32]*curves.EcPoint, x uint32) (*curves.EcPoint, error) {
+	field := curves.NewField(curve.Params().N)
+	xx := field.NewElement(big.NewInt(int64(x)))
+	result, err := curves.NewScalarBaseMult(curve, big.NewInt(0))
+	if err != nil {
+		return nil, err
+	}
+	for i, point := range points {
+		// lambda_i(x) = \Prod_{k != i} { (x - x_k) / (x_i - x_k) }
+		l := field.One()
+		xi := field.NewElement(big.NewInt(int64(i)))
+		for k := range points {
+			if k == i {
+				continue
+			}
+			xk := field.NewElement(big.NewInt(int64(k)))
+			l = l.Mul(xx.Sub(xk).Div(xi.Sub(xk)))
+		}
+		t, err := point.ScalarMult(l.BigInt())
+		if err != nil {
+			return nil, err
+		}
+		result, err = result.Add(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Check refresh round number is valid
+func (rp *RefreshParticipant) verifyRefreshRound(round uint) error {
+	if rp == nil || rp.state == nil {
+		return internal.ErrNilArguments
+	}
+	if rp.Round != round {
+		return internal.ErrInvalidRound
+	}
+	return nil
+}
+
+// verifyRefreshInput checks that in has a non nil value from each other participant
+func (rp *RefreshParticipant) verifyRefreshInput(in map[uint32]bool) error {
+	for id := range rp.state.data.PublicShares {
+		if id != rp.id && !in[id] {
+			return fmt.Errorf("missing input from participant %d", id)
+		}
+	}
+	for id := range in {
+		if id != rp.id && rp.state.data.PublicShares[id] == nil {
+			return fmt.Errorf("participant id=%v is not valid", id)
+		}
+	}
+	return nil
+}
+
+// evaluateVerifiers computes the sum of v_k x^k for the verifiers v of a sharing, that is
+// the public value of the share at x
+func evaluateVerifiers(curve elliptic.Curve, verifiers []*v1.ShareVerifier, x uint32) (*curves.EcPoint, error) {
+	result, err := curves.NewScalarBaseMult(curve, big.NewInt(0))
+	if err != nil {
+		return nil, err
+	}
+	xk := big.NewInt(1)
+	for k, v := range verifiers {
+		if k > 0 {
+			xk, err = core.Mul(xk, big.NewInt(int64(x)), curve.Params().N)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if v.IsIdentity() {
+			continue
+		}
+		t, err := v.ScalarMult(xk)
+		if err != nil {
+			return nil, err
+		}
+		result, err = result.Add(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
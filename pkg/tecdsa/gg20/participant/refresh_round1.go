@@ -0,0 +1,105 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"math/big"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+	"github.com/coinbase/kryptology/pkg/sharing/v1"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/dealer"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/proof"
+)
+
+// RefreshRound1Bcast contains values to be broadcast to all players after the completion of refresh round 1
+type RefreshRound1Bcast struct {
+	Identifier uint32
+	// V are the verifiers of the sharing of zero, V[0] is the identity
+	V []*v1.ShareVerifier
+	// The new paillier public key and proof params with their proofs, nil unless the player rotates its keys
+	Pk             *paillier.PublicKey
+	ProofParams    *dealer.ProofParams
+	Proof1, Proof2 *proof.CdlProof
+}
+
+// RefreshRound1P2PSend contains the share of zero P2PSend to player Pj
+type RefreshRound1P2PSend struct {
+	Xij *v1.ShamirShare
+}
+
+// RefreshRound1 shares zero among the players, and generates a new paillier key and proof params
+// if the player rotates its keys
+func (rp *RefreshParticipant) RefreshRound1() (*RefreshRound1Bcast, map[uint32]*RefreshRound1P2PSend, error) {
+	if err := rp.verifyRefreshRound(1); err != nil {
+		return nil, nil, err
+	}
+	total := uint32(len(rp.state.data.PublicShares))
+
+	// Share a random u, then subtract u from the shares and replace its verifier
+	// with the identity to get a sharing of zero
+	u, err := core.Rand(rp.Curve.Params().N)
+	if err != nil {
+		return nil, nil, err
+	}
+	feldman, err := v1.NewFeldman(rp.state.threshold, total, rp.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	V, X, err := feldman.Split(u.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, x := range X {
+		x.Value = x.Value.Sub(x.Value.Field().NewElement(u))
+	}
+	V[0], err = curves.NewScalarBaseMult(rp.Curve, big.NewInt(0))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bcast := &RefreshRound1Bcast{
+		Identifier: rp.id,
+		V:          V,
+	}
+	if rp.state.rotate {
+		// Generate a paillier key as in [spec] fig 5: DistKeyGenRound1 step 4
+		var p, q *big.Int
+		for p == q {
+			if p, err = rp.state.genSafePrime(paillier.PaillierPrimeBits); err != nil {
+				return nil, nil, err
+			}
+			if q, err = rp.state.genSafePrime(paillier.PaillierPrimeBits); err != nil {
+				return nil, nil, err
+			}
+		}
+		rp.state.Sk, err = paillier.NewSecretKey(p, q)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Steps 5-14
+		rp.state.ProofParams, bcast.Proof1, bcast.Proof2, err = newProofParams(rp.Curve, rp.state.genSafePrime)
+		if err != nil {
+			return nil, nil, err
+		}
+		bcast.Pk = &rp.state.Sk.PublicKey
+		bcast.ProofParams = rp.state.ProofParams
+	}
+
+	p2p := make(map[uint32]*RefreshRound1P2PSend, total-1)
+	for _, x := range X {
+		if x.Identifier != rp.id {
+			p2p[x.Identifier] = &RefreshRound1P2PSend{Xij: x}
+		}
+	}
+	rp.state.V = V
+	rp.state.X = X
+	rp.Round = 2
+	return bcast, p2p, nil
+}
@@ -0,0 +1,123 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"fmt"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+	"github.com/coinbase/kryptology/pkg/sharing/v1"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/dealer"
+)
+
+// RefreshRound2 verifies the sharings of zero of the other players and adds them to the share of this player.
+// If the player rotates its keys, it returns the proof that its new paillier modulus is square free,
+// to be broadcast to all players, otherwise the proof is nil.
+func (rp *RefreshParticipant) RefreshRound2(bcast map[uint32]*RefreshRound1Bcast, p2p map[uint32]*RefreshRound1P2PSend) (paillier.PsfProof, error) {
+	if err := rp.verifyRefreshRound(2); err != nil {
+		return nil, err
+	}
+	in := make(map[uint32]bool, len(bcast))
+	for id, b := range bcast {
+		in[id] = b != nil && p2p[id] != nil && p2p[id].Xij != nil
+	}
+	if err := rp.verifyRefreshInput(in); err != nil {
+		return nil, err
+	}
+	total := uint32(len(rp.state.data.PublicShares))
+	feldman, err := v1.NewFeldman(rp.state.threshold, total, rp.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	// Our own share of our zero sharing
+	old := rp.state.data.SecretKeyShare.ShamirShare
+	share := &v1.ShamirShare{
+		Identifier: old.Identifier,
+		Value:      old.Value.Add(rp.state.X[rp.id-1].Value),
+	}
+	verifiers := map[uint32][]*v1.ShareVerifier{rp.id: rp.state.V}
+	rp.state.otherParticipantData = make(map[uint32]*dkgParticipantData)
+	for id, b := range bcast {
+		if id == rp.id {
+			continue
+		}
+		if uint32(len(b.V)) != rp.state.threshold {
+			return nil, fmt.Errorf("invalid number of verifiers for participant %d", id)
+		}
+		if !b.V[0].IsIdentity() {
+			return nil, fmt.Errorf("participant %d did not share zero", id)
+		}
+		x := p2p[id].Xij
+		if x.Identifier != rp.id {
+			return nil, fmt.Errorf("participant %d sent the share of participant %d", id, x.Identifier)
+		}
+		if ok, err := feldman.Verify(x, b.V); !ok {
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("invalid share for participant %d", id)
+		}
+		share.Value = share.Value.Add(x.Value)
+		verifiers[id] = b.V
+
+		if b.Pk != nil || b.ProofParams != nil {
+			if err := verifyParticipantKeys(rp.Curve, b.Pk, b.ProofParams, b.Proof1, b.Proof2); err != nil {
+				return nil, fmt.Errorf("invalid keys for participant %d: %w", id, err)
+			}
+			rp.state.otherParticipantData[id] = &dkgParticipantData{
+				PublicKey:   b.Pk,
+				ProofParams: b.ProofParams,
+			}
+		}
+	}
+
+	// X_k = X_k + \sum_j f_j(k) for the zero sharings f_j
+	publicShares := make(map[uint32]*dealer.PublicShare, total)
+	for k, publicShare := range rp.state.data.PublicShares {
+		point := publicShare.Point
+		for _, v := range verifiers {
+			t, err := evaluateVerifiers(rp.Curve, v, k)
+			if err != nil {
+				return nil, err
+			}
+			point, err = point.Add(t)
+			if err != nil {
+				return nil, err
+			}
+		}
+		publicShares[k] = &dealer.PublicShare{Point: point}
+	}
+	// Sanity check that the public share of this player matches its new share
+	point, err := curves.NewScalarBaseMult(rp.Curve, share.Value.BigInt())
+	if err != nil {
+		return nil, err
+	}
+	if !point.Equals(publicShares[rp.id].Point) {
+		return nil, fmt.Errorf("refreshed share does not match its public share")
+	}
+	rp.state.share = share
+	rp.state.publicShares = publicShares
+
+	var psfProof paillier.PsfProof
+	if rp.state.rotate {
+		// Compute πPSF = ProvePSF(ski.N, ski.φ(N), y, g, q, pi) as in [spec] fig 5: DistKeyGenRound3
+		psfParams := paillier.PsfProofParams{
+			Curve:     rp.Curve,
+			SecretKey: rp.state.Sk,
+			Pi:        rp.id,
+			Y:         rp.state.data.EcdsaPublicKey,
+		}
+		psfProof, err = psfParams.Prove()
+		if err != nil {
+			return nil, err
+		}
+	}
+	rp.Round = 3
+	return psfProof, nil
+}
@@ -0,0 +1,82 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"fmt"
+
+	"github.com/coinbase/kryptology/pkg/paillier"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/dealer"
+)
+
+// RefreshRound3 verifies the proofs of the players that rotated their keys and returns the refreshed
+// participant data. It has the same public key, and replaces the data passed to NewRefreshParticipant,
+// which must be deleted.
+func (rp *RefreshParticipant) RefreshRound3(psfProofs map[uint32]paillier.PsfProof) (*dealer.ParticipantData, error) {
+	if err := rp.verifyRefreshRound(3); err != nil {
+		return nil, err
+	}
+	data := rp.state.data
+
+	verifyPsfParams := paillier.PsfVerifyParams{
+		Curve: rp.Curve,
+		Y:     data.EcdsaPublicKey,
+	}
+	encryptKeys := make(map[uint32]*paillier.PublicKey, len(data.EncryptKeys))
+	for id, pk := range data.EncryptKeys {
+		encryptKeys[id] = pk
+	}
+	for id, other := range rp.state.otherParticipantData {
+		p, ok := psfProofs[id]
+		if !ok {
+			return nil, fmt.Errorf("missing proof for participant %d", id)
+		}
+		// if VerifyPSF(\pi_j, pk_j.N, y, g, q, pj) = false, abort
+		verifyPsfParams.PublicKey = other.PublicKey
+		verifyPsfParams.Pi = id
+		if err := p.Verify(&verifyPsfParams); err != nil {
+			return nil, err
+		}
+		encryptKeys[id] = other.PublicKey
+	}
+
+	decryptKey := data.DecryptKey
+	if rp.state.rotate {
+		decryptKey = rp.state.Sk
+		encryptKeys[rp.id] = &rp.state.Sk.PublicKey
+	}
+
+	// Players that rotated their proof params use their own from now on
+	keyGenType := data.KeyGenType
+	if rp.state.rotate || len(rp.state.otherParticipantData) > 0 || !keyGenType.IsTrustedDealer() {
+		proofParams := make(map[uint32]*dealer.ProofParams, len(data.PublicShares))
+		for id := range data.PublicShares {
+			proofParams[id] = keyGenType.GetProofParams(id)
+			if other, ok := rp.state.otherParticipantData[id]; ok {
+				proofParams[id] = other.ProofParams
+			}
+		}
+		if rp.state.rotate {
+			proofParams[rp.id] = rp.state.ProofParams
+		}
+		keyGenType = &dealer.DistributedKeyGenType{ProofParams: proofParams}
+	}
+
+	rp.Round = 4
+	return &dealer.ParticipantData{
+		Id:         data.Id,
+		DecryptKey: decryptKey,
+		SecretKeyShare: &dealer.Share{
+			ShamirShare: rp.state.share,
+			Point:       rp.state.publicShares[rp.id].Point,
+		},
+		EcdsaPublicKey: data.EcdsaPublicKey,
+		KeyGenType:     keyGenType,
+		PublicShares:   rp.state.publicShares,
+		EncryptKeys:    encryptKeys,
+	}, nil
+}
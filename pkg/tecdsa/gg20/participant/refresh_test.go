@@ -0,0 +1,211 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package participant
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/paillier"
+	"github.com/coinbase/kryptology/pkg/sharing/v1"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/dealer"
+)
+
+// setupParticipantData deals the participant data of a threshold of total key
+func setupParticipantData(t *testing.T, threshold, total uint32) map[uint32]*dealer.ParticipantData {
+	t.Helper()
+	curve := btcec.S256()
+	pk, sharesMap, err := dealer.NewDealerShares(curve, threshold, total, nil)
+	require.NoError(t, err)
+	publicShares, err := dealer.PreparePublicShares(sharesMap)
+	require.NoError(t, err)
+	primes := genPrimesArray(int(total))
+	secretKeys := make(map[uint32]*paillier.SecretKey, total)
+	encryptKeys := make(map[uint32]*paillier.PublicKey, total)
+	for id := range sharesMap {
+		secretKeys[id], err = paillier.NewSecretKey(primes[id-1].p, primes[id-1].q)
+		require.NoError(t, err)
+		encryptKeys[id] = &secretKeys[id].PublicKey
+	}
+	data := make(map[uint32]*dealer.ParticipantData, total)
+	for id, share := range sharesMap {
+		data[id] = &dealer.ParticipantData{
+			Id:             id,
+			DecryptKey:     secretKeys[id],
+			SecretKeyShare: share,
+			EcdsaPublicKey: pk,
+			KeyGenType:     dealerKeyGenType(t, curve),
+			PublicShares:   publicShares,
+			EncryptKeys:    encryptKeys,
+		}
+	}
+	return data
+}
+
+// testSafePrimes returns the test primes in turn, starting from first
+func testSafePrimes(first int) func(uint) (*big.Int, error) {
+	var lock sync.Mutex
+	next := first
+	return func(uint) (*big.Int, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		p := testPrimes[next%len(testPrimes)]
+		next++
+		return p, nil
+	}
+}
+
+func runRefresh(t *testing.T, data map[uint32]*dealer.ParticipantData, threshold uint32, rotate map[uint32]bool) map[uint32]*dealer.ParticipantData {
+	t.Helper()
+	participants := make(map[uint32]*RefreshParticipant, len(data))
+	for id, d := range data {
+		var err error
+		participants[id], err = NewRefreshParticipant(d, threshold, rotate[id])
+		require.NoError(t, err)
+		participants[id].state.genSafePrime = testSafePrimes(int(id) * 4)
+	}
+
+	bcast := make(map[uint32]*RefreshRound1Bcast)
+	p2p := make(map[uint32]map[uint32]*RefreshRound1P2PSend)
+	for id, p := range participants {
+		var err error
+		bcast[id], p2p[id], err = p.RefreshRound1()
+		require.NoError(t, err)
+		require.Equal(t, rotate[id], bcast[id].Pk != nil)
+	}
+	psfProofs := make(map[uint32]paillier.PsfProof)
+	for id, p := range participants {
+		in := make(map[uint32]*RefreshRound1P2PSend)
+		for j := range participants {
+			if j != id {
+				in[j] = p2p[j][id]
+			}
+		}
+		psfProof, err := p.RefreshRound2(bcast, in)
+		require.NoError(t, err)
+		if psfProof != nil {
+			psfProofs[id] = psfProof
+		}
+	}
+	refreshed := make(map[uint32]*dealer.ParticipantData)
+	for id, p := range participants {
+		var err error
+		refreshed[id], err = p.RefreshRound3(psfProofs)
+		require.NoError(t, err)
+	}
+	return refreshed
+}
+
+func TestRefresh(t *testing.T) {
+	threshold, total := uint32(2), uint32(3)
+	data := setupParticipantData(t, threshold, total)
+	refreshed := runRefresh(t, data, threshold, map[uint32]bool{1: true})
+
+	field := curves.NewField(btcec.S256().Params().N)
+	shamir, err := v1.NewShamir(int(threshold), int(total), field)
+	require.NoError(t, err)
+	secret, err := shamir.Combine(data[1].SecretKeyShare.ShamirShare, data[3].SecretKeyShare.ShamirShare)
+	require.NoError(t, err)
+	for id, d := range refreshed {
+		require.True(t, d.EcdsaPublicKey.Equals(data[id].EcdsaPublicKey))
+		require.False(t, d.SecretKeyShare.Value.IsEqual(data[id].SecretKeyShare.Value))
+		for j := range refreshed {
+			require.True(t, d.PublicShares[j].Point.Equals(refreshed[j].SecretKeyShare.Point))
+		}
+		require.False(t, d.KeyGenType.IsTrustedDealer())
+	}
+	// Only participant 1 rotated its keys
+	require.NotEqual(t, data[1].DecryptKey.N, refreshed[1].DecryptKey.N)
+	require.Equal(t, refreshed[1].DecryptKey.N, refreshed[2].EncryptKeys[1].N)
+	require.Equal(t, data[2].DecryptKey, refreshed[2].DecryptKey)
+	dealerParams := data[3].KeyGenType.GetProofParams(0)
+	require.NotEqual(t, dealerParams.N, refreshed[3].KeyGenType.GetProofParams(1).N)
+	require.Equal(t, dealerParams, refreshed[3].KeyGenType.GetProofParams(2))
+
+	// The refreshed shares are a sharing of the same secret
+	refreshedSecret, err := shamir.Combine(refreshed[2].SecretKeyShare.ShamirShare, refreshed[3].SecretKeyShare.ShamirShare)
+	require.NoError(t, err)
+	require.Equal(t, secret, refreshedSecret)
+	mixed, err := shamir.Combine(data[2].SecretKeyShare.ShamirShare, refreshed[3].SecretKeyShare.ShamirShare)
+	require.NoError(t, err)
+	require.NotEqual(t, secret, mixed)
+
+	// Participants 1 and 3 sign with the refreshed data
+	signers := make(map[uint32]*Signer)
+	for _, id := range []uint32{1, 3} {
+		signers[id], err = NewSigner(refreshed[id], []uint32{1, 3})
+		require.NoError(t, err)
+	}
+	r5, r5P2p := runSignRounds1To5(t, signers)
+	hash, err := core.Hash([]byte("signed after refresh"), btcec.S256())
+	require.NoError(t, err)
+	r6 := make(map[uint32]*Round6FullBcast)
+	for i, s := range signers {
+		j := 4 - i
+		r6[i], err = s.SignRound6Full(hash.Bytes(), map[uint32]*Round5Bcast{j: r5[j]}, map[uint32]*Round5P2PSend{j: r5P2p[j][i]})
+		require.NoError(t, err)
+	}
+	signature, err := signers[1].SignOutput(map[uint32]*Round6FullBcast{3: r6[3]})
+	require.NoError(t, err)
+	require.True(t, k256Verifier(data[1].EcdsaPublicKey, hash.Bytes(), signature))
+}
+
+func TestRefreshRejectsNonZeroSharing(t *testing.T) {
+	threshold, total := uint32(2), uint32(3)
+	data := setupParticipantData(t, threshold, total)
+	participants := make(map[uint32]*RefreshParticipant, total)
+	bcast := make(map[uint32]*RefreshRound1Bcast)
+	p2p := make(map[uint32]map[uint32]*RefreshRound1P2PSend)
+	for id, d := range data {
+		var err error
+		participants[id], err = NewRefreshParticipant(d, threshold, false)
+		require.NoError(t, err)
+		bcast[id], p2p[id], err = participants[id].RefreshRound1()
+		require.NoError(t, err)
+	}
+
+	// Participant 2 shares a non zero value consistently with its verifiers
+	one, err := curves.NewScalarBaseMult(btcec.S256(), big.NewInt(1))
+	require.NoError(t, err)
+	bcast[2].V[0] = one
+	for _, share := range p2p[2] {
+		share.Xij.Value = share.Xij.Value.Add(share.Xij.Value.Field().One())
+	}
+	_, err = participants[1].RefreshRound2(bcast, map[uint32]*RefreshRound1P2PSend{2: p2p[2][1], 3: p2p[3][1]})
+	require.Error(t, err)
+
+	// A missing share is rejected
+	_, err = participants[3].RefreshRound2(bcast, map[uint32]*RefreshRound1P2PSend{1: p2p[1][3]})
+	require.Error(t, err)
+}
+
+func TestNewRefreshParticipantChecksThreshold(t *testing.T) {
+	data := setupParticipantData(t, 3, 4)
+	_, err := NewRefreshParticipant(data[1], 3, false)
+	require.NoError(t, err)
+
+	// The public shares are of degree 2, so they cannot be refreshed with another threshold
+	_, err = NewRefreshParticipant(data[1], 2, false)
+	require.Error(t, err)
+	_, err = NewRefreshParticipant(data[1], 4, false)
+	require.Error(t, err)
+
+	// A public share off the sharing polynomial is rejected
+	one, err := curves.NewScalarBaseMult(btcec.S256(), big.NewInt(1))
+	require.NoError(t, err)
+	point, err := data[1].PublicShares[4].Point.Add(one)
+	require.NoError(t, err)
+	data[1].PublicShares[4] = &dealer.PublicShare{Point: point}
+	_, err = NewRefreshParticipant(data[1], 3, false)
+	require.Error(t, err)
+}
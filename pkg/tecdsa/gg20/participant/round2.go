@@ -8,6 +8,7 @@ package participant
 
 import (
 	"encoding/json"
+	"fmt"
 	"math/big"
 
 	"github.com/coinbase/kryptology/pkg/core"
@@ -81,15 +82,22 @@ func (signer *Signer) SignRound2(params map[uint32]*Round1Bcast, p2p map[uint32]
 		pp.Pk = signer.state.pks[j]
 		pp.C = param.Ctxt
 
-		if signer.state.keyGenType.IsTrustedDealer() {
-			if err := param.Proof.Verify(pp); err != nil {
-				return nil, err
-			}
-		} else {
+		range1 := param.Proof
+		if !signer.state.keyGenType.IsTrustedDealer() {
 			// The case using DKG, verify range proof in P2PSend
-			if err := p2p[j].Verify(pp); err != nil {
-				return nil, err
-			}
+			range1 = p2p[j]
+		}
+		if range1 == nil {
+			return nil, blame(j, 2, CheckMessage, nil, fmt.Errorf("range proof cannot be nil"))
+		}
+		if err := range1.Verify(pp); err != nil {
+			return nil, blame(j, 2, CheckRange1Proof, &Range1Evidence{
+				Curve:        signer.Curve,
+				Pk:           pp.Pk,
+				DealerParams: pp.DealerParams,
+				Ctxt:         pp.C,
+				Proof:        range1,
+			}, err)
 		}
 
 		// 4. Compute c^{\gamma}_{ji}, \beta_{ji}, \pi^{Range2}_{ji} = MtaResponse(γ_i,g,q,pk_j,N~,h1,h2,c_j)
@@ -84,16 +84,17 @@ func (s *Signer) SignRound3(in map[uint32]*P2PSend) (*Round3Bcast, error) {
 			continue
 		}
 
-		if value == nil {
-			return nil, fmt.Errorf("P2P message for participant %v cannot be nil", j)
+		if value == nil || value.Proof2 == nil || value.Proof3 == nil {
+			return nil, blame(j, 3, CheckMessage, nil, fmt.Errorf("P2P message for participant %v cannot be nil", j))
 		}
 
 		// 5. Compute α_ij = MtAFinalize(g,q,sk_i,pk_i,N~,h1,h2,c_i,c_ij,π_ij)
+		verifyParams.B = nil
 		alphaij, err := value.Proof2.Finalize(verifyParams)
 
 		// 6. If α_ij = ⊥, Abort
 		if err != nil {
-			return nil, err
+			return nil, blame(j, 3, CheckRange2Proof, s.responseEvidence(value.Proof2, verifyParams), err)
 		}
 
 		// 7. Compute μ_ij = MtAFinalize_wc(g,q,sk_i,pk_i,N~,h1,h2,c_i,c_ij,π_ij,W_j)
@@ -102,7 +103,7 @@ func (s *Signer) SignRound3(in map[uint32]*P2PSend) (*Round3Bcast, error) {
 
 		// 8. If μ_ij = ⊥, Abort
 		if err != nil {
-			return nil, err
+			return nil, blame(j, 3, CheckRange3Proof, s.responseEvidence(value.Proof3, verifyParams), err)
 		}
 
 		// 9. Compute δ_i = δ_i + α_ij + β_ji  mod q
@@ -138,3 +139,20 @@ func (s *Signer) SignRound3(in map[uint32]*P2PSend) (*Round3Bcast, error) {
 	// 11. Broadcast δ_i to all other players
 	return &Round3Bcast{deltai}, nil
 }
+
+// responseEvidence returns the public values of a response that failed to finalize,
+// or nil when the response is not a proof.ResponseProof
+func (s *Signer) responseEvidence(response proof.ResponseFinalizer, vp *proof.ResponseVerifyParams) Evidence {
+	rp, ok := response.(*proof.ResponseProof)
+	if !ok {
+		return nil
+	}
+	return &ResponseEvidence{
+		Curve:        vp.Curve,
+		Pk:           &s.sk.PublicKey,
+		DealerParams: vp.DealerParams,
+		C1:           vp.C1,
+		B:            vp.B,
+		Proof:        rp,
+	}
+}
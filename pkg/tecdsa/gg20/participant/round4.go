@@ -7,6 +7,7 @@
 package participant
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/coinbase/kryptology/pkg/core"
@@ -39,6 +40,10 @@ func (s *Signer) SignRound4(deltas map[uint32]*Round3Bcast) (*Round4Bcast, error
 			continue
 		}
 
+		if deltaj == nil || deltaj.deltaElement == nil {
+			return nil, blame(j, 4, CheckMessage, nil, fmt.Errorf("delta cannot be nil"))
+		}
+
 		// 4. Compute δ = δ + δ_j mod q
 		delta, err = core.Add(delta, deltaj.deltaElement, s.Curve.Params().N)
 		if err != nil {
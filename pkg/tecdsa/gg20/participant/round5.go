@@ -8,6 +8,7 @@ package participant
 
 import (
 	"fmt"
+	"math/big"
 
 	"github.com/coinbase/kryptology/pkg/core"
 	"github.com/coinbase/kryptology/pkg/core/curves"
@@ -17,7 +18,9 @@ import (
 // Round5Bcast are the values to be broadcast to the other players at the conclusion
 // of signing round 5
 type Round5Bcast struct {
-	Rbar  *curves.EcPoint
+	Rbar *curves.EcPoint
+	// S is S_i = R^{σ_i}, against which the share s_i of the signature is checked
+	S     *curves.EcPoint
 	Proof *proof.PdlProof
 }
 
@@ -40,28 +43,33 @@ func (signer *Signer) SignRound5(witnesses map[uint32]*Round4Bcast) (*Round5Bcas
 
 	// 2. For j = [1,...,t+1]
 	for j, d := range witnesses {
-		if d == nil {
-			return nil, nil, fmt.Errorf("input witnesses cannot be nil")
+		if d == nil || d.Witness == nil {
+			return nil, nil, blame(j, 5, CheckMessage, nil, fmt.Errorf("input witnesses cannot be nil"))
 		}
 		// 3. If i == j, continue
 		if j == signer.id {
 			continue
 		}
 
+		evidence := &DecommitmentEvidence{
+			Curve:      signer.Curve,
+			Commitment: signer.state.Cj[j],
+			Witness:    d.Witness,
+		}
 		// FUTURE: match commitment with identifier instead of index
 		// 4. Compute Γ_j = Open(C_j , D_j)
 		ok, err := core.Open(signer.state.Cj[j], *d.Witness)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, blame(j, 5, CheckDecommitment, evidence, err)
 		}
 		if !ok {
-			return nil, nil, fmt.Errorf("commitment couldn't be opened")
+			return nil, nil, blame(j, 5, CheckDecommitment, evidence, fmt.Errorf("commitment couldn't be opened"))
 		}
 
 		// 5. If Γ_j = ⊥, Abort
 		Gammaj, err := curves.PointFromBytesUncompressed(signer.Curve, d.Witness.Msg)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, blame(j, 5, CheckDecommitment, evidence, err)
 		}
 
 		// 6. Compute R = R · Γ_j in G
@@ -87,13 +95,13 @@ func (signer *Signer) SignRound5(witnesses map[uint32]*Round4Bcast) (*Round5Bcas
 		return nil, nil, err
 	}
 
-	// Sanity check
+	// Compute S_i = R^{σ_i}
 	Rbark, err := R.ScalarMult(signer.state.sigmai)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	bcast := &Round5Bcast{Rbari, nil}
+	bcast := &Round5Bcast{Rbar: Rbari, S: Rbark}
 	p2p := make(map[uint32]*Round5P2PSend)
 	pdlParams := proof.PdlProofParams{
 		Curve:   signer.Curve,
@@ -136,8 +144,8 @@ func (signer *Signer) SignRound5(witnesses map[uint32]*Round4Bcast) (*Round5Bcas
 	// Used in Round 6
 	signer.state.R = R
 
-	// 8. Set r = R_x
-	signer.state.r = R.X
+	// 8. Set r = R_x mod q
+	signer.state.r = new(big.Int).Mod(R.X, signer.Curve.Params().N)
 
 	// 12. Set \overline{R}_i
 	signer.state.Rbari = Rbari
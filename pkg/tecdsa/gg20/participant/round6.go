@@ -7,6 +7,7 @@
 package participant
 
 import (
+	"crypto/elliptic"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -84,6 +85,10 @@ func (signer *Signer) signRound6Offline(in map[uint32]*Round5Bcast, p2p map[uint
 
 	// 1. Set V = \bar{R}_i
 	v := signer.state.Rbari
+	// Set S = S_i
+	s := signer.state.Rbark
+	signer.state.Rbarj = map[uint32]*curves.EcPoint{signer.id: signer.state.Rbari}
+	signer.state.Sj = map[uint32]*curves.EcPoint{signer.id: signer.state.Rbark}
 
 	// 2. For j=[1,...,t+1]
 	for j, value := range in {
@@ -92,6 +97,13 @@ func (signer *Signer) signRound6Offline(in map[uint32]*Round5Bcast, p2p map[uint
 			continue
 		}
 
+		if value == nil || value.Rbar == nil || value.S == nil {
+			return blame(j, 6, CheckMessage, nil, fmt.Errorf("round 5 broadcast cannot be nil"))
+		}
+		if !value.S.IsOnCurve() {
+			return blame(j, 6, CheckMessage, nil, fmt.Errorf("S_j is not a point of the curve"))
+		}
+
 		// 4. TrustedDealer - If VerifyPDL(πkCONSIST,g,q,R,pkj,N,h1,h2,cj,Rj) = False, Abort
 		// 4. DKG - If VerifyPDL(πkCONSIST_j,g,q,R,pkj,Nj,h1j,h2j,cj,Rj) = False, Abort
 		// Pseudocode says i when it should be j
@@ -103,14 +115,23 @@ func (signer *Signer) signRound6Offline(in map[uint32]*Round5Bcast, p2p map[uint
 			PointR:       signer.state.R,
 			C:            signer.state.cj[j],
 		}
-		if signer.state.keyGenType.IsTrustedDealer() {
-			if err := value.Proof.Verify(verifyProofParams); err != nil {
-				return err
-			}
-		} else {
-			if err := p2p[j].Verify(verifyProofParams); err != nil {
-				return err
-			}
+		pdl := value.Proof
+		if !signer.state.keyGenType.IsTrustedDealer() {
+			pdl = p2p[j]
+		}
+		if pdl == nil {
+			return blame(j, 6, CheckMessage, nil, fmt.Errorf("pdl proof cannot be nil"))
+		}
+		if err := pdl.Verify(verifyProofParams); err != nil {
+			return blame(j, 6, CheckPdlProof, &PdlEvidence{
+				Curve:        signer.Curve,
+				Pk:           verifyProofParams.Pk,
+				DealerParams: verifyProofParams.DealerParams,
+				Rbar:         value.Rbar,
+				R:            verifyProofParams.PointR,
+				C:            verifyProofParams.C,
+				Proof:        pdl,
+			}, err)
 		}
 
 		// 5. Compute V = V · R_j in G
@@ -118,11 +139,26 @@ func (signer *Signer) signRound6Offline(in map[uint32]*Round5Bcast, p2p map[uint
 		if err != nil {
 			return err
 		}
+		// Compute S = S · S_j in G
+		s, err = s.Add(value.S)
+		if err != nil {
+			return err
+		}
+		signer.state.Rbarj[j] = value.Rbar
+		signer.state.Sj[j] = value.S
 	}
 	// 6 If V != g, Abort
+	// The \overline{R_j} are proven consistent with the k_j, so V != g means that δ is not k·γ. The shares δ_j
+	// are not proven, so the cosigner who sent a wrong one cannot be identified.
 	if !v.IsBasePoint() {
 		return fmt.Errorf("V != g")
 	}
+	// If S != y, Abort
+	// The S_j are not proven consistent with the shares of the MtAwc, so the cosigner who sent a wrong one cannot
+	// be identified either. Once S = y, a share s_j that does not match S_j identifies its sender in SignOutput.
+	if !s.Equals(signer.PublicKey) {
+		return fmt.Errorf("S != y")
+	}
 	// 7. return r, k, \sigma,
 	// These are already stored
 	return nil
@@ -133,76 +169,113 @@ func (signer *Signer) signRound6Offline(in map[uint32]*Round5Bcast, p2p map[uint
 // func (p Participant) SignRound6Online(msg []byte, k, r, sigma *big.Int, curve elliptic.Curve) (*Round6FullBcast, *Round6FullOut, error) {
 func (signer *Signer) signRound6Online(hash []byte) (*Round6FullBcast, error) {
 	// FUTURE: check the current round state if allowed to be called separately
+	si, err := signShare(signer.Curve, hash, signer.state.ki, signer.state.r, signer.state.sigmai)
+	if err != nil {
+		return nil, err
+	}
+	signer.state.msgHash = hash
+	signer.state.si = si
+	signer.Round = 7
+
+	// 9. Broadcast s_i to all other players
+	// 10. Return s_i
+	return &Round6FullBcast{si}, nil
+}
 
+// signShare computes steps 7-8 of SignRound6Online, the share s_i of the signature
+func signShare(curve elliptic.Curve, hash []byte, ki, r, sigmai *big.Int) (*big.Int, error) {
 	// 7. Compute m = H(M) ∈ Z_q
 	// We receive the message already hashed to allow flexibility for the callers
 	// to hash the message according to the library they use
 	// However, we check the hash is in the field
 	m := new(big.Int).SetBytes(hash)
-	if err := core.In(m, signer.Curve.Params().N); err != nil {
+	if err := core.In(m, curve.Params().N); err != nil {
 		return nil, err
 	}
-	signer.state.msgHash = hash
 
 	// 8. Compute s_i = m k_i + r σ_i mod q
-	m, err := core.Mul(m, signer.state.ki, signer.Curve.Params().N)
+	m, err := core.Mul(m, ki, curve.Params().N)
 	if err != nil {
 		return nil, err
 	}
-	rTmp, err := core.Mul(signer.state.r, signer.state.sigmai, signer.Curve.Params().N)
+	rTmp, err := core.Mul(r, sigmai, curve.Params().N)
 	if err != nil {
 		return nil, err
 	}
-	si, err := core.Add(m, rTmp, signer.Curve.Params().N)
-	if err != nil {
-		return nil, err
-	}
-
-	signer.state.si = si
-	signer.Round = 7
-
-	// 9. Broadcast s_i to all other players
-	// 10. Return s_i
-	return &Round6FullBcast{si}, nil
+	return core.Add(m, rTmp, curve.Params().N)
 }
 
 // SignOutput performs the signature aggregation step in
 // [spec] §5.fig 5
 func (signer *Signer) SignOutput(in map[uint32]*Round6FullBcast) (*curves.EcdsaSignature, error) {
-	var err error
-	if err = signer.verifyStateMap(7, in); err != nil {
+	if err := signer.verifyStateMap(7, in); err != nil {
 		return nil, err
 	}
+	return outputSignature(signer.Curve, signer.id, signer.state.si, signer.state.R, signer.state.Rbarj, signer.state.Sj,
+		in, signer.state.verify, signer.PublicKey, signer.state.msgHash)
+}
+
+// outputSignature sums the shares s_j with the share si of the signer id into
+// the signature with nonce point R, and checks it against the public key. When the
+// signature is not valid, the shares are checked against the \overline{R_j} in rbar and the S_j in sj
+// to identify the cosigner who sent a wrong one.
+func outputSignature(curve elliptic.Curve, id uint32, si *big.Int, R *curves.EcPoint, rbar, sj map[uint32]*curves.EcPoint,
+	in map[uint32]*Round6FullBcast, verify curves.EcdsaVerify, publicKey *curves.EcPoint, hash []byte) (*curves.EcdsaSignature, error) {
+	var err error
 	// 1. Set s = s_i
-	s := new(big.Int).Set(signer.state.si)
+	s := new(big.Int).Set(si)
 
 	// 2. For j = [1,...,t+1]
 	for j, sj := range in {
 		// 3. If i = j, continue
-		if j == signer.id {
+		if j == id {
 			continue
 		}
 
+		if sj == nil || sj.sElement == nil {
+			return nil, blame(j, 7, CheckMessage, nil, fmt.Errorf("signature share cannot be nil"))
+		}
+
 		// 4. Compute s = s + s_j mod q
-		s, err = core.Add(s, sj.sElement, signer.Curve.Params().N)
+		s, err = core.Add(s, sj.sElement, curve.Params().N)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	sOld := new(big.Int).Set(s)
-	s = signer.normalizeS(s)
-	v := int(signer.state.R.Y.Bit(0))
+	s = normalizeS(curve, s)
+	v := int(R.Y.Bit(0))
+	// bit(1) of the recovery id is set when the x coordinate of R was reduced by the group order
+	if R.X.Cmp(curve.Params().N) >= 0 {
+		v |= 2
+	}
 
 	if sOld.Cmp(s) != 0 {
 		v ^= 1
 	}
 
-	// 5. Set \sigma = (r, s)
-	sigma := &curves.EcdsaSignature{V: v, R: signer.state.r, S: s}
+	// 5. Set \sigma = (r, s) with r = R_x mod q
+	sigma := &curves.EcdsaSignature{V: v, R: new(big.Int).Mod(R.X, curve.Params().N), S: s}
 
 	// 6. If ECDSAVerify(y, \sigma, M) = False, Abort
-	if !signer.state.verify(signer.PublicKey, signer.state.msgHash, sigma) {
+	if !verify(publicKey, hash, sigma) {
+		for j, share := range in {
+			if j == id {
+				continue
+			}
+			evidence := &SignatureShareEvidence{
+				Curve: curve,
+				Hash:  hash,
+				R:     R,
+				Rbar:  rbar[j],
+				S:     sj[j],
+				Share: share.sElement,
+			}
+			if err = evidence.check(); err != nil {
+				return nil, blame(j, 7, CheckSignatureShare, evidence, err)
+			}
+		}
 		return nil, fmt.Errorf("signature is not valid")
 	}
 
@@ -211,6 +284,10 @@ func (signer *Signer) SignOutput(in map[uint32]*Round6FullBcast) (*curves.EcdsaS
 }
 
 func (signer Signer) normalizeS(s *big.Int) *big.Int {
+	return normalizeS(signer.Curve, s)
+}
+
+func normalizeS(curve elliptic.Curve, s *big.Int) *big.Int {
 	// Normalize the signature to a "low S" form. In ECDSA, signatures are
 	// of the form (r, s) where r and s are numbers lying in some finite
 	// field. The verification equation will pass for (r, s) iff it passes
@@ -230,14 +307,14 @@ func (signer Signer) normalizeS(s *big.Int) *big.Int {
 	// lies in the lower half of its range.
 	// See <https://en.bitcoin.it/wiki/BIP_0062#Low_S_values_in_signatures>
 	qDiv2 := new(big.Int)
-	qDiv2 = qDiv2.Div(signer.Curve.Params().N, core.Two)
+	qDiv2 = qDiv2.Div(curve.Params().N, core.Two)
 
 	// Check whether a scalar is higher than the group order divided
 	// by 2. If true, we negate s.
 	// Not constant time, it would be better to conditionally negate with check in constant time
 	// but since `s` is a public value anyway, this is allowed to be variable time
 	if s.Cmp(qDiv2) == 1 {
-		return new(big.Int).Sub(signer.Curve.Params().N, s)
+		return new(big.Int).Sub(curve.Params().N, s)
 	}
 	return new(big.Int).Set(s)
 }
@@ -9,8 +9,11 @@ package participant
 import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/json"
 	"math/big"
+	"sync"
 	"testing"
 
 	"github.com/btcsuite/btcd/btcec"
@@ -25,11 +28,9 @@ import (
 )
 
 var (
-	dealerParams = &dealer.ProofParams{
-		N:  tt.B10("135817986946410153263607521492868157288929876347703239389804036854326452848342067707805833332721355089496671444901101084429868705550525577068432132709786157994652561102559125256427177197007418406633665154772412807319781659630513167839812152507439439445572264448924538846645935065905728327076331348468251587961"),
-		H1: tt.B10("130372793360787914947629694846841279927281520987029701609177523587189885120190605946568222485341643012763305061268138793179515860485547361500345083617939280336315872961605437911597699438598556875524679018909165548046362772751058504008161659270331468227764192850055032058007664070200355866555886402826731196521"),
-		H2: tt.B10("44244046835929503435200723089247234648450309906417041731862368762294548874401406999952605461193318451278897748111402857920811242015075045913904246368542432908791195758912278843108225743582704689703680577207804641185952235173475863508072754204128218500376538767731592009803034641269409627751217232043111126391"),
-	}
+	dealerKeyGenTypes     = make(map[string]*dealer.TrustedDealerKeyGenType)
+	dealerKeyGenTypesLock sync.Mutex
+
 	testPrimes = []*big.Int{
 		tt.B10("186141419611617071752010179586510154515933389116254425631491755419216243670159714804545944298892950871169229878325987039840135057969555324774918895952900547869933648175107076399993833724447909579697857041081987997463765989497319509683575289675966710007879762972723174353568113668226442698275449371212397561567"),
 		tt.B10("94210786053667323206442523040419729883258172350738703980637961803118626748668924192069593010365236618255120977661397310932923345291377692570649198560048403943687994859423283474169530971418656709749020402756179383990602363122039939937953514870699284906666247063852187255623958659551404494107714695311474384687"),
@@ -111,6 +112,21 @@ func genPrimesArray(count int) []struct{ p, q *big.Int } {
 	return primesArray
 }
 
+// dealerKeyGenType returns trusted dealer params for curve with the proof of their well-formedness.
+// The params are made from test primes that are not used as Paillier keys.
+func dealerKeyGenType(t testing.TB, curve elliptic.Curve) *dealer.TrustedDealerKeyGenType {
+	t.Helper()
+	dealerKeyGenTypesLock.Lock()
+	defer dealerKeyGenTypesLock.Unlock()
+	name := curve.Params().Name
+	if _, ok := dealerKeyGenTypes[name]; !ok {
+		kgt, err := proof.NewTrustedDealerKeyGenTypeWithPrimes(curve, testPrimes[len(testPrimes)-2], testPrimes[len(testPrimes)-1])
+		require.NoError(t, err)
+		dealerKeyGenTypes[name] = kgt
+	}
+	return dealerKeyGenTypes[name]
+}
+
 // Creates a set of signers that are usable for testing
 func setupSignersMap(t *testing.T, curve elliptic.Curve, playerThreshold, playerCnt int,
 	addRound1 bool, verify curves.EcdsaVerify, useDistributed bool) (*curves.EcPoint, map[uint32]*Signer) {
@@ -162,9 +178,7 @@ func setupSignersMap(t *testing.T, curve elliptic.Curve, playerThreshold, player
 			ProofParams: distributedProofParams,
 		}
 	} else {
-		proofParams = &dealer.TrustedDealerKeyGenType{
-			ProofParams: dealerParams,
-		}
+		proofParams = dealerKeyGenType(t, curve)
 	}
 
 	// Create participants and signers
@@ -223,7 +237,7 @@ func TestSignerSignRound1Works(t *testing.T) {
 				err = signerIOut.Proof.Verify(&proof.Proof1Params{
 					Curve:        curve,
 					Pk:           &signer.sk.PublicKey,
-					DealerParams: dealerParams,
+					DealerParams: signer.state.keyGenType.GetProofParams(0),
 					C:            signerIOut.Ctxt,
 				})
 				require.NoError(t, err)
@@ -611,8 +625,8 @@ func TestSignerSignRound5Works(t *testing.T) {
 		if signers[1].state.R == nil {
 			t.Errorf("Expected R to be set")
 		}
-		if signers[1].state.r.Cmp(signers[1].state.R.X) != 0 {
-			t.Errorf("Expected r == Rx")
+		if signers[1].state.r.Cmp(new(big.Int).Mod(signers[1].state.R.X, curve.Params().N)) != 0 {
+			t.Errorf("Expected r == Rx mod q")
 		}
 
 		round5Bcast[2], round5P2P[2], err = signers[2].SignRound5(round4Bcast)
@@ -815,6 +829,17 @@ func fullroundstest3Signers(t *testing.T, curve elliptic.Curve, msg []byte, veri
 			2: round6FullBcast[1],
 		})
 		require.NoError(t, err)
+
+		// The recovery id of the signature recovers the public key
+		recoveryCurve := curves.GetCurveByName(curve.Params().Name)
+		require.NotNil(t, recoveryCurve)
+		for _, sig := range sigs {
+			recovered, err := curves.RecoverPublicKey(recoveryCurve, msg, sig)
+			require.NoError(t, err)
+			expected, err := recoveryCurve.Point.Set(pk.X, pk.Y)
+			require.NoError(t, err)
+			require.True(t, expected.Equal(recovered))
+		}
 	}
 }
 
@@ -916,3 +941,64 @@ func TestMarshalRound6FullBcast(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expected, actual)
 }
+
+// TestOutputSignatureRecoveryIdOfLargeX combines the shares of a nonce point whose x coordinate is larger than the
+// group order, with the public key for which the shares form a valid signature
+func TestOutputSignatureRecoveryIdOfLargeX(t *testing.T) {
+	for _, test := range []struct {
+		curve  *curves.Curve
+		verify curves.EcdsaVerify
+	}{
+		{curves.K256(), k256Verifier},
+		{curves.P256(), ecdsaVerifier},
+	} {
+		curve := test.curve
+		ellipticCurve, err := curve.ToEllipticCurve()
+		require.NoError(t, err)
+		n := ellipticCurve.Params().N
+
+		// R = (x, y) with x > q
+		var bigR curves.Point
+		compressed := make([]byte, 33)
+		compressed[0] = 2
+		for x := new(big.Int).Add(n, big.NewInt(1)); bigR == nil; x.Add(x, big.NewInt(1)) {
+			x.FillBytes(compressed[1:])
+			if point, err := curve.Point.FromAffineCompressed(compressed); err == nil && !point.IsIdentity() {
+				bigR = point
+			}
+		}
+		uncompressed := bigR.ToAffineUncompressed()
+		R := &curves.EcPoint{
+			Curve: ellipticCurve,
+			X:     new(big.Int).SetBytes(uncompressed[1:33]),
+			Y:     new(big.Int).SetBytes(uncompressed[33:]),
+		}
+
+		// Q = r^-1 (sR - mG)
+		digest := sha256.Sum256([]byte("large x"))
+		si, sj := curve.Scalar.Random(rand.Reader), curve.Scalar.Random(rand.Reader)
+		m, err := curve.Scalar.SetBigInt(new(big.Int).SetBytes(digest[:]))
+		require.NoError(t, err)
+		r, err := curve.Scalar.SetBigInt(R.X)
+		require.NoError(t, err)
+		rInv, err := r.Invert()
+		require.NoError(t, err)
+		q := bigR.Mul(si.Add(sj)).Sub(curve.ScalarBaseMult(m)).Mul(rInv).ToAffineUncompressed()
+		publicKey := &curves.EcPoint{
+			Curve: ellipticCurve,
+			X:     new(big.Int).SetBytes(q[1:33]),
+			Y:     new(big.Int).SetBytes(q[33:]),
+		}
+
+		sig, err := outputSignature(ellipticCurve, 1, si.BigInt(), R, nil, nil,
+			map[uint32]*Round6FullBcast{2: {sj.BigInt()}}, test.verify, publicKey, digest[:])
+		require.NoError(t, err)
+		require.Equal(t, 2, sig.V&2)
+		require.Equal(t, -1, sig.R.Cmp(n))
+		recovered, err := curves.RecoverPublicKey(curve, digest[:], sig)
+		require.NoError(t, err)
+		expected, err := curve.Point.Set(publicKey.X, publicKey.Y)
+		require.NoError(t, err)
+		require.True(t, expected.Equal(recovered))
+	}
+}
@@ -121,6 +121,14 @@ func (p CdlProof) Verify(cv *CdlVerifyParams) error {
 	if p.u == nil || p.s == nil {
 		return fmt.Errorf("proof values cannot be nil")
 	}
+	if len(p.u) != ell || len(p.s) != ell {
+		return fmt.Errorf("proof must have %d values", ell)
+	}
+	for i := 0; i < ell; i++ {
+		if p.u[i] == nil || p.s[i] == nil {
+			return fmt.Errorf("proof values cannot be nil")
+		}
+	}
 
 	if cv == nil || cv.Curve == nil || cv.H1 == nil || cv.H2 == nil || cv.N == nil {
 		return fmt.Errorf("proof verify params cannot be nil")
@@ -266,17 +266,7 @@ func genResponseProof(rp ResponseProofParams, wc bool) (*ResponseProof, error) {
 // Finalize checks a range (2) proof: [spec] fig 13: MtaFinalize
 // and returns the paillier encrypted random value
 func (rp ResponseProof) Finalize(vp *ResponseVerifyParams) (*big.Int, error) {
-	v2Params := verifyProof2Params{
-		curve:        vp.Curve,
-		dealerParams: vp.DealerParams,
-		pk: &paillier.PublicKey{
-			N:  vp.Sk.N,
-			N2: vp.Sk.N2,
-		},
-		c1: vp.C1,
-		c2: rp.C2,
-	}
-	if err := rp.R2proof.Verify(&v2Params); err != nil {
+	if err := rp.Verify(&vp.Sk.PublicKey, vp); err != nil {
 		return nil, err
 	}
 	alpha, err := vp.Sk.Decrypt(rp.C2)
@@ -290,19 +280,8 @@ func (rp ResponseProof) Finalize(vp *ResponseVerifyParams) (*big.Int, error) {
 // FinalizeWc checks a range (2) proof: [spec] fig 13: MtaFinalize_wc
 // and returns the paillier encrypted random value
 func (rp ResponseProof) FinalizeWc(vp *ResponseVerifyParams) (*big.Int, error) {
-	v2Params := verifyProof2Params{
-		curve:        vp.Curve,
-		dealerParams: vp.DealerParams,
-		pk: &paillier.PublicKey{
-			N:  vp.Sk.N,
-			N2: vp.Sk.N2,
-		},
-		c1: vp.C1,
-		c2: rp.C2,
-		X:  vp.B,
-	}
 	// 1. If MtaVerifyRange2_wc(...) = False, Return Error
-	if err := rp.R2proof.VerifyWc(&v2Params); err != nil {
+	if err := rp.VerifyWc(&vp.Sk.PublicKey, vp); err != nil {
 		return nil, err
 	}
 	// 2. Compute \alpha = Decrypt(sk, C2)
@@ -314,6 +293,39 @@ func (rp ResponseProof) FinalizeWc(vp *ResponseVerifyParams) (*big.Int, error) {
 	return alpha.Mod(alpha, vp.Curve.Params().N), nil
 }
 
+// Verify checks the range (2) proof of a response to the holder of pk without decrypting it,
+// so that a party without the secret key can check it. vp.Sk is ignored.
+func (rp ResponseProof) Verify(pk *paillier.PublicKey, vp *ResponseVerifyParams) error {
+	return rp.verify(pk, vp, false)
+}
+
+// VerifyWc checks the range (2) proof of a response with check to the holder of pk
+// without decrypting it. vp.Sk is ignored.
+func (rp ResponseProof) VerifyWc(pk *paillier.PublicKey, vp *ResponseVerifyParams) error {
+	return rp.verify(pk, vp, true)
+}
+
+func (rp ResponseProof) verify(pk *paillier.PublicKey, vp *ResponseVerifyParams, wc bool) error {
+	if rp.R2proof == nil || rp.C2 == nil {
+		return fmt.Errorf("response proof values cannot be nil")
+	}
+	v2Params := verifyProof2Params{
+		curve:        vp.Curve,
+		dealerParams: vp.DealerParams,
+		pk: &paillier.PublicKey{
+			N:  pk.N,
+			N2: pk.N2,
+		},
+		c1: vp.C1,
+		c2: rp.C2,
+	}
+	if wc {
+		v2Params.X = vp.B
+		return rp.R2proof.VerifyWc(&v2Params)
+	}
+	return rp.R2proof.Verify(&v2Params)
+}
+
 // Prove computes a range proof over these parameters
 // [spec] fig 10: MtaProveRange1
 func (pp Proof1Params) Prove() (*Range1Proof, error) {
@@ -0,0 +1,224 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	"crypto/elliptic"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	mod "github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/paillier"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/dealer"
+)
+
+// RingPedersenProof proves that the ring-Pedersen generators h1, h2 of dealer.ProofParams generate the
+// same group, that is h2 = h1^alpha and h1 = h2^beta for some alpha, beta known to the prover, see
+// [spec] §10.fig 16. Without it a malicious owner of the params can choose h2 outside of the group
+// generated by h1 and extract secrets from the range proofs of the signing rounds.
+type RingPedersenProof struct {
+	// H2InH1 proves that h2 = h1^alpha
+	H2InH1 *CdlProof
+	// H1InH2 proves that h1 = h2^beta
+	H1InH2 *CdlProof
+}
+
+// NewRingPedersenParams creates new ProofParams from new safe primes together with the proof of their
+// well-formedness. It can be used by a trusted dealer instead of dealer.NewProofParams.
+func NewRingPedersenParams(curve elliptic.Curve) (*dealer.ProofParams, *RingPedersenProof, error) {
+	var p, q *big.Int
+	for p == nil || p.Cmp(q) == 0 {
+		values := make(chan *big.Int, 2)
+		errors := make(chan error, 2)
+		for range []int{1, 2} {
+			go func() {
+				value, err := mod.GenerateSafePrime(paillier.PaillierPrimeBits)
+				values <- value
+				errors <- err
+			}()
+		}
+		for _, err := range []error{<-errors, <-errors} {
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		p, q = <-values, <-values
+	}
+	return NewRingPedersenParamsWithPrimes(curve, p, q)
+}
+
+// NewRingPedersenParamsWithPrimes creates new ProofParams using the distinct safe primes P, Q
+// together with the proof of their well-formedness
+func NewRingPedersenParamsWithPrimes(curve elliptic.Curve, P, Q *big.Int) (*dealer.ProofParams, *RingPedersenProof, error) {
+	if curve == nil || P == nil || Q == nil {
+		return nil, nil, fmt.Errorf("invalid params")
+	}
+	if P.Cmp(Q) == 0 {
+		return nil, nil, fmt.Errorf("safe primes must be distinct")
+	}
+
+	// Compute tildeN = PQ and p = (P-1)/2, q = (Q-1)/2
+	n := new(big.Int).Mul(P, Q)
+	pi := new(big.Int).Rsh(P, 1)
+	qi := new(big.Int).Rsh(Q, 1)
+	pq := new(big.Int).Mul(pi, qi)
+
+	// Sample f, alpha from Z_tildeN* with alpha invertible mod pq
+	f, err := mod.Rand(n)
+	if err != nil {
+		return nil, nil, err
+	}
+	var alpha, beta *big.Int
+	for beta == nil {
+		alpha, err = mod.Rand(n)
+		if err != nil {
+			return nil, nil, err
+		}
+		beta = new(big.Int).ModInverse(alpha, pq)
+	}
+
+	// h1 = f^2 mod tildeN, h2 = h1^alpha mod tildeN
+	h1, err := mod.Mul(f, f, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	h2 := new(big.Int).Exp(h1, alpha, n)
+	params := &dealer.ProofParams{N: n, H1: h1, H2: h2}
+	if err := validateProofParams(params); err != nil {
+		return nil, nil, err
+	}
+
+	h2InH1, err := CdlProofParams{
+		Curve:   curve,
+		Pi:      pi,
+		Qi:      qi,
+		H1:      h1,
+		H2:      h2,
+		ScalarX: alpha,
+		N:       n,
+	}.Prove()
+	if err != nil {
+		return nil, nil, err
+	}
+	h1InH2, err := CdlProofParams{
+		Curve:   curve,
+		Pi:      pi,
+		Qi:      qi,
+		H1:      h2,
+		H2:      h1,
+		ScalarX: beta,
+		N:       n,
+	}.Prove()
+	if err != nil {
+		return nil, nil, err
+	}
+	return params, &RingPedersenProof{H2InH1: h2InH1, H1InH2: h1InH2}, nil
+}
+
+// Verify checks that params are well formed with the proofs of [spec] §10.fig 16
+func (p RingPedersenProof) Verify(curve elliptic.Curve, params *dealer.ProofParams) error {
+	if curve == nil || p.H2InH1 == nil || p.H1InH2 == nil {
+		return fmt.Errorf("proof values cannot be nil")
+	}
+	if err := validateProofParams(params); err != nil {
+		return err
+	}
+
+	if err := p.H2InH1.Verify(&CdlVerifyParams{
+		Curve: curve,
+		H1:    params.H1,
+		H2:    params.H2,
+		N:     params.N,
+	}); err != nil {
+		return err
+	}
+	// Note the position of h1 and h2, they are reversed in the second verification
+	return p.H1InH2.Verify(&CdlVerifyParams{
+		Curve: curve,
+		H1:    params.H2,
+		H2:    params.H1,
+		N:     params.N,
+	})
+}
+
+// NewTrustedDealerKeyGenType creates the proof params of a trusted dealer together with the
+// proof of their well-formedness that participants check before signing
+func NewTrustedDealerKeyGenType(curve elliptic.Curve) (*dealer.TrustedDealerKeyGenType, error) {
+	params, proof, err := NewRingPedersenParams(curve)
+	if err != nil {
+		return nil, err
+	}
+	return newTrustedDealerKeyGenType(params, proof)
+}
+
+// NewTrustedDealerKeyGenTypeWithPrimes creates the proof params of a trusted dealer using the
+// distinct safe primes P, Q together with the proof of their well-formedness
+func NewTrustedDealerKeyGenTypeWithPrimes(curve elliptic.Curve, P, Q *big.Int) (*dealer.TrustedDealerKeyGenType, error) {
+	params, proof, err := NewRingPedersenParamsWithPrimes(curve, P, Q)
+	if err != nil {
+		return nil, err
+	}
+	return newTrustedDealerKeyGenType(params, proof)
+}
+
+func newTrustedDealerKeyGenType(params *dealer.ProofParams, proof *RingPedersenProof) (*dealer.TrustedDealerKeyGenType, error) {
+	proofBytes, err := json.Marshal(proof)
+	if err != nil {
+		return nil, err
+	}
+	return &dealer.TrustedDealerKeyGenType{ProofParams: params, ProofParamsProof: proofBytes}, nil
+}
+
+// VerifyDealerParams checks the proof that the proof params of a trusted dealer are well formed.
+// Params of a distributed key generation are proven during the DKG and are not checked here.
+func VerifyDealerParams(curve elliptic.Curve, keyGenType dealer.KeyGenType) error {
+	var td *dealer.TrustedDealerKeyGenType
+	switch kgt := keyGenType.(type) {
+	case dealer.TrustedDealerKeyGenType:
+		td = &kgt
+	case *dealer.TrustedDealerKeyGenType:
+		td = kgt
+	default:
+		return nil
+	}
+	if td == nil || len(td.ProofParamsProof) == 0 {
+		return fmt.Errorf("missing proof of dealer proof params")
+	}
+	proof := new(RingPedersenProof)
+	if err := json.Unmarshal(td.ProofParamsProof, proof); err != nil {
+		return err
+	}
+	if err := proof.Verify(curve, td.ProofParams); err != nil {
+		return fmt.Errorf("invalid dealer proof params: %v", err)
+	}
+	return nil
+}
+
+// validateProofParams checks that tildeN is odd and that h1, h2 are distinct units of Z_tildeN
+// that are neither 1 nor -1
+func validateProofParams(params *dealer.ProofParams) error {
+	if params == nil || params.N == nil || params.H1 == nil || params.H2 == nil {
+		return fmt.Errorf("proof params cannot be nil")
+	}
+	if params.N.Bit(0) == 0 {
+		return fmt.Errorf("proof params modulus must be odd")
+	}
+	nMinusOne := new(big.Int).Sub(params.N, big.NewInt(1))
+	for _, h := range []*big.Int{params.H1, params.H2} {
+		if h.Cmp(big.NewInt(1)) <= 0 || h.Cmp(nMinusOne) >= 0 {
+			return fmt.Errorf("proof params generator is out of range")
+		}
+		if new(big.Int).GCD(nil, nil, h, params.N).Cmp(big.NewInt(1)) != 0 {
+			return fmt.Errorf("proof params generator is not a unit")
+		}
+	}
+	if params.H1.Cmp(params.H2) == 0 {
+		return fmt.Errorf("proof params generators must be distinct")
+	}
+	return nil
+}
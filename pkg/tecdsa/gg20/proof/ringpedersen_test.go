@@ -0,0 +1,108 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package proof
+
+import (
+	"crypto/elliptic"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+
+	tt "github.com/coinbase/kryptology/internal"
+	"github.com/coinbase/kryptology/pkg/tecdsa/gg20/dealer"
+)
+
+var (
+	testSafePrimeP = tt.B10("165767109498679333927172882988675240871786832994588749158965767673945611886648976955012980205938446757878886183316455017521300834957764745162353525575268392435587843607612470895721541047254417540270756145682130189228024724293713540420700979243740619312487567234262826018540404128735554374146182348085336281439")
+	testSafePrimeQ = tt.B10("138451631119797627683944394514738428837020078954518535124773153508211993035282574374708542371034182930645915695336645534003697272777264261785750332260172837811934440302323563909582466079621984165168311186255004180759468783222026674257257121598775537240537892901360209680738434517685889621651841176243400986247")
+)
+
+func TestRingPedersenProof(t *testing.T) {
+	curve := btcec.S256()
+	params, proof, err := NewRingPedersenParamsWithPrimes(curve, testSafePrimeP, testSafePrimeQ)
+	require.NoError(t, err)
+	require.Equal(t, new(big.Int).Mul(testSafePrimeP, testSafePrimeQ), params.N)
+	require.NoError(t, proof.Verify(curve, params))
+
+	// The proof survives a JSON round trip
+	data, err := json.Marshal(proof)
+	require.NoError(t, err)
+	restored := new(RingPedersenProof)
+	require.NoError(t, json.Unmarshal(data, restored))
+	require.NoError(t, restored.Verify(curve, params))
+
+	// The proof is bound to the params
+	other, _, err := NewRingPedersenParamsWithPrimes(curve, testSafePrimeP, testSafePrimeQ)
+	require.NoError(t, err)
+	require.Error(t, proof.Verify(curve, other))
+	require.Error(t, proof.Verify(curve, &dealer.ProofParams{N: params.N, H1: params.H2, H2: params.H1}))
+
+	// Both proofs are needed
+	require.Error(t, RingPedersenProof{H2InH1: proof.H2InH1}.Verify(curve, params))
+	require.Error(t, RingPedersenProof{H2InH1: proof.H2InH1, H1InH2: proof.H2InH1}.Verify(curve, params))
+
+	// A truncated proof is rejected
+	short := &CdlProof{u: proof.H2InH1.u[:ell-1], s: proof.H2InH1.s[:ell-1]}
+	require.Error(t, RingPedersenProof{H2InH1: short, H1InH2: proof.H1InH2}.Verify(curve, params))
+}
+
+func TestRingPedersenProofInvalidParams(t *testing.T) {
+	curve := btcec.S256()
+	_, _, err := NewRingPedersenParamsWithPrimes(curve, testSafePrimeP, testSafePrimeP)
+	require.Error(t, err)
+	_, _, err = NewRingPedersenParamsWithPrimes(curve, nil, testSafePrimeQ)
+	require.Error(t, err)
+
+	params, proof, err := NewRingPedersenParamsWithPrimes(curve, testSafePrimeP, testSafePrimeQ)
+	require.NoError(t, err)
+	one := big.NewInt(1)
+	minusOne := new(big.Int).Sub(params.N, one)
+	for _, bad := range []*dealer.ProofParams{
+		nil,
+		{N: params.N, H1: params.H1},
+		{N: new(big.Int).Lsh(params.N, 1), H1: params.H1, H2: params.H2},
+		{N: params.N, H1: one, H2: params.H2},
+		{N: params.N, H1: params.H1, H2: minusOne},
+		{N: params.N, H1: params.H1, H2: new(big.Int).Add(params.N, params.H2)},
+		{N: params.N, H1: new(big.Int).Set(testSafePrimeP), H2: params.H2},
+		{N: params.N, H1: params.H1, H2: params.H1},
+	} {
+		require.Error(t, proof.Verify(curve, bad))
+	}
+}
+
+func TestVerifyDealerParams(t *testing.T) {
+	curve := btcec.S256()
+	keyGenType, err := NewTrustedDealerKeyGenTypeWithPrimes(curve, testSafePrimeP, testSafePrimeQ)
+	require.NoError(t, err)
+	require.NoError(t, VerifyDealerParams(curve, keyGenType))
+	require.NoError(t, VerifyDealerParams(curve, *keyGenType))
+
+	// The proof is bound to the curve
+	require.Error(t, VerifyDealerParams(elliptic.P256(), keyGenType))
+
+	// Dealer params without a proof are rejected
+	require.Error(t, VerifyDealerParams(curve, dealer.TrustedDealerKeyGenType{ProofParams: keyGenType.ProofParams}))
+	require.Error(t, VerifyDealerParams(curve, dealer.TrustedDealerKeyGenType{
+		ProofParams:      keyGenType.ProofParams,
+		ProofParamsProof: []byte("{}"),
+	}))
+
+	// The proof is bound to the params
+	other, err := NewTrustedDealerKeyGenTypeWithPrimes(curve, testSafePrimeP, testSafePrimeQ)
+	require.NoError(t, err)
+	require.Error(t, VerifyDealerParams(curve, dealer.TrustedDealerKeyGenType{
+		ProofParams:      other.ProofParams,
+		ProofParamsProof: keyGenType.ProofParamsProof,
+	}))
+
+	// Distributed params are proven in the DKG
+	require.NoError(t, VerifyDealerParams(curve, dealer.DistributedKeyGenType{}))
+}
@@ -0,0 +1,234 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/coinbase/kryptology/internal"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/sharing"
+	"github.com/coinbase/kryptology/pkg/signatures/schnorr/bip340"
+)
+
+// InvalidSharesError is returned by SignatureAggregator when participants sent
+// signature shares or nonce commitments that do not verify.
+// Culprits lists the ids of the misbehaving participants in ascending order.
+type InvalidSharesError struct {
+	Culprits []uint32
+}
+
+func (e *InvalidSharesError) Error() string {
+	return fmt.Sprintf("invalid signature shares from participants %v", e.Culprits)
+}
+
+// SignatureAggregator is the coordinator of a FROST signing session. It checks the
+// signature share of every participant against its verification share and nonce commitments
+// and combines valid shares into a signature. It holds no secret material.
+//
+// Signers in the default mode compute binding factors over their cosigners in the order
+// they were created with, the aggregator expects that order to be ascending.
+type SignatureAggregator struct {
+	curve            *curves.Curve
+	verificationKey  curves.Point
+	vkShares         map[uint32]curves.Point
+	threshold        uint32
+	challengeDeriver ChallengeDerive
+	suite            Ciphersuite
+	bip340           bool
+}
+
+// NewSignatureAggregator creates an aggregator for signers created with NewSigner.
+// vkShares holds the verification share of every participant output by the DKG.
+func NewSignatureAggregator(curve *curves.Curve, verificationKey curves.Point, vkShares map[uint32]curves.Point, threshold uint32, challengeDeriver ChallengeDerive) (*SignatureAggregator, error) {
+	if curve == nil || verificationKey == nil || len(vkShares) == 0 || challengeDeriver == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if threshold == 0 || threshold > uint32(len(vkShares)) {
+		return nil, fmt.Errorf("invalid threshold")
+	}
+	shares := make(map[uint32]curves.Point, len(vkShares))
+	for id, vk := range vkShares {
+		if vk == nil || !vk.IsOnCurve() || vk.IsIdentity() {
+			return nil, fmt.Errorf("invalid verification share for participant %d", id)
+		}
+		shares[id] = vk
+	}
+	return &SignatureAggregator{
+		curve:            curve,
+		verificationKey:  verificationKey,
+		vkShares:         shares,
+		threshold:        threshold,
+		challengeDeriver: challengeDeriver,
+	}, nil
+}
+
+// NewCiphersuiteAggregator creates an aggregator for signers created with NewCiphersuiteSigner
+func NewCiphersuiteAggregator(verificationKey curves.Point, vkShares map[uint32]curves.Point, threshold uint32, suite Ciphersuite) (*SignatureAggregator, error) {
+	if suite == nil {
+		return nil, internal.ErrNilArguments
+	}
+	aggregator, err := NewSignatureAggregator(suite.Curve(), verificationKey, vkShares, threshold, suite)
+	if err != nil {
+		return nil, err
+	}
+	aggregator.suite = suite
+	return aggregator, nil
+}
+
+// NewBip340Aggregator creates an aggregator for signers created with NewBip340Signer
+func NewBip340Aggregator(verificationKey curves.Point, vkShares map[uint32]curves.Point, threshold uint32) (*SignatureAggregator, error) {
+	aggregator, err := newBip340Aggregator(verificationKey, vkShares, threshold)
+	if err != nil {
+		return nil, err
+	}
+	aggregator.normalizeKeys()
+	return aggregator, nil
+}
+
+// NewTaprootAggregator creates an aggregator for signers created with NewTaprootSigner
+func NewTaprootAggregator(verificationKey curves.Point, vkShares map[uint32]curves.Point, threshold uint32, merkleRoot []byte) (*SignatureAggregator, error) {
+	aggregator, err := newBip340Aggregator(verificationKey, vkShares, threshold)
+	if err != nil {
+		return nil, err
+	}
+	aggregator.normalizeKeys()
+	t, err := TaprootTweak(aggregator.verificationKey, merkleRoot)
+	if err != nil {
+		return nil, err
+	}
+	// Mirror NewTaprootSigner which adds t to every signing share
+	tG := aggregator.curve.ScalarBaseMult(t)
+	for id, vk := range aggregator.vkShares {
+		aggregator.vkShares[id] = vk.Add(tG)
+	}
+	aggregator.verificationKey = aggregator.verificationKey.Add(tG)
+	if aggregator.verificationKey.IsIdentity() {
+		return nil, fmt.Errorf("invalid taproot tweak")
+	}
+	aggregator.normalizeKeys()
+	return aggregator, nil
+}
+
+func newBip340Aggregator(verificationKey curves.Point, vkShares map[uint32]curves.Point, threshold uint32) (*SignatureAggregator, error) {
+	aggregator, err := NewSignatureAggregator(curves.K256(), verificationKey, vkShares, threshold, Bip340ChallengeDeriver{})
+	if err != nil {
+		return nil, err
+	}
+	if verificationKey.CurveName() != curves.K256Name {
+		return nil, fmt.Errorf("bip340 signing requires secp256k1")
+	}
+	aggregator.bip340 = true
+	return aggregator, nil
+}
+
+// normalizeKeys negates the key material when the group key has an odd Y coordinate
+func (aggregator *SignatureAggregator) normalizeKeys() {
+	if bip340.HasEvenY(aggregator.verificationKey) {
+		return
+	}
+	for id, vk := range aggregator.vkShares {
+		aggregator.vkShares[id] = vk.Neg()
+	}
+	aggregator.verificationKey = aggregator.verificationKey.Neg()
+}
+
+// Aggregate verifies the signature shares output by SignRound2 or SignPreprocessed against the
+// nonce commitments of the session and combines them into a signature over msg.
+// When any participant misbehaved an *InvalidSharesError naming all of them is returned,
+// so the session can be retried without them.
+func (aggregator *SignatureAggregator) Aggregate(msg []byte, commitments map[uint32]*Round1Bcast, shares map[uint32]*Round2Bcast) (*Round3Bcast, error) {
+	if aggregator == nil || aggregator.curve == nil || len(msg) == 0 {
+		return nil, internal.ErrNilArguments
+	}
+	if uint32(len(commitments)) != aggregator.threshold {
+		return nil, fmt.Errorf("invalid number of commitments")
+	}
+	ids := sortedIds(commitments)
+	for _, id := range ids {
+		if _, ok := aggregator.vkShares[id]; !ok {
+			return nil, fmt.Errorf("unknown participant %d", id)
+		}
+	}
+
+	// Participants with malformed commitments are blamed before anything else is computed
+	var culprits []uint32
+	for _, id := range ids {
+		c := commitments[id]
+		if c == nil || c.Di == nil || c.Ei == nil ||
+			!c.Di.IsOnCurve() || c.Di.IsIdentity() || !c.Ei.IsOnCurve() || c.Ei.IsIdentity() {
+			culprits = append(culprits, id)
+		}
+	}
+	if len(culprits) > 0 {
+		return nil, &InvalidSharesError{culprits}
+	}
+
+	scheme, err := sharing.NewShamir(aggregator.threshold, ids[len(ids)-1], aggregator.curve)
+	if err != nil {
+		return nil, err
+	}
+	lCoeffs, err := scheme.LagrangeCoeffs(ids)
+	if err != nil {
+		return nil, err
+	}
+	factors, err := bindingFactors(aggregator.curve, aggregator.suite, aggregator.verificationKey, msg, commitments, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// R = sum(Dj + rj*Ej)
+	R := aggregator.curve.NewIdentityPoint()
+	Rs := make(map[uint32]curves.Point, len(ids))
+	for _, id := range ids {
+		Rs[id] = commitments[id].Di.Add(commitments[id].Ei.Mul(factors[id]))
+		R = R.Add(Rs[id])
+	}
+	c, err := aggregator.challengeDeriver.DeriveChallenge(msg, aggregator.verificationKey, R)
+	if err != nil {
+		return nil, err
+	}
+	negate := negateNonce(aggregator.suite, aggregator.bip340, R)
+
+	// Verify zj*G = Rj + c*Lj*vkj for every participant using the verification share from the DKG
+	z := aggregator.curve.NewScalar()
+	for _, id := range ids {
+		share, ok := shares[id]
+		if !ok || share == nil || share.Zi == nil {
+			culprits = append(culprits, id)
+			continue
+		}
+		Rj := Rs[id]
+		if negate {
+			Rj = Rj.Neg()
+		}
+		right := aggregator.vkShares[id].Mul(c.Mul(lCoeffs[id])).Add(Rj)
+		if !aggregator.curve.ScalarBaseMult(share.Zi).Equal(right) {
+			culprits = append(culprits, id)
+			continue
+		}
+		z = z.Add(share.Zi)
+	}
+	// Shares from participants without a commitment in this session are also misbehaviour
+	for id := range shares {
+		if _, ok := commitments[id]; !ok {
+			culprits = append(culprits, id)
+		}
+	}
+	if len(culprits) > 0 {
+		sort.Slice(culprits, func(i, j int) bool { return culprits[i] < culprits[j] })
+		return nil, &InvalidSharesError{culprits}
+	}
+
+	// Self verify the signature
+	ok, err := Verify(aggregator.curve, aggregator.challengeDeriver, aggregator.verificationKey, msg, &Signature{z, c})
+	if err != nil || !ok {
+		return nil, fmt.Errorf("invalid signature: c != c'")
+	}
+	return &Round3Bcast{R, z, c, msg}, nil
+}
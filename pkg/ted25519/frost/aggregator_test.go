@@ -0,0 +1,166 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	crand "crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	dkg "github.com/coinbase/kryptology/pkg/dkg/frost"
+	"github.com/coinbase/kryptology/pkg/signatures/schnorr/bip340"
+)
+
+func vkShares(participants map[uint32]*dkg.DkgParticipant) map[uint32]curves.Point {
+	shares := make(map[uint32]curves.Point, len(participants))
+	for id, p := range participants {
+		shares[id] = p.VkShare
+	}
+	return shares
+}
+
+// runRounds12 runs signing rounds 1 and 2 and returns their outputs
+func runRounds12(t *testing.T, signers map[uint32]*Signer, msg []byte) (map[uint32]*Round1Bcast, map[uint32]*Round2Bcast) {
+	commitments := make(map[uint32]*Round1Bcast, len(signers))
+	for id, s := range signers {
+		out, err := s.SignRound1()
+		require.NoError(t, err)
+		commitments[id] = out
+	}
+	shares := make(map[uint32]*Round2Bcast, len(signers))
+	for id, s := range signers {
+		out, err := s.SignRound2(msg, commitments)
+		require.NoError(t, err)
+		shares[id] = out
+	}
+	return commitments, shares
+}
+
+func newCiphersuiteSigners(t *testing.T, participants map[uint32]*dkg.DkgParticipant, threshold uint32, cosigners []uint32, suite Ciphersuite) map[uint32]*Signer {
+	signers := make(map[uint32]*Signer, len(cosigners))
+	for _, id := range cosigners {
+		s, err := NewCiphersuiteSigner(participants[id], id, threshold, cosigners, suite)
+		require.NoError(t, err)
+		signers[id] = s
+	}
+	return signers
+}
+
+func TestSignatureAggregatorWorks(t *testing.T) {
+	participants := runDkg(t, testCurve, 3, 5)
+	cosigners := []uint32{1, 3, 4}
+	signers := newCiphersuiteSigners(t, participants, 3, cosigners, Ed25519Sha512{})
+	aggregator, err := NewCiphersuiteAggregator(participants[1].VerificationKey, vkShares(participants), 3, Ed25519Sha512{})
+	require.NoError(t, err)
+
+	msg := []byte("aggregated message")
+	commitments, shares := runRounds12(t, signers, msg)
+	result, err := aggregator.Aggregate(msg, commitments, shares)
+	require.NoError(t, err)
+	ok, err := Verify(testCurve, Ed25519Sha512{}, participants[1].VerificationKey, msg, &Signature{result.Z, result.C})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// The signers agree with the aggregator
+	signerResult, err := signers[1].SignRound3(shares)
+	require.NoError(t, err)
+	require.Equal(t, 0, signerResult.Z.Cmp(result.Z))
+}
+
+func TestSignatureAggregatorDefaultSigners(t *testing.T) {
+	signer1, signer2 := PrepareNewSigners(t)
+	signers := map[uint32]*Signer{1: signer1, 2: signer2}
+	aggregator, err := NewSignatureAggregator(testCurve, signer1.verificationKey,
+		map[uint32]curves.Point{1: signer1.vkShare, 2: signer2.vkShare}, 2, Ed25519ChallengeDeriver{})
+	require.NoError(t, err)
+
+	msg := []byte("aggregated message")
+	commitments, shares := runRounds12(t, signers, msg)
+	result, err := aggregator.Aggregate(msg, commitments, shares)
+	require.NoError(t, err)
+	ok, err := Verify(testCurve, Ed25519ChallengeDeriver{}, signer1.verificationKey, msg, &Signature{result.Z, result.C})
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestSignatureAggregatorTaproot(t *testing.T) {
+	merkleRoot := make([]byte, 32)
+	merkleRoot[31] = 0x07
+	for i := 0; i < 4; i++ {
+		participants := runDkg(t, curves.K256(), 2, 3)
+		cosigners := []uint32{2, 3}
+		signers := newBip340Signers(t, participants, 2, cosigners, merkleRoot, true)
+		aggregator, err := NewTaprootAggregator(participants[1].VerificationKey, vkShares(participants), 2, merkleRoot)
+		require.NoError(t, err)
+
+		msg := []byte("taproot aggregated message")
+		commitments, shares := runRounds12(t, signers, msg)
+		result, err := aggregator.Aggregate(msg, commitments, shares)
+		require.NoError(t, err)
+		sig, err := result.Bip340Signature()
+		require.NoError(t, err)
+		outputKey, err := TaprootOutputKey(participants[1].VerificationKey, merkleRoot)
+		require.NoError(t, err)
+		require.NoError(t, bip340.Verify(outputKey, msg, sig))
+	}
+}
+
+func TestSignatureAggregatorIdentifiesCheaters(t *testing.T) {
+	participants := runDkg(t, curves.K256(), 3, 5)
+	cosigners := []uint32{1, 2, 5}
+	signers := newCiphersuiteSigners(t, participants, 3, cosigners, Secp256k1Sha256{})
+	aggregator, err := NewCiphersuiteAggregator(participants[1].VerificationKey, vkShares(participants), 3, Secp256k1Sha256{})
+	require.NoError(t, err)
+
+	msg := []byte("aggregated message")
+	commitments, shares := runRounds12(t, signers, msg)
+
+	// Participants 2 and 5 send bad responses, 5 also lies about its verification share
+	shares[2] = &Round2Bcast{shares[2].Zi.Add(curves.K256().Scalar.One()), shares[2].Vki}
+	fakeSk := curves.K256().Scalar.Random(crand.Reader)
+	shares[5] = &Round2Bcast{fakeSk, curves.K256().ScalarBaseMult(fakeSk)}
+
+	_, err = aggregator.Aggregate(msg, commitments, shares)
+	require.Error(t, err)
+	var invalid *InvalidSharesError
+	require.True(t, errors.As(err, &invalid))
+	require.Equal(t, []uint32{2, 5}, invalid.Culprits)
+
+	// A missing share and a share from outside the session are blamed as well
+	delete(shares, 2)
+	shares[5] = &Round2Bcast{fakeSk, nil}
+	shares[4] = &Round2Bcast{fakeSk, nil}
+	_, err = aggregator.Aggregate(msg, commitments, shares)
+	require.True(t, errors.As(err, &invalid))
+	require.Equal(t, []uint32{2, 4, 5}, invalid.Culprits)
+
+	// Malformed commitments are blamed
+	commitments[1] = &Round1Bcast{curves.K256().NewIdentityPoint(), commitments[1].Ei}
+	_, err = aggregator.Aggregate(msg, commitments, shares)
+	require.True(t, errors.As(err, &invalid))
+	require.Equal(t, []uint32{1}, invalid.Culprits)
+}
+
+func TestSignatureAggregatorBadInput(t *testing.T) {
+	participants := runDkg(t, testCurve, 2, 3)
+	_, err := NewSignatureAggregator(nil, participants[1].VerificationKey, vkShares(participants), 2, Ed25519ChallengeDeriver{})
+	require.Error(t, err)
+	_, err = NewSignatureAggregator(testCurve, participants[1].VerificationKey, vkShares(participants), 4, Ed25519ChallengeDeriver{})
+	require.Error(t, err)
+	_, err = NewBip340Aggregator(participants[1].VerificationKey, vkShares(participants), 2)
+	require.Error(t, err)
+
+	aggregator, err := NewSignatureAggregator(testCurve, participants[1].VerificationKey, vkShares(participants), 2, Ed25519ChallengeDeriver{})
+	require.NoError(t, err)
+	_, err = aggregator.Aggregate(nil, nil, nil)
+	require.Error(t, err)
+	_, err = aggregator.Aggregate([]byte("msg"), map[uint32]*Round1Bcast{1: nil, 9: nil}, nil)
+	require.Error(t, err)
+}
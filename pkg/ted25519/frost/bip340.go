@@ -0,0 +1,147 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"fmt"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/dkg/frost"
+	"github.com/coinbase/kryptology/pkg/signatures/schnorr/bip340"
+)
+
+const tagTapTweak = "TapTweak"
+
+// NewBip340Signer creates a signer whose signatures verify under BIP-340 for the x-only
+// group verification key. The DKG output must be over secp256k1. If the group key has
+// an odd Y coordinate, the signing share, verification share and group key are negated locally
+// so that every cosigner consistently signs for the point with even Y.
+func NewBip340Signer(info *frost.DkgParticipant, id, thresh uint32, lcoeffs map[uint32]curves.Scalar, cosigners []uint32) (*Signer, error) {
+	signer, err := newBip340Signer(info, id, thresh, lcoeffs, cosigners)
+	if err != nil {
+		return nil, err
+	}
+	signer.normalizeKeys()
+	return signer, nil
+}
+
+// NewTaprootSigner creates a signer whose signatures verify under BIP-340 for the taproot output key
+// Q = P + hash_TapTweak(bytes(P) || merkleRoot)⋅G as described in BIP-341, where P is the x-only group
+// verification key. merkleRoot is empty for outputs without a script path.
+func NewTaprootSigner(info *frost.DkgParticipant, id, thresh uint32, lcoeffs map[uint32]curves.Scalar, cosigners []uint32, merkleRoot []byte) (*Signer, error) {
+	signer, err := newBip340Signer(info, id, thresh, lcoeffs, cosigners)
+	if err != nil {
+		return nil, err
+	}
+	signer.normalizeKeys()
+	t, err := TaprootTweak(signer.verificationKey, merkleRoot)
+	if err != nil {
+		return nil, err
+	}
+	// The Lagrange coefficients of the signing set sum to one,
+	// so adding t to every share adds t to the group secret.
+	tG := signer.curve.ScalarBaseMult(t)
+	signer.skShare = signer.skShare.Add(t)
+	signer.vkShare = signer.vkShare.Add(tG)
+	signer.verificationKey = signer.verificationKey.Add(tG)
+	if signer.verificationKey.IsIdentity() {
+		return nil, fmt.Errorf("invalid taproot tweak")
+	}
+	signer.normalizeKeys()
+	return signer, nil
+}
+
+func newBip340Signer(info *frost.DkgParticipant, id, thresh uint32, lcoeffs map[uint32]curves.Scalar, cosigners []uint32) (*Signer, error) {
+	signer, err := NewSigner(info, id, thresh, lcoeffs, cosigners, Bip340ChallengeDeriver{})
+	if err != nil {
+		return nil, err
+	}
+	if signer.curve.Name != curves.K256Name {
+		return nil, fmt.Errorf("bip340 signing requires secp256k1")
+	}
+	if signer.skShare == nil || signer.vkShare == nil || signer.verificationKey == nil {
+		return nil, fmt.Errorf("dkg has not completed")
+	}
+	signer.bip340 = true
+	return signer, nil
+}
+
+// negateNonce reports whether the nonces must be negated because of the aggregated
+// nonce commitment R. In BIP-340 mode this is the case when R has an odd Y coordinate,
+// which makes the effective nonce commitment -R have an even one.
+// Ciphersuite signers never negate as RFC 9591 uses R as is.
+func (signer *Signer) negateNonce(R curves.Point) bool {
+	return negateNonce(signer.suite, signer.bip340, R)
+}
+
+func negateNonce(suite Ciphersuite, bip340Mode bool, R curves.Point) bool {
+	if suite != nil {
+		return false
+	}
+	if bip340Mode {
+		return !bip340.HasEvenY(R)
+	}
+	return R.IsNegative()
+}
+
+// normalizeKeys negates this signer's key material when the group key has an odd Y coordinate
+func (signer *Signer) normalizeKeys() {
+	if bip340.HasEvenY(signer.verificationKey) {
+		return
+	}
+	signer.skShare = signer.skShare.Neg()
+	signer.vkShare = signer.vkShare.Neg()
+	signer.verificationKey = signer.verificationKey.Neg()
+}
+
+// TaprootTweak computes t = int(hash_TapTweak(bytes(P) || merkleRoot)) for the x-only encoding of pubKey
+func TaprootTweak(pubKey curves.Point, merkleRoot []byte) (curves.Scalar, error) {
+	if pubKey == nil || pubKey.IsIdentity() {
+		return nil, fmt.Errorf("invalid public key")
+	}
+	if _, ok := pubKey.(*curves.PointK256); !ok {
+		return nil, fmt.Errorf("taproot tweaks require secp256k1 points")
+	}
+	if len(merkleRoot) != 0 && len(merkleRoot) != 32 {
+		return nil, fmt.Errorf("merkle root must be empty or 32 bytes")
+	}
+	h := bip340.TaggedHash(tagTapTweak, pubKey.ToAffineCompressed()[1:], merkleRoot)
+	// The tweak must be less than the group order, this happens with negligible probability
+	t, err := curves.K256().Scalar.SetBytes(h)
+	if err != nil {
+		return nil, fmt.Errorf("invalid taproot tweak")
+	}
+	return t, nil
+}
+
+// TaprootOutputKey computes the x-only BIP-341 output key for the group verification key
+// and merkleRoot. The result is what NewTaprootSigner signatures verify against.
+func TaprootOutputKey(verificationKey curves.Point, merkleRoot []byte) (*bip340.PublicKey, error) {
+	internalKey, err := bip340.NewPublicKey(verificationKey)
+	if err != nil {
+		return nil, err
+	}
+	t, err := TaprootTweak(internalKey.Point(), merkleRoot)
+	if err != nil {
+		return nil, err
+	}
+	return bip340.NewPublicKey(internalKey.Point().Add(curves.K256().ScalarBaseMult(t)))
+}
+
+// Bip340Signature converts the output of SignRound3 into a 64 byte BIP-340 signature.
+// The signers must have been created with NewBip340Signer or NewTaprootSigner.
+func (result *Round3Bcast) Bip340Signature() (*bip340.Signature, error) {
+	if result == nil || result.R == nil || result.Z == nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	if _, ok := result.R.(*curves.PointK256); !ok {
+		return nil, fmt.Errorf("bip340 signatures require secp256k1 points")
+	}
+	sig := &bip340.Signature{S: result.Z}
+	copy(sig.R[:], result.R.ToAffineCompressed()[1:])
+	return sig, nil
+}
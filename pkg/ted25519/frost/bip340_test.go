@@ -0,0 +1,177 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	dkg "github.com/coinbase/kryptology/pkg/dkg/frost"
+	"github.com/coinbase/kryptology/pkg/sharing"
+	"github.com/coinbase/kryptology/pkg/signatures/schnorr/bip340"
+)
+
+// runDkg runs the FROST DKG among limit participants and returns them indexed by id
+func runDkg(t *testing.T, curve *curves.Curve, threshold, limit uint32) map[uint32]*dkg.DkgParticipant {
+	participants := make(map[uint32]*dkg.DkgParticipant, limit)
+	for i := uint32(1); i <= limit; i++ {
+		var others []uint32
+		for j := uint32(1); j <= limit; j++ {
+			if i != j {
+				others = append(others, j)
+			}
+		}
+		p, err := dkg.NewDkgParticipant(i, threshold, ctx, curve, others...)
+		require.NoError(t, err)
+		participants[i] = p
+	}
+	bcast := make(map[uint32]*dkg.Round1Bcast, limit)
+	p2p := make(map[uint32]map[uint32]*sharing.ShamirShare, limit)
+	for id, p := range participants {
+		b, send, err := p.Round1(nil)
+		require.NoError(t, err)
+		bcast[id] = b
+		for to, share := range send {
+			if p2p[to] == nil {
+				p2p[to] = make(map[uint32]*sharing.ShamirShare)
+			}
+			p2p[to][id] = share
+		}
+	}
+	for id, p := range participants {
+		_, err := p.Round2(bcast, p2p[id])
+		require.NoError(t, err)
+	}
+	return participants
+}
+
+// runSigning runs all three FROST signing rounds and returns the output of the first signer
+func runSigning(t *testing.T, signers map[uint32]*Signer, msg []byte) *Round3Bcast {
+	round2Input := make(map[uint32]*Round1Bcast, len(signers))
+	for id, s := range signers {
+		out, err := s.SignRound1()
+		require.NoError(t, err)
+		round2Input[id] = out
+	}
+	round3Input := make(map[uint32]*Round2Bcast, len(signers))
+	for id, s := range signers {
+		out, err := s.SignRound2(msg, round2Input)
+		require.NoError(t, err)
+		round3Input[id] = out
+	}
+	var result *Round3Bcast
+	for _, s := range signers {
+		out, err := s.SignRound3(round3Input)
+		require.NoError(t, err)
+		result = out
+	}
+	return result
+}
+
+func newBip340Signers(t *testing.T, participants map[uint32]*dkg.DkgParticipant, threshold uint32, cosigners []uint32, merkleRoot []byte, taproot bool) map[uint32]*Signer {
+	scheme, err := sharing.NewShamir(threshold, uint32(len(participants)), curves.K256())
+	require.NoError(t, err)
+	lCoeffs, err := scheme.LagrangeCoeffs(cosigners)
+	require.NoError(t, err)
+	signers := make(map[uint32]*Signer, len(cosigners))
+	for _, id := range cosigners {
+		var s *Signer
+		if taproot {
+			s, err = NewTaprootSigner(participants[id], id, threshold, lCoeffs, cosigners, merkleRoot)
+		} else {
+			s, err = NewBip340Signer(participants[id], id, threshold, lCoeffs, cosigners)
+		}
+		require.NoError(t, err)
+		signers[id] = s
+	}
+	return signers
+}
+
+func TestBip340SigningVerifies(t *testing.T) {
+	// Run several times so both parities of the group key and nonce are exercised
+	for i := 0; i < 8; i++ {
+		participants := runDkg(t, curves.K256(), 2, 3)
+		signers := newBip340Signers(t, participants, 2, []uint32{1, 3}, nil, false)
+		msg := []byte(fmt.Sprintf("bip340 message %d", i))
+		result := runSigning(t, signers, msg)
+
+		sig, err := result.Bip340Signature()
+		require.NoError(t, err)
+		pk, err := bip340.NewPublicKey(participants[1].VerificationKey)
+		require.NoError(t, err)
+		require.NoError(t, bip340.Verify(pk, msg, sig))
+
+		// The signature survives serialization as 64 bytes
+		data, err := sig.MarshalBinary()
+		require.NoError(t, err)
+		sig2 := new(bip340.Signature)
+		require.NoError(t, sig2.UnmarshalBinary(data))
+		pkBytes, err := pk.MarshalBinary()
+		require.NoError(t, err)
+		pk2 := new(bip340.PublicKey)
+		require.NoError(t, pk2.UnmarshalBinary(pkBytes))
+		require.NoError(t, bip340.Verify(pk2, msg, sig2))
+		require.Error(t, bip340.Verify(pk2, []byte("other"), sig2))
+	}
+}
+
+func TestTaprootSigningVerifies(t *testing.T) {
+	merkleRoot := make([]byte, 32)
+	merkleRoot[0] = 0x42
+	for i := 0; i < 8; i++ {
+		participants := runDkg(t, curves.K256(), 3, 5)
+		for _, root := range [][]byte{nil, merkleRoot} {
+			signers := newBip340Signers(t, participants, 3, []uint32{2, 4, 5}, root, true)
+			msg := []byte(fmt.Sprintf("taproot message %d", i))
+			result := runSigning(t, signers, msg)
+
+			sig, err := result.Bip340Signature()
+			require.NoError(t, err)
+			outputKey, err := TaprootOutputKey(participants[1].VerificationKey, root)
+			require.NoError(t, err)
+			require.NoError(t, bip340.Verify(outputKey, msg, sig))
+
+			// The untweaked key must not verify the signature
+			internalKey, err := bip340.NewPublicKey(participants[1].VerificationKey)
+			require.NoError(t, err)
+			require.Error(t, bip340.Verify(internalKey, msg, sig))
+		}
+	}
+}
+
+func TestTaprootOutputKeyVector(t *testing.T) {
+	// https://github.com/bitcoin/bips/blob/master/bip-0341/wallet-test-vectors.json
+	internalKey, _ := hex.DecodeString("d6889cb081036e0faefa3a35157ad71086b123b2b144b649798b494c300a961d")
+	expectedTweak, _ := hex.DecodeString("b86e7be8f39bab32a6f2c0443abbc210f0edac0e2c53d501b36b64437d9c6c70")
+	expectedOutput, _ := hex.DecodeString("53a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343")
+	pk := new(bip340.PublicKey)
+	require.NoError(t, pk.UnmarshalBinary(internalKey))
+	tweak, err := TaprootTweak(pk.Point(), nil)
+	require.NoError(t, err)
+	require.Equal(t, expectedTweak, tweak.Bytes())
+	outputKey, err := TaprootOutputKey(pk.Point(), nil)
+	require.NoError(t, err)
+	require.Equal(t, expectedOutput, outputKey.Bytes())
+}
+
+func TestBip340SignerInvalidCurve(t *testing.T) {
+	participants := runDkg(t, curves.ED25519(), 2, 2)
+	scheme, err := sharing.NewShamir(2, 2, curves.ED25519())
+	require.NoError(t, err)
+	lCoeffs, err := scheme.LagrangeCoeffs([]uint32{1, 2})
+	require.NoError(t, err)
+	_, err = NewBip340Signer(participants[1], 1, 2, lCoeffs, []uint32{1, 2})
+	require.Error(t, err)
+	_, err = NewTaprootSigner(participants[1], 1, 2, lCoeffs, []uint32{1, 2}, nil)
+	require.Error(t, err)
+	_, err = TaprootTweak(participants[1].VerificationKey, nil)
+	require.Error(t, err)
+}
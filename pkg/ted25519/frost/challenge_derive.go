@@ -8,8 +8,10 @@ package frost
 
 import (
 	"crypto/sha512"
+	"fmt"
 
 	"github.com/coinbase/kryptology/pkg/core/curves"
+	"github.com/coinbase/kryptology/pkg/signatures/schnorr/bip340"
 )
 
 type ChallengeDerive interface {
@@ -25,3 +27,17 @@ func (ed Ed25519ChallengeDeriver) DeriveChallenge(msg []byte, pubKey curves.Poin
 	_, _ = h.Write(msg)
 	return new(curves.ScalarEd25519).SetBytesWide(h.Sum(nil))
 }
+
+// Bip340ChallengeDeriver computes the BIP-340 challenge over secp256k1 using only
+// the x coordinates of the public key and nonce commitment.
+type Bip340ChallengeDeriver struct{}
+
+func (b Bip340ChallengeDeriver) DeriveChallenge(msg []byte, pubKey curves.Point, r curves.Point) (curves.Scalar, error) {
+	if _, ok := pubKey.(*curves.PointK256); !ok {
+		return nil, fmt.Errorf("bip340 challenges require secp256k1 points")
+	}
+	if _, ok := r.(*curves.PointK256); !ok {
+		return nil, fmt.Errorf("bip340 challenges require secp256k1 points")
+	}
+	return bip340.Challenge(r.ToAffineCompressed()[1:], pubKey.ToAffineCompressed()[1:], msg), nil
+}
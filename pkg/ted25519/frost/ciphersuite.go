@@ -0,0 +1,242 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/coinbase/kryptology/pkg/core"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+const (
+	ed25519Sha512Context   = "FROST-ED25519-SHA512-v1"
+	secp256k1Sha256Context = "FROST-secp256k1-SHA256-v1"
+	p256Sha256Context      = "FROST-P256-SHA256-v1"
+
+	// hashToFieldLen is L = ceil((ceil(log2(p)) + k) / 8) for 256-bit primes with k = 128
+	hashToFieldLen = 48
+	// nonceRandomnessLen is the number of random bytes used to generate each nonce
+	nonceRandomnessLen = 32
+)
+
+// Ciphersuite is a FROST ciphersuite as defined in section 6 of RFC 9591
+// https://datatracker.ietf.org/doc/html/rfc9591#section-6
+// Elements are serialized with ToAffineCompressed and scalars with Bytes,
+// which matches the encodings required by every ciphersuite in this package.
+type Ciphersuite interface {
+	// ChallengeDerive computes H2(SerializeElement(R) || SerializeElement(PK) || msg)
+	ChallengeDerive
+	// Curve returns the prime order group of the ciphersuite
+	Curve() *curves.Curve
+	// H1 derives the binding factors
+	H1(m []byte) (curves.Scalar, error)
+	// H2 derives the challenge
+	H2(m []byte) (curves.Scalar, error)
+	// H3 derives the nonces
+	H3(m []byte) (curves.Scalar, error)
+	// H4 hashes the message
+	H4(m []byte) []byte
+	// H5 hashes the encoded commitment list
+	H5(m []byte) []byte
+}
+
+// Ed25519Sha512 is the FROST(Ed25519, SHA-512) ciphersuite. Its signatures verify under RFC 8032.
+type Ed25519Sha512 struct{}
+
+func (Ed25519Sha512) Curve() *curves.Curve {
+	return curves.ED25519()
+}
+
+func (Ed25519Sha512) H1(m []byte) (curves.Scalar, error) {
+	return ed25519HashToScalar([]byte(ed25519Sha512Context+"rho"), m)
+}
+
+// H2 omits the context string for compatibility with RFC 8032
+func (Ed25519Sha512) H2(m []byte) (curves.Scalar, error) {
+	return ed25519HashToScalar(nil, m)
+}
+
+func (Ed25519Sha512) H3(m []byte) (curves.Scalar, error) {
+	return ed25519HashToScalar([]byte(ed25519Sha512Context+"nonce"), m)
+}
+
+func (Ed25519Sha512) H4(m []byte) []byte {
+	return hashWithPrefix(sha512.New(), []byte(ed25519Sha512Context+"msg"), m)
+}
+
+func (Ed25519Sha512) H5(m []byte) []byte {
+	return hashWithPrefix(sha512.New(), []byte(ed25519Sha512Context+"com"), m)
+}
+
+func (suite Ed25519Sha512) DeriveChallenge(msg []byte, pubKey curves.Point, r curves.Point) (curves.Scalar, error) {
+	return deriveChallenge(suite, msg, pubKey, r)
+}
+
+// Secp256k1Sha256 is the FROST(secp256k1, SHA-256) ciphersuite
+type Secp256k1Sha256 struct{}
+
+func (Secp256k1Sha256) Curve() *curves.Curve {
+	return curves.K256()
+}
+
+func (suite Secp256k1Sha256) H1(m []byte) (curves.Scalar, error) {
+	return hashToField(suite.Curve(), []byte(secp256k1Sha256Context+"rho"), m)
+}
+
+func (suite Secp256k1Sha256) H2(m []byte) (curves.Scalar, error) {
+	return hashToField(suite.Curve(), []byte(secp256k1Sha256Context+"chal"), m)
+}
+
+func (suite Secp256k1Sha256) H3(m []byte) (curves.Scalar, error) {
+	return hashToField(suite.Curve(), []byte(secp256k1Sha256Context+"nonce"), m)
+}
+
+func (Secp256k1Sha256) H4(m []byte) []byte {
+	return hashWithPrefix(sha256.New(), []byte(secp256k1Sha256Context+"msg"), m)
+}
+
+func (Secp256k1Sha256) H5(m []byte) []byte {
+	return hashWithPrefix(sha256.New(), []byte(secp256k1Sha256Context+"com"), m)
+}
+
+func (suite Secp256k1Sha256) DeriveChallenge(msg []byte, pubKey curves.Point, r curves.Point) (curves.Scalar, error) {
+	return deriveChallenge(suite, msg, pubKey, r)
+}
+
+// P256Sha256 is the FROST(P-256, SHA-256) ciphersuite
+type P256Sha256 struct{}
+
+func (P256Sha256) Curve() *curves.Curve {
+	return curves.P256()
+}
+
+func (suite P256Sha256) H1(m []byte) (curves.Scalar, error) {
+	return hashToField(suite.Curve(), []byte(p256Sha256Context+"rho"), m)
+}
+
+func (suite P256Sha256) H2(m []byte) (curves.Scalar, error) {
+	return hashToField(suite.Curve(), []byte(p256Sha256Context+"chal"), m)
+}
+
+func (suite P256Sha256) H3(m []byte) (curves.Scalar, error) {
+	return hashToField(suite.Curve(), []byte(p256Sha256Context+"nonce"), m)
+}
+
+func (P256Sha256) H4(m []byte) []byte {
+	return hashWithPrefix(sha256.New(), []byte(p256Sha256Context+"msg"), m)
+}
+
+func (P256Sha256) H5(m []byte) []byte {
+	return hashWithPrefix(sha256.New(), []byte(p256Sha256Context+"com"), m)
+}
+
+func (suite P256Sha256) DeriveChallenge(msg []byte, pubKey curves.Point, r curves.Point) (curves.Scalar, error) {
+	return deriveChallenge(suite, msg, pubKey, r)
+}
+
+// deriveChallenge implements compute_challenge from section 4.6 of RFC 9591
+func deriveChallenge(suite Ciphersuite, msg []byte, pubKey curves.Point, r curves.Point) (curves.Scalar, error) {
+	if pubKey == nil || r == nil {
+		return nil, fmt.Errorf("invalid challenge input")
+	}
+	var input []byte
+	input = append(input, r.ToAffineCompressed()...)
+	input = append(input, pubKey.ToAffineCompressed()...)
+	input = append(input, msg...)
+	return suite.H2(input)
+}
+
+// nonceGenerate implements nonce_generate from section 4.1 of RFC 9591
+// using randomness read from reader
+func nonceGenerate(suite Ciphersuite, secret curves.Scalar, reader io.Reader) (curves.Scalar, error) {
+	var random [nonceRandomnessLen]byte
+	if _, err := io.ReadFull(reader, random[:]); err != nil {
+		return nil, err
+	}
+	return suite.H3(append(random[:], secret.Bytes()...))
+}
+
+// encodeGroupCommitmentList implements encode_group_commitment_list from section 4.3 of RFC 9591.
+// The list is sorted in ascending order by identifier.
+func encodeGroupCommitmentList(curve *curves.Curve, ids []uint32, commitments map[uint32]*Round1Bcast) []byte {
+	var out []byte
+	for _, id := range ids {
+		out = append(out, curve.Scalar.New(int(id)).Bytes()...)
+		out = append(out, commitments[id].Di.ToAffineCompressed()...)
+		out = append(out, commitments[id].Ei.ToAffineCompressed()...)
+	}
+	return out
+}
+
+// computeBindingFactors implements compute_binding_factors from section 4.4 of RFC 9591
+func computeBindingFactors(suite Ciphersuite, groupPublicKey curves.Point, commitments map[uint32]*Round1Bcast, msg []byte) (map[uint32]curves.Scalar, error) {
+	curve := suite.Curve()
+	ids := sortedIds(commitments)
+	var prefix []byte
+	prefix = append(prefix, groupPublicKey.ToAffineCompressed()...)
+	prefix = append(prefix, suite.H4(msg)...)
+	prefix = append(prefix, suite.H5(encodeGroupCommitmentList(curve, ids, commitments))...)
+
+	factors := make(map[uint32]curves.Scalar, len(ids))
+	for _, id := range ids {
+		input := make([]byte, 0, len(prefix)+len(curve.Scalar.Bytes()))
+		input = append(input, prefix...)
+		input = append(input, curve.Scalar.New(int(id)).Bytes()...)
+		rho, err := suite.H1(input)
+		if err != nil {
+			return nil, err
+		}
+		factors[id] = rho
+	}
+	return factors, nil
+}
+
+// SerializeSignature encodes the signature as SerializeElement(R) || SerializeScalar(z)
+// as described in section 6 of RFC 9591
+func (result *Round3Bcast) SerializeSignature() ([]byte, error) {
+	if result == nil || result.R == nil || result.Z == nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	return append(result.R.ToAffineCompressed(), result.Z.Bytes()...), nil
+}
+
+func sortedIds(commitments map[uint32]*Round1Bcast) []uint32 {
+	ids := make([]uint32, 0, len(commitments))
+	for id := range commitments {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func hashWithPrefix(h hash.Hash, prefix, m []byte) []byte {
+	_, _ = h.Write(prefix)
+	_, _ = h.Write(m)
+	return h.Sum(nil)
+}
+
+// ed25519HashToScalar interprets SHA-512(prefix || m) as a little-endian integer reduced modulo L
+func ed25519HashToScalar(prefix, m []byte) (curves.Scalar, error) {
+	return curves.ED25519().Scalar.SetBytesWide(hashWithPrefix(sha512.New(), prefix, m))
+}
+
+// hashToField implements hash_to_field from RFC 9380 with expand_message_xmd using SHA-256
+// and the group order as the modulus
+func hashToField(curve *curves.Curve, dst, m []byte) (curves.Scalar, error) {
+	uniform, err := core.ExpandMessageXmd(sha256.New, m, dst, hashToFieldLen)
+	if err != nil {
+		return nil, err
+	}
+	return curve.Scalar.SetBigInt(new(big.Int).SetBytes(uniform))
+}
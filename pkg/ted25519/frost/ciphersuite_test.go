@@ -0,0 +1,249 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coinbase/kryptology/pkg/core/curves"
+	dkg "github.com/coinbase/kryptology/pkg/dkg/frost"
+)
+
+type ciphersuiteParticipantVector struct {
+	id                uint32
+	share             string
+	hidingRandomness  string
+	bindingRandomness string
+	hidingNonce       string
+	bindingNonce      string
+	hidingCommitment  string
+	bindingCommitment string
+	bindingFactor     string
+	sigShare          string
+}
+
+type ciphersuiteVector struct {
+	suite          Ciphersuite
+	groupPublicKey string
+	message        string
+	participants   []ciphersuiteParticipantVector
+	signature      string
+}
+
+// Test vectors from appendix E of RFC 9591
+var ciphersuiteVectors = map[string]ciphersuiteVector{
+	"FROST(Ed25519, SHA-512)": {
+		suite:          Ed25519Sha512{},
+		groupPublicKey: "15d21ccd7ee42959562fc8aa63224c8851fb3ec85a3faf66040d380fb9738673",
+		message:        "74657374",
+		participants: []ciphersuiteParticipantVector{
+			{
+				id:                1,
+				share:             "929dcc590407aae7d388761cddb0c0db6f5627aea8e217f4a033f2ec83d93509",
+				hidingRandomness:  "0fd2e39e111cdc266f6c0f4d0fd45c947761f1f5d3cb583dfcb9bbaf8d4c9fec",
+				bindingRandomness: "69cd85f631d5f7f2721ed5e40519b1366f340a87c2f6856363dbdcda348a7501",
+				hidingNonce:       "812d6104142944d5a55924de6d49940956206909f2acaeedecda2b726e630407",
+				bindingNonce:      "b1110165fc2334149750b28dd813a39244f315cff14d4e89e6142f262ed83301",
+				hidingCommitment:  "b5aa8ab305882a6fc69cbee9327e5a45e54c08af61ae77cb8207be3d2ce13de3",
+				bindingCommitment: "67e98ab55aa310c3120418e5050c9cf76cf387cb20ac9e4b6fdb6f82a469f932",
+				bindingFactor:     "f2cb9d7dd9beff688da6fcc83fa89046b3479417f47f55600b106760eb3b5603",
+				sigShare:          "001719ab5a53ee1a12095cd088fd149702c0720ce5fd2f29dbecf24b7281b603",
+			},
+			{
+				id:                3,
+				share:             "d3cb090a075eb154e82fdb4b3cb507f110040905468bb9c46da8bdea643a9a02",
+				hidingRandomness:  "86d64a260059e495d0fb4fcc17ea3da7452391baa494d4b00321098ed2a0062f",
+				bindingRandomness: "13e6b25afb2eba51716a9a7d44130c0dbae0004a9ef8d7b5550c8a0e07c61775",
+				hidingNonce:       "c256de65476204095ebdc01bd11dc10e57b36bc96284595b8215222374f99c0e",
+				bindingNonce:      "243d71944d929063bc51205714ae3c2218bd3451d0214dfb5aeec2a90c35180d",
+				hidingCommitment:  "cfbdb165bd8aad6eb79deb8d287bcc0ab6658ae57fdcc98ed12c0669e90aec91",
+				bindingCommitment: "7487bc41a6e712eea2f2af24681b58b1cf1da278ea11fe4e8b78398965f13552",
+				bindingFactor:     "b087686bf35a13f3dc78e780a34b0fe8a77fef1b9938c563f5573d71d8d7890f",
+				sigShare:          "bd86125de990acc5e1f13781d8e32c03a9bbd4c53539bbc106058bfd14326007",
+			},
+		},
+		signature: "36282629c383bb820a88b71cae937d41f2f2adfcc3d02e55507e2fb9e2dd3cbebd9d2b0844e49ae0f3fa935161e1419aab7b47d21a37ebeae1f17d4987b3160b",
+	},
+	"FROST(secp256k1, SHA-256)": {
+		suite:          Secp256k1Sha256{},
+		groupPublicKey: "02f37c34b66ced1fb51c34a90bdae006901f10625cc06c4f64663b0eae87d87b4f",
+		message:        "74657374",
+		participants: []ciphersuiteParticipantVector{
+			{
+				id:                1,
+				share:             "08f89ffe80ac94dcb920c26f3f46140bfc7f95b493f8310f5fc1ea2b01f4254c",
+				hidingRandomness:  "7ea5ed09af19f6ff21040c07ec2d2adbd35b759da5a401d4c99dd26b82391cb2",
+				bindingRandomness: "47acab018f116020c10cb9b9abdc7ac10aae1b48ca6e36dc15acb6ec9be5cdc5",
+				hidingNonce:       "841d3a6450d7580b4da83c8e618414d0f024391f2aeb511d7579224420aa81f0",
+				bindingNonce:      "8d2624f532af631377f33cf44b5ac5f849067cae2eacb88680a31e77c79b5a80",
+				hidingCommitment:  "03c699af97d26bb4d3f05232ec5e1938c12f1e6ae97643c8f8f11c9820303f1904",
+				bindingCommitment: "02fa2aaccd51b948c9dc1a325d77226e98a5a3fe65fe9ba213761a60123040a45e",
+				bindingFactor:     "3e08fe561e075c653cbfd46908a10e7637c70c74f0a77d5fd45d1a750c739ec6",
+				sigShare:          "c4fce1775a1e141fb579944166eab0d65eefe7b98d480a569bbbfcb14f91c197",
+			},
+			{
+				id:                3,
+				share:             "00e95d59dd0d46b0e303e500b62b7ccb0e555d49f5b849f5e748c071da8c0dbc",
+				hidingRandomness:  "e6cc56ccbd0502b3f6f831d91e2ebd01c4de0479e0191b66895a4ffd9b68d544",
+				bindingRandomness: "7203d55eb82a5ca0d7d83674541ab55f6e76f1b85391d2c13706a89a064fd5b9",
+				hidingNonce:       "2b19b13f193f4ce83a399362a90cdc1e0ddcd83e57089a7af0bdca71d47869b2",
+				bindingNonce:      "7a443bde83dc63ef52dda354005225ba0e553243402a4705ce28ffaafe0f5b98",
+				hidingCommitment:  "03077507ba327fc074d2793955ef3410ee3f03b82b4cdc2370f71d865beb926ef6",
+				bindingCommitment: "02ad53031ddfbbacfc5fbda3d3b0c2445c8e3e99cbc4ca2db2aa283fa68525b135",
+				bindingFactor:     "93f79041bb3fd266105be251adaeb5fd7f8b104fb554a4ba9a0becea48ddbfd7",
+				sigShare:          "0160fd0d388932f4826d2ebcd6b9eaba734f7c71cf25b4279a4ca2581e47b18d",
+			},
+		},
+		signature: "0205b6d04d3774c8929413e3c76024d54149c372d57aae62574ed74319b5ea14d0c65dde8492a7471437e6c2fe3da49b90d23f642b5c6dbe7e36089f096dd97324",
+	},
+	"FROST(P-256, SHA-256)": {
+		suite:          P256Sha256{},
+		groupPublicKey: "023a309ad94e9fe8a7ba45dfc58f38bf091959d3c99cfbd02b4dc00585ec45ab70",
+		message:        "74657374",
+		participants: []ciphersuiteParticipantVector{
+			{
+				id:                1,
+				share:             "0c9c1a0fe806c184add50bbdcac913dda73e482daf95dcb9f35dbb0d8a9f7731",
+				hidingRandomness:  "ec4c891c85fee802a9d757a67d1252e7f4e5efb8a538991ac18fbd0e06fb6fd3",
+				bindingRandomness: "9334e29d09061223f69a09421715a347e4e6deba77444c8f42b0c833f80f4ef9",
+				hidingNonce:       "9f0542a5ba879a58f255c09f06da7102ef6a2dec6279700c656d58394d8facd4",
+				bindingNonce:      "6513dfe7429aa2fc972c69bb495b27118c45bbc6e654bb9dc9be55385b55c0d7",
+				hidingCommitment:  "0213b3e6298bf8ad46fd5e9389519a8665d63d98f4ec6a1fcca434e809d2d8070e",
+				bindingCommitment: "02188ff1390bf69374d7b272e454b1878ef10a6b6ea3ff36f114b300b4dbd5233b",
+				bindingFactor:     "7925f0d4693f204e6e59233e92227c7124664a99739d2c06b81cf64ddf90559e",
+				sigShare:          "400308eaed7a2ddee02a265abe6a1cfe04d946ee8720768899619cfabe7a3aeb",
+			},
+			{
+				id:                3,
+				share:             "0e80d6e8f6192c003b5488ce1eec8f5429587d48cf001541e713b2d53c09d928",
+				hidingRandomness:  "c0451c5a0a5480d6c1f860e5db7d655233dca2669fd90ff048454b8ce983367b",
+				bindingRandomness: "2ba5f7793ae700e40e78937a82f407dd35e847e33d1e607b5c7eb6ed2a8ed799",
+				hidingNonce:       "f73444a8972bcda9e506bbca3d2b1c083c10facdf4bb5d47fef7c2dc1d9f2a0d",
+				bindingNonce:      "44c6a29075d6e7e4f8b97796205f9e22062e7835141470afe9417fd317c1c303",
+				hidingCommitment:  "033ac9a5fe4a8b57316ba1c34e8a6de453033b750e8984924a984eb67a11e73a3f",
+				bindingCommitment: "03a7a2480ee16199262e648aea3acab628a53e9b8c1945078f2ddfbdc98b7df369",
+				bindingFactor:     "e10d24a8a403723bcb6f9bb4c537f316593683b472f7a89f166630dde11822c4",
+				sigShare:          "561da3c179edbb0502d941bb3e3ace3c37d122aaa46fb54499f15f3a3331de44",
+			},
+		},
+		signature: "026d8d434874f87bdb7bc0dfd239b2c00639044f9dcb195e9a04426f70bfa4b70d9620acac6767e8e3e3036815fca4eb3a3caa69992b902bcd3352fc34f1ac192f",
+	},
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestCiphersuiteVectors(t *testing.T) {
+	for name, vector := range ciphersuiteVectors {
+		t.Run(name, func(t *testing.T) {
+			curve := vector.suite.Curve()
+			vk, err := curve.Point.FromAffineCompressed(decodeHex(t, vector.groupPublicKey))
+			require.NoError(t, err)
+			msg := decodeHex(t, vector.message)
+
+			cosigners := make([]uint32, len(vector.participants))
+			for i, p := range vector.participants {
+				cosigners[i] = p.id
+			}
+			threshold := uint32(len(cosigners))
+
+			signers := make(map[uint32]*Signer, len(cosigners))
+			round2Input := make(map[uint32]*Round1Bcast, len(cosigners))
+			for _, p := range vector.participants {
+				sk, err := curve.Scalar.SetBytes(decodeHex(t, p.share))
+				require.NoError(t, err)
+				info := &dkg.DkgParticipant{
+					Curve:           curve,
+					Id:              p.id,
+					SkShare:         sk,
+					VkShare:         curve.ScalarBaseMult(sk),
+					VerificationKey: vk,
+				}
+				signer, err := NewCiphersuiteSigner(info, p.id, threshold, cosigners, vector.suite)
+				require.NoError(t, err)
+				signers[p.id] = signer
+
+				randomness := append(decodeHex(t, p.hidingRandomness), decodeHex(t, p.bindingRandomness)...)
+				out, err := signer.signRound1(bytes.NewReader(randomness))
+				require.NoError(t, err)
+				require.Equal(t, decodeHex(t, p.hidingNonce), signer.state.smallD.Bytes())
+				require.Equal(t, decodeHex(t, p.bindingNonce), signer.state.smallE.Bytes())
+				require.Equal(t, decodeHex(t, p.hidingCommitment), out.Di.ToAffineCompressed())
+				require.Equal(t, decodeHex(t, p.bindingCommitment), out.Ei.ToAffineCompressed())
+				round2Input[p.id] = out
+			}
+
+			factors, err := computeBindingFactors(vector.suite, vk, round2Input, msg)
+			require.NoError(t, err)
+			for _, p := range vector.participants {
+				require.Equal(t, decodeHex(t, p.bindingFactor), factors[p.id].Bytes())
+			}
+
+			round3Input := make(map[uint32]*Round2Bcast, len(cosigners))
+			for _, p := range vector.participants {
+				out, err := signers[p.id].SignRound2(msg, round2Input)
+				require.NoError(t, err)
+				require.Equal(t, decodeHex(t, p.sigShare), out.Zi.Bytes())
+				round3Input[p.id] = out
+			}
+
+			for _, p := range vector.participants {
+				result, err := signers[p.id].SignRound3(round3Input)
+				require.NoError(t, err)
+				sig, err := result.SerializeSignature()
+				require.NoError(t, err)
+				require.Equal(t, decodeHex(t, vector.signature), sig)
+				ok, err := Verify(curve, vector.suite, vk, msg, &Signature{Z: result.Z, C: result.C})
+				require.NoError(t, err)
+				require.True(t, ok)
+			}
+		})
+	}
+}
+
+func TestCiphersuiteSigningWithDkg(t *testing.T) {
+	for _, suite := range []Ciphersuite{Ed25519Sha512{}, Secp256k1Sha256{}, P256Sha256{}} {
+		participants := runDkg(t, suite.Curve(), 3, 5)
+		cosigners := []uint32{1, 2, 5}
+		signers := make(map[uint32]*Signer, len(cosigners))
+		for _, id := range cosigners {
+			signer, err := NewCiphersuiteSigner(participants[id], id, 3, cosigners, suite)
+			require.NoError(t, err)
+			signers[id] = signer
+		}
+		msg := []byte("ciphersuite message")
+		result := runSigning(t, signers, msg)
+		vk := participants[1].VerificationKey
+		ok, err := Verify(suite.Curve(), suite, vk, msg, &Signature{Z: result.Z, C: result.C})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		if _, isEd25519 := suite.(Ed25519Sha512); isEd25519 {
+			// FROST(Ed25519, SHA-512) signatures are plain RFC 8032 signatures
+			sig, err := result.SerializeSignature()
+			require.NoError(t, err)
+			require.True(t, ed25519.Verify(vk.ToAffineCompressed(), msg, sig))
+			require.False(t, ed25519.Verify(vk.ToAffineCompressed(), []byte("other"), sig))
+		}
+	}
+}
+
+func TestCiphersuiteSignerInvalidCurve(t *testing.T) {
+	participants := runDkg(t, curves.ED25519(), 2, 3)
+	_, err := NewCiphersuiteSigner(participants[1], 1, 2, []uint32{1, 2}, Secp256k1Sha256{})
+	require.Error(t, err)
+	_, err = NewCiphersuiteSigner(participants[1], 1, 2, []uint32{1, 2}, nil)
+	require.Error(t, err)
+}
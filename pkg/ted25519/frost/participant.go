@@ -13,6 +13,7 @@ import (
 	"github.com/coinbase/kryptology/internal"
 	"github.com/coinbase/kryptology/pkg/core/curves"
 	"github.com/coinbase/kryptology/pkg/dkg/frost"
+	"github.com/coinbase/kryptology/pkg/sharing"
 )
 
 // Signer is a tSchnorr player performing the signing operation.
@@ -28,12 +29,17 @@ type Signer struct {
 	cosigners        []uint32
 	state            *state // Accumulated intermediate values associated with signing
 	challengeDeriver ChallengeDerive
+	bip340           bool                          // normalize the group key and nonce commitment to even Y as required by BIP-340
+	suite            Ciphersuite                   // when set, nonces, binding factors and challenges follow RFC 9591
+	nonces           map[uint64]*preprocessedNonce // unused nonce pairs generated by Preprocess
+	nextNonce        uint64                        // index of the next preprocessed nonce pair
 }
 
 type state struct {
 	// Round 1
 	capD, capE     curves.Point  // capD, capE are commitments this signer generates in signing round 1
 	smallD, smallE curves.Scalar // smallD, smallE are scalars this signer generates in signing round 1
+	preprocessed   bool          // preprocessed is set when the session was started by SignPreprocessed
 
 	// Round 2
 	commitments map[uint32]*Round1Bcast // Store commitments broadcast after signing round 1
@@ -81,3 +87,35 @@ func NewSigner(info *frost.DkgParticipant, id, thresh uint32, lcoeffs map[uint32
 		challengeDeriver: challengeDeriver,
 	}, nil
 }
+
+// NewCiphersuiteSigner creates a signer that follows RFC 9591 for the given ciphersuite.
+// The Lagrange coefficients of the cosigners are derived as in section 4.2 of the RFC.
+// Signatures interoperate with other implementations of the same ciphersuite.
+func NewCiphersuiteSigner(info *frost.DkgParticipant, id, thresh uint32, cosigners []uint32, suite Ciphersuite) (*Signer, error) {
+	if info == nil || info.Curve == nil || suite == nil || len(cosigners) == 0 {
+		return nil, internal.ErrNilArguments
+	}
+	if info.Curve.Name != suite.Curve().Name {
+		return nil, fmt.Errorf("ciphersuite curve %s does not match %s", suite.Curve().Name, info.Curve.Name)
+	}
+	limit := uint32(0)
+	for _, cosigner := range cosigners {
+		if cosigner > limit {
+			limit = cosigner
+		}
+	}
+	scheme, err := sharing.NewShamir(thresh, limit, info.Curve)
+	if err != nil {
+		return nil, err
+	}
+	lCoeffs, err := scheme.LagrangeCoeffs(cosigners)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := NewSigner(info, id, thresh, lCoeffs, cosigners, suite)
+	if err != nil {
+		return nil, err
+	}
+	signer.suite = suite
+	return signer, nil
+}
@@ -0,0 +1,139 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/coinbase/kryptology/internal"
+	"github.com/coinbase/kryptology/pkg/core/curves"
+)
+
+// PreprocessBcast contains nonce commitment pairs a signer publishes ahead of signing.
+// Commitments[i] is stored under the index Index+i.
+type PreprocessBcast struct {
+	Index       uint64
+	Commitments []*Round1Bcast
+}
+
+func (result *PreprocessBcast) Encode() ([]byte, error) {
+	for _, c := range result.Commitments {
+		gob.Register(c.Di)
+		gob.Register(c.Ei)
+	}
+	buf := &bytes.Buffer{}
+	enc := gob.NewEncoder(buf)
+	if err := enc.Encode(result); err != nil {
+		return nil, errors.Wrap(err, "couldn't encode preprocess broadcast")
+	}
+	return buf.Bytes(), nil
+}
+
+func (result *PreprocessBcast) Decode(input []byte) error {
+	buf := bytes.NewBuffer(input)
+	dec := gob.NewDecoder(buf)
+	if err := dec.Decode(result); err != nil {
+		return errors.Wrap(err, "couldn't decode preprocess broadcast")
+	}
+	return nil
+}
+
+// preprocessedNonce is a nonce pair generated in advance and its commitments
+type preprocessedNonce struct {
+	capD, capE     curves.Point
+	smallD, smallE curves.Scalar
+}
+
+// Preprocess generates k nonce pairs and returns their commitments to be published.
+// It can be called repeatedly, each call continues the indices of the previous one.
+// Every nonce pair is consumed by at most one call to SignPreprocessed.
+func (signer *Signer) Preprocess(k int) (*PreprocessBcast, error) {
+	if signer == nil || signer.curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if k <= 0 {
+		return nil, fmt.Errorf("number of nonce pairs must be positive")
+	}
+	if signer.nonces == nil {
+		signer.nonces = make(map[uint64]*preprocessedNonce, k)
+	}
+	result := &PreprocessBcast{
+		Index:       signer.nextNonce,
+		Commitments: make([]*Round1Bcast, k),
+	}
+	for i := 0; i < k; i++ {
+		di, ei, Di, Ei, err := signer.sampleNonces(crand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		signer.nonces[signer.nextNonce] = &preprocessedNonce{
+			capD:   Di,
+			capE:   Ei,
+			smallD: di,
+			smallE: ei,
+		}
+		signer.nextNonce++
+		result.Commitments[i] = &Round1Bcast{Di, Ei}
+	}
+	return result, nil
+}
+
+// RemainingNonces returns the number of preprocessed nonce pairs that have not been used yet
+func (signer *Signer) RemainingNonces() int {
+	return len(signer.nonces)
+}
+
+// SignPreprocessed computes this signer's signature share over msg in a single round
+// using the preprocessed nonce pair stored under index. commitments holds the nonce
+// commitment pair chosen for each cosigner and must contain the pair at index of this signer.
+// The nonce pair is deleted before the share is computed so it can never be used again.
+// A new signing session is started, so the result is aggregated with SignRound3 as usual.
+// It fails while an interactive session started by SignRound1 is in progress; a previous
+// preprocessed session that was not aggregated is abandoned.
+func (signer *Signer) SignPreprocessed(msg []byte, index uint64, commitments map[uint32]*Round1Bcast) (*Round2Bcast, error) {
+	if signer == nil || signer.curve == nil || signer.state == nil {
+		return nil, internal.ErrNilArguments
+	}
+	if (signer.round == 2 || signer.round == 3) && !signer.state.preprocessed {
+		return nil, internal.ErrInvalidRound
+	}
+	nonce, ok := signer.nonces[index]
+	if !ok {
+		if index < signer.nextNonce {
+			return nil, fmt.Errorf("nonce commitment %d has already been used", index)
+		}
+		return nil, fmt.Errorf("unknown nonce commitment %d", index)
+	}
+	own, ok := commitments[signer.id]
+	if !ok || own == nil || own.Di == nil || own.Ei == nil ||
+		!own.Di.Equal(nonce.capD) || !own.Ei.Equal(nonce.capE) {
+		return nil, fmt.Errorf("commitment of this signer does not match nonce commitment %d", index)
+	}
+	delete(signer.nonces, index)
+
+	round, st := signer.round, signer.state
+	signer.state = &state{
+		capD:         nonce.capD,
+		capE:         nonce.capE,
+		smallD:       nonce.smallD,
+		smallE:       nonce.smallE,
+		preprocessed: true,
+	}
+	signer.round = 2
+	out, err := signer.SignRound2(msg, commitments)
+	if err != nil {
+		// The nonce pair stays deleted, but the signer returns to where it was
+		signer.round, signer.state = round, st
+		return nil, err
+	}
+	return out, nil
+}
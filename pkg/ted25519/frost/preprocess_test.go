@@ -0,0 +1,150 @@
+//
+// Copyright Coinbase, Inc. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package frost
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// selectCommitments picks the preprocessed commitment with index from each signer
+func selectCommitments(bcasts map[uint32]*PreprocessBcast, index uint64) map[uint32]*Round1Bcast {
+	commitments := make(map[uint32]*Round1Bcast, len(bcasts))
+	for id, b := range bcasts {
+		commitments[id] = b.Commitments[index-b.Index]
+	}
+	return commitments
+}
+
+func TestPreprocessedSigningWorks(t *testing.T) {
+	signer1, signer2 := PrepareNewSigners(t)
+	signers := map[uint32]*Signer{1: signer1, 2: signer2}
+	const k = 4
+
+	bcasts := make(map[uint32]*PreprocessBcast, len(signers))
+	for id, s := range signers {
+		b, err := s.Preprocess(k)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), b.Index)
+		require.Len(t, b.Commitments, k)
+
+		// Published commitments survive serialization
+		data, err := b.Encode()
+		require.NoError(t, err)
+		decoded := new(PreprocessBcast)
+		require.NoError(t, decoded.Decode(data))
+		require.Equal(t, b.Index, decoded.Index)
+		require.True(t, b.Commitments[k-1].Di.Equal(decoded.Commitments[k-1].Di))
+		bcasts[id] = decoded
+	}
+
+	// Every nonce pair signs one message with a single round of communication
+	for index := uint64(0); index < k; index++ {
+		msg := []byte(fmt.Sprintf("preprocessed message %d", index))
+		commitments := selectCommitments(bcasts, index)
+		round3Input := make(map[uint32]*Round2Bcast, len(signers))
+		for id, s := range signers {
+			out, err := s.SignPreprocessed(msg, index, commitments)
+			require.NoError(t, err)
+			round3Input[id] = out
+		}
+		result, err := signer1.SignRound3(round3Input)
+		require.NoError(t, err)
+		ok, err := Verify(testCurve, &Ed25519ChallengeDeriver{}, signer1.verificationKey, msg, &Signature{result.Z, result.C})
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, k-int(index)-1, signer1.RemainingNonces())
+	}
+
+	// Later batches continue the indices
+	b, err := signer1.Preprocess(2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(k), b.Index)
+	require.Equal(t, 2, signer1.RemainingNonces())
+}
+
+func TestPreprocessedNonceReplay(t *testing.T) {
+	signer1, signer2 := PrepareNewSigners(t)
+	b1, err := signer1.Preprocess(2)
+	require.NoError(t, err)
+	b2, err := signer2.Preprocess(2)
+	require.NoError(t, err)
+	bcasts := map[uint32]*PreprocessBcast{1: b1, 2: b2}
+	commitments := selectCommitments(bcasts, 0)
+
+	_, err = signer1.SignPreprocessed([]byte("first"), 0, commitments)
+	require.NoError(t, err)
+
+	// The same nonce pair must not sign a second message
+	_, err = signer1.SignPreprocessed([]byte("second"), 0, commitments)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already been used")
+
+	// Unknown indices are rejected
+	_, err = signer1.SignPreprocessed([]byte("second"), 5, commitments)
+	require.Error(t, err)
+
+	// The commitment of the signer must match the stored nonce pair and the pair is kept on mismatch
+	_, err = signer1.SignPreprocessed([]byte("second"), 1, commitments)
+	require.Error(t, err)
+	require.Equal(t, 1, signer1.RemainingNonces())
+	_, err = signer1.SignPreprocessed([]byte("second"), 1, selectCommitments(bcasts, 1))
+	require.NoError(t, err)
+	require.Equal(t, 0, signer1.RemainingNonces())
+}
+
+func TestPreprocessBadInput(t *testing.T) {
+	signer1, _ := PrepareNewSigners(t)
+	_, err := signer1.Preprocess(0)
+	require.Error(t, err)
+	var nilSigner *Signer
+	_, err = nilSigner.Preprocess(1)
+	require.Error(t, err)
+	_, err = signer1.SignPreprocessed([]byte("msg"), 0, nil)
+	require.Error(t, err)
+}
+
+func TestPreprocessedSigningDuringInteractiveSession(t *testing.T) {
+	signer1, signer2 := PrepareNewSigners(t)
+	b1, err := signer1.Preprocess(1)
+	require.NoError(t, err)
+	b2, err := signer2.Preprocess(1)
+	require.NoError(t, err)
+	commitments := selectCommitments(map[uint32]*PreprocessBcast{1: b1, 2: b2}, 0)
+
+	// An interactive session in progress is not overwritten
+	round1Out, err := signer1.SignRound1()
+	require.NoError(t, err)
+	_, err = signer1.SignPreprocessed([]byte("preprocessed"), 0, commitments)
+	require.Error(t, err)
+	require.Equal(t, 1, signer1.RemainingNonces())
+	require.True(t, signer1.state.capD.Equal(round1Out.Di))
+
+	// The interactive session completes once it is finished
+	round1Out2, err := signer2.SignRound1()
+	require.NoError(t, err)
+	msg := []byte("interactive")
+	round2Input := map[uint32]*Round1Bcast{1: round1Out, 2: round1Out2}
+	round3Input := make(map[uint32]*Round2Bcast, 2)
+	for id, s := range map[uint32]*Signer{1: signer1, 2: signer2} {
+		round3Input[id], err = s.SignRound2(msg, round2Input)
+		require.NoError(t, err)
+	}
+	_, err = signer1.SignPreprocessed([]byte("preprocessed"), 0, commitments)
+	require.Error(t, err)
+	result, err := signer1.SignRound3(round3Input)
+	require.NoError(t, err)
+	ok, err := Verify(testCurve, &Ed25519ChallengeDeriver{}, signer1.verificationKey, msg, &Signature{result.Z, result.C})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// A finished signer can sign with preprocessed nonces
+	_, err = signer1.SignPreprocessed([]byte("preprocessed"), 0, commitments)
+	require.NoError(t, err)
+}
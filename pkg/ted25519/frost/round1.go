@@ -10,6 +10,7 @@ import (
 	"bytes"
 	crand "crypto/rand"
 	"encoding/gob"
+	"io"
 
 	"github.com/pkg/errors"
 
@@ -43,6 +44,10 @@ func (result *Round1Bcast) Decode(input []byte) error {
 }
 
 func (signer *Signer) SignRound1() (*Round1Bcast, error) {
+	return signer.signRound1(crand.Reader)
+}
+
+func (signer *Signer) signRound1(reader io.Reader) (*Round1Bcast, error) {
 	// Make sure signer is not empty
 	if signer == nil || signer.curve == nil {
 		return nil, internal.ErrNilArguments
@@ -53,15 +58,11 @@ func (signer *Signer) SignRound1() (*Round1Bcast, error) {
 		return nil, internal.ErrInvalidRound
 	}
 
-	// Step 1 - Sample di, ei
-	di := signer.curve.Scalar.Random(crand.Reader)
-
-	ei := signer.curve.Scalar.Random(crand.Reader)
-
-	// Step 2 - Compute Di, Ei
-	Di := signer.curve.ScalarBaseMult(di)
-
-	Ei := signer.curve.ScalarBaseMult(ei)
+	// Step 1-2 - Sample di, ei and compute Di, Ei
+	di, ei, Di, Ei, err := signer.sampleNonces(reader)
+	if err != nil {
+		return nil, err
+	}
 
 	// Update round number
 	signer.round = 2
@@ -76,3 +77,21 @@ func (signer *Signer) SignRound1() (*Round1Bcast, error) {
 		Ei,
 	}, nil
 }
+
+// sampleNonces samples the nonces di, ei and computes their commitments Di, Ei
+func (signer *Signer) sampleNonces(reader io.Reader) (di, ei curves.Scalar, Di, Ei curves.Point, err error) {
+	if signer.suite != nil {
+		if di, err = nonceGenerate(signer.suite, signer.skShare, reader); err != nil {
+			return nil, nil, nil, nil, err
+		}
+		if ei, err = nonceGenerate(signer.suite, signer.skShare, reader); err != nil {
+			return nil, nil, nil, nil, err
+		}
+	} else {
+		di = signer.curve.Scalar.Random(reader)
+		ei = signer.curve.Scalar.Random(reader)
+	}
+	Di = signer.curve.ScalarBaseMult(di)
+	Ei = signer.curve.ScalarBaseMult(ei)
+	return di, ei, Di, Ei, nil
+}
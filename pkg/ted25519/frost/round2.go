@@ -50,6 +50,11 @@ func (signer *Signer) SignRound2(msg []byte, round2Input map[uint32]*Round1Bcast
 		return nil, internal.ErrNilArguments
 	}
 
+	// Make sure this signer takes part in the signing
+	if _, ok := round2Input[signer.id]; !ok {
+		return nil, fmt.Errorf("round2Input is missing the commitment of this signer")
+	}
+
 	// Make sure those private d is not empty and not zero
 	if signer.state.smallD == nil || signer.state.smallD.IsZero() {
 		return nil, fmt.Errorf("empty d or d is zero")
@@ -91,18 +96,16 @@ func (signer *Signer) SignRound2(msg []byte, round2Input map[uint32]*Round1Bcast
 	signer.state.commitments = round2Input
 
 	// Step 3-6
+	bindingFactors, err := signer.bindingFactors(msg, round2Input)
+	if err != nil {
+		return nil, err
+	}
 	R := signer.curve.NewIdentityPoint()
-	var ri curves.Scalar
+	ri := bindingFactors[signer.id]
 	Rs := make(map[uint32]curves.Point, signer.threshold)
 	for id, data := range round2Input {
-		// Construct the blob (j, m, {Dj, Ej})
-		blob := concatHashArray(id, msg, round2Input, signer.cosigners)
-
 		// Step 4 - rj = H(j,m,{Dj,Ej}_{j in [1...t]})
-		rj := signer.curve.Scalar.Hash(blob)
-		if signer.id == id {
-			ri = rj
-		}
+		rj := bindingFactors[id]
 
 		// Step 5 - R_j = D_j + r_j*E_j
 		rjEj := data.Ei.Mul(rj)
@@ -131,7 +134,7 @@ func (signer *Signer) SignRound2(msg []byte, round2Input map[uint32]*Round1Bcast
 
 	Liskic := Liski.Mul(c)
 
-	if R.IsNegative() {
+	if signer.negateNonce(R) {
 		signer.state.smallE = signer.state.smallE.Neg()
 		signer.state.smallD = signer.state.smallD.Neg()
 	}
@@ -157,6 +160,25 @@ func (signer *Signer) SignRound2(msg []byte, round2Input map[uint32]*Round1Bcast
 	}, nil
 }
 
+// bindingFactors computes the binding factor rj of every signer. Signers with a ciphersuite
+// follow RFC 9591, otherwise rj is the hash of concatHashArray.
+func (signer *Signer) bindingFactors(msg []byte, round2Input map[uint32]*Round1Bcast) (map[uint32]curves.Scalar, error) {
+	return bindingFactors(signer.curve, signer.suite, signer.verificationKey, msg, round2Input, signer.cosigners)
+}
+
+func bindingFactors(curve *curves.Curve, suite Ciphersuite, verificationKey curves.Point, msg []byte, round2Input map[uint32]*Round1Bcast, cosigners []uint32) (map[uint32]curves.Scalar, error) {
+	if suite != nil {
+		return computeBindingFactors(suite, verificationKey, round2Input, msg)
+	}
+	factors := make(map[uint32]curves.Scalar, len(round2Input))
+	for id := range round2Input {
+		// Construct the blob (j, m, {Dj, Ej})
+		blob := concatHashArray(id, msg, round2Input, cosigners)
+		factors[id] = curve.Scalar.Hash(blob)
+	}
+	return factors, nil
+}
+
 // concatHashArray puts id, msg and (Dj,Ej), j=1...t into a byte array
 func concatHashArray(id uint32, msg []byte, round2Input map[uint32]*Round1Bcast, cosigners []uint32) []byte {
 	var blob []byte
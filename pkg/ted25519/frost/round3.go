@@ -71,7 +71,7 @@ func (signer *Signer) SignRound3(round3Input map[uint32]*Round2Bcast) (*Round3Bc
 	// Step 1-3
 	// Step 1: For j in [1...t]
 	z := signer.curve.NewScalar()
-	negate := signer.state.sumR.IsNegative()
+	negate := signer.negateNonce(signer.state.sumR)
 	for id, data := range round3Input {
 		zj := data.Zi
 		vkj := data.Vki
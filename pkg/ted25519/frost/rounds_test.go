@@ -7,6 +7,7 @@
 package frost
 
 import (
+	crand "crypto/rand"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -402,3 +403,100 @@ func TestFullRoundsWorks(t *testing.T) {
 	require.Equal(t, result[1].C, result[3].C)
 	// require.Equal(t, c, result[3].C)
 }
+
+func TestSigningWithDerivedKeys(t *testing.T) {
+	participants := runDkg(t, testCurve, 2, 3)
+	chainCode := make([]byte, dkg.ChainCodeSize)
+	chainCode[31] = 0x5a
+	path := []uint32{0, 12}
+
+	signerIds := []uint32{2, 3}
+	scheme, err := sharing.NewShamir(2, 3, testCurve)
+	require.NoError(t, err)
+	lCoeffs, err := scheme.LagrangeCoeffs(signerIds)
+	require.NoError(t, err)
+	signers := make(map[uint32]*Signer, len(signerIds))
+	for _, id := range signerIds {
+		child, _, err := participants[id].DerivePath(chainCode, path)
+		require.NoError(t, err)
+		signers[id], err = NewSigner(child, id, 2, lCoeffs, signerIds, &Ed25519ChallengeDeriver{})
+		require.NoError(t, err)
+	}
+
+	msg := []byte("derived account")
+	result := runSigning(t, signers, msg)
+	vk, _, err := participants[1].DerivePath(chainCode, path)
+	require.NoError(t, err)
+	ok, err := Verify(testCurve, &Ed25519ChallengeDeriver{}, vk.VerificationKey, msg, &Signature{result.Z, result.C})
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, err = Verify(testCurve, &Ed25519ChallengeDeriver{}, participants[1].VerificationKey, msg, &Signature{result.Z, result.C})
+	require.Error(t, err)
+}
+
+func TestSigningWithHierarchicalShares(t *testing.T) {
+	// Two of three officers and three of six staff
+	scheme, err := sharing.NewHierarchical(testCurve, sharing.Level{Threshold: 2, Limit: 3}, sharing.Level{Threshold: 5, Limit: 6})
+	require.NoError(t, err)
+	secret := testCurve.Scalar.Random(crand.Reader)
+	verifier, shares, err := scheme.Split(secret, crand.Reader)
+	require.NoError(t, err)
+
+	signerIds := []uint32{1, 3, 4, 6, 9}
+	coefficients, err := scheme.BirkhoffCoeffs(signerIds)
+	require.NoError(t, err)
+	signers := make(map[uint32]*Signer, len(signerIds))
+	for _, id := range signerIds {
+		sk, err := testCurve.Scalar.SetBytes(shares[id-1].Value)
+		require.NoError(t, err)
+		info := &dkg.DkgParticipant{
+			Curve:           testCurve,
+			Id:              id,
+			SkShare:         sk,
+			VkShare:         testCurve.ScalarBaseMult(sk),
+			VerificationKey: verifier.Commitments[0],
+		}
+		signers[id], err = NewSigner(info, id, scheme.Threshold(), coefficients, signerIds, &Ed25519ChallengeDeriver{})
+		require.NoError(t, err)
+	}
+
+	msg := []byte("hierarchical")
+	result := runSigning(t, signers, msg)
+	ok, err := Verify(testCurve, &Ed25519ChallengeDeriver{}, verifier.Commitments[0], msg, &Signature{result.Z, result.C})
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestSigningWithWeightedShares(t *testing.T) {
+	scheme, err := sharing.NewWeighted(4, map[uint32]uint32{1: 3, 2: 1, 3: 2}, testCurve)
+	require.NoError(t, err)
+	secret := testCurve.Scalar.Random(crand.Reader)
+	verifier, shares, err := scheme.Split(secret, crand.Reader)
+	require.NoError(t, err)
+
+	// The Lagrange coefficients are folded into the signing shares
+	signerIds := []uint32{1, 2}
+	ones := map[uint32]curves.Scalar{1: testCurve.Scalar.One(), 2: testCurve.Scalar.One()}
+	signers := make(map[uint32]*Signer, len(signerIds))
+	for _, id := range signerIds {
+		sk, err := scheme.SigningShare(shares[id], signerIds)
+		require.NoError(t, err)
+		vk, err := scheme.PublicSigningShare(verifier, id, signerIds)
+		require.NoError(t, err)
+		info := &dkg.DkgParticipant{
+			Curve:           testCurve,
+			Id:              id,
+			SkShare:         sk,
+			VkShare:         vk,
+			VerificationKey: verifier.Commitments[0],
+		}
+		signers[id], err = NewSigner(info, id, uint32(len(signerIds)), ones, signerIds, &Ed25519ChallengeDeriver{})
+		require.NoError(t, err)
+	}
+
+	msg := []byte("weighted")
+	result := runSigning(t, signers, msg)
+	ok, err := Verify(testCurve, &Ed25519ChallengeDeriver{}, verifier.Commitments[0], msg, &Signature{result.Z, result.C})
+	require.NoError(t, err)
+	require.True(t, ok)
+}
@@ -12,6 +12,7 @@ import (
 	"crypto/rand"
 	"crypto/subtle"
 	"fmt"
+	"math/big"
 
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
@@ -70,7 +71,7 @@ func (p *Prover) Prove(x curves.Scalar) (*Proof, error) {
 	if _, err = hash.Write(random.ToAffineCompressed()); err != nil {
 		return nil, errors.Wrap(err, "writing point K to hash in schnorr prove")
 	}
-	result.C, err = p.curve.Scalar.SetBytes(hash.Sum(nil))
+	result.C, err = challenge(p.curve, hash.Sum(nil))
 	if err != nil {
 		return nil, errors.Wrap(err, "writing point K to hash in schnorr prove")
 	}
@@ -100,12 +101,28 @@ func Verify(proof *Proof, curve *curves.Curve, basepoint curves.Point, uniqueSes
 	if _, err := hash.Write(random.ToAffineCompressed()); err != nil {
 		return errors.Wrap(err, "writing point K to hash in schnorr verify")
 	}
-	if subtle.ConstantTimeCompare(proof.C.Bytes(), hash.Sum(nil)) != 1 {
+	c, err := challenge(curve, hash.Sum(nil))
+	if err != nil {
+		return errors.Wrap(err, "computing challenge in schnorr verify")
+	}
+	if subtle.ConstantTimeCompare(proof.C.Bytes(), c.Bytes()) != 1 {
 		return fmt.Errorf("schnorr verification failed")
 	}
 	return nil
 }
 
+// challenge maps the transcript digest to a scalar, the same way for every digest of a given curve. On groups of
+// order at least 2^255, such as K256 and P256, the digest is the big-endian encoding of the challenge, which fails
+// for the negligible fraction of digests that are not canonical. On smaller groups, such as ed25519, ristretto255
+// and pallas, a large fraction of digests are not canonical, so the digest is always reduced with Hash.
+func challenge(curve *curves.Curve, digest []byte) (curves.Scalar, error) {
+	order := new(big.Int).Add(curve.Scalar.One().Neg().BigInt(), big.NewInt(1))
+	if order.BitLen() <= 255 {
+		return curve.Scalar.Hash(digest), nil
+	}
+	return curve.Scalar.SetBytes(digest)
+}
+
 // ProveCommit generates _and_ commits to a schnorr proof which is later revealed; see Functionality 7.
 // returns the Proof and Commitment.
 func (p *Prover) ProveCommit(x curves.Scalar) (*Proof, Commitment, error) {